@@ -21,11 +21,27 @@ import (
 )
 
 // DynamicPrefixSpec defines the desired state of DynamicPrefix
+// +kubebuilder:validation:XValidation:rule="!has(self.primaryUplink) || (has(self.uplinks) && self.uplinks.exists(u, u.name == self.primaryUplink))",message="primaryUplink must name one of uplinks"
 type DynamicPrefixSpec struct {
 	// Acquisition defines how to receive the IPv6 prefix
 	// +required
 	Acquisition AcquisitionSpec `json:"acquisition"`
 
+	// Uplinks acquire further prefixes that stay active alongside the one from
+	// acquisition, e.g. one delegation per ISP on a multihomed network. Address
+	// ranges and subnets are calculated for each, pools get blocks from all of
+	// them, and HA Services get one address per prefix.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=8
+	Uplinks []UplinkSpec `json:"uplinks,omitempty"`
+
+	// PrimaryUplink names the uplink whose prefix HA Services publish as
+	// external-dns target. Defaults to the prefix from acquisition.
+	// +optional
+	PrimaryUplink string `json:"primaryUplink,omitempty"`
+
 	// AddressRanges defines address ranges within the received prefix.
 	// Use this for Mode 1 (recommended): reserve a range within your /64 that
 	// your router's DHCPv6/SLAAC won't hand out. No BGP required.
@@ -41,9 +57,168 @@ type DynamicPrefixSpec struct {
 	// Transition defines graceful transition settings when prefix changes
 	// +optional
 	Transition *TransitionSpec `json:"transition,omitempty"`
+
+	// IPv4 tracks the WAN IPv4 address alongside the IPv6 prefix, so that
+	// dual-stack pools and Services follow both
+	// +optional
+	IPv4 *IPv4Spec `json:"ipv4,omitempty"`
+
+	// Pinholes opens inbound firewall pinholes on the upstream router for the
+	// addresses of LoadBalancer Services annotated with dynamic-prefix.io/pinholes: "true"
+	// +optional
+	Pinholes *PinholeSpec `json:"pinholes,omitempty"`
+
+	// Override holds the prefix during maintenance instead of following the
+	// receivers: either the current prefix or a forced one. Receivers keep
+	// running and what they observe is still reported in status.
+	// +optional
+	Override *OverrideSpec `json:"override,omitempty"`
+
+	// LostPrefixPolicy defines what happens when the lease of the current
+	// prefix expires without the receivers reporting a prefix.
+	// Defaults to retaining the last known prefix.
+	// +optional
+	LostPrefixPolicy *LostPrefixPolicySpec `json:"lostPrefixPolicy,omitempty"`
+
+	// ChangeRecords configures the retention of the PrefixChange objects
+	// recorded for each prefix transition
+	// +optional
+	ChangeRecords *ChangeRecordsSpec `json:"changeRecords,omitempty"`
+
+	// ULA adds a stable RFC 4193 unique local /48 next to the dynamic prefix.
+	// The address ranges and subnets are calculated on it as well, and pools
+	// and HA Services annotated with dynamic-prefix.io/ula: "true" also carry
+	// its blocks and addresses, which never change.
+	// +optional
+	ULA *ULASpec `json:"ula,omitempty"`
+
+	// NPT publishes an RFC 6296 NPTv6 mapping between a stable internal
+	// prefix and the current external one, rendered as nftables and
+	// ip6tables rules into a ConfigMap that a router agent can apply
+	// +optional
+	NPT *NPTSpec `json:"npt,omitempty"`
+}
+
+// NPTSpec configures the NPTv6 mapping output
+type NPTSpec struct {
+	// InternalPrefix is the stable prefix hosts are numbered from, e.g. a
+	// fixed ULA. Defaults to the ULA prefix when spec.ula is set. It is
+	// mapped at the length of the external prefix, so it must not be longer.
+	// +optional
+	InternalPrefix string `json:"internalPrefix,omitempty"`
+
+	// Interface restricts the rules to the upstream interface, e.g. wan0
+	// +optional
+	// +kubebuilder:validation:MaxLength=15
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.-]+$`
+	Interface string `json:"interface,omitempty"`
+
+	// ConfigMap is where the mapping and the rules are rendered
+	// +required
+	ConfigMap NPTConfigMapSpec `json:"configMap"`
+}
+
+// NPTConfigMapSpec references the ConfigMap receiving the NPTv6 rules
+type NPTConfigMapSpec struct {
+	// Namespace of the ConfigMap
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Name of the ConfigMap. Defaults to "<DynamicPrefix name>-npt".
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// ULASpec configures the unique local companion prefix
+type ULASpec struct {
+	// Prefix pins the ULA /48, e.g. to keep it when the DynamicPrefix is
+	// recreated. When unset, a prefix is generated from the object's UID
+	// and persisted in status.ula.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[fF][dD][0-9a-fA-F:]*/48$`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// UplinkSpec acquires a prefix that is active at the same time as the main one
+type UplinkSpec struct {
+	// Name identifies the uplink, e.g. after its ISP
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// Acquisition defines how to receive the uplink's prefix
+	// +required
+	Acquisition AcquisitionSpec `json:"acquisition"`
+}
+
+// ChangeRecordsSpec defines how long PrefixChange records are kept
+type ChangeRecordsSpec struct {
+	// MaxCount is the number of PrefixChange records kept for this DynamicPrefix
+	// +kubebuilder:default=50
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	// +optional
+	MaxCount int `json:"maxCount,omitempty"`
+
+	// MaxAge deletes PrefixChange records older than this, even below MaxCount
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+// LostPrefixAction selects what happens to a lost prefix
+// +kubebuilder:validation:Enum=retain;withdraw
+type LostPrefixAction string
+
+const (
+	// LostPrefixActionRetain keeps the last known prefix, for the grace period if one is set
+	LostPrefixActionRetain LostPrefixAction = "retain"
+	// LostPrefixActionWithdraw removes the prefix from pools, Services and BGP advertisements at once
+	LostPrefixActionWithdraw LostPrefixAction = "withdraw"
+)
+
+// LostPrefixPolicySpec defines how a lost prefix is handled
+type LostPrefixPolicySpec struct {
+	// Action is "retain" to keep the last known prefix or "withdraw" to stop using it
+	// +kubebuilder:default=retain
+	// +optional
+	Action LostPrefixAction `json:"action,omitempty"`
+
+	// GracePeriod limits how long a retained prefix is kept after its lease
+	// expired; it is withdrawn afterwards. Without it the prefix is retained
+	// until the receivers report a prefix again.
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// OverrideMode selects how spec.override holds the prefix
+// +kubebuilder:validation:Enum=freeze;force
+type OverrideMode string
+
+const (
+	// OverrideModeFreeze keeps the prefix that was current when the override began
+	OverrideModeFreeze OverrideMode = "freeze"
+	// OverrideModeForce uses the prefix given in the override
+	OverrideModeForce OverrideMode = "force"
+)
+
+// OverrideSpec defines a manual prefix override
+type OverrideSpec struct {
+	// Mode is "freeze" to keep the current prefix or "force" to use Prefix
+	// +required
+	Mode OverrideMode `json:"mode"`
+
+	// Prefix is the IPv6 prefix to use in force mode, in CIDR notation
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// ExpiresAt ends the override; the receivers' prefix is followed again afterwards.
+	// Without it the override lasts until it is removed.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
 }
 
 // AcquisitionSpec defines how to acquire/receive the IPv6 prefix
+// +kubebuilder:validation:XValidation:rule="!has(self.sources) || self.sources.all(s, !has(s.crossCheck) || self.sources.exists(o, o.name == s.crossCheck.within))",message="sources[].crossCheck.within must name one of sources"
 type AcquisitionSpec struct {
 	// DHCPv6PD configures DHCPv6 Prefix Delegation to receive prefix from upstream router
 	// +optional
@@ -52,28 +227,374 @@ type AcquisitionSpec struct {
 	// RouterAdvertisement configures Router Advertisement monitoring as fallback
 	// +optional
 	RouterAdvertisement *RouterAdvertisementSpec `json:"routerAdvertisement,omitempty"`
+
+	// Sources is an ordered list of prefix sources with an explicit failover policy.
+	// When set, DHCPv6PD and RouterAdvertisement above are ignored.
+	// The first healthy source in priority order provides the prefix.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=8
+	Sources []AcquisitionSourceSpec `json:"sources,omitempty"`
 }
 
-// DHCPv6PDSpec configures the DHCPv6 Prefix Delegation client
-type DHCPv6PDSpec struct {
-	// Interface is the network interface to receive the delegated prefix on
+// AcquisitionSourceSpec configures a single prefix source and its failover policy.
+// Exactly one of DHCPv6PD, RouterAdvertisement, Static or DNS must be set.
+// +kubebuilder:validation:XValidation:rule="[has(self.dhcpv6pd), has(self.routerAdvertisement), has(self.static), has(self.dns)].filter(x, x).size() == 1",message="exactly one of dhcpv6pd, routerAdvertisement, static or dns must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.crossCheck) || self.crossCheck.within != self.name",message="crossCheck.within must name another source"
+type AcquisitionSourceSpec struct {
+	// Name identifies this source (reported in status and used by cross-checks)
 	// +required
 	// +kubebuilder:validation:MinLength=1
-	Interface string `json:"interface"`
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// Priority orders the sources; lower values are preferred.
+	// Sources with equal priority keep their list order.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Priority int `json:"priority,omitempty"`
+
+	// DHCPv6PD uses a DHCPv6 Prefix Delegation client as this source
+	// +optional
+	DHCPv6PD *DHCPv6PDSpec `json:"dhcpv6pd,omitempty"`
+
+	// RouterAdvertisement uses Router Advertisement monitoring as this source
+	// +optional
+	RouterAdvertisement *RouterAdvertisementSpec `json:"routerAdvertisement,omitempty"`
+
+	// Static uses a fixed, manually configured prefix as this source
+	// +optional
+	Static *StaticSourceSpec `json:"static,omitempty"`
+
+	// DNS derives the prefix from the AAAA record of a dynamic DNS hostname
+	// +optional
+	DNS *DNSSourceSpec `json:"dns,omitempty"`
+
+	// FailureThreshold is the number of consecutive failures after which
+	// this source is considered unhealthy and the next source takes over.
+	// +optional
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold int `json:"failureThreshold,omitempty"`
+
+	// FailbackDelay is how long this source must stay healthy again before
+	// it takes back over from a lower-priority source. Defaults to immediate failback.
+	// +optional
+	FailbackDelay *metav1.Duration `json:"failbackDelay,omitempty"`
+
+	// CrossCheck validates this source's prefix against another source
+	// +optional
+	CrossCheck *CrossCheckSpec `json:"crossCheck,omitempty"`
+}
+
+// StaticSourceSpec configures a fixed prefix source
+type StaticSourceSpec struct {
+	// Prefix is the IPv6 prefix in CIDR notation (e.g., "2001:db8:1234::/56")
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Prefix string `json:"prefix"`
+}
+
+// DNSSourceSpec configures a prefix source that follows a dynamic DNS hostname.
+// The hostname is resolved periodically and the prefix is the returned IPv6
+// address truncated to PrefixLength. No network privileges are needed.
+type DNSSourceSpec struct {
+	// Hostname is the name whose AAAA record tracks the prefix, e.g. "home.example.dyndns.org"
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Hostname string `json:"hostname"`
+
+	// Resolver is the DNS server to query, as an address with an optional port (default 53),
+	// e.g. "2001:4860:4860::8888" or "[2001:db8::53]:5353"
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Resolver string `json:"resolver"`
+
+	// PrefixLength is applied to the resolved address to derive the prefix, e.g. 56
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=128
+	PrefixLength int `json:"prefixLength"`
+
+	// Interval is how often the hostname is resolved. A query is not repeated
+	// before the TTL of the previous answer has expired.
+	// +optional
+	// +kubebuilder:default="1m"
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// CrossCheckSpec defines a consistency rule between two acquisition sources
+type CrossCheckSpec struct {
+	// Within names another source whose prefix must contain this source's prefix.
+	// For example, an RA /64 must lie inside the DHCPv6-PD /56.
+	// While the check fails, this source is not eligible to provide the prefix.
+	// The check is skipped while the referenced source has no prefix.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Within string `json:"within"`
+}
+
+// DHCPv6PDSpec configures the DHCPv6 Prefix Delegation client
+// +kubebuilder:validation:XValidation:rule="(has(self.interface) && self.interface != '') != has(self.interfaceSelector)",message="exactly one of interface and interfaceSelector must be set"
+// +kubebuilder:validation:XValidation:rule="!(has(self.serverAddress) && has(self.relay))",message="serverAddress and relay are mutually exclusive"
+type DHCPv6PDSpec struct {
+	// Interface is the network interface to receive the delegated prefix on.
+	// Exactly one of Interface and InterfaceSelector must be set.
+	// +optional
+	Interface string `json:"interface,omitempty"`
+
+	// InterfaceSelector selects the interface by MAC address, name pattern or
+	// default route, for nodes whose uplink is named differently depending on
+	// hardware. The selection is re-evaluated when links change.
+	// +optional
+	InterfaceSelector *InterfaceSelector `json:"interfaceSelector,omitempty"`
+
+	// NetworkNamespace is the path of the network namespace Interface lives in,
+	// such as /var/run/netns/uplink or /proc/1/ns/net. The client's sockets are
+	// opened inside it, so the operator does not need hostNetwork.
+	// Defaults to the operator's own network namespace.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	NetworkNamespace string `json:"networkNamespace,omitempty"`
 
 	// RequestedPrefixLength hints the desired prefix length to request
 	// +optional
 	// +kubebuilder:validation:Minimum=48
 	// +kubebuilder:validation:Maximum=64
 	RequestedPrefixLength *int `json:"requestedPrefixLength,omitempty"`
+
+	// ServerAddress is the unicast IPv6 address of the DHCPv6 server.
+	// When set, all messages are sent to this address instead of the
+	// All_DHCP_Relay_Agents_and_Servers multicast group.
+	// Without it, REQUEST and RENEW use the address from the server's
+	// Server Unicast option, if it sends one.
+	// +optional
+	ServerAddress string `json:"serverAddress,omitempty"`
+
+	// Relay runs the client on a routed interface, relaying its own messages
+	// to a DHCPv6 server or relay agent on another link.
+	// Mutually exclusive with ServerAddress.
+	// +optional
+	Relay *DHCPv6RelaySpec `json:"relay,omitempty"`
+
+	// Options adds client options to SOLICIT, REQUEST, RENEW and REBIND messages,
+	// for ISPs that only delegate prefixes to clients that identify themselves
+	// +optional
+	Options *DHCPv6ClientOptionsSpec `json:"options,omitempty"`
+
+	// Authentication authenticates DHCPv6 exchanges with the server
+	// +optional
+	Authentication *DHCPv6AuthenticationSpec `json:"authentication,omitempty"`
+
+	// Macvlan runs the client on a macvlan sub-interface of Interface with its own
+	// MAC address and DUID. The cluster then requests prefixes as a separate router
+	// next to, rather than in conflict with, a DHCPv6 client of the host on Interface.
+	// The sub-interface is created, kept up and removed by the operator.
+	// +optional
+	Macvlan *MacvlanSpec `json:"macvlan,omitempty"`
+}
+
+// InterfaceSelector selects a network interface by something other than its name.
+// Exactly one field must be set. If several interfaces match, interfaces that are
+// up are preferred, then the one with the lowest index.
+// +kubebuilder:validation:XValidation:rule="[has(self.macAddress) && self.macAddress != '', has(self.namePattern) && self.namePattern != '', has(self.nameRegex) && self.nameRegex != '', has(self.defaultRoute) && self.defaultRoute].filter(x, x).size() == 1",message="exactly one of macAddress, namePattern, nameRegex or defaultRoute must be set"
+type InterfaceSelector struct {
+	// MACAddress selects the interface with this hardware address
+	// +optional
+	// +kubebuilder:validation:Pattern=`^([0-9a-fA-F]{2}:){5}[0-9a-fA-F]{2}$`
+	MACAddress string `json:"macAddress,omitempty"`
+
+	// NamePattern selects interfaces whose name matches this shell glob, e.g. "enp*"
+	// +optional
+	NamePattern string `json:"namePattern,omitempty"`
+
+	// NameRegex selects interfaces whose name matches this regular expression,
+	// e.g. "^(eth|enp)[0-9]". The expression is not anchored.
+	// +optional
+	NameRegex string `json:"nameRegex,omitempty"`
+
+	// DefaultRoute selects the interface carrying the IPv6 default route.
+	// If there are several, the one with the lowest metric is used.
+	// +optional
+	DefaultRoute bool `json:"defaultRoute,omitempty"`
+}
+
+// MacvlanSpec defines the macvlan sub-interface used by the DHCPv6-PD client
+type MacvlanSpec struct {
+	// Name of the sub-interface. Defaults to a name derived from the DynamicPrefix.
+	// +optional
+	// +kubebuilder:validation:MaxLength=15
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.-]+$`
+	Name string `json:"name,omitempty"`
+
+	// MACAddress of the sub-interface. Defaults to a stable, locally administered
+	// address derived from the DynamicPrefix.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^([0-9a-fA-F]{2}:){5}[0-9a-fA-F]{2}$`
+	MACAddress string `json:"macAddress,omitempty"`
+
+	// Mode is the macvlan mode of the sub-interface
+	// +optional
+	// +kubebuilder:default=bridge
+	// +kubebuilder:validation:Enum=bridge;private;vepa
+	Mode string `json:"mode,omitempty"`
+}
+
+// DHCPv6ClientOptionsSpec defines additional options sent by the DHCPv6 client
+type DHCPv6ClientOptionsSpec struct {
+	// VendorClass is sent as the Vendor Class option (16)
+	// +optional
+	VendorClass *DHCPv6VendorClassSpec `json:"vendorClass,omitempty"`
+
+	// UserClass is sent as the User Class option (15), one entry per user class
+	// +optional
+	UserClass []DHCPv6OptionValue `json:"userClass,omitempty"`
+
+	// VendorOptions is sent as the Vendor-specific Information option (17)
+	// +optional
+	VendorOptions *DHCPv6VendorOptionsSpec `json:"vendorOptions,omitempty"`
+
+	// Raw adds options by code, in the given order
+	// +optional
+	Raw []DHCPv6RawOption `json:"raw,omitempty"`
+}
+
+// DHCPv6VendorClassSpec defines a Vendor Class option
+type DHCPv6VendorClassSpec struct {
+	// EnterpriseNumber is the IANA Private Enterprise Number of the vendor
+	// +required
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4294967295
+	EnterpriseNumber int64 `json:"enterpriseNumber"`
+
+	// Data contains the vendor class data items
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	Data []DHCPv6OptionValue `json:"data"`
+}
+
+// DHCPv6VendorOptionsSpec defines a Vendor-specific Information option
+type DHCPv6VendorOptionsSpec struct {
+	// EnterpriseNumber is the IANA Private Enterprise Number of the vendor
+	// +required
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4294967295
+	EnterpriseNumber int64 `json:"enterpriseNumber"`
+
+	// Options are the vendor-specific sub-options
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	Options []DHCPv6RawOption `json:"options"`
+}
+
+// DHCPv6RawOption is an option given by its code and payload
+type DHCPv6RawOption struct {
+	// Code is the option code
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Code int `json:"code"`
+
+	DHCPv6OptionValue `json:",inline"`
+}
+
+// DHCPv6OptionValue is an option payload.
+// At most one of Text, Hex or SecretKeyRef may be set; none means an empty payload.
+type DHCPv6OptionValue struct {
+	// Text is the payload as a string
+	// +optional
+	Text string `json:"text,omitempty"`
+
+	// Hex is the payload as hex bytes, optionally separated by colons (e.g., "00:01:02")
+	// +optional
+	// +kubebuilder:validation:Pattern=`^([0-9a-fA-F]{2}(:?[0-9a-fA-F]{2})*)?$`
+	Hex string `json:"hex,omitempty"`
+
+	// SecretKeyRef reads the payload from a Secret, for credentials and other sensitive values
+	// +optional
+	SecretKeyRef *SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// SecretKeySelector selects a key of a Secret
+type SecretKeySelector struct {
+	// Namespace of the Secret
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Name of the Secret
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key within the Secret
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+// DHCPv6AuthenticationSpec configures DHCPv6 authentication
+type DHCPv6AuthenticationSpec struct {
+	// Protocol is the authentication protocol:
+	// "delayed" signs messages with a shared key (RFC 3315 delayed authentication)
+	// +required
+	// +kubebuilder:validation:Enum=delayed
+	Protocol string `json:"protocol"`
+
+	// Realm is the DHCP realm of the delayed authentication key
+	// +optional
+	Realm string `json:"realm,omitempty"`
+
+	// KeyID identifies the delayed authentication key within the realm
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4294967295
+	KeyID int64 `json:"keyID,omitempty"`
+
+	// Key is the delayed authentication key. Use secretKeyRef to keep it out of the DynamicPrefix.
+	// +optional
+	Key *DHCPv6OptionValue `json:"key,omitempty"`
+}
+
+// DHCPv6RelaySpec configures relayed DHCPv6 operation.
+// The operator wraps its messages in RELAY-FORW messages and unwraps the RELAY-REPL answers,
+// acting as a relay agent for itself.
+type DHCPv6RelaySpec struct {
+	// Destination is the unicast IPv6 address of the upstream relay agent or server
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Destination string `json:"destination"`
+
+	// LinkAddress identifies the link of the client to the server.
+	// Defaults to the first global address of the interface.
+	// +optional
+	LinkAddress string `json:"linkAddress,omitempty"`
 }
 
 // RouterAdvertisementSpec configures Router Advertisement monitoring
+// +kubebuilder:validation:XValidation:rule="(has(self.interface) && self.interface != '') != has(self.interfaceSelector)",message="exactly one of interface and interfaceSelector must be set"
 type RouterAdvertisementSpec struct {
-	// Interface is the network interface to monitor for Router Advertisements
+	// Interface is the network interface to monitor for Router Advertisements.
+	// Exactly one of Interface and InterfaceSelector must be set.
 	// +optional
 	Interface string `json:"interface,omitempty"`
 
+	// InterfaceSelector selects the interface by MAC address, name pattern or
+	// default route. The selection is re-evaluated when links change.
+	// +optional
+	InterfaceSelector *InterfaceSelector `json:"interfaceSelector,omitempty"`
+
+	// NetworkNamespace is the path of the network namespace Interface lives in,
+	// such as /var/run/netns/uplink or /proc/1/ns/net. The NDP socket is opened
+	// inside it, so the operator does not need hostNetwork.
+	// Defaults to the operator's own network namespace.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	NetworkNamespace string `json:"networkNamespace,omitempty"`
+
 	// Enabled controls whether RA monitoring is active
 	// +optional
 	// +kubebuilder:default=true
@@ -168,13 +689,113 @@ type TransitionSpec struct {
 	// +kubebuilder:default=simple
 	Mode TransitionMode `json:"mode,omitempty"`
 
-	// MaxPrefixHistory is the maximum number of previous prefixes to retain in pool blocks.
-	// When a new prefix is received, historical prefixes beyond this limit are dropped.
+	// MaxPrefixHistory is the maximum number of previous prefixes kept in status.history.
+	// When a new prefix is received, the oldest entries beyond this limit are dropped.
+	// Pools and Services keep only draining prefixes, whatever this limit.
 	// +optional
 	// +kubebuilder:default=2
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=10
 	MaxPrefixHistory int `json:"maxPrefixHistory,omitempty"`
+
+	// DrainPeriod is how long a replaced prefix stays in pools and on Services.
+	// A prefix expires when its valid lifetime ends or the drain period passes,
+	// whichever comes first. Without a drain period, prefixes without a known
+	// lifetime keep draining until they drop out of the history.
+	// +optional
+	DrainPeriod *metav1.Duration `json:"drainPeriod,omitempty"`
+
+	// Phases orchestrates each prefix change in phases instead of letting
+	// pools, Services and DNS follow at once: add the new blocks, wait for
+	// the Services to get an address from the new prefix, move the DNS
+	// targets, drain for the drain period, then remove the old blocks.
+	// The drain period then starts when the DNS targets moved and defaults to 1h.
+	// +optional
+	Phases *TransitionPhasesSpec `json:"phases,omitempty"`
+}
+
+// TransitionPhasesSpec defines the timeouts of the phased transition.
+// A phase that times out is recorded and the transition moves on, so that a
+// stuck Service cannot hold the old prefix past its lease.
+type TransitionPhasesSpec struct {
+	// AddBlocksTimeout bounds the wait for every pool to carry the new prefix
+	// +optional
+	// +kubebuilder:default="5m"
+	AddBlocksTimeout *metav1.Duration `json:"addBlocksTimeout,omitempty"`
+
+	// ServiceIPsTimeout bounds the wait for every HA mode Service to get an
+	// address from the new prefix
+	// +optional
+	// +kubebuilder:default="10m"
+	ServiceIPsTimeout *metav1.Duration `json:"serviceIPsTimeout,omitempty"`
+
+	// DNSTimeout bounds the wait for every HA mode Service to publish its
+	// address in the new prefix as external-dns target
+	// +optional
+	// +kubebuilder:default="5m"
+	DNSTimeout *metav1.Duration `json:"dnsTimeout,omitempty"`
+
+	// RemoveBlocksTimeout bounds the wait for every pool to drop the old prefix
+	// +optional
+	// +kubebuilder:default="5m"
+	RemoveBlocksTimeout *metav1.Duration `json:"removeBlocksTimeout,omitempty"`
+}
+
+// IPv4Spec defines how the WAN IPv4 address is tracked
+type IPv4Spec struct {
+	// DHCPv4 observes the address a DHCPv4 client on the node assigns to an interface
+	// +required
+	DHCPv4 DHCPv4Spec `json:"dhcpv4"`
+}
+
+// DHCPv4Spec defines the interface whose DHCPv4 address is observed
+type DHCPv4Spec struct {
+	// Interface is the WAN interface configured by DHCPv4
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Interface string `json:"interface"`
+
+	// NetworkNamespace is the path of the network namespace Interface lives in.
+	// Defaults to the operator's own network namespace.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	NetworkNamespace string `json:"networkNamespace,omitempty"`
+}
+
+// PinholeProtocol selects how pinholes are opened on the router
+type PinholeProtocol string
+
+const (
+	// PinholeProtocolPCP uses the Port Control Protocol (RFC 6887)
+	PinholeProtocolPCP PinholeProtocol = "PCP"
+
+	// PinholeProtocolUPnP uses the UPnP IGDv2 WANIPv6FirewallControl service
+	PinholeProtocolUPnP PinholeProtocol = "UPnP"
+)
+
+// PinholeSpec defines how inbound firewall pinholes are opened on the router
+type PinholeSpec struct {
+	// Protocol used to open pinholes
+	// +required
+	// +kubebuilder:validation:Enum=PCP;UPnP
+	Protocol PinholeProtocol `json:"protocol"`
+
+	// PCPServer is the address of the PCP server, usually the router, with an
+	// optional port (default 5351), e.g. "fd00::1" or "[fd00::1]:5351".
+	// Required for PCP. The server must accept the THIRD_PARTY option.
+	// +optional
+	PCPServer string `json:"pcpServer,omitempty"`
+
+	// UPnPDescriptionURL is the URL of the router's UPnP device description,
+	// e.g. "http://192.168.1.1:5000/rootDesc.xml". Required for UPnP.
+	// +optional
+	UPnPDescriptionURL string `json:"upnpDescriptionURL,omitempty"`
+
+	// Lifetime of each pinhole. Pinholes are refreshed halfway through their
+	// lifetime, so they close on their own soon after the operator stops.
+	// Defaults to 1h.
+	// +optional
+	Lifetime *metav1.Duration `json:"lifetime,omitempty"`
 }
 
 // DynamicPrefixStatus defines the observed state of DynamicPrefix
@@ -187,18 +808,67 @@ type DynamicPrefixStatus struct {
 	// +optional
 	PrefixSource PrefixSource `json:"prefixSource,omitempty"`
 
+	// PrefixAcquiredAt is when the current prefix was first acquired
+	// +optional
+	PrefixAcquiredAt *metav1.Time `json:"prefixAcquiredAt,omitempty"`
+
+	// Override reports the active spec.override, if any
+	// +optional
+	Override *OverrideStatus `json:"override,omitempty"`
+
+	// LostPrefix reports a prefix whose lease expired without a replacement
+	// +optional
+	LostPrefix *LostPrefixStatus `json:"lostPrefix,omitempty"`
+
 	// LeaseExpiresAt indicates when the DHCPv6 lease expires
 	// +optional
 	LeaseExpiresAt *metav1.Time `json:"leaseExpiresAt,omitempty"`
 
+	// NetworkConfig contains configuration data the upstream server delivered with the prefix,
+	// such as the ISP's DNS resolvers
+	// +optional
+	NetworkConfig *NetworkConfigStatus `json:"networkConfig,omitempty"`
+
+	// IPv4 contains the WAN IPv4 address when spec.ipv4 is set
+	// +optional
+	IPv4 *IPv4Status `json:"ipv4,omitempty"`
+
+	// Acquisition reports which acquisition source is active and why,
+	// together with per-receiver diagnostics
+	// +optional
+	Acquisition *AcquisitionStatus `json:"acquisition,omitempty"`
+
 	// AddressRanges contains the calculated address ranges
 	// +optional
 	AddressRanges []AddressRangeStatus `json:"addressRanges,omitempty"`
 
-	// Subnets contains the calculated subnet CIDRs
+	// ULA reports the unique local companion prefix with its calculated
+	// address ranges and subnets
+	// +optional
+	ULA *ULAStatus `json:"ula,omitempty"`
+
+	// Uplinks reports the prefix of each of spec.uplinks with its calculated
+	// address ranges and subnets
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Uplinks []UplinkStatus `json:"uplinks,omitempty"`
+
+	// NPT reports the NPTv6 mapping rendered for spec.npt
+	// +optional
+	NPT *NPTStatus `json:"npt,omitempty"`
+
+	// Subnets contains the calculated subnet CIDRs.
+	// Keyed by name, so the BGP controller can own bgpAdvertisement on each entry.
+	// +listType=map
+	// +listMapKey=name
 	// +optional
 	Subnets []SubnetStatus `json:"subnets,omitempty"`
 
+	// Transition reports the phased transition to the current prefix (only with spec.transition.phases)
+	// +optional
+	Transition *TransitionStatus `json:"transition,omitempty"`
+
 	// History contains previous prefixes
 	// +optional
 	History []PrefixHistoryEntry `json:"history,omitempty"`
@@ -210,17 +880,229 @@ type DynamicPrefixStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// OverrideStatus reports an active prefix override
+type OverrideStatus struct {
+	// Mode is the override mode in effect
+	Mode OverrideMode `json:"mode"`
+
+	// Prefix is the prefix held by the override
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Since is when the override took effect
+	// +optional
+	Since *metav1.Time `json:"since,omitempty"`
+
+	// ExpiresAt is when the override ends
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// ObservedPrefix is the prefix the receivers currently report, which
+	// becomes current again once the override ends
+	// +optional
+	ObservedPrefix string `json:"observedPrefix,omitempty"`
+}
+
+// LostPrefixStatus reports a lost prefix and what was done about it
+type LostPrefixStatus struct {
+	// Prefix is the prefix that was lost
+	Prefix string `json:"prefix"`
+
+	// LostAt is when the lease of the prefix expired
+	// +optional
+	LostAt *metav1.Time `json:"lostAt,omitempty"`
+
+	// WithdrawAt is when a retained prefix will be withdrawn
+	// +optional
+	WithdrawAt *metav1.Time `json:"withdrawAt,omitempty"`
+
+	// Withdrawn is true once the prefix has been removed from pools, Services and BGP advertisements
+	// +optional
+	Withdrawn bool `json:"withdrawn,omitempty"`
+}
+
+// IPv4Status contains the observed WAN IPv4 address
+type IPv4Status struct {
+	// Address is the WAN IPv4 address, e.g. 203.0.113.7
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// Subnet is the subnet the address was assigned in, in CIDR notation, e.g. 203.0.113.0/29
+	// +optional
+	Subnet string `json:"subnet,omitempty"`
+
+	// Router is the IPv4 default gateway on the interface
+	// +optional
+	Router string `json:"router,omitempty"`
+
+	// LeaseExpiresAt is when the DHCPv4 lease of the address expires
+	// +optional
+	LeaseExpiresAt *metav1.Time `json:"leaseExpiresAt,omitempty"`
+
+	// PreviousAddress is the address held before the last change
+	// +optional
+	PreviousAddress string `json:"previousAddress,omitempty"`
+
+	// ChangedAt is when the address last changed
+	// +optional
+	ChangedAt *metav1.Time `json:"changedAt,omitempty"`
+}
+
+// NetworkConfigStatus contains configuration data received from a DHCPv6 server
+type NetworkConfigStatus struct {
+	// DNSServers are the recursive DNS servers
+	// +optional
+	DNSServers []string `json:"dnsServers,omitempty"`
+
+	// DomainSearch is the domain search list
+	// +optional
+	DomainSearch []string `json:"domainSearch,omitempty"`
+
+	// NTPServers are NTP server addresses or FQDNs
+	// +optional
+	NTPServers []string `json:"ntpServers,omitempty"`
+
+	// SNTPServers are SNTP server addresses
+	// +optional
+	SNTPServers []string `json:"sntpServers,omitempty"`
+
+	// SolMaxRT is the maximum SOLICIT retransmission time requested by the server
+	// +optional
+	SolMaxRT *metav1.Duration `json:"solMaxRT,omitempty"`
+}
+
 // PrefixSource indicates how a prefix was obtained
-// +kubebuilder:validation:Enum=dhcpv6-pd;router-advertisement;static;unknown
+// +kubebuilder:validation:Enum=dhcpv6-pd;router-advertisement;static;dns;unknown
 type PrefixSource string
 
 const (
 	PrefixSourceDHCPv6PD            PrefixSource = "dhcpv6-pd"
 	PrefixSourceRouterAdvertisement PrefixSource = "router-advertisement"
 	PrefixSourceStatic              PrefixSource = "static"
+	PrefixSourceDNS                 PrefixSource = "dns"
 	PrefixSourceUnknown             PrefixSource = "unknown"
 )
 
+// AcquisitionStatus reports the state of prefix acquisition
+type AcquisitionStatus struct {
+	// ActiveSource is the name of the source currently providing the prefix
+	// +optional
+	ActiveSource string `json:"activeSource,omitempty"`
+
+	// FailoverReason explains why the active source was last switched
+	// +optional
+	FailoverReason string `json:"failoverReason,omitempty"`
+
+	// LastFailoverTime is when the active source was last switched
+	// +optional
+	LastFailoverTime *metav1.Time `json:"lastFailoverTime,omitempty"`
+
+	// UsingFallback is true while a source other than the preferred one provides the prefix
+	// +optional
+	UsingFallback bool `json:"usingFallback,omitempty"`
+
+	// Receivers contains diagnostics for each prefix receiver
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Receivers []ReceiverStatus `json:"receivers,omitempty"`
+}
+
+// ReceiverStatus contains diagnostics for a single prefix receiver
+type ReceiverStatus struct {
+	// Name identifies the receiver (the source name when using spec.acquisition.sources)
+	Name string `json:"name"`
+
+	// Source is the type of the receiver
+	// +optional
+	Source PrefixSource `json:"source,omitempty"`
+
+	// Active is true if this receiver currently provides the prefix
+	// +optional
+	Active bool `json:"active,omitempty"`
+
+	// Interface is the network interface the receiver runs on
+	// +optional
+	Interface string `json:"interface,omitempty"`
+
+	// ConsecutiveFailures is the number of failures since the last success
+	// +optional
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"`
+
+	// LastError is the most recent error reported by the receiver
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// LastErrorTime is when the most recent error occurred
+	// +optional
+	LastErrorTime *metav1.Time `json:"lastErrorTime,omitempty"`
+
+	// RouterAdvertisement contains Router Advertisement diagnostics
+	// +optional
+	RouterAdvertisement *RouterAdvertisementDiagnostics `json:"routerAdvertisement,omitempty"`
+
+	// DHCPv6 contains DHCPv6-PD diagnostics
+	// +optional
+	DHCPv6 *DHCPv6Diagnostics `json:"dhcpv6,omitempty"`
+
+	// DNS contains dynamic DNS receiver diagnostics
+	// +optional
+	DNS *DNSDiagnostics `json:"dns,omitempty"`
+}
+
+// RouterAdvertisementDiagnostics contains Router Advertisement receiver diagnostics
+type RouterAdvertisementDiagnostics struct {
+	// LastReceivedTime is when the last Router Advertisement was received
+	// +optional
+	LastReceivedTime *metav1.Time `json:"lastReceivedTime,omitempty"`
+
+	// Router is the address of the router that sent the last Router Advertisement
+	// +optional
+	Router string `json:"router,omitempty"`
+}
+
+// DHCPv6Diagnostics contains DHCPv6-PD client diagnostics
+type DHCPv6Diagnostics struct {
+	// LastExchange is the last attempted exchange (solicit, renew or rebind)
+	// +optional
+	LastExchange string `json:"lastExchange,omitempty"`
+
+	// LastExchangeTime is when the last exchange finished
+	// +optional
+	LastExchangeTime *metav1.Time `json:"lastExchangeTime,omitempty"`
+
+	// LastResult is "success" or the error of the last exchange
+	// +optional
+	LastResult string `json:"lastResult,omitempty"`
+
+	// ServerDUID identifies the DHCPv6 server that delegated the prefix
+	// +optional
+	ServerDUID string `json:"serverDUID,omitempty"`
+
+	// T1 is the renewal time of the current lease
+	// +optional
+	T1 *metav1.Duration `json:"t1,omitempty"`
+
+	// T2 is the rebind time of the current lease
+	// +optional
+	T2 *metav1.Duration `json:"t2,omitempty"`
+}
+
+// DNSDiagnostics contains dynamic DNS receiver diagnostics
+type DNSDiagnostics struct {
+	// LastQueryTime is when the hostname was last resolved
+	// +optional
+	LastQueryTime *metav1.Time `json:"lastQueryTime,omitempty"`
+
+	// Address is the IPv6 address of the last successful answer
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// TTL is the time to live of the last successful answer
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+}
+
 // AddressRangeStatus represents the current state of an address range
 type AddressRangeStatus struct {
 	// Name is the address range identifier
@@ -243,8 +1125,11 @@ type SubnetStatus struct {
 	// Name is the subnet identifier
 	Name string `json:"name"`
 
-	// CIDR is the calculated subnet in CIDR notation
-	CIDR string `json:"cidr"`
+	// CIDR is the calculated subnet in CIDR notation.
+	// Empty while an entry the DynamicPrefix controller no longer lists still
+	// carries the bgpAdvertisement of the BGP controller, until that drops it.
+	// +optional
+	CIDR string `json:"cidr,omitempty"`
 
 	// BGPAdvertisement is the name of the managed CiliumBGPAdvertisement resource.
 	// Only set when bgp.advertise is true for this subnet.
@@ -252,6 +1137,74 @@ type SubnetStatus struct {
 	BGPAdvertisement string `json:"bgpAdvertisement,omitempty"`
 }
 
+// ULAStatus reports the unique local companion prefix
+type ULAStatus struct {
+	// Prefix is the ULA /48 in CIDR notation
+	Prefix string `json:"prefix"`
+
+	// AddressRanges contains the address ranges calculated from the ULA prefix
+	// +optional
+	AddressRanges []AddressRangeStatus `json:"addressRanges,omitempty"`
+
+	// Subnets contains the subnets calculated from the ULA prefix
+	// +optional
+	Subnets []SubnetStatus `json:"subnets,omitempty"`
+}
+
+// NPTStatus reports the NPTv6 mapping between the internal and external prefix
+type NPTStatus struct {
+	// InternalPrefix is the internal side of the mapping, at the length of the external prefix
+	// +optional
+	InternalPrefix string `json:"internalPrefix,omitempty"`
+
+	// ExternalPrefix is the prefix the internal one is translated to.
+	// Empty while no prefix is active, in which case the rules translate nothing.
+	// +optional
+	ExternalPrefix string `json:"externalPrefix,omitempty"`
+
+	// ConfigMap is the namespace/name of the ConfigMap holding the rules
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+
+	// LastUpdated is when the rules were last rendered with a different mapping
+	// +optional
+	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
+}
+
+// UplinkStatus reports the prefix acquired on an uplink
+type UplinkStatus struct {
+	// Name is the uplink name from spec.uplinks
+	Name string `json:"name"`
+
+	// Prefix is the uplink's active IPv6 prefix in CIDR notation
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// PrefixSource indicates how the prefix was obtained
+	// +optional
+	PrefixSource PrefixSource `json:"prefixSource,omitempty"`
+
+	// PrefixAcquiredAt is when the prefix was first acquired
+	// +optional
+	PrefixAcquiredAt *metav1.Time `json:"prefixAcquiredAt,omitempty"`
+
+	// LeaseExpiresAt indicates when the prefix's lease expires
+	// +optional
+	LeaseExpiresAt *metav1.Time `json:"leaseExpiresAt,omitempty"`
+
+	// Primary is true for the uplink selected by spec.primaryUplink
+	// +optional
+	Primary bool `json:"primary,omitempty"`
+
+	// AddressRanges contains the address ranges calculated from the prefix
+	// +optional
+	AddressRanges []AddressRangeStatus `json:"addressRanges,omitempty"`
+
+	// Subnets contains the subnets calculated from the prefix
+	// +optional
+	Subnets []SubnetStatus `json:"subnets,omitempty"`
+}
+
 // PrefixHistoryEntry represents a historical prefix
 type PrefixHistoryEntry struct {
 	// Prefix is the historical prefix in CIDR notation
@@ -264,11 +1217,79 @@ type PrefixHistoryEntry struct {
 	// +optional
 	DeprecatedAt *metav1.Time `json:"deprecatedAt,omitempty"`
 
+	// ExpiresAt is when this prefix stops draining: the end of its valid
+	// lifetime or of the drain period, whichever comes first. Unset when neither is known.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
 	// State indicates the current state of this historical prefix
 	// +optional
 	State PrefixState `json:"state,omitempty"`
 }
 
+// TransitionPhase is a phase of a phased prefix transition
+// +kubebuilder:validation:Enum=AddingBlocks;AwaitingServiceIPs;UpdatingDNS;Draining;RemovingOldBlocks;Complete
+type TransitionPhase string
+
+const (
+	// TransitionPhaseAddingBlocks waits for every pool to carry the new prefix
+	TransitionPhaseAddingBlocks TransitionPhase = "AddingBlocks"
+	// TransitionPhaseAwaitingServiceIPs waits for HA mode Services to get an address from the new prefix
+	TransitionPhaseAwaitingServiceIPs TransitionPhase = "AwaitingServiceIPs"
+	// TransitionPhaseUpdatingDNS waits for HA mode Services to publish the new address as DNS target
+	TransitionPhaseUpdatingDNS TransitionPhase = "UpdatingDNS"
+	// TransitionPhaseDraining keeps the old prefix for the drain period
+	TransitionPhaseDraining TransitionPhase = "Draining"
+	// TransitionPhaseRemovingOldBlocks waits for every pool to drop the old prefix
+	TransitionPhaseRemovingOldBlocks TransitionPhase = "RemovingOldBlocks"
+	// TransitionPhaseComplete marks a finished transition
+	TransitionPhaseComplete TransitionPhase = "Complete"
+)
+
+// TransitionStatus reports a phased prefix transition
+type TransitionStatus struct {
+	// OldPrefix is the prefix being replaced
+	OldPrefix string `json:"oldPrefix"`
+
+	// NewPrefix is the prefix being introduced
+	NewPrefix string `json:"newPrefix"`
+
+	// Phase is the current phase
+	Phase TransitionPhase `json:"phase"`
+
+	// StartedAt is when the prefix change was detected
+	StartedAt metav1.Time `json:"startedAt"`
+
+	// PhaseDeadline is when the current phase times out, or for Draining when it ends
+	// +optional
+	PhaseDeadline *metav1.Time `json:"phaseDeadline,omitempty"`
+
+	// Phases records each phase entered so far, in order
+	// +optional
+	Phases []TransitionPhaseStatus `json:"phases,omitempty"`
+}
+
+// TransitionPhaseStatus records one phase of a transition
+type TransitionPhaseStatus struct {
+	// Phase is the phase this entry records
+	Phase TransitionPhase `json:"phase"`
+
+	// StartedAt is when the phase was entered
+	StartedAt metav1.Time `json:"startedAt"`
+
+	// CompletedAt is when the phase was left
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	// TimedOut is set when the phase was left because its timeout passed
+	// +optional
+	TimedOut bool `json:"timedOut,omitempty"`
+
+	// Message describes what the phase waited for
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 // PrefixState indicates the state of a prefix
 // +kubebuilder:validation:Enum=active;draining;expired
 type PrefixState string
@@ -292,6 +1313,22 @@ const (
 
 	// ConditionTypeBGPAdvertisementReady indicates whether BGP advertisements are configured
 	ConditionTypeBGPAdvertisementReady = "BGPAdvertisementReady"
+
+	// ConditionTypeOverridden indicates whether spec.override holds the prefix
+	ConditionTypeOverridden = "Overridden"
+
+	// ConditionTypeIPv4Acquired indicates whether the WAN IPv4 address is known (only with spec.ipv4)
+	ConditionTypeIPv4Acquired = "IPv4Acquired"
+
+	// ConditionTypeUplinksAcquired indicates whether every uplink has a prefix (only with spec.uplinks)
+	ConditionTypeUplinksAcquired = "UplinksAcquired"
+
+	// ConditionTypeTransitionComplete indicates whether the phased transition to the
+	// current prefix has finished; its reason names the current phase (only with spec.transition.phases)
+	ConditionTypeTransitionComplete = "TransitionComplete"
+
+	// ConditionTypeNPTReady indicates whether the NPTv6 rules are rendered (only with spec.npt)
+	ConditionTypeNPTReady = "NPTReady"
 )
 
 // +kubebuilder:object:root=true
@@ -299,6 +1336,11 @@ const (
 // +kubebuilder:resource:scope=Cluster,shortName=dp;dprefix
 // +kubebuilder:printcolumn:name="Prefix",type=string,JSONPath=`.status.currentPrefix`
 // +kubebuilder:printcolumn:name="Source",type=string,JSONPath=`.status.prefixSource`
+// +kubebuilder:printcolumn:name="Transition",type=string,JSONPath=`.status.transition.phase`,priority=1
+// +kubebuilder:printcolumn:name="ULA",type=string,JSONPath=`.status.ula.prefix`,priority=1
+// +kubebuilder:printcolumn:name="Uplinks",type=string,JSONPath=`.status.uplinks[*].prefix`,priority=1
+// +kubebuilder:printcolumn:name="IPv4",type=string,JSONPath=`.status.ipv4.address`,priority=1
+// +kubebuilder:printcolumn:name="Active",type=string,JSONPath=`.status.acquisition.activeSource`,priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // DynamicPrefix is the Schema for the dynamicprefixes API.
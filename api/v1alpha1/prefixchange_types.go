@@ -0,0 +1,112 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PrefixChangeSpec records a prefix transition. It is written once and never changes.
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+type PrefixChangeSpec struct {
+	// DynamicPrefix is the name of the DynamicPrefix whose prefix changed
+	// +required
+	DynamicPrefix string `json:"dynamicPrefix"`
+
+	// OldPrefix is the prefix that was replaced, in CIDR notation
+	// +required
+	OldPrefix string `json:"oldPrefix"`
+
+	// NewPrefix is the prefix that replaced it, in CIDR notation
+	// +required
+	NewPrefix string `json:"newPrefix"`
+
+	// Source indicates how the new prefix was obtained
+	// +optional
+	Source PrefixSource `json:"source,omitempty"`
+
+	// DetectedAt is when the operator detected the change
+	// +required
+	DetectedAt metav1.Time `json:"detectedAt"`
+}
+
+// PrefixChangeStatus records how the change propagated
+type PrefixChangeStatus struct {
+	// Updates lists the pools, Services and BGP advertisements updated for
+	// the new prefix, each with the time its update completed
+	// +optional
+	Updates []PrefixChangeUpdate `json:"updates,omitempty"`
+
+	// LastUpdateAt is when the most recent update completed
+	// +optional
+	LastUpdateAt *metav1.Time `json:"lastUpdateAt,omitempty"`
+}
+
+// PrefixChangeUpdate records the update of one resource
+type PrefixChangeUpdate struct {
+	// Kind is the kind of the updated resource, e.g. CiliumLoadBalancerIPPool
+	Kind string `json:"kind"`
+
+	// Namespace is the namespace of the updated resource, empty for cluster-scoped resources
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name is the name of the updated resource
+	Name string `json:"name"`
+
+	// CompletedAt is when the resource was updated for the new prefix
+	CompletedAt metav1.Time `json:"completedAt"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=pc
+// +kubebuilder:printcolumn:name="DynamicPrefix",type=string,JSONPath=`.spec.dynamicPrefix`
+// +kubebuilder:printcolumn:name="Old",type=string,JSONPath=`.spec.oldPrefix`
+// +kubebuilder:printcolumn:name="New",type=string,JSONPath=`.spec.newPrefix`
+// +kubebuilder:printcolumn:name="Source",type=string,JSONPath=`.spec.source`,priority=1
+// +kubebuilder:printcolumn:name="Detected",type=date,JSONPath=`.spec.detectedAt`
+// +kubebuilder:printcolumn:name="Last Update",type=date,JSONPath=`.status.lastUpdateAt`
+
+// PrefixChange is the Schema for the prefixchanges API.
+// It is an audit record of one prefix transition of a DynamicPrefix,
+// kept according to spec.changeRecords of that DynamicPrefix.
+type PrefixChange struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec records the transition
+	// +required
+	Spec PrefixChangeSpec `json:"spec"`
+
+	// Status records the resources updated for the transition
+	// +optional
+	Status PrefixChangeStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PrefixChangeList contains a list of PrefixChange
+type PrefixChangeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PrefixChange `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PrefixChange{}, &PrefixChangeList{})
+}
@@ -26,7 +26,7 @@ import (
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AcquisitionSpec) DeepCopyInto(out *AcquisitionSpec) {
+func (in *AcquisitionSourceSpec) DeepCopyInto(out *AcquisitionSourceSpec) {
 	*out = *in
 	if in.DHCPv6PD != nil {
 		in, out := &in.DHCPv6PD, &out.DHCPv6PD
@@ -36,10 +36,62 @@ func (in *AcquisitionSpec) DeepCopyInto(out *AcquisitionSpec) {
 	if in.RouterAdvertisement != nil {
 		in, out := &in.RouterAdvertisement, &out.RouterAdvertisement
 		*out = new(RouterAdvertisementSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Static != nil {
+		in, out := &in.Static, &out.Static
+		*out = new(StaticSourceSpec)
+		**out = **in
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(DNSSourceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailbackDelay != nil {
+		in, out := &in.FailbackDelay, &out.FailbackDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.CrossCheck != nil {
+		in, out := &in.CrossCheck, &out.CrossCheck
+		*out = new(CrossCheckSpec)
 		**out = **in
 	}
 }
 
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AcquisitionSourceSpec.
+func (in *AcquisitionSourceSpec) DeepCopy() *AcquisitionSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AcquisitionSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AcquisitionSpec) DeepCopyInto(out *AcquisitionSpec) {
+	*out = *in
+	if in.DHCPv6PD != nil {
+		in, out := &in.DHCPv6PD, &out.DHCPv6PD
+		*out = new(DHCPv6PDSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RouterAdvertisement != nil {
+		in, out := &in.RouterAdvertisement, &out.RouterAdvertisement
+		*out = new(RouterAdvertisementSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]AcquisitionSourceSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AcquisitionSpec.
 func (in *AcquisitionSpec) DeepCopy() *AcquisitionSpec {
 	if in == nil {
@@ -50,6 +102,32 @@ func (in *AcquisitionSpec) DeepCopy() *AcquisitionSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AcquisitionStatus) DeepCopyInto(out *AcquisitionStatus) {
+	*out = *in
+	if in.LastFailoverTime != nil {
+		in, out := &in.LastFailoverTime, &out.LastFailoverTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Receivers != nil {
+		in, out := &in.Receivers, &out.Receivers
+		*out = make([]ReceiverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AcquisitionStatus.
+func (in *AcquisitionStatus) DeepCopy() *AcquisitionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AcquisitionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AddressRangeSpec) DeepCopyInto(out *AddressRangeSpec) {
 	*out = *in
@@ -81,256 +159,1305 @@ func (in *AddressRangeStatus) DeepCopy() *AddressRangeStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DHCPv6PDSpec) DeepCopyInto(out *DHCPv6PDSpec) {
+func (in *ChangeRecordsSpec) DeepCopyInto(out *ChangeRecordsSpec) {
 	*out = *in
-	if in.RequestedPrefixLength != nil {
-		in, out := &in.RequestedPrefixLength, &out.RequestedPrefixLength
-		*out = new(int)
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(v1.Duration)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DHCPv6PDSpec.
-func (in *DHCPv6PDSpec) DeepCopy() *DHCPv6PDSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangeRecordsSpec.
+func (in *ChangeRecordsSpec) DeepCopy() *ChangeRecordsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DHCPv6PDSpec)
+	out := new(ChangeRecordsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DynamicPrefix) DeepCopyInto(out *DynamicPrefix) {
+func (in *CrossCheckSpec) DeepCopyInto(out *CrossCheckSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicPrefix.
-func (in *DynamicPrefix) DeepCopy() *DynamicPrefix {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrossCheckSpec.
+func (in *CrossCheckSpec) DeepCopy() *CrossCheckSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DynamicPrefix)
+	out := new(CrossCheckSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DynamicPrefix) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DHCPv4Spec) DeepCopyInto(out *DHCPv4Spec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DHCPv4Spec.
+func (in *DHCPv4Spec) DeepCopy() *DHCPv4Spec {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(DHCPv4Spec)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DynamicPrefixList) DeepCopyInto(out *DynamicPrefixList) {
+func (in *DHCPv6AuthenticationSpec) DeepCopyInto(out *DHCPv6AuthenticationSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]DynamicPrefix, len(*in))
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = new(DHCPv6OptionValue)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DHCPv6AuthenticationSpec.
+func (in *DHCPv6AuthenticationSpec) DeepCopy() *DHCPv6AuthenticationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DHCPv6AuthenticationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DHCPv6ClientOptionsSpec) DeepCopyInto(out *DHCPv6ClientOptionsSpec) {
+	*out = *in
+	if in.VendorClass != nil {
+		in, out := &in.VendorClass, &out.VendorClass
+		*out = new(DHCPv6VendorClassSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UserClass != nil {
+		in, out := &in.UserClass, &out.UserClass
+		*out = make([]DHCPv6OptionValue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VendorOptions != nil {
+		in, out := &in.VendorOptions, &out.VendorOptions
+		*out = new(DHCPv6VendorOptionsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Raw != nil {
+		in, out := &in.Raw, &out.Raw
+		*out = make([]DHCPv6RawOption, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicPrefixList.
-func (in *DynamicPrefixList) DeepCopy() *DynamicPrefixList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DHCPv6ClientOptionsSpec.
+func (in *DHCPv6ClientOptionsSpec) DeepCopy() *DHCPv6ClientOptionsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DynamicPrefixList)
+	out := new(DHCPv6ClientOptionsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DynamicPrefixList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DHCPv6Diagnostics) DeepCopyInto(out *DHCPv6Diagnostics) {
+	*out = *in
+	if in.LastExchangeTime != nil {
+		in, out := &in.LastExchangeTime, &out.LastExchangeTime
+		*out = (*in).DeepCopy()
 	}
-	return nil
+	if in.T1 != nil {
+		in, out := &in.T1, &out.T1
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.T2 != nil {
+		in, out := &in.T2, &out.T2
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DHCPv6Diagnostics.
+func (in *DHCPv6Diagnostics) DeepCopy() *DHCPv6Diagnostics {
+	if in == nil {
+		return nil
+	}
+	out := new(DHCPv6Diagnostics)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DynamicPrefixSpec) DeepCopyInto(out *DynamicPrefixSpec) {
+func (in *DHCPv6OptionValue) DeepCopyInto(out *DHCPv6OptionValue) {
 	*out = *in
-	in.Acquisition.DeepCopyInto(&out.Acquisition)
-	if in.AddressRanges != nil {
-		in, out := &in.AddressRanges, &out.AddressRanges
-		*out = make([]AddressRangeSpec, len(*in))
-		copy(*out, *in)
-	}
-	if in.Subnets != nil {
-		in, out := &in.Subnets, &out.Subnets
-		*out = make([]SubnetSpec, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Transition != nil {
-		in, out := &in.Transition, &out.Transition
-		*out = new(TransitionSpec)
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(SecretKeySelector)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicPrefixSpec.
-func (in *DynamicPrefixSpec) DeepCopy() *DynamicPrefixSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DHCPv6OptionValue.
+func (in *DHCPv6OptionValue) DeepCopy() *DHCPv6OptionValue {
 	if in == nil {
 		return nil
 	}
-	out := new(DynamicPrefixSpec)
+	out := new(DHCPv6OptionValue)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DynamicPrefixStatus) DeepCopyInto(out *DynamicPrefixStatus) {
+func (in *DHCPv6PDSpec) DeepCopyInto(out *DHCPv6PDSpec) {
 	*out = *in
-	if in.LeaseExpiresAt != nil {
-		in, out := &in.LeaseExpiresAt, &out.LeaseExpiresAt
-		*out = (*in).DeepCopy()
+	if in.InterfaceSelector != nil {
+		in, out := &in.InterfaceSelector, &out.InterfaceSelector
+		*out = new(InterfaceSelector)
+		**out = **in
 	}
-	if in.AddressRanges != nil {
-		in, out := &in.AddressRanges, &out.AddressRanges
-		*out = make([]AddressRangeStatus, len(*in))
-		copy(*out, *in)
+	if in.RequestedPrefixLength != nil {
+		in, out := &in.RequestedPrefixLength, &out.RequestedPrefixLength
+		*out = new(int)
+		**out = **in
 	}
-	if in.Subnets != nil {
-		in, out := &in.Subnets, &out.Subnets
-		*out = make([]SubnetStatus, len(*in))
-		copy(*out, *in)
+	if in.Relay != nil {
+		in, out := &in.Relay, &out.Relay
+		*out = new(DHCPv6RelaySpec)
+		**out = **in
 	}
-	if in.History != nil {
-		in, out := &in.History, &out.History
-		*out = make([]PrefixHistoryEntry, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = new(DHCPv6ClientOptionsSpec)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Authentication != nil {
+		in, out := &in.Authentication, &out.Authentication
+		*out = new(DHCPv6AuthenticationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Macvlan != nil {
+		in, out := &in.Macvlan, &out.Macvlan
+		*out = new(MacvlanSpec)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicPrefixStatus.
-func (in *DynamicPrefixStatus) DeepCopy() *DynamicPrefixStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DHCPv6PDSpec.
+func (in *DHCPv6PDSpec) DeepCopy() *DHCPv6PDSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DynamicPrefixStatus)
+	out := new(DHCPv6PDSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrefixHistoryEntry) DeepCopyInto(out *PrefixHistoryEntry) {
+func (in *DHCPv6RawOption) DeepCopyInto(out *DHCPv6RawOption) {
 	*out = *in
-	in.AcquiredAt.DeepCopyInto(&out.AcquiredAt)
-	if in.DeprecatedAt != nil {
-		in, out := &in.DeprecatedAt, &out.DeprecatedAt
-		*out = (*in).DeepCopy()
+	in.DHCPv6OptionValue.DeepCopyInto(&out.DHCPv6OptionValue)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DHCPv6RawOption.
+func (in *DHCPv6RawOption) DeepCopy() *DHCPv6RawOption {
+	if in == nil {
+		return nil
 	}
+	out := new(DHCPv6RawOption)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrefixHistoryEntry.
-func (in *PrefixHistoryEntry) DeepCopy() *PrefixHistoryEntry {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DHCPv6RelaySpec) DeepCopyInto(out *DHCPv6RelaySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DHCPv6RelaySpec.
+func (in *DHCPv6RelaySpec) DeepCopy() *DHCPv6RelaySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PrefixHistoryEntry)
+	out := new(DHCPv6RelaySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RouterAdvertisementSpec) DeepCopyInto(out *RouterAdvertisementSpec) {
+func (in *DHCPv6VendorClassSpec) DeepCopyInto(out *DHCPv6VendorClassSpec) {
 	*out = *in
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = make([]DHCPv6OptionValue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterAdvertisementSpec.
-func (in *RouterAdvertisementSpec) DeepCopy() *RouterAdvertisementSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DHCPv6VendorClassSpec.
+func (in *DHCPv6VendorClassSpec) DeepCopy() *DHCPv6VendorClassSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(RouterAdvertisementSpec)
+	out := new(DHCPv6VendorClassSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SubnetBGPSpec) DeepCopyInto(out *SubnetBGPSpec) {
+func (in *DHCPv6VendorOptionsSpec) DeepCopyInto(out *DHCPv6VendorOptionsSpec) {
 	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make([]DHCPv6RawOption, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetBGPSpec.
-func (in *SubnetBGPSpec) DeepCopy() *SubnetBGPSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DHCPv6VendorOptionsSpec.
+func (in *DHCPv6VendorOptionsSpec) DeepCopy() *DHCPv6VendorOptionsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SubnetBGPSpec)
+	out := new(DHCPv6VendorOptionsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SubnetSpec) DeepCopyInto(out *SubnetSpec) {
+func (in *DNSDiagnostics) DeepCopyInto(out *DNSDiagnostics) {
 	*out = *in
-	if in.BGP != nil {
-		in, out := &in.BGP, &out.BGP
-		*out = new(SubnetBGPSpec)
+	if in.LastQueryTime != nil {
+		in, out := &in.LastQueryTime, &out.LastQueryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetSpec.
-func (in *SubnetSpec) DeepCopy() *SubnetSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSDiagnostics.
+func (in *DNSDiagnostics) DeepCopy() *DNSDiagnostics {
 	if in == nil {
 		return nil
 	}
-	out := new(SubnetSpec)
+	out := new(DNSDiagnostics)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SubnetStatus) DeepCopyInto(out *SubnetStatus) {
+func (in *DNSSourceSpec) DeepCopyInto(out *DNSSourceSpec) {
 	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetStatus.
-func (in *SubnetStatus) DeepCopy() *SubnetStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSSourceSpec.
+func (in *DNSSourceSpec) DeepCopy() *DNSSourceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SubnetStatus)
+	out := new(DNSSourceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TransitionSpec) DeepCopyInto(out *TransitionSpec) {
+func (in *DynamicPrefix) DeepCopyInto(out *DynamicPrefix) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransitionSpec.
-func (in *TransitionSpec) DeepCopy() *TransitionSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicPrefix.
+func (in *DynamicPrefix) DeepCopy() *DynamicPrefix {
 	if in == nil {
 		return nil
 	}
-	out := new(TransitionSpec)
+	out := new(DynamicPrefix)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DynamicPrefix) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicPrefixList) DeepCopyInto(out *DynamicPrefixList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DynamicPrefix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicPrefixList.
+func (in *DynamicPrefixList) DeepCopy() *DynamicPrefixList {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicPrefixList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DynamicPrefixList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicPrefixSpec) DeepCopyInto(out *DynamicPrefixSpec) {
+	*out = *in
+	in.Acquisition.DeepCopyInto(&out.Acquisition)
+	if in.Uplinks != nil {
+		in, out := &in.Uplinks, &out.Uplinks
+		*out = make([]UplinkSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AddressRanges != nil {
+		in, out := &in.AddressRanges, &out.AddressRanges
+		*out = make([]AddressRangeSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Subnets != nil {
+		in, out := &in.Subnets, &out.Subnets
+		*out = make([]SubnetSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Transition != nil {
+		in, out := &in.Transition, &out.Transition
+		*out = new(TransitionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IPv4 != nil {
+		in, out := &in.IPv4, &out.IPv4
+		*out = new(IPv4Spec)
+		**out = **in
+	}
+	if in.Pinholes != nil {
+		in, out := &in.Pinholes, &out.Pinholes
+		*out = new(PinholeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Override != nil {
+		in, out := &in.Override, &out.Override
+		*out = new(OverrideSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LostPrefixPolicy != nil {
+		in, out := &in.LostPrefixPolicy, &out.LostPrefixPolicy
+		*out = new(LostPrefixPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ChangeRecords != nil {
+		in, out := &in.ChangeRecords, &out.ChangeRecords
+		*out = new(ChangeRecordsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ULA != nil {
+		in, out := &in.ULA, &out.ULA
+		*out = new(ULASpec)
+		**out = **in
+	}
+	if in.NPT != nil {
+		in, out := &in.NPT, &out.NPT
+		*out = new(NPTSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicPrefixSpec.
+func (in *DynamicPrefixSpec) DeepCopy() *DynamicPrefixSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicPrefixSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicPrefixStatus) DeepCopyInto(out *DynamicPrefixStatus) {
+	*out = *in
+	if in.PrefixAcquiredAt != nil {
+		in, out := &in.PrefixAcquiredAt, &out.PrefixAcquiredAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Override != nil {
+		in, out := &in.Override, &out.Override
+		*out = new(OverrideStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LostPrefix != nil {
+		in, out := &in.LostPrefix, &out.LostPrefix
+		*out = new(LostPrefixStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LeaseExpiresAt != nil {
+		in, out := &in.LeaseExpiresAt, &out.LeaseExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.NetworkConfig != nil {
+		in, out := &in.NetworkConfig, &out.NetworkConfig
+		*out = new(NetworkConfigStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IPv4 != nil {
+		in, out := &in.IPv4, &out.IPv4
+		*out = new(IPv4Status)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Acquisition != nil {
+		in, out := &in.Acquisition, &out.Acquisition
+		*out = new(AcquisitionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AddressRanges != nil {
+		in, out := &in.AddressRanges, &out.AddressRanges
+		*out = make([]AddressRangeStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.ULA != nil {
+		in, out := &in.ULA, &out.ULA
+		*out = new(ULAStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Uplinks != nil {
+		in, out := &in.Uplinks, &out.Uplinks
+		*out = make([]UplinkStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NPT != nil {
+		in, out := &in.NPT, &out.NPT
+		*out = new(NPTStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Subnets != nil {
+		in, out := &in.Subnets, &out.Subnets
+		*out = make([]SubnetStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Transition != nil {
+		in, out := &in.Transition, &out.Transition
+		*out = new(TransitionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]PrefixHistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicPrefixStatus.
+func (in *DynamicPrefixStatus) DeepCopy() *DynamicPrefixStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicPrefixStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPv4Spec) DeepCopyInto(out *IPv4Spec) {
+	*out = *in
+	out.DHCPv4 = in.DHCPv4
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPv4Spec.
+func (in *IPv4Spec) DeepCopy() *IPv4Spec {
+	if in == nil {
+		return nil
+	}
+	out := new(IPv4Spec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPv4Status) DeepCopyInto(out *IPv4Status) {
+	*out = *in
+	if in.LeaseExpiresAt != nil {
+		in, out := &in.LeaseExpiresAt, &out.LeaseExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.ChangedAt != nil {
+		in, out := &in.ChangedAt, &out.ChangedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPv4Status.
+func (in *IPv4Status) DeepCopy() *IPv4Status {
+	if in == nil {
+		return nil
+	}
+	out := new(IPv4Status)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InterfaceSelector) DeepCopyInto(out *InterfaceSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InterfaceSelector.
+func (in *InterfaceSelector) DeepCopy() *InterfaceSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(InterfaceSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LostPrefixPolicySpec) DeepCopyInto(out *LostPrefixPolicySpec) {
+	*out = *in
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LostPrefixPolicySpec.
+func (in *LostPrefixPolicySpec) DeepCopy() *LostPrefixPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LostPrefixPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LostPrefixStatus) DeepCopyInto(out *LostPrefixStatus) {
+	*out = *in
+	if in.LostAt != nil {
+		in, out := &in.LostAt, &out.LostAt
+		*out = (*in).DeepCopy()
+	}
+	if in.WithdrawAt != nil {
+		in, out := &in.WithdrawAt, &out.WithdrawAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LostPrefixStatus.
+func (in *LostPrefixStatus) DeepCopy() *LostPrefixStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LostPrefixStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MacvlanSpec) DeepCopyInto(out *MacvlanSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MacvlanSpec.
+func (in *MacvlanSpec) DeepCopy() *MacvlanSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MacvlanSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NPTConfigMapSpec) DeepCopyInto(out *NPTConfigMapSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NPTConfigMapSpec.
+func (in *NPTConfigMapSpec) DeepCopy() *NPTConfigMapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NPTConfigMapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NPTSpec) DeepCopyInto(out *NPTSpec) {
+	*out = *in
+	out.ConfigMap = in.ConfigMap
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NPTSpec.
+func (in *NPTSpec) DeepCopy() *NPTSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NPTSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NPTStatus) DeepCopyInto(out *NPTStatus) {
+	*out = *in
+	if in.LastUpdated != nil {
+		in, out := &in.LastUpdated, &out.LastUpdated
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NPTStatus.
+func (in *NPTStatus) DeepCopy() *NPTStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NPTStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkConfigStatus) DeepCopyInto(out *NetworkConfigStatus) {
+	*out = *in
+	if in.DNSServers != nil {
+		in, out := &in.DNSServers, &out.DNSServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DomainSearch != nil {
+		in, out := &in.DomainSearch, &out.DomainSearch
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NTPServers != nil {
+		in, out := &in.NTPServers, &out.NTPServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SNTPServers != nil {
+		in, out := &in.SNTPServers, &out.SNTPServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SolMaxRT != nil {
+		in, out := &in.SolMaxRT, &out.SolMaxRT
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkConfigStatus.
+func (in *NetworkConfigStatus) DeepCopy() *NetworkConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OverrideSpec) DeepCopyInto(out *OverrideSpec) {
+	*out = *in
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverrideSpec.
+func (in *OverrideSpec) DeepCopy() *OverrideSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OverrideSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OverrideStatus) DeepCopyInto(out *OverrideStatus) {
+	*out = *in
+	if in.Since != nil {
+		in, out := &in.Since, &out.Since
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverrideStatus.
+func (in *OverrideStatus) DeepCopy() *OverrideStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OverrideStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PinholeSpec) DeepCopyInto(out *PinholeSpec) {
+	*out = *in
+	if in.Lifetime != nil {
+		in, out := &in.Lifetime, &out.Lifetime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PinholeSpec.
+func (in *PinholeSpec) DeepCopy() *PinholeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PinholeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrefixChange) DeepCopyInto(out *PrefixChange) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrefixChange.
+func (in *PrefixChange) DeepCopy() *PrefixChange {
+	if in == nil {
+		return nil
+	}
+	out := new(PrefixChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PrefixChange) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrefixChangeList) DeepCopyInto(out *PrefixChangeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PrefixChange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrefixChangeList.
+func (in *PrefixChangeList) DeepCopy() *PrefixChangeList {
+	if in == nil {
+		return nil
+	}
+	out := new(PrefixChangeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PrefixChangeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrefixChangeSpec) DeepCopyInto(out *PrefixChangeSpec) {
+	*out = *in
+	in.DetectedAt.DeepCopyInto(&out.DetectedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrefixChangeSpec.
+func (in *PrefixChangeSpec) DeepCopy() *PrefixChangeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PrefixChangeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrefixChangeStatus) DeepCopyInto(out *PrefixChangeStatus) {
+	*out = *in
+	if in.Updates != nil {
+		in, out := &in.Updates, &out.Updates
+		*out = make([]PrefixChangeUpdate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastUpdateAt != nil {
+		in, out := &in.LastUpdateAt, &out.LastUpdateAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrefixChangeStatus.
+func (in *PrefixChangeStatus) DeepCopy() *PrefixChangeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PrefixChangeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrefixChangeUpdate) DeepCopyInto(out *PrefixChangeUpdate) {
+	*out = *in
+	in.CompletedAt.DeepCopyInto(&out.CompletedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrefixChangeUpdate.
+func (in *PrefixChangeUpdate) DeepCopy() *PrefixChangeUpdate {
+	if in == nil {
+		return nil
+	}
+	out := new(PrefixChangeUpdate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrefixHistoryEntry) DeepCopyInto(out *PrefixHistoryEntry) {
+	*out = *in
+	in.AcquiredAt.DeepCopyInto(&out.AcquiredAt)
+	if in.DeprecatedAt != nil {
+		in, out := &in.DeprecatedAt, &out.DeprecatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrefixHistoryEntry.
+func (in *PrefixHistoryEntry) DeepCopy() *PrefixHistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(PrefixHistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReceiverStatus) DeepCopyInto(out *ReceiverStatus) {
+	*out = *in
+	if in.LastErrorTime != nil {
+		in, out := &in.LastErrorTime, &out.LastErrorTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RouterAdvertisement != nil {
+		in, out := &in.RouterAdvertisement, &out.RouterAdvertisement
+		*out = new(RouterAdvertisementDiagnostics)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DHCPv6 != nil {
+		in, out := &in.DHCPv6, &out.DHCPv6
+		*out = new(DHCPv6Diagnostics)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(DNSDiagnostics)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReceiverStatus.
+func (in *ReceiverStatus) DeepCopy() *ReceiverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReceiverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouterAdvertisementDiagnostics) DeepCopyInto(out *RouterAdvertisementDiagnostics) {
+	*out = *in
+	if in.LastReceivedTime != nil {
+		in, out := &in.LastReceivedTime, &out.LastReceivedTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterAdvertisementDiagnostics.
+func (in *RouterAdvertisementDiagnostics) DeepCopy() *RouterAdvertisementDiagnostics {
+	if in == nil {
+		return nil
+	}
+	out := new(RouterAdvertisementDiagnostics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouterAdvertisementSpec) DeepCopyInto(out *RouterAdvertisementSpec) {
+	*out = *in
+	if in.InterfaceSelector != nil {
+		in, out := &in.InterfaceSelector, &out.InterfaceSelector
+		*out = new(InterfaceSelector)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouterAdvertisementSpec.
+func (in *RouterAdvertisementSpec) DeepCopy() *RouterAdvertisementSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RouterAdvertisementSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeySelector) DeepCopyInto(out *SecretKeySelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeySelector.
+func (in *SecretKeySelector) DeepCopy() *SecretKeySelector {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeySelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticSourceSpec) DeepCopyInto(out *StaticSourceSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticSourceSpec.
+func (in *StaticSourceSpec) DeepCopy() *StaticSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetBGPSpec) DeepCopyInto(out *SubnetBGPSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetBGPSpec.
+func (in *SubnetBGPSpec) DeepCopy() *SubnetBGPSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetBGPSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetSpec) DeepCopyInto(out *SubnetSpec) {
+	*out = *in
+	if in.BGP != nil {
+		in, out := &in.BGP, &out.BGP
+		*out = new(SubnetBGPSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetSpec.
+func (in *SubnetSpec) DeepCopy() *SubnetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetStatus) DeepCopyInto(out *SubnetStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetStatus.
+func (in *SubnetStatus) DeepCopy() *SubnetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransitionPhaseStatus) DeepCopyInto(out *TransitionPhaseStatus) {
+	*out = *in
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransitionPhaseStatus.
+func (in *TransitionPhaseStatus) DeepCopy() *TransitionPhaseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TransitionPhaseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransitionPhasesSpec) DeepCopyInto(out *TransitionPhasesSpec) {
+	*out = *in
+	if in.AddBlocksTimeout != nil {
+		in, out := &in.AddBlocksTimeout, &out.AddBlocksTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ServiceIPsTimeout != nil {
+		in, out := &in.ServiceIPsTimeout, &out.ServiceIPsTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DNSTimeout != nil {
+		in, out := &in.DNSTimeout, &out.DNSTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RemoveBlocksTimeout != nil {
+		in, out := &in.RemoveBlocksTimeout, &out.RemoveBlocksTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransitionPhasesSpec.
+func (in *TransitionPhasesSpec) DeepCopy() *TransitionPhasesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TransitionPhasesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransitionSpec) DeepCopyInto(out *TransitionSpec) {
+	*out = *in
+	if in.DrainPeriod != nil {
+		in, out := &in.DrainPeriod, &out.DrainPeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Phases != nil {
+		in, out := &in.Phases, &out.Phases
+		*out = new(TransitionPhasesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransitionSpec.
+func (in *TransitionSpec) DeepCopy() *TransitionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TransitionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransitionStatus) DeepCopyInto(out *TransitionStatus) {
+	*out = *in
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+	if in.PhaseDeadline != nil {
+		in, out := &in.PhaseDeadline, &out.PhaseDeadline
+		*out = (*in).DeepCopy()
+	}
+	if in.Phases != nil {
+		in, out := &in.Phases, &out.Phases
+		*out = make([]TransitionPhaseStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransitionStatus.
+func (in *TransitionStatus) DeepCopy() *TransitionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TransitionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ULASpec) DeepCopyInto(out *ULASpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ULASpec.
+func (in *ULASpec) DeepCopy() *ULASpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ULASpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ULAStatus) DeepCopyInto(out *ULAStatus) {
+	*out = *in
+	if in.AddressRanges != nil {
+		in, out := &in.AddressRanges, &out.AddressRanges
+		*out = make([]AddressRangeStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Subnets != nil {
+		in, out := &in.Subnets, &out.Subnets
+		*out = make([]SubnetStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ULAStatus.
+func (in *ULAStatus) DeepCopy() *ULAStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ULAStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UplinkSpec) DeepCopyInto(out *UplinkSpec) {
+	*out = *in
+	in.Acquisition.DeepCopyInto(&out.Acquisition)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UplinkSpec.
+func (in *UplinkSpec) DeepCopy() *UplinkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UplinkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UplinkStatus) DeepCopyInto(out *UplinkStatus) {
+	*out = *in
+	if in.PrefixAcquiredAt != nil {
+		in, out := &in.PrefixAcquiredAt, &out.PrefixAcquiredAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LeaseExpiresAt != nil {
+		in, out := &in.LeaseExpiresAt, &out.LeaseExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.AddressRanges != nil {
+		in, out := &in.AddressRanges, &out.AddressRanges
+		*out = make([]AddressRangeStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Subnets != nil {
+		in, out := &in.Subnets, &out.Subnets
+		*out = make([]SubnetStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UplinkStatus.
+func (in *UplinkStatus) DeepCopy() *UplinkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UplinkStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -25,10 +25,14 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -62,6 +66,7 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var standbyReceivers bool
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -80,6 +85,8 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&standbyReceivers, "standby-receivers", true,
+		"If set, every replica runs the passive prefix receivers, so that a new leader starts warm.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -162,6 +169,14 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "2949475c.dynamic-prefix.io",
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				// Only the ConfigMaps the operator renders are cached and watched
+				&corev1.ConfigMap{}: {
+					Label: labels.SelectorFromSet(labels.Set{controller.LabelManagedBy: controller.LabelManagedByValue}),
+				},
+			},
+		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -188,11 +203,24 @@ func main() {
 		mgr.GetScheme(),
 	)
 	dynamicPrefixReconciler.ReceiverFactory = receiverFactory
+	dynamicPrefixReconciler.APIReader = mgr.GetAPIReader()
+	dynamicPrefixReconciler.Recorder = mgr.GetEventRecorderFor("dynamicprefix-controller")
 	if err := dynamicPrefixReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DynamicPrefix")
 		os.Exit(1)
 	}
 
+	// Keep passive receivers warm on every replica, sharing listeners with the leader's receivers
+	if standbyReceivers {
+		if err := mgr.Add(&controller.StandbyReceivers{
+			Client:  mgr.GetClient(),
+			Factory: receiverFactory,
+		}); err != nil {
+			setupLog.Error(err, "unable to add standby receivers")
+			os.Exit(1)
+		}
+	}
+
 	// Set up PoolSync controller for Cilium resource synchronization
 	if err := (&controller.PoolSyncReconciler{
 		Client: mgr.GetClient(),
@@ -219,6 +247,24 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "BGPSync")
 		os.Exit(1)
 	}
+
+	// Set up Pinhole controller for router firewall pinholes
+	if err := (&controller.PinholeReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Pinhole")
+		os.Exit(1)
+	}
+
+	// Set up NPTSync controller for NPTv6 rule ConfigMaps
+	if err := (&controller.NPTSyncReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NPTSync")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
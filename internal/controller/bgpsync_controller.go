@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -79,6 +80,10 @@ func (r *BGPSyncReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 	// Collect subnets that need BGP advertisements
 	subnetsWithBGP := r.getSubnetsWithBGP(&dp)
+	if prefixWithdrawn(&dp) {
+		// A withdrawn prefix is no longer advertised
+		subnetsWithBGP = nil
+	}
 
 	// Track which advertisements we expect to exist
 	expectedAdvertisements := make(map[string]bool)
@@ -91,6 +96,10 @@ func (r *BGPSyncReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		if err := r.reconcileAdvertisement(ctx, &dp, &subnet); err != nil {
 			log.Error(err, "Failed to reconcile BGP advertisement", "subnet", subnet.Name)
 			// Continue with other subnets
+			continue
+		}
+		if err := recordPrefixChangeUpdate(ctx, r.Client, &dp, CiliumBGPAdvertisementGVK.Kind, "", advName); err != nil {
+			log.Error(err, "Failed to record BGP advertisement update in PrefixChange")
 		}
 	}
 
@@ -312,7 +321,8 @@ func (r *BGPSyncReconciler) deleteOrphanedAdvertisements(
 	return nil
 }
 
-// updateStatus updates the DynamicPrefix status with BGP advertisement information.
+// updateStatus server-side applies the BGP advertisement names and the
+// BGPAdvertisementReady condition, the only status fields this controller owns.
 func (r *BGPSyncReconciler) updateStatus(
 	ctx context.Context,
 	dp *dynamicprefixiov1alpha1.DynamicPrefix,
@@ -324,38 +334,48 @@ func (r *BGPSyncReconciler) updateStatus(
 		advNames[subnet.Name] = r.advertisementName(dp.Name, subnet.Name)
 	}
 
-	// Update subnet status with advertisement names
+	// Update subnet status with advertisement names. Only subnets the DynamicPrefix
+	// controller lists get one: an entry without a CIDR is left over from a subnet it
+	// dropped, and dropping the advertisement name removes the entry altogether.
 	statusChanged := false
+	var subnets []interface{}
 	for i := range dp.Status.Subnets {
-		advName, hasBGP := advNames[dp.Status.Subnets[i].Name]
-		if hasBGP {
-			if dp.Status.Subnets[i].BGPAdvertisement != advName {
-				dp.Status.Subnets[i].BGPAdvertisement = advName
-				statusChanged = true
-			}
-		} else {
-			if dp.Status.Subnets[i].BGPAdvertisement != "" {
-				dp.Status.Subnets[i].BGPAdvertisement = ""
-				statusChanged = true
-			}
+		advName := ""
+		if dp.Status.Subnets[i].CIDR != "" {
+			advName = advNames[dp.Status.Subnets[i].Name]
+		}
+		if dp.Status.Subnets[i].BGPAdvertisement != advName {
+			dp.Status.Subnets[i].BGPAdvertisement = advName
+			statusChanged = true
+		}
+		if advName != "" {
+			subnets = append(subnets, map[string]interface{}{
+				"name":             dp.Status.Subnets[i].Name,
+				"bgpAdvertisement": advName,
+			})
 		}
 	}
 
-	// Update BGPAdvertisementReady condition
-	condition := r.buildBGPCondition(ctx, dp, subnetsWithBGP)
-	existingCondition := r.findCondition(dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeBGPAdvertisementReady)
-	if existingCondition == nil || existingCondition.Status != condition.Status || existingCondition.Message != condition.Message {
-		r.setCondition(&dp.Status.Conditions, condition)
+	// Update BGPAdvertisementReady condition, keeping its LastTransitionTime unless the status flips
+	if meta.SetStatusCondition(&dp.Status.Conditions, r.buildBGPCondition(ctx, dp, subnetsWithBGP)) {
 		statusChanged = true
 	}
-
-	if statusChanged {
-		if err := r.Status().Update(ctx, dp); err != nil {
-			return fmt.Errorf("failed to update status: %w", err)
-		}
+	if !statusChanged {
+		return nil
 	}
 
-	return nil
+	condition, err := runtime.DefaultUnstructuredConverter.ToUnstructured(
+		meta.FindStatusCondition(dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeBGPAdvertisementReady))
+	if err != nil {
+		return fmt.Errorf("failed to convert condition: %w", err)
+	}
+	status := map[string]interface{}{
+		"conditions": []interface{}{condition},
+	}
+	if len(subnets) > 0 {
+		status["subnets"] = subnets
+	}
+	return applyDynamicPrefixStatus(ctx, r.Client, dp, status, FieldManagerBGPSync)
 }
 
 // buildBGPCondition builds the BGPAdvertisementReady condition.
@@ -405,27 +425,6 @@ func (r *BGPSyncReconciler) buildBGPCondition(
 	}
 }
 
-// findCondition finds a condition by type.
-func (r *BGPSyncReconciler) findCondition(conditions []metav1.Condition, conditionType string) *metav1.Condition {
-	for i := range conditions {
-		if conditions[i].Type == conditionType {
-			return &conditions[i]
-		}
-	}
-	return nil
-}
-
-// setCondition updates or adds a condition.
-func (r *BGPSyncReconciler) setCondition(conditions *[]metav1.Condition, condition metav1.Condition) {
-	for i := range *conditions {
-		if (*conditions)[i].Type == condition.Type {
-			(*conditions)[i] = condition
-			return
-		}
-	}
-	*conditions = append(*conditions, condition)
-}
-
 // SetupWithManager sets up the controller with the Manager.
 func (r *BGPSyncReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// Watch CiliumBGPAdvertisement for owned resources
@@ -361,6 +361,79 @@ var _ = Describe("BGPSync Controller", func() {
 	})
 })
 
+var _ = Describe("BGPSync Controller with a withdrawn prefix", func() {
+	const (
+		dpName     = "test-bgp-withdraw-dp"
+		subnetName = "lb"
+	)
+
+	ctx := context.Background()
+
+	AfterEach(func() {
+		dp := &dynamicprefixiov1alpha1.DynamicPrefix{}
+		dp.Name = dpName
+		_ = k8sClient.Delete(ctx, dp)
+
+		adv := &unstructured.Unstructured{}
+		adv.SetGroupVersionKind(CiliumBGPAdvertisementGVK)
+		adv.SetName("dp-" + dpName + "-" + subnetName)
+		_ = k8sClient.Delete(ctx, adv)
+	})
+
+	It("should store the withdrawal and then drop the advertisement name", func() {
+		dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+			ObjectMeta: metav1.ObjectMeta{Name: dpName},
+			Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+				Acquisition: dynamicprefixiov1alpha1.AcquisitionSpec{
+					RouterAdvertisement: &dynamicprefixiov1alpha1.RouterAdvertisementSpec{Interface: "eth0", Enabled: true},
+				},
+				Subnets: []dynamicprefixiov1alpha1.SubnetSpec{
+					{Name: subnetName, PrefixLength: 64, BGP: &dynamicprefixiov1alpha1.SubnetBGPSpec{Advertise: true}},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, dp)).To(Succeed())
+
+		prefixReconciler := &DynamicPrefixReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+		bgpReconciler := &BGPSyncReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+		req := reconcile.Request{NamespacedName: types.NamespacedName{Name: dpName}}
+
+		// The prefix controller lists the subnet, the BGP controller adds its advertisement name
+		Expect(k8sClient.Get(ctx, req.NamespacedName, dp)).To(Succeed())
+		original := dp.Status.DeepCopy()
+		dp.Status.CurrentPrefix = "2001:db8::/48"
+		dp.Status.Subnets = []dynamicprefixiov1alpha1.SubnetStatus{{Name: subnetName, CIDR: "2001:db8::/64"}}
+		Expect(prefixReconciler.updateStatus(ctx, dp, original)).To(Succeed())
+		_, err := bgpReconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Withdrawing drops the subnets; the API server must accept the entry the BGP controller still owns
+		Expect(k8sClient.Get(ctx, req.NamespacedName, dp)).To(Succeed())
+		Expect(dp.Status.Subnets).To(HaveLen(1))
+		Expect(dp.Status.Subnets[0].BGPAdvertisement).To(Equal("dp-" + dpName + "-" + subnetName))
+		original = dp.Status.DeepCopy()
+		dp.Status.LostPrefix = &dynamicprefixiov1alpha1.LostPrefixStatus{Prefix: "2001:db8::/48", Withdrawn: true}
+		dp.Status.CurrentPrefix = ""
+		dp.Status.Subnets = nil
+		Expect(prefixReconciler.updateStatus(ctx, dp, original)).To(Succeed())
+
+		Expect(k8sClient.Get(ctx, req.NamespacedName, dp)).To(Succeed())
+		Expect(dp.Status.LostPrefix).NotTo(BeNil())
+		Expect(dp.Status.LostPrefix.Withdrawn).To(BeTrue())
+
+		// The BGP controller then deletes the advertisement and drops the leftover entry
+		_, err = bgpReconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, req.NamespacedName, dp)).To(Succeed())
+		Expect(dp.Status.Subnets).To(BeEmpty())
+
+		adv := &unstructured.Unstructured{}
+		adv.SetGroupVersionKind(CiliumBGPAdvertisementGVK)
+		err = k8sClient.Get(ctx, types.NamespacedName{Name: "dp-" + dpName + "-" + subnetName}, adv)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
 // ============================================================================
 // Standard Go Unit Tests (run without envtest, use fake client)
 // ============================================================================
@@ -522,6 +595,7 @@ func TestBGPSyncReconciler_Reconcile_UpdateStatus(t *testing.T) {
 		WithScheme(scheme).
 		WithObjects(dp).
 		WithStatusSubresource(dp).
+		WithTypeConverters(newDynamicPrefixTypeConverter(t)...).
 		Build()
 
 	reconciler := &BGPSyncReconciler{
@@ -552,6 +626,12 @@ func TestBGPSyncReconciler_Reconcile_UpdateStatus(t *testing.T) {
 	if updatedDP.Status.Subnets[0].BGPAdvertisement != expectedAdvName {
 		t.Errorf("BGPAdvertisement = %q, want %q", updatedDP.Status.Subnets[0].BGPAdvertisement, expectedAdvName)
 	}
+	if updatedDP.Status.Subnets[0].CIDR != "2001:db8::/64" {
+		t.Errorf("CIDR = %q, want it kept by the BGP status apply", updatedDP.Status.Subnets[0].CIDR)
+	}
+	if updatedDP.Status.CurrentPrefix != "2001:db8::/48" {
+		t.Errorf("CurrentPrefix = %q, want it kept by the BGP status apply", updatedDP.Status.CurrentPrefix)
+	}
 
 	// Check condition
 	var bgpCondition *metav1.Condition
@@ -18,18 +18,29 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/netip"
+	"slices"
 	"sync"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
 	"github.com/jr42/dynamic-prefix-operator/internal/prefix"
@@ -37,12 +48,22 @@ import (
 
 const (
 	finalizerName = "dynamic-prefix.io/finalizer"
+
+	// defaultMaxPrefixHistory matches the default of spec.transition.maxPrefixHistory
+	defaultMaxPrefixHistory = 2
 )
 
 // ReceiverFactory creates prefix receivers for DynamicPrefix resources
 type ReceiverFactory interface {
-	// CreateReceiver creates a new receiver based on the acquisition spec
-	CreateReceiver(spec dynamicprefixiov1alpha1.AcquisitionSpec) (prefix.Receiver, error)
+	// CreateReceiver creates a new receiver for the named DynamicPrefix based on the acquisition spec
+	CreateReceiver(owner string, spec dynamicprefixiov1alpha1.AcquisitionSpec) (prefix.Receiver, error)
+}
+
+// IPv4ReceiverFactory creates receivers for the WAN IPv4 address. A
+// ReceiverFactory that also implements it enables spec.ipv4.
+type IPv4ReceiverFactory interface {
+	// CreateIPv4Receiver creates a new receiver from the IPv4 spec
+	CreateIPv4Receiver(spec *dynamicprefixiov1alpha1.IPv4Spec) (prefix.IPv4Receiver, error)
 }
 
 // DynamicPrefixReconciler reconciles a DynamicPrefix object
@@ -51,24 +72,66 @@ type DynamicPrefixReconciler struct {
 	Scheme          *runtime.Scheme
 	ReceiverFactory ReceiverFactory
 
+	// APIReader reads Secrets referenced by the acquisition spec; defaults to the client
+	APIReader client.Reader
+
+	// Recorder emits Kubernetes events for prefix losses; events are skipped when nil
+	Recorder record.EventRecorder
+
 	// receiversMu protects the receivers map
 	receiversMu sync.RWMutex
 	// receivers maps DynamicPrefix name to its active receiver
 	receivers map[string]prefix.Receiver
+	// receiverWatches maps DynamicPrefix name to the cancel func of its event watch
+	receiverWatches map[string]context.CancelFunc
+	// receiverSpecs maps DynamicPrefix name to the hash of the acquisition spec its receiver was created from
+	receiverSpecs map[string]string
+	// pendingReceivers maps DynamicPrefix name to a replacement receiver started after a spec change
+	pendingReceivers map[string]*pendingReceiver
+	// ipv4Receivers maps DynamicPrefix name to the receiver of its WAN IPv4 address
+	ipv4Receivers map[string]*ipv4Receiver
+	// uplinkReceivers maps DynamicPrefix name to the receivers of its spec.uplinks, by uplink name
+	uplinkReceivers map[string]map[string]*uplinkReceiver
+
+	// receiverEvents triggers a reconcile whenever a receiver reports an event,
+	// so that status reflects failures without waiting for the next requeue
+	receiverEvents chan event.GenericEvent
+}
+
+// pendingReceiver is a receiver for an updated acquisition spec that has not taken over yet
+type pendingReceiver struct {
+	receiver    prefix.Receiver
+	specHash    string
+	cancelWatch context.CancelFunc
+}
+
+// ipv4Receiver is a running IPv4 receiver and the spec it was created from
+type ipv4Receiver struct {
+	receiver    prefix.IPv4Receiver
+	spec        dynamicprefixiov1alpha1.IPv4Spec
+	cancelWatch context.CancelFunc
 }
 
 // NewDynamicPrefixReconciler creates a new reconciler with default configuration
 func NewDynamicPrefixReconciler(c client.Client, scheme *runtime.Scheme) *DynamicPrefixReconciler {
 	return &DynamicPrefixReconciler{
-		Client:    c,
-		Scheme:    scheme,
-		receivers: make(map[string]prefix.Receiver),
+		Client:           c,
+		Scheme:           scheme,
+		receivers:        make(map[string]prefix.Receiver),
+		receiverWatches:  make(map[string]context.CancelFunc),
+		receiverSpecs:    make(map[string]string),
+		pendingReceivers: make(map[string]*pendingReceiver),
+		ipv4Receivers:    make(map[string]*ipv4Receiver),
+		uplinkReceivers:  make(map[string]map[string]*uplinkReceiver),
+		receiverEvents:   make(chan event.GenericEvent, 100),
 	}
 }
 
 // +kubebuilder:rbac:groups=dynamic-prefix.io,resources=dynamicprefixes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=dynamic-prefix.io,resources=dynamicprefixes/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=dynamic-prefix.io,resources=dynamicprefixes/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -82,6 +145,8 @@ func (r *DynamicPrefixReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		r.cleanupReceiver(req.Name)
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	// The status as read, so the apply can be skipped when nothing changed
+	originalStatus := dp.Status.DeepCopy()
 
 	// Handle deletion
 	if !dp.DeletionTimestamp.IsZero() {
@@ -106,28 +171,60 @@ func (r *DynamicPrefixReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{RequeueAfter: time.Second}, nil
 	}
 
+	// The IPv4 address is tracked independently of the IPv6 prefix
+	r.updateIPv4Status(ctx, &dp)
+
+	// Uplink prefixes are active alongside the main one, whatever its state
+	uplinkExpiry := r.updateUplinkStatus(ctx, &dp, time.Now())
+
+	// The ULA companion prefix does not depend on any receiver
+	r.updateULAStatus(ctx, &dp)
+
 	// Get or create the receiver for this DynamicPrefix
 	receiver, err := r.getOrCreateReceiver(ctx, &dp)
 	if err != nil {
 		log.Error(err, "Failed to create receiver")
 		r.setCondition(&dp, dynamicprefixiov1alpha1.ConditionTypePrefixAcquired, metav1.ConditionFalse,
 			"ReceiverCreationFailed", err.Error())
-		if statusErr := r.Status().Update(ctx, &dp); statusErr != nil {
+		if statusErr := r.updateStatus(ctx, &dp, originalStatus); statusErr != nil {
 			log.Error(statusErr, "Failed to update status")
 		}
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 	}
 
-	// Get current prefix from receiver
-	currentPrefix := receiver.CurrentPrefix()
+	// Get current prefix from receiver, unless an override holds another one
+	observedPrefix := receiver.CurrentPrefix()
+	r.updateAcquisitionStatus(&dp, receiver)
+	currentPrefix, overrideExpiry := r.applyOverride(ctx, &dp, observedPrefix, time.Now())
 	if currentPrefix == nil {
 		log.Info("No prefix acquired yet")
-		r.setCondition(&dp, dynamicprefixiov1alpha1.ConditionTypePrefixAcquired, metav1.ConditionFalse,
-			"WaitingForPrefix", "Waiting to receive prefix from upstream")
-		if err := r.Status().Update(ctx, &dp); err != nil {
+		now := time.Now()
+		r.expireHistory(ctx, &dp, now)
+		recordExpiry, err := r.expirePrefixChanges(ctx, &dp, now)
+		if err != nil {
+			log.Error(err, "Failed to prune PrefixChanges")
+		}
+		requeueAfter := 10 * time.Second
+		for _, deadline := range []time.Time{r.handleLostPrefix(ctx, &dp, now), uplinkExpiry, recordExpiry} {
+			if !deadline.IsZero() {
+				requeueAfter = min(requeueAfter, max(deadline.Sub(now), time.Second))
+			}
+		}
+		if err := r.updateStatus(ctx, &dp, originalStatus); err != nil {
 			return ctrl.Result{}, err
 		}
-		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	// The prefix this transition starts from, for the PrefixChange record
+	previousPrefix := dp.Status.CurrentPrefix
+	if previousPrefix == "" && dp.Status.LostPrefix != nil {
+		previousPrefix = dp.Status.LostPrefix.Prefix
+	}
+	if dp.Status.LostPrefix != nil {
+		log.Info("Prefix recovered", "lostPrefix", dp.Status.LostPrefix.Prefix, "prefix", currentPrefix.Network)
+		r.recordEvent(&dp, corev1.EventTypeNormal, "PrefixRecovered",
+			"Now using prefix %s after %s was lost", currentPrefix.Network, dp.Status.LostPrefix.Prefix)
+		dp.Status.LostPrefix = nil
 	}
 
 	// Update status with current prefix
@@ -137,14 +234,24 @@ func (r *DynamicPrefixReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		r.handlePrefixChange(ctx, &dp, currentPrefix)
 	}
 
+	if prefixChanged || dp.Status.PrefixAcquiredAt == nil {
+		now := metav1.Now()
+		dp.Status.PrefixAcquiredAt = &now
+	}
 	dp.Status.CurrentPrefix = currentPrefix.Network.String()
-	dp.Status.PrefixSource = sourceToPrefixSource(receiver.Source())
+	dp.Status.PrefixSource = prefixSourceOf(currentPrefix, receiver)
+	dp.Status.NetworkConfig = networkConfigToStatus(currentPrefix.Config)
 
 	// Calculate lease expiration
+	dp.Status.LeaseExpiresAt = nil
 	if currentPrefix.ValidLifetime > 0 {
 		expiresAt := metav1.NewTime(currentPrefix.ReceivedAt.Add(currentPrefix.ValidLifetime))
 		dp.Status.LeaseExpiresAt = &expiresAt
 	}
+	nextExpiry := r.expireHistory(ctx, &dp, time.Now())
+
+	// Move the phased transition on once the pools and Services caught up
+	transitionNext := r.advanceTransition(ctx, &dp, time.Now())
 
 	// Calculate subnets (Mode 2)
 	subnets, err := r.calculateSubnets(currentPrefix.Network, dp.Spec.Subnets)
@@ -172,56 +279,354 @@ func (r *DynamicPrefixReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 
 	// Set prefix acquired condition
-	r.setCondition(&dp, dynamicprefixiov1alpha1.ConditionTypePrefixAcquired, metav1.ConditionTrue,
-		"PrefixAcquired", fmt.Sprintf("Prefix %s acquired via %s", currentPrefix.Network, receiver.Source()))
+	if dp.Status.Override != nil {
+		r.setCondition(&dp, dynamicprefixiov1alpha1.ConditionTypePrefixAcquired, metav1.ConditionTrue,
+			"PrefixOverridden", fmt.Sprintf("Prefix %s held by %s override", currentPrefix.Network, dp.Status.Override.Mode))
+	} else {
+		r.setCondition(&dp, dynamicprefixiov1alpha1.ConditionTypePrefixAcquired, metav1.ConditionTrue,
+			"PrefixAcquired", fmt.Sprintf("Prefix %s acquired via %s", currentPrefix.Network, receiver.Source()))
+	}
+
+	// Record the transition before the status update triggers the pool, Service and BGP updates
+	if previousPrefix != "" && previousPrefix != dp.Status.CurrentPrefix {
+		if err := r.recordPrefixChange(ctx, &dp, previousPrefix, time.Now()); err != nil {
+			log.Error(err, "Failed to record PrefixChange")
+		}
+	}
+	// Records also age out while the prefix is stable
+	recordExpiry, err := r.expirePrefixChanges(ctx, &dp, time.Now())
+	if err != nil {
+		log.Error(err, "Failed to prune PrefixChanges")
+	}
 
 	// Update status
-	if err := r.Status().Update(ctx, &dp); err != nil {
+	if err := r.updateStatus(ctx, &dp, originalStatus); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	// Requeue to handle lease renewal
+	// Requeue to handle lease renewal, or to expire the next draining prefix
 	requeueAfter := r.calculateRequeueTime(currentPrefix)
+	for _, deadline := range []time.Time{nextExpiry, overrideExpiry, uplinkExpiry, transitionNext, recordExpiry} {
+		if !deadline.IsZero() {
+			requeueAfter = min(requeueAfter, max(time.Until(deadline), time.Second))
+		}
+	}
 	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
-// getOrCreateReceiver returns an existing receiver or creates a new one
+// getOrCreateReceiver returns the receiver for the DynamicPrefix, creating one if needed.
+// When spec.acquisition changed since the receiver was created, a replacement is
+// started alongside it. The old receiver keeps serving its prefix until the
+// replacement reports one, then it is stopped and the replacement takes over.
 func (r *DynamicPrefixReconciler) getOrCreateReceiver(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix) (prefix.Receiver, error) {
-	r.receiversMu.RLock()
-	receiver, exists := r.receivers[dp.Name]
-	r.receiversMu.RUnlock()
+	log := logf.FromContext(ctx)
 
-	if exists {
-		return receiver, nil
+	// Secrets are resolved up front, so that rotating a Secret also swaps the receiver
+	spec, err := r.resolveAcquisitionSecrets(ctx, dp.Spec.Acquisition)
+	if err != nil {
+		r.receiversMu.RLock()
+		receiver, exists := r.receivers[dp.Name]
+		r.receiversMu.RUnlock()
+		if exists {
+			log.Error(err, "Failed to resolve acquisition secrets, keeping the current receiver")
+			return receiver, nil
+		}
+		return nil, err
+	}
+
+	specHash, err := acquisitionSpecHash(spec)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create new receiver
 	r.receiversMu.Lock()
 	defer r.receiversMu.Unlock()
 
-	// Double-check after acquiring write lock
-	if receiver, exists = r.receivers[dp.Name]; exists {
+	if r.receiverSpecs == nil {
+		r.receiverSpecs = make(map[string]string)
+	}
+	if r.pendingReceivers == nil {
+		r.pendingReceivers = make(map[string]*pendingReceiver)
+	}
+	if r.receiverWatches == nil {
+		r.receiverWatches = make(map[string]context.CancelFunc)
+	}
+
+	receiver, exists := r.receivers[dp.Name]
+	if !exists {
+		receiver, cancel, err := r.startReceiver(ctx, dp.Name, spec, lastKnownPrefix(dp), lastKnownSource(dp))
+		if err != nil {
+			return nil, err
+		}
+		r.receivers[dp.Name] = receiver
+		r.receiverSpecs[dp.Name] = specHash
+		if cancel != nil {
+			r.receiverWatches[dp.Name] = cancel
+		}
+		return receiver, nil
+	}
+
+	// Receivers registered without a spec hash are adopted as up to date
+	if _, ok := r.receiverSpecs[dp.Name]; !ok {
+		r.receiverSpecs[dp.Name] = specHash
+	}
+
+	pending := r.pendingReceivers[dp.Name]
+	if r.receiverSpecs[dp.Name] == specHash {
+		// Spec unchanged, or reverted before the replacement took over
+		if pending != nil {
+			r.stopPendingReceiver(dp.Name)
+		}
 		return receiver, nil
 	}
 
+	// The acquisition spec changed: (re)start a replacement for the current spec
+	if pending != nil && pending.specHash != specHash {
+		r.stopPendingReceiver(dp.Name)
+		pending = nil
+	}
+	if pending == nil {
+		// A DHCPv6 replacement on the same interface reuses the owner's IAID,
+		// so it continues the IA_PD of the receiver it replaces
+		log.Info("Acquisition spec changed, starting replacement receiver")
+		replacement, cancel, err := r.startReceiver(ctx, dp.Name, spec, lastKnownPrefix(dp), lastKnownSource(dp))
+		if err != nil {
+			// Keep serving from the old receiver and retry on the next reconcile
+			log.Error(err, "Failed to start replacement receiver, keeping the current one")
+			return receiver, nil
+		}
+		pending = &pendingReceiver{receiver: replacement, specHash: specHash, cancelWatch: cancel}
+		r.pendingReceivers[dp.Name] = pending
+	}
+
+	if pending.receiver.CurrentPrefix() == nil && receiver.CurrentPrefix() != nil {
+		log.Info("Serving last known prefix until the replacement receiver reports one",
+			"prefix", receiver.CurrentPrefix().Network.String())
+		return receiver, nil
+	}
+
+	// Swap: the replacement has a prefix, or there is nothing left to serve from the old receiver
+	log.Info("Switching to replacement receiver")
+	if cancel, ok := r.receiverWatches[dp.Name]; ok {
+		cancel()
+		delete(r.receiverWatches, dp.Name)
+	}
+	if err := receiver.Stop(); err != nil {
+		log.Error(err, "Failed to stop replaced receiver")
+	}
+	delete(r.pendingReceivers, dp.Name)
+	r.receivers[dp.Name] = pending.receiver
+	r.receiverSpecs[dp.Name] = pending.specHash
+	if pending.cancelWatch != nil {
+		r.receiverWatches[dp.Name] = pending.cancelWatch
+	}
+
+	return pending.receiver, nil
+}
+
+// startReceiver creates and starts a receiver for the named DynamicPrefix and watches its events.
+// Receivers that support it are asked to request the given hint, if valid; see setPrefixHint.
+// The returned cancel func stops the watch; it is nil when events are not watched.
+// Caller must hold receiversMu.
+func (r *DynamicPrefixReconciler) startReceiver(ctx context.Context, name string, spec dynamicprefixiov1alpha1.AcquisitionSpec, hint netip.Prefix, hintSource string) (prefix.Receiver, context.CancelFunc, error) {
+	var receiver prefix.Receiver
 	if r.ReceiverFactory == nil {
 		// Use mock receiver for testing
 		receiver = prefix.NewMockReceiver(prefix.SourceDHCPv6PD)
 	} else {
 		var err error
-		receiver, err = r.ReceiverFactory.CreateReceiver(dp.Spec.Acquisition)
+		receiver, err = r.ReceiverFactory.CreateReceiver(name, spec)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create receiver: %w", err)
+			return nil, nil, fmt.Errorf("failed to create receiver: %w", err)
 		}
 	}
 
+	setPrefixHint(receiver, hint, hintSource)
+
 	// Start the receiver
 	if err := receiver.Start(ctx); err != nil {
-		return nil, fmt.Errorf("failed to start receiver: %w", err)
+		return nil, nil, fmt.Errorf("failed to start receiver: %w", err)
 	}
 
-	r.receivers[dp.Name] = receiver
-	return receiver, nil
+	if r.receiverEvents == nil {
+		return receiver, nil, nil
+	}
+	watchCtx, cancel := context.WithCancel(ctx)
+	go r.watchReceiverEvents(watchCtx, name, receiver.Events())
+
+	return receiver, cancel, nil
+}
+
+// lastKnownPrefix returns the prefix the DynamicPrefix last held, from its status.
+// It survives operator restarts, so the receiver can ask the server for the same prefix again.
+func lastKnownPrefix(dp *dynamicprefixiov1alpha1.DynamicPrefix) netip.Prefix {
+	candidates := []string{dp.Status.CurrentPrefix}
+	// History is appended in order, so the most recent entry comes last
+	for i := len(dp.Status.History) - 1; i >= 0; i-- {
+		candidates = append(candidates, dp.Status.History[i].Prefix)
+	}
+	for _, candidate := range candidates {
+		if p, err := netip.ParsePrefix(candidate); err == nil {
+			return p
+		}
+	}
+	return netip.Prefix{}
+}
+
+// lastKnownSource returns the name of the acquisition source that provided the
+// current prefix, or "" if it is unknown.
+func lastKnownSource(dp *dynamicprefixiov1alpha1.DynamicPrefix) string {
+	if dp.Status.Acquisition == nil {
+		return ""
+	}
+	if _, err := netip.ParsePrefix(dp.Status.CurrentPrefix); err != nil {
+		// The hint then comes from history, whose source is not recorded
+		return ""
+	}
+	return dp.Status.Acquisition.ActiveSource
+}
+
+// setPrefixHint asks the receiver to request hint, if valid. A receiver with several
+// sources only passes it to hintSource, the source that delegated it; without a
+// known source none of them is hinted.
+func setPrefixHint(receiver prefix.Receiver, hint netip.Prefix, hintSource string) {
+	if !hint.IsValid() {
+		return
+	}
+	switch hinter := receiver.(type) {
+	case prefix.SourcePrefixHinter:
+		if hintSource != "" {
+			hinter.SetSourcePrefixHint(hintSource, hint)
+		}
+	case prefix.PrefixHinter:
+		hinter.SetPrefixHint(hint)
+	}
+}
+
+// stopPendingReceiver stops and forgets a replacement receiver that never took over.
+// Caller must hold receiversMu.
+func (r *DynamicPrefixReconciler) stopPendingReceiver(name string) {
+	pending, ok := r.pendingReceivers[name]
+	if !ok {
+		return
+	}
+	if pending.cancelWatch != nil {
+		pending.cancelWatch()
+	}
+	if err := pending.receiver.Stop(); err != nil {
+		logf.Log.Error(err, "Failed to stop replacement receiver", "name", name)
+	}
+	delete(r.pendingReceivers, name)
+}
+
+// resolveAcquisitionSecrets returns a copy of the acquisition spec with every Secret
+// reference replaced by the referenced value, so that receivers never read Secrets themselves
+func (r *DynamicPrefixReconciler) resolveAcquisitionSecrets(ctx context.Context, spec dynamicprefixiov1alpha1.AcquisitionSpec) (dynamicprefixiov1alpha1.AcquisitionSpec, error) {
+	resolved := *spec.DeepCopy()
+
+	pdSpecs := []*dynamicprefixiov1alpha1.DHCPv6PDSpec{resolved.DHCPv6PD}
+	for i := range resolved.Sources {
+		pdSpecs = append(pdSpecs, resolved.Sources[i].DHCPv6PD)
+	}
+
+	for _, pd := range pdSpecs {
+		for _, value := range dhcpv6OptionValues(pd) {
+			if value.SecretKeyRef == nil {
+				continue
+			}
+			data, err := r.readSecretKey(ctx, *value.SecretKeyRef)
+			if err != nil {
+				return resolved, err
+			}
+			value.Hex = hex.EncodeToString(data)
+			value.Text = ""
+			value.SecretKeyRef = nil
+		}
+	}
+
+	return resolved, nil
+}
+
+// dhcpv6OptionValues returns all option values of a DHCPv6-PD spec that may reference a Secret
+func dhcpv6OptionValues(pd *dynamicprefixiov1alpha1.DHCPv6PDSpec) []*dynamicprefixiov1alpha1.DHCPv6OptionValue {
+	if pd == nil {
+		return nil
+	}
+
+	var values []*dynamicprefixiov1alpha1.DHCPv6OptionValue
+	if opts := pd.Options; opts != nil {
+		if opts.VendorClass != nil {
+			for i := range opts.VendorClass.Data {
+				values = append(values, &opts.VendorClass.Data[i])
+			}
+		}
+		for i := range opts.UserClass {
+			values = append(values, &opts.UserClass[i])
+		}
+		if opts.VendorOptions != nil {
+			for i := range opts.VendorOptions.Options {
+				values = append(values, &opts.VendorOptions.Options[i].DHCPv6OptionValue)
+			}
+		}
+		for i := range opts.Raw {
+			values = append(values, &opts.Raw[i].DHCPv6OptionValue)
+		}
+	}
+	if pd.Authentication != nil && pd.Authentication.Key != nil {
+		values = append(values, pd.Authentication.Key)
+	}
+	return values
+}
+
+// readSecretKey reads a single key of a Secret.
+// Secrets are read directly from the API server, so the operator does not cache every Secret in the cluster.
+func (r *DynamicPrefixReconciler) readSecretKey(ctx context.Context, ref dynamicprefixiov1alpha1.SecretKeySelector) ([]byte, error) {
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+
+	var secret corev1.Secret
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &secret); err != nil {
+		return nil, fmt.Errorf("failed to read secret %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+	data, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s has no key %q", ref.Namespace, ref.Name, ref.Key)
+	}
+	return data, nil
+}
+
+// acquisitionSpecHash returns a hash identifying the acquisition spec a receiver was created from
+func acquisitionSpecHash(spec dynamicprefixiov1alpha1.AcquisitionSpec) (string, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash acquisition spec: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// watchReceiverEvents enqueues the DynamicPrefix whenever its receiver reports an event
+func (r *DynamicPrefixReconciler) watchReceiverEvents(ctx context.Context, name string, events <-chan prefix.Event) {
+	log := logf.FromContext(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-events:
+			log.V(1).Info("Receiver event", "dynamicPrefix", name, "type", ev.Type, "error", ev.Error)
+			trigger := event.GenericEvent{
+				Object: &dynamicprefixiov1alpha1.DynamicPrefix{ObjectMeta: metav1.ObjectMeta{Name: name}},
+			}
+			select {
+			case r.receiverEvents <- trigger:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
 }
 
 // cleanupReceiver stops and removes a receiver
@@ -229,6 +634,15 @@ func (r *DynamicPrefixReconciler) cleanupReceiver(name string) {
 	r.receiversMu.Lock()
 	defer r.receiversMu.Unlock()
 
+	if cancel, ok := r.receiverWatches[name]; ok {
+		cancel()
+		delete(r.receiverWatches, name)
+	}
+	r.stopPendingReceiver(name)
+	r.stopIPv4Receiver(name)
+	r.stopUplinkReceivers(name, nil)
+	delete(r.receiverSpecs, name)
+
 	receiver, exists := r.receivers[name]
 	if !exists {
 		return
@@ -240,6 +654,224 @@ func (r *DynamicPrefixReconciler) cleanupReceiver(name string) {
 	delete(r.receivers, name)
 }
 
+// getOrCreateIPv4Receiver returns the IPv4 receiver for the DynamicPrefix,
+// replacing it when spec.ipv4 changed. Without spec.ipv4 it stops any receiver
+// left from an earlier spec and returns nil.
+func (r *DynamicPrefixReconciler) getOrCreateIPv4Receiver(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix) (prefix.IPv4Receiver, error) {
+	r.receiversMu.Lock()
+	defer r.receiversMu.Unlock()
+
+	if dp.Spec.IPv4 == nil {
+		r.stopIPv4Receiver(dp.Name)
+		return nil, nil
+	}
+
+	if existing, ok := r.ipv4Receivers[dp.Name]; ok {
+		if existing.spec == *dp.Spec.IPv4 {
+			return existing.receiver, nil
+		}
+		logf.FromContext(ctx).Info("IPv4 spec changed, recreating receiver")
+		r.stopIPv4Receiver(dp.Name)
+	}
+
+	factory, ok := r.ReceiverFactory.(IPv4ReceiverFactory)
+	if !ok {
+		return nil, fmt.Errorf("receiver factory does not support IPv4")
+	}
+	receiver, err := factory.CreateIPv4Receiver(dp.Spec.IPv4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create IPv4 receiver: %w", err)
+	}
+	if err := receiver.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start IPv4 receiver: %w", err)
+	}
+
+	entry := &ipv4Receiver{receiver: receiver, spec: *dp.Spec.IPv4}
+	if r.receiverEvents != nil {
+		var watchCtx context.Context
+		watchCtx, entry.cancelWatch = context.WithCancel(ctx)
+		go r.watchReceiverEvents(watchCtx, dp.Name, receiver.Events())
+	}
+	if r.ipv4Receivers == nil {
+		r.ipv4Receivers = make(map[string]*ipv4Receiver)
+	}
+	r.ipv4Receivers[dp.Name] = entry
+	return receiver, nil
+}
+
+// stopIPv4Receiver stops and forgets the IPv4 receiver of a DynamicPrefix.
+// Caller must hold receiversMu.
+func (r *DynamicPrefixReconciler) stopIPv4Receiver(name string) {
+	entry, ok := r.ipv4Receivers[name]
+	if !ok {
+		return
+	}
+	if entry.cancelWatch != nil {
+		entry.cancelWatch()
+	}
+	if err := entry.receiver.Stop(); err != nil {
+		logf.Log.Error(err, "Failed to stop IPv4 receiver", "name", name)
+	}
+	delete(r.ipv4Receivers, name)
+}
+
+// updateIPv4Status reports the WAN IPv4 address in status.ipv4 and the
+// IPv4Acquired condition. The last known address is kept while none is
+// available, so that consumers do not lose their IPv4 configuration.
+func (r *DynamicPrefixReconciler) updateIPv4Status(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix) {
+	receiver, err := r.getOrCreateIPv4Receiver(ctx, dp)
+	switch {
+	case err != nil:
+		logf.FromContext(ctx).Error(err, "Failed to create IPv4 receiver")
+		r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypeIPv4Acquired, metav1.ConditionFalse,
+			"ReceiverCreationFailed", err.Error())
+		return
+	case receiver == nil:
+		dp.Status.IPv4 = nil
+		meta.RemoveStatusCondition(&dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeIPv4Acquired)
+		return
+	}
+
+	current := receiver.CurrentIPv4()
+	if current == nil {
+		r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypeIPv4Acquired, metav1.ConditionFalse,
+			"WaitingForAddress", fmt.Sprintf("No IPv4 address on %s", dp.Spec.IPv4.DHCPv4.Interface))
+		return
+	}
+
+	status := dp.Status.IPv4
+	if status == nil {
+		status = &dynamicprefixiov1alpha1.IPv4Status{}
+	}
+	address := current.Address.Addr().String()
+	if status.Address != "" && status.Address != address {
+		now := metav1.Now()
+		status.PreviousAddress = status.Address
+		status.ChangedAt = &now
+	}
+	status.Address = address
+	status.Subnet = current.Subnet().String()
+	status.Router = ""
+	if current.Router.IsValid() {
+		status.Router = current.Router.String()
+	}
+	status.LeaseExpiresAt = nil
+	if current.ValidLifetime > 0 {
+		expiresAt := metav1.NewTime(current.ReceivedAt.Add(current.ValidLifetime))
+		status.LeaseExpiresAt = &expiresAt
+	}
+	dp.Status.IPv4 = status
+
+	r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypeIPv4Acquired, metav1.ConditionTrue,
+		"AddressAcquired", fmt.Sprintf("IPv4 address %s acquired on %s", current.Address, dp.Spec.IPv4.DHCPv4.Interface))
+}
+
+// updateAcquisitionStatus reports the active source and per-receiver diagnostics
+func (r *DynamicPrefixReconciler) updateAcquisitionStatus(dp *dynamicprefixiov1alpha1.DynamicPrefix, receiver prefix.Receiver) {
+	status := &dynamicprefixiov1alpha1.AcquisitionStatus{}
+	reported := false
+
+	if reporter, ok := receiver.(prefix.FailoverReporter); ok {
+		failover := reporter.FailoverStatus()
+		status.ActiveSource = failover.ActiveSource
+		status.FailoverReason = failover.Reason
+		status.LastFailoverTime = optionalTime(failover.LastFailover)
+		reported = true
+	}
+
+	if composite, ok := receiver.(*prefix.CompositeReceiver); ok {
+		status.UsingFallback = composite.IsUsingFallback()
+	}
+
+	if reporter, ok := receiver.(prefix.DiagnosticsReporter); ok {
+		for _, d := range reporter.Diagnostics() {
+			status.Receivers = append(status.Receivers, diagnosticsToReceiverStatus(d))
+		}
+		reported = true
+	}
+
+	if !reported {
+		dp.Status.Acquisition = nil
+		return
+	}
+	dp.Status.Acquisition = status
+}
+
+// networkConfigToStatus converts configuration data received with a prefix to its status representation
+func networkConfigToStatus(config *prefix.NetworkConfig) *dynamicprefixiov1alpha1.NetworkConfigStatus {
+	if config == nil {
+		return nil
+	}
+
+	status := &dynamicprefixiov1alpha1.NetworkConfigStatus{
+		DomainSearch: config.DomainSearch,
+		NTPServers:   config.NTPServers,
+		SolMaxRT:     optionalDuration(config.SolMaxRT),
+	}
+	for _, addr := range config.DNSServers {
+		status.DNSServers = append(status.DNSServers, addr.String())
+	}
+	for _, addr := range config.SNTPServers {
+		status.SNTPServers = append(status.SNTPServers, addr.String())
+	}
+	return status
+}
+
+// diagnosticsToReceiverStatus converts receiver diagnostics to their status representation
+func diagnosticsToReceiverStatus(d prefix.Diagnostics) dynamicprefixiov1alpha1.ReceiverStatus {
+	status := dynamicprefixiov1alpha1.ReceiverStatus{
+		Name:                d.Name,
+		Source:              sourceToPrefixSource(d.Source),
+		Active:              d.Active,
+		Interface:           d.Interface,
+		ConsecutiveFailures: d.ConsecutiveFailures,
+		LastError:           d.LastError,
+		LastErrorTime:       optionalTime(d.LastErrorTime),
+	}
+
+	switch d.Source {
+	case prefix.SourceRouterAdvertisement:
+		status.RouterAdvertisement = &dynamicprefixiov1alpha1.RouterAdvertisementDiagnostics{
+			LastReceivedTime: optionalTime(d.LastRouterAdvertisement),
+			Router:           d.Router,
+		}
+	case prefix.SourceDHCPv6PD:
+		status.DHCPv6 = &dynamicprefixiov1alpha1.DHCPv6Diagnostics{
+			LastExchange:     d.LastExchange,
+			LastExchangeTime: optionalTime(d.LastExchangeTime),
+			LastResult:       d.LastExchangeResult,
+			ServerDUID:       d.ServerDUID,
+			T1:               optionalDuration(d.T1),
+			T2:               optionalDuration(d.T2),
+		}
+	case prefix.SourceDNS:
+		status.DNS = &dynamicprefixiov1alpha1.DNSDiagnostics{
+			LastQueryTime: optionalTime(d.LastQueryTime),
+			Address:       d.DNSAddress,
+			TTL:           optionalDuration(d.DNSTTL),
+		}
+	}
+
+	return status
+}
+
+// optionalTime converts a time to a metav1.Time pointer, returning nil for the zero time
+func optionalTime(t time.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
+	}
+	mt := metav1.NewTime(t)
+	return &mt
+}
+
+// optionalDuration converts a duration to a metav1.Duration pointer, returning nil for zero
+func optionalDuration(d time.Duration) *metav1.Duration {
+	if d == 0 {
+		return nil
+	}
+	return &metav1.Duration{Duration: d}
+}
+
 // calculateSubnets calculates subnet CIDRs from the base prefix
 func (r *DynamicPrefixReconciler) calculateSubnets(basePrefix netip.Prefix, specs []dynamicprefixiov1alpha1.SubnetSpec) ([]dynamicprefixiov1alpha1.SubnetStatus, error) {
 	if len(specs) == 0 {
@@ -311,35 +943,334 @@ func (r *DynamicPrefixReconciler) handlePrefixChange(ctx context.Context, dp *dy
 	log := logf.FromContext(ctx)
 	now := metav1.Now()
 
+	// A prefix that comes back is current again, not history
+	dp.Status.History = slices.DeleteFunc(dp.Status.History, func(entry dynamicprefixiov1alpha1.PrefixHistoryEntry) bool {
+		return entry.Prefix == newPrefix.Network.String()
+	})
+
 	// Add old prefix to history if it exists
 	if dp.Status.CurrentPrefix != "" {
+		// Status written before acquisition times were tracked falls back to the creation time
+		acquiredAt := dp.CreationTimestamp
+		if dp.Status.PrefixAcquiredAt != nil {
+			acquiredAt = *dp.Status.PrefixAcquiredAt
+		}
 		oldEntry := dynamicprefixiov1alpha1.PrefixHistoryEntry{
 			Prefix:       dp.Status.CurrentPrefix,
-			AcquiredAt:   dp.CreationTimestamp,
+			AcquiredAt:   acquiredAt,
 			DeprecatedAt: &now,
+			ExpiresAt:    drainDeadline(dp, now),
 			State:        dynamicprefixiov1alpha1.PrefixStateDraining,
 		}
 
-		// Find and update existing entry or add new one
-		dp.Status.History = append(dp.Status.History, oldEntry)
-
-		// Limit history size
-		maxHistory := 2
-		if dp.Spec.Transition != nil && dp.Spec.Transition.MaxPrefixHistory > 0 {
-			maxHistory = dp.Spec.Transition.MaxPrefixHistory
-		}
-		if len(dp.Status.History) > maxHistory {
-			dp.Status.History = dp.Status.History[len(dp.Status.History)-maxHistory:]
-		}
+		appendHistory(dp, oldEntry)
 
 		log.Info("Added prefix to history",
 			"oldPrefix", dp.Status.CurrentPrefix,
 			"newPrefix", newPrefix.Network.String(),
 			"state", dynamicprefixiov1alpha1.PrefixStateDraining)
+
+		if phasedTransitions(dp) {
+			r.startTransition(ctx, dp, dp.Status.CurrentPrefix, newPrefix.Network.String(), now.Time)
+		}
+	}
+}
+
+// handleLostPrefix applies spec.lostPrefixPolicy while the receivers report no
+// prefix. A prefix counts as lost once its lease has expired; without a known
+// lease the controller keeps waiting, as it does after a restart. It sets the
+// PrefixAcquired condition and returns when a retained prefix is withdrawn, or the zero time.
+func (r *DynamicPrefixReconciler) handleLostPrefix(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix, now time.Time) time.Time {
+	log := logf.FromContext(ctx)
+	lost := dp.Status.LostPrefix
+
+	if lost != nil && lost.Withdrawn {
+		r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypePrefixAcquired, metav1.ConditionFalse,
+			"PrefixWithdrawn", fmt.Sprintf("Prefix %s was lost and withdrawn; waiting to receive a prefix from upstream", lost.Prefix))
+		return time.Time{}
+	}
+	if lost == nil {
+		if dp.Status.CurrentPrefix == "" || dp.Status.LeaseExpiresAt == nil || dp.Status.LeaseExpiresAt.After(now) {
+			r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypePrefixAcquired, metav1.ConditionFalse,
+				"WaitingForPrefix", "Waiting to receive prefix from upstream")
+			return time.Time{}
+		}
+		lostAt := *dp.Status.LeaseExpiresAt
+		lost = &dynamicprefixiov1alpha1.LostPrefixStatus{Prefix: dp.Status.CurrentPrefix, LostAt: &lostAt}
+		dp.Status.LostPrefix = lost
+		log.Info("Prefix lost", "prefix", lost.Prefix, "leaseExpiredAt", lostAt.Time)
+		r.recordEvent(dp, corev1.EventTypeWarning, "PrefixLost",
+			"Lease of prefix %s expired at %s without a replacement", lost.Prefix, lostAt.UTC().Format(time.RFC3339))
+	}
+
+	policy := dp.Spec.LostPrefixPolicy
+	withdraw := policy != nil && policy.Action == dynamicprefixiov1alpha1.LostPrefixActionWithdraw
+	lost.WithdrawAt = nil
+	if !withdraw && policy != nil && policy.GracePeriod != nil && lost.LostAt != nil {
+		withdrawAt := metav1.NewTime(lost.LostAt.Add(policy.GracePeriod.Duration))
+		lost.WithdrawAt = &withdrawAt
+		withdraw = !withdrawAt.After(now)
+	}
+
+	if withdraw {
+		r.withdrawPrefix(ctx, dp, now)
+		r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypePrefixAcquired, metav1.ConditionFalse,
+			"PrefixWithdrawn", fmt.Sprintf("Prefix %s was lost and withdrawn; waiting to receive a prefix from upstream", lost.Prefix))
+		return time.Time{}
+	}
+
+	message := fmt.Sprintf("Prefix %s was lost; retaining it", lost.Prefix)
+	if lost.WithdrawAt != nil {
+		message += " until " + lost.WithdrawAt.UTC().Format(time.RFC3339)
+		r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypePrefixAcquired, metav1.ConditionFalse, "PrefixRetained", message)
+		return lost.WithdrawAt.Time
 	}
+	r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypePrefixAcquired, metav1.ConditionFalse, "PrefixRetained", message)
+	return time.Time{}
 }
 
-// setCondition sets a condition on the DynamicPrefix status
+// withdrawPrefix stops using the lost current prefix and all draining
+// prefixes, so that pools, Services and BGP advertisements drop them.
+func (r *DynamicPrefixReconciler) withdrawPrefix(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix, now time.Time) {
+	log := logf.FromContext(ctx)
+	lost := dp.Status.LostPrefix
+	expiredAt := metav1.NewTime(now)
+
+	for i := range dp.Status.History {
+		if dp.Status.History[i].State != dynamicprefixiov1alpha1.PrefixStateExpired {
+			dp.Status.History[i].State = dynamicprefixiov1alpha1.PrefixStateExpired
+			dp.Status.History[i].ExpiresAt = &expiredAt
+		}
+	}
+	if dp.Status.CurrentPrefix != "" {
+		acquiredAt := dp.CreationTimestamp
+		if dp.Status.PrefixAcquiredAt != nil {
+			acquiredAt = *dp.Status.PrefixAcquiredAt
+		}
+		appendHistory(dp, dynamicprefixiov1alpha1.PrefixHistoryEntry{
+			Prefix:       dp.Status.CurrentPrefix,
+			AcquiredAt:   acquiredAt,
+			DeprecatedAt: lost.LostAt,
+			ExpiresAt:    &expiredAt,
+			State:        dynamicprefixiov1alpha1.PrefixStateExpired,
+		})
+	}
+
+	dp.Status.CurrentPrefix = ""
+	dp.Status.PrefixSource = ""
+	dp.Status.PrefixAcquiredAt = nil
+	dp.Status.LeaseExpiresAt = nil
+	dp.Status.NetworkConfig = nil
+	dp.Status.AddressRanges = nil
+	dp.Status.Subnets = nil
+	lost.Withdrawn = true
+
+	log.Info("Lost prefix withdrawn", "prefix", lost.Prefix)
+	r.recordEvent(dp, corev1.EventTypeWarning, "PrefixWithdrawn",
+		"Prefix %s withdrawn from pools, Services and BGP advertisements", lost.Prefix)
+}
+
+// recordEvent emits an event for the DynamicPrefix if a recorder is configured
+func (r *DynamicPrefixReconciler) recordEvent(dp *dynamicprefixiov1alpha1.DynamicPrefix, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.Recorder != nil {
+		r.Recorder.Eventf(dp, eventType, reason, messageFmt, args...)
+	}
+}
+
+// applyOverride returns the prefix the DynamicPrefix uses: the observed one,
+// or the one spec.override holds. It reports the override in status.override
+// and the Overridden condition, and returns when the override ends, or the
+// zero time.
+func (r *DynamicPrefixReconciler) applyOverride(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix, observed *prefix.Prefix, now time.Time) (*prefix.Prefix, time.Time) {
+	log := logf.FromContext(ctx)
+	spec := dp.Spec.Override
+
+	if spec == nil {
+		if dp.Status.Override != nil {
+			log.Info("Override removed, following the receivers again")
+		}
+		dp.Status.Override = nil
+		meta.RemoveStatusCondition(&dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeOverridden)
+		return observed, time.Time{}
+	}
+	if spec.ExpiresAt != nil && !spec.ExpiresAt.After(now) {
+		if dp.Status.Override != nil {
+			log.Info("Override expired, following the receivers again", "expiresAt", spec.ExpiresAt.Time)
+		}
+		dp.Status.Override = nil
+		r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypeOverridden, metav1.ConditionFalse,
+			"OverrideExpired", fmt.Sprintf("Override expired at %s", spec.ExpiresAt.UTC().Format(time.RFC3339)))
+		return observed, time.Time{}
+	}
+
+	var held netip.Prefix
+	switch spec.Mode {
+	case dynamicprefixiov1alpha1.OverrideModeForce:
+		p, err := prefix.ParsePrefix(spec.Prefix)
+		if err == nil && (!p.Addr().Is6() || p.Addr().Is4In6()) {
+			err = fmt.Errorf("override prefix %q is not an IPv6 prefix", spec.Prefix)
+		}
+		if err != nil {
+			dp.Status.Override = nil
+			r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypeOverridden, metav1.ConditionFalse,
+				"InvalidOverride", err.Error())
+			return observed, time.Time{}
+		}
+		held = p
+	case dynamicprefixiov1alpha1.OverrideModeFreeze:
+		// The frozen prefix is kept in status, so that it survives reconciles and restarts
+		candidates := []string{dp.Status.CurrentPrefix}
+		if st := dp.Status.Override; st != nil && st.Mode == dynamicprefixiov1alpha1.OverrideModeFreeze {
+			candidates = append([]string{st.Prefix}, candidates...)
+		}
+		if observed != nil {
+			candidates = append(candidates, observed.Network.String())
+		}
+		for _, candidate := range candidates {
+			if p, err := netip.ParsePrefix(candidate); err == nil {
+				held = p
+				break
+			}
+		}
+		if !held.IsValid() {
+			dp.Status.Override = nil
+			r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypeOverridden, metav1.ConditionFalse,
+				"WaitingForPrefix", "No prefix to freeze yet")
+			return observed, time.Time{}
+		}
+	default:
+		dp.Status.Override = nil
+		r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypeOverridden, metav1.ConditionFalse,
+			"InvalidOverride", fmt.Sprintf("unknown override mode %q", spec.Mode))
+		return observed, time.Time{}
+	}
+
+	status := dp.Status.Override
+	if status == nil || status.Mode != spec.Mode || status.Prefix != held.String() {
+		since := metav1.NewTime(now)
+		status = &dynamicprefixiov1alpha1.OverrideStatus{Mode: spec.Mode, Prefix: held.String(), Since: &since}
+		log.Info("Override in effect", "mode", spec.Mode, "prefix", held)
+	}
+	status.ExpiresAt = spec.ExpiresAt
+	status.ObservedPrefix = ""
+	if observed != nil {
+		status.ObservedPrefix = observed.Network.String()
+	}
+	dp.Status.Override = status
+
+	reason := "Frozen"
+	if spec.Mode == dynamicprefixiov1alpha1.OverrideModeForce {
+		reason = "Forced"
+	}
+	message := fmt.Sprintf("Prefix %s held by %s override", held, spec.Mode)
+	var expiry time.Time
+	if spec.ExpiresAt != nil {
+		expiry = spec.ExpiresAt.Time
+		message += " until " + expiry.UTC().Format(time.RFC3339)
+	}
+	r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypeOverridden, metav1.ConditionTrue, reason, message)
+
+	// While the receivers agree, their lifetimes still apply
+	if observed != nil && observed.Network == held {
+		return observed, expiry
+	}
+	return &prefix.Prefix{Network: held, Source: prefix.SourceStatic, ReceivedAt: now}, expiry
+}
+
+// appendHistory appends entry to status.history, dropping the oldest entries
+// beyond spec.transition.maxPrefixHistory.
+func appendHistory(dp *dynamicprefixiov1alpha1.DynamicPrefix, entry dynamicprefixiov1alpha1.PrefixHistoryEntry) {
+	maxHistory := defaultMaxPrefixHistory
+	if dp.Spec.Transition != nil && dp.Spec.Transition.MaxPrefixHistory > 0 {
+		maxHistory = dp.Spec.Transition.MaxPrefixHistory
+	}
+	dp.Status.History = append(dp.Status.History, entry)
+	if len(dp.Status.History) > maxHistory {
+		dp.Status.History = dp.Status.History[len(dp.Status.History)-maxHistory:]
+	}
+}
+
+// drainDeadline returns when the current prefix, about to be replaced at now,
+// expires: at the end of its valid lifetime or of the drain period, whichever
+// comes first. It returns nil when neither is known. With phased transitions
+// the drain period starts later, when the DNS targets moved.
+func drainDeadline(dp *dynamicprefixiov1alpha1.DynamicPrefix, now metav1.Time) *metav1.Time {
+	var deadline *metav1.Time
+	if dp.Status.LeaseExpiresAt != nil {
+		expiresAt := *dp.Status.LeaseExpiresAt
+		deadline = &expiresAt
+	}
+	if dp.Spec.Transition != nil && dp.Spec.Transition.DrainPeriod != nil && !phasedTransitions(dp) {
+		drained := metav1.NewTime(now.Add(dp.Spec.Transition.DrainPeriod.Duration))
+		if deadline == nil || drained.Before(deadline) {
+			deadline = &drained
+		}
+	}
+	return deadline
+}
+
+// expireHistory moves draining history entries whose deadline has passed to
+// expired. It returns the earliest deadline still ahead, or the zero time.
+func (r *DynamicPrefixReconciler) expireHistory(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix, now time.Time) time.Time {
+	log := logf.FromContext(ctx)
+
+	var next time.Time
+	for i := range dp.Status.History {
+		entry := &dp.Status.History[i]
+		if entry.State == dynamicprefixiov1alpha1.PrefixStateExpired || entry.ExpiresAt == nil {
+			continue
+		}
+		if !entry.ExpiresAt.After(now) {
+			entry.State = dynamicprefixiov1alpha1.PrefixStateExpired
+			log.Info("Historical prefix expired", "prefix", entry.Prefix, "expiresAt", entry.ExpiresAt.Time)
+			continue
+		}
+		if next.IsZero() || entry.ExpiresAt.Time.Before(next) {
+			next = entry.ExpiresAt.Time
+		}
+	}
+	return next
+}
+
+// drainingHistory returns the historical prefixes that pools and Services
+// still carry, oldest first. Entries without a state predate state tracking
+// and count as draining.
+func drainingHistory(dp *dynamicprefixiov1alpha1.DynamicPrefix) []dynamicprefixiov1alpha1.PrefixHistoryEntry {
+	var entries []dynamicprefixiov1alpha1.PrefixHistoryEntry
+	for _, entry := range dp.Status.History {
+		if entry.State != dynamicprefixiov1alpha1.PrefixStateExpired {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// prefixWithdrawn reports whether a lost prefix has been withdrawn, in which
+// case pools, Services and BGP advertisements carry no IPv6 prefix at all.
+func prefixWithdrawn(dp *dynamicprefixiov1alpha1.DynamicPrefix) bool {
+	return dp.Status.LostPrefix != nil && dp.Status.LostPrefix.Withdrawn
+}
+
+// updateStatus server-side applies the status fields owned by this controller.
+// The write is skipped when they are unchanged since the object was read, so a
+// no-op reconcile causes no API traffic and no status event for the sync controllers.
+func (r *DynamicPrefixReconciler) updateStatus(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix, original *dynamicprefixiov1alpha1.DynamicPrefixStatus) error {
+	desired, err := dynamicPrefixOwnedStatus(&dp.Status)
+	if err != nil {
+		return fmt.Errorf("failed to convert status: %w", err)
+	}
+	current, err := dynamicPrefixOwnedStatus(original)
+	if err != nil {
+		return fmt.Errorf("failed to convert status: %w", err)
+	}
+	if equality.Semantic.DeepEqual(desired, current) {
+		return nil
+	}
+	return applyDynamicPrefixStatus(ctx, r.Client, dp, desired, FieldManagerDynamicPrefix)
+}
+
+// setCondition sets a condition on the DynamicPrefix status.
+// LastTransitionTime only moves when the condition status changes.
 func (r *DynamicPrefixReconciler) setCondition(dp *dynamicprefixiov1alpha1.DynamicPrefix, condType string, status metav1.ConditionStatus, reason, message string) {
 	condition := metav1.Condition{
 		Type:               condType,
@@ -371,6 +1302,15 @@ func (r *DynamicPrefixReconciler) calculateRequeueTime(p *prefix.Prefix) time.Du
 	return requeue
 }
 
+// prefixSourceOf returns the source that provided p. The prefix records its own source,
+// so a composite receiver switching sources after p was read cannot mismatch the two.
+func prefixSourceOf(p *prefix.Prefix, receiver prefix.Receiver) dynamicprefixiov1alpha1.PrefixSource {
+	if p.Source != "" {
+		return sourceToPrefixSource(p.Source)
+	}
+	return sourceToPrefixSource(receiver.Source())
+}
+
 // sourceToPrefixSource converts prefix.Source to v1alpha1.PrefixSource
 func sourceToPrefixSource(s prefix.Source) dynamicprefixiov1alpha1.PrefixSource {
 	switch s {
@@ -380,6 +1320,8 @@ func sourceToPrefixSource(s prefix.Source) dynamicprefixiov1alpha1.PrefixSource
 		return dynamicprefixiov1alpha1.PrefixSourceRouterAdvertisement
 	case prefix.SourceStatic:
 		return dynamicprefixiov1alpha1.PrefixSourceStatic
+	case prefix.SourceDNS:
+		return dynamicprefixiov1alpha1.PrefixSourceDNS
 	default:
 		return dynamicprefixiov1alpha1.PrefixSourceUnknown
 	}
@@ -387,8 +1329,14 @@ func sourceToPrefixSource(s prefix.Source) dynamicprefixiov1alpha1.PrefixSource
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *DynamicPrefixReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&dynamicprefixiov1alpha1.DynamicPrefix{}).
-		Named("dynamicprefix").
-		Complete(r)
+		Named("dynamicprefix")
+
+	// Reconcile on receiver events (acquired, changed, failed, ...)
+	if r.receiverEvents != nil {
+		b = b.WatchesRawSource(source.Channel(r.receiverEvents, &handler.EnqueueRequestForObject{}))
+	}
+
+	return b.Complete(r)
 }
@@ -19,12 +19,18 @@ package controller
 import (
 	"context"
 	"net/netip"
+	"strings"
+	"testing"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
@@ -198,6 +204,79 @@ var _ = Describe("DynamicPrefix Controller", func() {
 		})
 	})
 
+	Context("When acquiring from multiple sources", func() {
+		It("Should report the active source in status", func() {
+			ctx := context.Background()
+
+			dpName := "test-dp-sources"
+			dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: dpName,
+				},
+				Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+					Acquisition: dynamicprefixiov1alpha1.AcquisitionSpec{
+						Sources: []dynamicprefixiov1alpha1.AcquisitionSourceSpec{
+							{
+								Name:     "pd",
+								DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{Interface: "eth0"},
+							},
+							{
+								Name:     "fixed",
+								Priority: 1,
+								Static:   &dynamicprefixiov1alpha1.StaticSourceSpec{Prefix: "2001:db8:ff::/48"},
+							},
+						},
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, dp)).Should(Succeed())
+
+			reconciler := &DynamicPrefixReconciler{
+				Client:    k8sClient,
+				Scheme:    k8sClient.Scheme(),
+				receivers: make(map[string]prefix.Receiver),
+			}
+
+			// Only the lower-priority static source has a prefix
+			pd := prefix.NewMockReceiver(prefix.SourceDHCPv6PD)
+			fixed := prefix.NewStaticReceiver(netip.MustParsePrefix("2001:db8:ff::/48"))
+			Expect(fixed.Start(ctx)).To(Succeed())
+			composite, err := prefix.NewCompositeReceiverFromSources([]prefix.CompositeSource{
+				{Receiver: pd, Policy: prefix.SourcePolicy{Name: "pd"}},
+				{Receiver: fixed, Policy: prefix.SourcePolicy{Name: "fixed", Priority: 1}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			reconciler.receivers[dpName] = composite
+
+			req := reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: dpName},
+			}
+
+			// Add finalizer
+			_, _ = reconciler.Reconcile(ctx, req)
+			// Process prefix
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			var updatedDP dynamicprefixiov1alpha1.DynamicPrefix
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: dpName}, &updatedDP)).Should(Succeed())
+			Expect(updatedDP.Status.CurrentPrefix).To(Equal("2001:db8:ff::/48"))
+			Expect(updatedDP.Status.PrefixSource).To(Equal(dynamicprefixiov1alpha1.PrefixSourceStatic))
+			Expect(updatedDP.Status.Acquisition).NotTo(BeNil())
+			Expect(updatedDP.Status.Acquisition.ActiveSource).To(Equal("fixed"))
+			Expect(updatedDP.Status.Acquisition.UsingFallback).To(BeTrue())
+			Expect(updatedDP.Status.Acquisition.Receivers).To(HaveLen(2))
+			Expect(updatedDP.Status.Acquisition.Receivers[0].Name).To(Equal("pd"))
+			Expect(updatedDP.Status.Acquisition.Receivers[0].Active).To(BeFalse())
+			Expect(updatedDP.Status.Acquisition.Receivers[1].Name).To(Equal("fixed"))
+			Expect(updatedDP.Status.Acquisition.Receivers[1].Active).To(BeTrue())
+
+			// Cleanup
+			Expect(k8sClient.Delete(ctx, dp)).Should(Succeed())
+		})
+	})
+
 	Context("When deleting a DynamicPrefix", func() {
 		It("Should remove finalizer and cleanup receiver", func() {
 			ctx := context.Background()
@@ -309,3 +388,791 @@ var _ = Describe("DynamicPrefix Controller", func() {
 		})
 	})
 })
+
+// fakeReceiverFactory hands out mock receivers and remembers them in creation order
+type fakeReceiverFactory struct {
+	created     []*prefix.MockReceiver
+	createdIPv4 []*fakeIPv4Receiver
+}
+
+func (f *fakeReceiverFactory) CreateReceiver(_ string, _ dynamicprefixiov1alpha1.AcquisitionSpec) (prefix.Receiver, error) {
+	mock := prefix.NewMockReceiver(prefix.SourceDHCPv6PD)
+	f.created = append(f.created, mock)
+	return mock, nil
+}
+
+func (f *fakeReceiverFactory) CreateIPv4Receiver(_ *dynamicprefixiov1alpha1.IPv4Spec) (prefix.IPv4Receiver, error) {
+	receiver := &fakeIPv4Receiver{events: make(chan prefix.Event)}
+	f.createdIPv4 = append(f.createdIPv4, receiver)
+	return receiver, nil
+}
+
+// fakeIPv4Receiver reports whatever address the test sets
+type fakeIPv4Receiver struct {
+	current *prefix.IPv4Address
+	events  chan prefix.Event
+	stopped bool
+}
+
+func (f *fakeIPv4Receiver) Start(context.Context) error      { return nil }
+func (f *fakeIPv4Receiver) Stop() error                      { f.stopped = true; return nil }
+func (f *fakeIPv4Receiver) Events() <-chan prefix.Event      { return f.events }
+func (f *fakeIPv4Receiver) CurrentIPv4() *prefix.IPv4Address { return f.current }
+
+func TestDynamicPrefixReconciler_getOrCreateReceiver_SpecChange(t *testing.T) {
+	ctx := context.Background()
+	factory := &fakeReceiverFactory{}
+	reconciler := NewDynamicPrefixReconciler(nil, nil)
+	reconciler.receiverEvents = nil
+	reconciler.ReceiverFactory = factory
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "swap"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			Acquisition: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{Interface: "eth0"},
+			},
+		},
+	}
+
+	first, err := reconciler.getOrCreateReceiver(ctx, dp)
+	if err != nil {
+		t.Fatalf("getOrCreateReceiver() error = %v", err)
+	}
+	factory.created[0].SimulatePrefix(netip.MustParsePrefix("2001:db8:1::/48"), time.Hour)
+
+	// Unchanged spec keeps the receiver
+	if got, _ := reconciler.getOrCreateReceiver(ctx, dp); got != first {
+		t.Fatal("Expected the same receiver for an unchanged spec")
+	}
+
+	// Changed spec starts a replacement but keeps serving the old prefix
+	dp.Spec.Acquisition.DHCPv6PD.Interface = "eth1"
+	got, _ := reconciler.getOrCreateReceiver(ctx, dp)
+	if got != first {
+		t.Fatal("Expected the old receiver to serve until the replacement has a prefix")
+	}
+	if len(factory.created) != 2 || !factory.created[1].IsStarted() {
+		t.Fatal("Expected a started replacement receiver")
+	}
+
+	// Reconciling again does not start another replacement
+	_, _ = reconciler.getOrCreateReceiver(ctx, dp)
+	if len(factory.created) != 2 {
+		t.Fatalf("created %d receivers, want 2", len(factory.created))
+	}
+
+	// Once the replacement has a prefix it takes over and the old receiver stops
+	factory.created[1].SimulatePrefix(netip.MustParsePrefix("2001:db8:2::/48"), time.Hour)
+	got, _ = reconciler.getOrCreateReceiver(ctx, dp)
+	if got != factory.created[1] {
+		t.Fatal("Expected the replacement receiver to take over")
+	}
+	if factory.created[0].IsStarted() {
+		t.Error("Expected the old receiver to be stopped")
+	}
+	if len(reconciler.pendingReceivers) != 0 {
+		t.Error("Expected no pending receivers after the swap")
+	}
+}
+
+func TestDynamicPrefixReconciler_getOrCreateReceiver_SpecReverted(t *testing.T) {
+	ctx := context.Background()
+	factory := &fakeReceiverFactory{}
+	reconciler := NewDynamicPrefixReconciler(nil, nil)
+	reconciler.receiverEvents = nil
+	reconciler.ReceiverFactory = factory
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "revert"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			Acquisition: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{Interface: "eth0"},
+			},
+		},
+	}
+
+	first, _ := reconciler.getOrCreateReceiver(ctx, dp)
+	factory.created[0].SimulatePrefix(netip.MustParsePrefix("2001:db8:1::/48"), time.Hour)
+
+	dp.Spec.Acquisition.DHCPv6PD.Interface = "eth1"
+	_, _ = reconciler.getOrCreateReceiver(ctx, dp)
+
+	// Reverting before the replacement took over stops the replacement
+	dp.Spec.Acquisition.DHCPv6PD.Interface = "eth0"
+	if got, _ := reconciler.getOrCreateReceiver(ctx, dp); got != first {
+		t.Fatal("Expected the original receiver after reverting the spec")
+	}
+	if factory.created[1].IsStarted() {
+		t.Error("Expected the replacement receiver to be stopped")
+	}
+}
+
+func TestNetworkConfigToStatus(t *testing.T) {
+	if got := networkConfigToStatus(nil); got != nil {
+		t.Errorf("networkConfigToStatus(nil) = %+v, want nil", got)
+	}
+
+	got := networkConfigToStatus(&prefix.NetworkConfig{
+		DNSServers:   []netip.Addr{netip.MustParseAddr("2001:db8::53")},
+		DomainSearch: []string{"home.arpa"},
+		NTPServers:   []string{"ntp.example.net"},
+		SNTPServers:  []netip.Addr{netip.MustParseAddr("2001:db8::123")},
+		SolMaxRT:     time.Hour,
+	})
+	if len(got.DNSServers) != 1 || got.DNSServers[0] != "2001:db8::53" {
+		t.Errorf("DNSServers = %v", got.DNSServers)
+	}
+	if len(got.SNTPServers) != 1 || got.SNTPServers[0] != "2001:db8::123" {
+		t.Errorf("SNTPServers = %v", got.SNTPServers)
+	}
+	if got.SolMaxRT == nil || got.SolMaxRT.Duration != time.Hour {
+		t.Errorf("SolMaxRT = %v, want 1h", got.SolMaxRT)
+	}
+}
+
+func TestDynamicPrefixReconciler_resolveAcquisitionSecrets(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "isp", Name: "credentials"},
+		Data:       map[string][]byte{"auth": {0xde, 0xad}},
+	}
+	reconciler := &DynamicPrefixReconciler{
+		Client: fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(secret).Build(),
+	}
+
+	ref := &dynamicprefixiov1alpha1.SecretKeySelector{Namespace: "isp", Name: "credentials", Key: "auth"}
+	spec := dynamicprefixiov1alpha1.AcquisitionSpec{
+		DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{
+			Interface: "eth0",
+			Options: &dynamicprefixiov1alpha1.DHCPv6ClientOptionsSpec{
+				Raw: []dynamicprefixiov1alpha1.DHCPv6RawOption{
+					{Code: 11, DHCPv6OptionValue: dynamicprefixiov1alpha1.DHCPv6OptionValue{SecretKeyRef: ref}},
+				},
+			},
+			Authentication: &dynamicprefixiov1alpha1.DHCPv6AuthenticationSpec{
+				Protocol: "delayed",
+				Key:      &dynamicprefixiov1alpha1.DHCPv6OptionValue{SecretKeyRef: ref},
+			},
+		},
+	}
+
+	resolved, err := reconciler.resolveAcquisitionSecrets(context.Background(), spec)
+	if err != nil {
+		t.Fatalf("resolveAcquisitionSecrets() error = %v", err)
+	}
+	raw := resolved.DHCPv6PD.Options.Raw[0]
+	if raw.SecretKeyRef != nil || raw.Hex != "dead" {
+		t.Errorf("raw option = %+v, want hex dead", raw.DHCPv6OptionValue)
+	}
+	if key := resolved.DHCPv6PD.Authentication.Key; key.SecretKeyRef != nil || key.Hex != "dead" {
+		t.Errorf("key = %+v, want hex dead", key)
+	}
+	// The original spec is left untouched
+	if spec.DHCPv6PD.Options.Raw[0].SecretKeyRef == nil {
+		t.Error("Expected the original spec to keep its secret reference")
+	}
+
+	// A missing key is an error
+	spec.DHCPv6PD.Authentication.Key.SecretKeyRef = &dynamicprefixiov1alpha1.SecretKeySelector{Namespace: "isp", Name: "credentials", Key: "missing"}
+	if _, err := reconciler.resolveAcquisitionSecrets(context.Background(), spec); err == nil {
+		t.Error("Expected an error for a missing secret key")
+	}
+}
+
+func TestAppendHistory(t *testing.T) {
+	entries := func(prefixes ...string) []dynamicprefixiov1alpha1.PrefixHistoryEntry {
+		var result []dynamicprefixiov1alpha1.PrefixHistoryEntry
+		for _, p := range prefixes {
+			result = append(result, dynamicprefixiov1alpha1.PrefixHistoryEntry{Prefix: p})
+		}
+		return result
+	}
+
+	tests := []struct {
+		name       string
+		transition *dynamicprefixiov1alpha1.TransitionSpec
+		want       []string
+	}{
+		{
+			name: "Default limit",
+			want: []string{"2001:db8:3::/56", "2001:db8:4::/56"},
+		},
+		{
+			name:       "Configured limit",
+			transition: &dynamicprefixiov1alpha1.TransitionSpec{MaxPrefixHistory: 3},
+			want:       []string{"2001:db8:2::/56", "2001:db8:3::/56", "2001:db8:4::/56"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+				Spec:   dynamicprefixiov1alpha1.DynamicPrefixSpec{Transition: tt.transition},
+				Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{History: entries("2001:db8:1::/56", "2001:db8:2::/56", "2001:db8:3::/56")},
+			}
+			appendHistory(dp, dynamicprefixiov1alpha1.PrefixHistoryEntry{Prefix: "2001:db8:4::/56"})
+
+			var got []string
+			for _, entry := range dp.Status.History {
+				got = append(got, entry.Prefix)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("history = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastKnownPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		status dynamicprefixiov1alpha1.DynamicPrefixStatus
+		want   string
+	}{
+		{name: "No prefix", want: "invalid Prefix"},
+		{
+			name: "Current prefix",
+			status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+				CurrentPrefix: "2001:db8:2::/56",
+				History:       []dynamicprefixiov1alpha1.PrefixHistoryEntry{{Prefix: "2001:db8:1::/56"}},
+			},
+			want: "2001:db8:2::/56",
+		},
+		{
+			name: "Most recent history entry",
+			status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+				History: []dynamicprefixiov1alpha1.PrefixHistoryEntry{
+					{Prefix: "2001:db8:1::/56"},
+					{Prefix: "2001:db8:2::/56"},
+				},
+			},
+			want: "2001:db8:2::/56",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := &dynamicprefixiov1alpha1.DynamicPrefix{Status: tt.status}
+			if got := lastKnownPrefix(dp).String(); got != tt.want {
+				t.Errorf("lastKnownPrefix() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastKnownSource(t *testing.T) {
+	tests := []struct {
+		name   string
+		status dynamicprefixiov1alpha1.DynamicPrefixStatus
+		want   string
+	}{
+		{
+			name:   "Single source",
+			status: dynamicprefixiov1alpha1.DynamicPrefixStatus{CurrentPrefix: "2001:db8:1::/56"},
+		},
+		{
+			name: "Active source of the current prefix",
+			status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+				CurrentPrefix: "2001:db8:1::/56",
+				Acquisition:   &dynamicprefixiov1alpha1.AcquisitionStatus{ActiveSource: "isp-b"},
+			},
+			want: "isp-b",
+		},
+		{
+			name: "Prefix from history has no known source",
+			status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+				Acquisition: &dynamicprefixiov1alpha1.AcquisitionStatus{ActiveSource: "isp-b"},
+				History:     []dynamicprefixiov1alpha1.PrefixHistoryEntry{{Prefix: "2001:db8:1::/56"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := &dynamicprefixiov1alpha1.DynamicPrefix{Status: tt.status}
+			if got := lastKnownSource(dp); got != tt.want {
+				t.Errorf("lastKnownSource() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// recordingHinter records the hints it receives
+type recordingHinter struct {
+	*prefix.MockReceiver
+	hint   netip.Prefix
+	source string
+}
+
+func (h *recordingHinter) SetPrefixHint(hint netip.Prefix) { h.hint = hint }
+
+// recordingSourceHinter records the per-source hints it receives
+type recordingSourceHinter struct {
+	recordingHinter
+}
+
+func (h *recordingSourceHinter) SetSourcePrefixHint(name string, hint netip.Prefix) {
+	h.source, h.hint = name, hint
+}
+
+func TestSetPrefixHint(t *testing.T) {
+	hint := netip.MustParsePrefix("2001:db8:1::/56")
+
+	single := &recordingHinter{MockReceiver: prefix.NewMockReceiver(prefix.SourceDHCPv6PD)}
+	setPrefixHint(single, hint, "")
+	if single.hint != hint {
+		t.Errorf("single-source receiver hint = %v, want %v", single.hint, hint)
+	}
+
+	composite := &recordingSourceHinter{recordingHinter{MockReceiver: prefix.NewMockReceiver(prefix.SourceDHCPv6PD)}}
+	setPrefixHint(composite, hint, "")
+	if composite.hint.IsValid() {
+		t.Errorf("multi-source receiver hinted %v without a known source", composite.hint)
+	}
+	setPrefixHint(composite, hint, "isp-b")
+	if composite.source != "isp-b" || composite.hint != hint {
+		t.Errorf("multi-source receiver hint = %q %v, want isp-b %v", composite.source, composite.hint, hint)
+	}
+}
+
+func TestDynamicPrefixReconciler_updateIPv4Status(t *testing.T) {
+	ctx := context.Background()
+	factory := &fakeReceiverFactory{}
+	reconciler := NewDynamicPrefixReconciler(nil, nil)
+	reconciler.receiverEvents = nil
+	reconciler.ReceiverFactory = factory
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "dual-stack"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			IPv4: &dynamicprefixiov1alpha1.IPv4Spec{
+				DHCPv4: dynamicprefixiov1alpha1.DHCPv4Spec{Interface: "wan0"},
+			},
+		},
+	}
+
+	// No address yet
+	reconciler.updateIPv4Status(ctx, dp)
+	cond := meta.FindStatusCondition(dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeIPv4Acquired)
+	if cond == nil || cond.Status != metav1.ConditionFalse {
+		t.Fatalf("IPv4Acquired = %+v, want False", cond)
+	}
+	if dp.Status.IPv4 != nil {
+		t.Fatalf("status.ipv4 = %+v, want nil", dp.Status.IPv4)
+	}
+
+	receiver := factory.createdIPv4[0]
+	receiver.current = &prefix.IPv4Address{
+		Address:       netip.MustParsePrefix("203.0.113.7/29"),
+		Router:        netip.MustParseAddr("203.0.113.1"),
+		ValidLifetime: time.Hour,
+		ReceivedAt:    time.Now(),
+	}
+	reconciler.updateIPv4Status(ctx, dp)
+	got := dp.Status.IPv4
+	if got == nil || got.Address != "203.0.113.7" || got.Subnet != "203.0.113.0/29" || got.Router != "203.0.113.1" {
+		t.Fatalf("status.ipv4 = %+v", got)
+	}
+	if got.LeaseExpiresAt == nil || got.ChangedAt != nil {
+		t.Errorf("status.ipv4 lease/changedAt = %v/%v", got.LeaseExpiresAt, got.ChangedAt)
+	}
+	if !meta.IsStatusConditionTrue(dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeIPv4Acquired) {
+		t.Error("Expected IPv4Acquired to be True")
+	}
+
+	// A new address records the previous one
+	receiver.current = &prefix.IPv4Address{
+		Address:    netip.MustParsePrefix("198.51.100.20/24"),
+		ReceivedAt: time.Now(),
+	}
+	reconciler.updateIPv4Status(ctx, dp)
+	got = dp.Status.IPv4
+	if got.Address != "198.51.100.20" || got.PreviousAddress != "203.0.113.7" || got.ChangedAt == nil {
+		t.Errorf("status.ipv4 after change = %+v", got)
+	}
+	if got.Router != "" || got.LeaseExpiresAt != nil {
+		t.Errorf("status.ipv4 kept stale router or lease: %+v", got)
+	}
+
+	// The same spec keeps the receiver
+	reconciler.updateIPv4Status(ctx, dp)
+	if len(factory.createdIPv4) != 1 {
+		t.Fatalf("created %d IPv4 receivers, want 1", len(factory.createdIPv4))
+	}
+
+	// Removing spec.ipv4 stops the receiver and clears the status
+	dp.Spec.IPv4 = nil
+	reconciler.updateIPv4Status(ctx, dp)
+	if !receiver.stopped {
+		t.Error("Expected the IPv4 receiver to be stopped")
+	}
+	if dp.Status.IPv4 != nil || meta.FindStatusCondition(dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeIPv4Acquired) != nil {
+		t.Error("Expected status.ipv4 and the IPv4Acquired condition to be removed")
+	}
+}
+
+func TestDynamicPrefixReconciler_handlePrefixChange(t *testing.T) {
+	ctx := context.Background()
+	reconciler := &DynamicPrefixReconciler{}
+	acquired := metav1.NewTime(time.Now().Add(-24 * time.Hour).Truncate(time.Second))
+	leaseEnd := metav1.NewTime(time.Now().Add(2 * time.Hour).Truncate(time.Second))
+
+	tests := []struct {
+		name        string
+		drainPeriod time.Duration
+		lease       *metav1.Time
+		wantExpiry  func(now time.Time) *time.Time
+	}{
+		{
+			name:       "lifetime only",
+			lease:      &leaseEnd,
+			wantExpiry: func(time.Time) *time.Time { return &leaseEnd.Time },
+		},
+		{
+			name:        "drain period shorter than lifetime",
+			drainPeriod: 30 * time.Minute,
+			lease:       &leaseEnd,
+			wantExpiry: func(now time.Time) *time.Time {
+				t := now.Add(30 * time.Minute)
+				return &t
+			},
+		},
+		{
+			name:       "neither known",
+			wantExpiry: func(time.Time) *time.Time { return nil },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+				Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+					CurrentPrefix:    "2001:db8:1::/48",
+					PrefixAcquiredAt: &acquired,
+					LeaseExpiresAt:   tt.lease,
+					History: []dynamicprefixiov1alpha1.PrefixHistoryEntry{
+						{Prefix: "2001:db8:2::/48", State: dynamicprefixiov1alpha1.PrefixStateExpired},
+					},
+				},
+			}
+			if tt.drainPeriod > 0 {
+				dp.Spec.Transition = &dynamicprefixiov1alpha1.TransitionSpec{
+					DrainPeriod: &metav1.Duration{Duration: tt.drainPeriod},
+				}
+			}
+
+			// The new prefix was in the history before and leaves it again
+			reconciler.handlePrefixChange(ctx, dp, &prefix.Prefix{Network: netip.MustParsePrefix("2001:db8:2::/48")})
+
+			if len(dp.Status.History) != 1 {
+				t.Fatalf("history = %+v, want only the replaced prefix", dp.Status.History)
+			}
+			entry := dp.Status.History[0]
+			if entry.Prefix != "2001:db8:1::/48" || entry.State != dynamicprefixiov1alpha1.PrefixStateDraining {
+				t.Errorf("entry = %+v", entry)
+			}
+			if !entry.AcquiredAt.Equal(&acquired) {
+				t.Errorf("AcquiredAt = %v, want %v", entry.AcquiredAt, acquired)
+			}
+			want := tt.wantExpiry(entry.DeprecatedAt.Time)
+			switch {
+			case want == nil && entry.ExpiresAt != nil:
+				t.Errorf("ExpiresAt = %v, want nil", entry.ExpiresAt)
+			case want != nil && (entry.ExpiresAt == nil || !entry.ExpiresAt.Time.Equal(*want)):
+				t.Errorf("ExpiresAt = %v, want %v", entry.ExpiresAt, *want)
+			}
+		})
+	}
+}
+
+func TestDynamicPrefixReconciler_expireHistory(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(d))
+		return &t
+	}
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			History: []dynamicprefixiov1alpha1.PrefixHistoryEntry{
+				{Prefix: "2001:db8:1::/48", State: dynamicprefixiov1alpha1.PrefixStateDraining, ExpiresAt: at(-time.Minute)},
+				{Prefix: "2001:db8:2::/48", State: dynamicprefixiov1alpha1.PrefixStateDraining, ExpiresAt: at(time.Hour)},
+				{Prefix: "2001:db8:3::/48", State: dynamicprefixiov1alpha1.PrefixStateDraining, ExpiresAt: at(10 * time.Minute)},
+				{Prefix: "2001:db8:4::/48", State: dynamicprefixiov1alpha1.PrefixStateDraining},
+			},
+		},
+	}
+
+	next := (&DynamicPrefixReconciler{}).expireHistory(context.Background(), dp, now)
+	if !next.Equal(now.Add(10 * time.Minute)) {
+		t.Errorf("next expiry = %v, want %v", next, now.Add(10*time.Minute))
+	}
+
+	wantStates := []dynamicprefixiov1alpha1.PrefixState{
+		dynamicprefixiov1alpha1.PrefixStateExpired,
+		dynamicprefixiov1alpha1.PrefixStateDraining,
+		dynamicprefixiov1alpha1.PrefixStateDraining,
+		dynamicprefixiov1alpha1.PrefixStateDraining,
+	}
+	for i, want := range wantStates {
+		if got := dp.Status.History[i].State; got != want {
+			t.Errorf("history[%d].State = %s, want %s", i, got, want)
+		}
+	}
+
+	draining := drainingHistory(dp)
+	if len(draining) != 3 || draining[0].Prefix != "2001:db8:2::/48" {
+		t.Errorf("drainingHistory() = %+v", draining)
+	}
+}
+
+func TestDynamicPrefixReconciler_applyOverride(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(d))
+		return &t
+	}
+	observed := &prefix.Prefix{
+		Network: netip.MustParsePrefix("2001:db8:2::/48"),
+		Source:  prefix.SourceRouterAdvertisement,
+	}
+
+	tests := []struct {
+		name          string
+		spec          *dynamicprefixiov1alpha1.OverrideSpec
+		status        dynamicprefixiov1alpha1.DynamicPrefixStatus
+		observed      *prefix.Prefix
+		wantPrefix    string
+		wantSynthetic bool
+		wantExpiry    time.Time
+		wantCondition metav1.ConditionStatus
+		wantReason    string
+	}{
+		{
+			name:       "no override",
+			observed:   observed,
+			wantPrefix: "2001:db8:2::/48",
+		},
+		{
+			name:          "freeze holds the current prefix",
+			spec:          &dynamicprefixiov1alpha1.OverrideSpec{Mode: dynamicprefixiov1alpha1.OverrideModeFreeze, ExpiresAt: at(time.Hour)},
+			status:        dynamicprefixiov1alpha1.DynamicPrefixStatus{CurrentPrefix: "2001:db8:1::/48"},
+			observed:      observed,
+			wantPrefix:    "2001:db8:1::/48",
+			wantSynthetic: true,
+			wantExpiry:    now.Add(time.Hour),
+			wantCondition: metav1.ConditionTrue,
+			wantReason:    "Frozen",
+		},
+		{
+			name: "freeze keeps the frozen prefix",
+			spec: &dynamicprefixiov1alpha1.OverrideSpec{Mode: dynamicprefixiov1alpha1.OverrideModeFreeze},
+			status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+				CurrentPrefix: "2001:db8:3::/48",
+				Override:      &dynamicprefixiov1alpha1.OverrideStatus{Mode: dynamicprefixiov1alpha1.OverrideModeFreeze, Prefix: "2001:db8:2::/48"},
+			},
+			observed:      observed,
+			wantPrefix:    "2001:db8:2::/48",
+			wantCondition: metav1.ConditionTrue,
+			wantReason:    "Frozen",
+		},
+		{
+			name:          "freeze without a prefix",
+			spec:          &dynamicprefixiov1alpha1.OverrideSpec{Mode: dynamicprefixiov1alpha1.OverrideModeFreeze},
+			wantCondition: metav1.ConditionFalse,
+			wantReason:    "WaitingForPrefix",
+		},
+		{
+			name:          "force",
+			spec:          &dynamicprefixiov1alpha1.OverrideSpec{Mode: dynamicprefixiov1alpha1.OverrideModeForce, Prefix: "2001:db8:ff::/48"},
+			observed:      observed,
+			wantPrefix:    "2001:db8:ff::/48",
+			wantSynthetic: true,
+			wantCondition: metav1.ConditionTrue,
+			wantReason:    "Forced",
+		},
+		{
+			name:          "force without receivers",
+			spec:          &dynamicprefixiov1alpha1.OverrideSpec{Mode: dynamicprefixiov1alpha1.OverrideModeForce, Prefix: "2001:db8:ff::/48"},
+			wantPrefix:    "2001:db8:ff::/48",
+			wantSynthetic: true,
+			wantCondition: metav1.ConditionTrue,
+			wantReason:    "Forced",
+		},
+		{
+			name:          "force with an IPv4 prefix",
+			spec:          &dynamicprefixiov1alpha1.OverrideSpec{Mode: dynamicprefixiov1alpha1.OverrideModeForce, Prefix: "192.0.2.0/24"},
+			observed:      observed,
+			wantPrefix:    "2001:db8:2::/48",
+			wantCondition: metav1.ConditionFalse,
+			wantReason:    "InvalidOverride",
+		},
+		{
+			name:          "expired",
+			spec:          &dynamicprefixiov1alpha1.OverrideSpec{Mode: dynamicprefixiov1alpha1.OverrideModeForce, Prefix: "2001:db8:ff::/48", ExpiresAt: at(-time.Minute)},
+			status:        dynamicprefixiov1alpha1.DynamicPrefixStatus{Override: &dynamicprefixiov1alpha1.OverrideStatus{Mode: dynamicprefixiov1alpha1.OverrideModeForce}},
+			observed:      observed,
+			wantPrefix:    "2001:db8:2::/48",
+			wantCondition: metav1.ConditionFalse,
+			wantReason:    "OverrideExpired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+				Spec:   dynamicprefixiov1alpha1.DynamicPrefixSpec{Override: tt.spec},
+				Status: tt.status,
+			}
+			got, expiry := (&DynamicPrefixReconciler{}).applyOverride(context.Background(), dp, tt.observed, now)
+
+			gotPrefix := ""
+			if got != nil {
+				gotPrefix = got.Network.String()
+			}
+			if gotPrefix != tt.wantPrefix {
+				t.Errorf("prefix = %q, want %q", gotPrefix, tt.wantPrefix)
+			}
+			if got != nil && (got != tt.observed) != tt.wantSynthetic {
+				t.Errorf("synthetic prefix = %v, want %v", got != tt.observed, tt.wantSynthetic)
+			}
+			if !expiry.Equal(tt.wantExpiry) {
+				t.Errorf("expiry = %v, want %v", expiry, tt.wantExpiry)
+			}
+
+			cond := meta.FindStatusCondition(dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeOverridden)
+			if tt.wantReason == "" {
+				if cond != nil {
+					t.Errorf("unexpected Overridden condition %+v", cond)
+				}
+				return
+			}
+			if cond == nil || cond.Status != tt.wantCondition || cond.Reason != tt.wantReason {
+				t.Fatalf("Overridden condition = %+v, want %s/%s", cond, tt.wantCondition, tt.wantReason)
+			}
+			if tt.wantCondition == metav1.ConditionTrue {
+				st := dp.Status.Override
+				if st == nil || st.Prefix != tt.wantPrefix || st.Mode != tt.spec.Mode {
+					t.Errorf("status.override = %+v", st)
+				} else if tt.observed != nil && st.ObservedPrefix != tt.observed.Network.String() {
+					t.Errorf("status.override.observedPrefix = %q", st.ObservedPrefix)
+				}
+			} else if dp.Status.Override != nil {
+				t.Errorf("status.override = %+v, want nil", dp.Status.Override)
+			}
+		})
+	}
+}
+
+func TestDynamicPrefixReconciler_handleLostPrefix(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(d))
+		return &t
+	}
+	withdrawnAfter := func(d time.Duration) *dynamicprefixiov1alpha1.LostPrefixPolicySpec {
+		return &dynamicprefixiov1alpha1.LostPrefixPolicySpec{
+			Action:      dynamicprefixiov1alpha1.LostPrefixActionRetain,
+			GracePeriod: &metav1.Duration{Duration: d},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		policy        *dynamicprefixiov1alpha1.LostPrefixPolicySpec
+		leaseExpires  *metav1.Time
+		wantReason    string
+		wantWithdrawn bool
+		wantWithdraw  time.Time
+		wantEvents    int
+	}{
+		{
+			name:         "lease still valid",
+			policy:       &dynamicprefixiov1alpha1.LostPrefixPolicySpec{Action: dynamicprefixiov1alpha1.LostPrefixActionWithdraw},
+			leaseExpires: at(time.Minute),
+			wantReason:   "WaitingForPrefix",
+		},
+		{
+			name:       "lease unknown",
+			policy:     &dynamicprefixiov1alpha1.LostPrefixPolicySpec{Action: dynamicprefixiov1alpha1.LostPrefixActionWithdraw},
+			wantReason: "WaitingForPrefix",
+		},
+		{
+			name:         "retain by default",
+			leaseExpires: at(-time.Minute),
+			wantReason:   "PrefixRetained",
+			wantEvents:   1,
+		},
+		{
+			name:         "retain for grace period",
+			policy:       withdrawnAfter(time.Hour),
+			leaseExpires: at(-time.Minute),
+			wantReason:   "PrefixRetained",
+			wantWithdraw: now.Add(time.Hour - time.Minute),
+			wantEvents:   1,
+		},
+		{
+			name:          "grace period over",
+			policy:        withdrawnAfter(time.Minute),
+			leaseExpires:  at(-time.Hour),
+			wantReason:    "PrefixWithdrawn",
+			wantWithdrawn: true,
+			wantEvents:    2,
+		},
+		{
+			name:          "withdraw",
+			policy:        &dynamicprefixiov1alpha1.LostPrefixPolicySpec{Action: dynamicprefixiov1alpha1.LostPrefixActionWithdraw},
+			leaseExpires:  at(-time.Minute),
+			wantReason:    "PrefixWithdrawn",
+			wantWithdrawn: true,
+			wantEvents:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+				Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{LostPrefixPolicy: tt.policy},
+				Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+					CurrentPrefix:  "2001:db8:2::/48",
+					LeaseExpiresAt: tt.leaseExpires,
+					Subnets:        []dynamicprefixiov1alpha1.SubnetStatus{{Name: "lb", CIDR: "2001:db8:2:1::/64"}},
+					History: []dynamicprefixiov1alpha1.PrefixHistoryEntry{
+						{Prefix: "2001:db8:1::/48", State: dynamicprefixiov1alpha1.PrefixStateDraining},
+					},
+				},
+			}
+			recorder := record.NewFakeRecorder(10)
+			r := &DynamicPrefixReconciler{Recorder: recorder}
+
+			withdrawAt := r.handleLostPrefix(context.Background(), dp, now)
+
+			cond := meta.FindStatusCondition(dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypePrefixAcquired)
+			if cond == nil || cond.Reason != tt.wantReason {
+				t.Fatalf("PrefixAcquired condition = %+v, want reason %s", cond, tt.wantReason)
+			}
+			if !withdrawAt.Equal(tt.wantWithdraw) {
+				t.Errorf("withdrawAt = %v, want %v", withdrawAt, tt.wantWithdraw)
+			}
+			if got := len(recorder.Events); got != tt.wantEvents {
+				t.Errorf("recorded %d events, want %d", got, tt.wantEvents)
+			}
+			if got := prefixWithdrawn(dp); got != tt.wantWithdrawn {
+				t.Errorf("prefixWithdrawn() = %v, want %v", got, tt.wantWithdrawn)
+			}
+
+			if tt.wantWithdrawn {
+				if dp.Status.CurrentPrefix != "" || dp.Status.Subnets != nil {
+					t.Errorf("withdrawn prefix still in status: %q %v", dp.Status.CurrentPrefix, dp.Status.Subnets)
+				}
+				if draining := drainingHistory(dp); len(draining) != 0 {
+					t.Errorf("drainingHistory() = %+v, want none", draining)
+				}
+				if last := dp.Status.History[len(dp.Status.History)-1]; last.Prefix != "2001:db8:2::/48" {
+					t.Errorf("last history entry = %+v, want the withdrawn prefix", last)
+				}
+			} else if dp.Status.CurrentPrefix != "2001:db8:2::/48" {
+				t.Errorf("CurrentPrefix = %q, want the retained prefix", dp.Status.CurrentPrefix)
+			}
+		})
+	}
+}
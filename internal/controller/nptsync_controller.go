@@ -0,0 +1,403 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+)
+
+// Keys of the NPTv6 ConfigMap
+const (
+	// NPTKeyInternalPrefix holds the internal side of the mapping
+	NPTKeyInternalPrefix = "internal-prefix"
+	// NPTKeyExternalPrefix holds the external side of the mapping, empty while no prefix is active
+	NPTKeyExternalPrefix = "external-prefix"
+	// NPTKeyNftables holds an nftables ruleset, applied with nft -f
+	NPTKeyNftables = "nptv6.nft"
+	// NPTKeyIP6Tables holds ip6tables rules, applied with ip6tables-restore --noflush
+	NPTKeyIP6Tables = "nptv6.rules"
+)
+
+// NPTSyncReconciler renders the NPTv6 mapping of a DynamicPrefix with spec.npt
+// into a ConfigMap, so that a router agent can translate a stable internal
+// prefix to the current one without knowing the ISP prefix.
+type NPTSyncReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// nptMapping is an RFC 6296 mapping of the internal prefix to the external one.
+// Without an external prefix the rules translate nothing.
+type nptMapping struct {
+	internal netip.Prefix
+	external netip.Prefix
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile renders the NPTv6 ConfigMap of a DynamicPrefix and reports the mapping in status.
+func (r *NPTSyncReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var dp dynamicprefixiov1alpha1.DynamicPrefix
+	if err := r.Get(ctx, req.NamespacedName, &dp); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if dp.Spec.NPT == nil {
+		if err := r.deleteOrphanedConfigMaps(ctx, &dp, types.NamespacedName{}); err != nil {
+			log.Error(err, "Failed to delete orphaned NPTv6 ConfigMaps")
+		}
+		return ctrl.Result{}, r.updateStatus(ctx, &dp, nil, nil)
+	}
+
+	log.V(1).Info("Reconciling NPTv6 mapping", "dynamicPrefix", dp.Name)
+
+	key := nptConfigMapKey(&dp)
+	mapping, mappingErr := nptMappingFor(&dp)
+	condition := nptCondition(mapping, mappingErr, key)
+
+	if err := r.reconcileConfigMap(ctx, &dp, key, mapping); err != nil {
+		log.Error(err, "Failed to render NPTv6 ConfigMap", "configMap", key)
+		condition = metav1.Condition{
+			Type:    dynamicprefixiov1alpha1.ConditionTypeNPTReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "ConfigMapFailed",
+			Message: err.Error(),
+		}
+		if statusErr := r.updateStatus(ctx, &dp, nil, &condition); statusErr != nil {
+			log.Error(statusErr, "Failed to update DynamicPrefix status")
+		}
+		return ctrl.Result{}, err
+	}
+	if mapping.external.IsValid() {
+		if err := recordPrefixChangeUpdate(ctx, r.Client, &dp, "ConfigMap", key.Namespace, key.Name); err != nil {
+			log.Error(err, "Failed to record NPTv6 ConfigMap update in PrefixChange")
+		}
+	}
+
+	if err := r.deleteOrphanedConfigMaps(ctx, &dp, key); err != nil {
+		log.Error(err, "Failed to delete orphaned NPTv6 ConfigMaps")
+	}
+
+	status := &dynamicprefixiov1alpha1.NPTStatus{
+		ConfigMap: key.String(),
+	}
+	if mapping.internal.IsValid() {
+		status.InternalPrefix = mapping.internal.String()
+	}
+	if mapping.external.IsValid() {
+		status.ExternalPrefix = mapping.external.String()
+	}
+	if err := r.updateStatus(ctx, &dp, status, &condition); err != nil {
+		log.Error(err, "Failed to update DynamicPrefix status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// nptConfigMapKey returns the ConfigMap spec.npt renders into.
+func nptConfigMapKey(dp *dynamicprefixiov1alpha1.DynamicPrefix) types.NamespacedName {
+	name := dp.Spec.NPT.ConfigMap.Name
+	if name == "" {
+		name = dp.Name + "-npt"
+	}
+	return types.NamespacedName{Namespace: dp.Spec.NPT.ConfigMap.Namespace, Name: name}
+}
+
+// nptMappingFor maps the internal prefix, spec.npt.internalPrefix or else the
+// ULA, to the primary active prefix. NPTv6 needs both sides at the same
+// length, so the internal prefix is narrowed to the length of the external one.
+func nptMappingFor(dp *dynamicprefixiov1alpha1.DynamicPrefix) (nptMapping, error) {
+	internalCIDR := dp.Spec.NPT.InternalPrefix
+	if internalCIDR == "" && dp.Status.ULA != nil {
+		internalCIDR = dp.Status.ULA.Prefix
+	}
+	if internalCIDR == "" {
+		return nptMapping{}, fmt.Errorf("no internal prefix: set spec.npt.internalPrefix or spec.ula")
+	}
+	internal, err := netip.ParsePrefix(internalCIDR)
+	if err != nil {
+		return nptMapping{}, fmt.Errorf("invalid internal prefix %q: %w", internalCIDR, err)
+	}
+	if !internal.Addr().Is6() || internal.Addr().Is4In6() {
+		return nptMapping{}, fmt.Errorf("internal prefix %s is not an IPv6 prefix", internalCIDR)
+	}
+	mapping := nptMapping{internal: internal.Masked()}
+
+	for _, active := range activePrefixes(dp) {
+		if !active.primary {
+			continue
+		}
+		external, err := netip.ParsePrefix(active.prefix)
+		if err != nil {
+			return mapping, fmt.Errorf("invalid external prefix %q: %w", active.prefix, err)
+		}
+		if internal.Bits() > external.Bits() {
+			return mapping, fmt.Errorf("internal prefix %s is longer than the external /%d", mapping.internal, external.Bits())
+		}
+		mapping.internal = netip.PrefixFrom(internal.Addr(), external.Bits()).Masked()
+		mapping.external = external.Masked()
+	}
+	return mapping, nil
+}
+
+// nptCondition builds the NPTReady condition for a mapping.
+func nptCondition(mapping nptMapping, mappingErr error, key types.NamespacedName) metav1.Condition {
+	condition := metav1.Condition{
+		Type: dynamicprefixiov1alpha1.ConditionTypeNPTReady,
+	}
+	switch {
+	case mappingErr != nil:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "InvalidMapping"
+		condition.Message = mappingErr.Error()
+	case !mapping.external.IsValid():
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "WaitingForPrefix"
+		condition.Message = fmt.Sprintf("No active prefix to map %s to, ConfigMap %s translates nothing", mapping.internal, key)
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "RulesRendered"
+		condition.Message = fmt.Sprintf("Mapping %s to %s in ConfigMap %s", mapping.internal, mapping.external, key)
+	}
+	return condition
+}
+
+// reconcileConfigMap creates or updates the NPTv6 ConfigMap. A mapping without
+// both sides renders rules that remove any previous translation.
+func (r *NPTSyncReconciler) reconcileConfigMap(
+	ctx context.Context,
+	dp *dynamicprefixiov1alpha1.DynamicPrefix,
+	key types.NamespacedName,
+	mapping nptMapping,
+) error {
+	log := logf.FromContext(ctx)
+
+	cm := &corev1.ConfigMap{}
+	cm.Namespace = key.Namespace
+	cm.Name = key.Name
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		if cm.Labels == nil {
+			cm.Labels = make(map[string]string)
+		}
+		cm.Labels[LabelManagedBy] = LabelManagedByValue
+		cm.Labels[LabelDynamicPrefixName] = dp.Name
+
+		data := map[string]string{
+			NPTKeyInternalPrefix: "",
+			NPTKeyExternalPrefix: "",
+			NPTKeyNftables:       renderNPTNftables(dp, mapping),
+			NPTKeyIP6Tables:      renderNPTIP6Tables(dp, mapping),
+		}
+		if mapping.internal.IsValid() {
+			data[NPTKeyInternalPrefix] = mapping.internal.String()
+		}
+		if mapping.external.IsValid() {
+			data[NPTKeyExternalPrefix] = mapping.external.String()
+		}
+		cm.Data = data
+
+		// The ConfigMap is garbage collected with its DynamicPrefix
+		return controllerutil.SetControllerReference(dp, cm, r.Scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create or update ConfigMap %s: %w", key, err)
+	}
+	if result != controllerutil.OperationResultNone {
+		log.Info("Rendered NPTv6 ConfigMap", "configMap", key, "operation", result)
+	}
+	return nil
+}
+
+// renderNPTNftables renders the mapping as an nftables ruleset. Loading it
+// replaces the table of the previous mapping atomically.
+func renderNPTNftables(dp *dynamicprefixiov1alpha1.DynamicPrefix, mapping nptMapping) string {
+	table := "dynamic_prefix_npt_" + strings.NewReplacer("-", "_", ".", "_").Replace(dp.Name)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# NPTv6 mapping of DynamicPrefix %s, rendered by %s\n", dp.Name, LabelManagedByValue)
+	fmt.Fprintf(&b, "table ip6 %s\n", table)
+	fmt.Fprintf(&b, "delete table ip6 %s\n", table)
+	if !mapping.internal.IsValid() || !mapping.external.IsValid() {
+		return b.String()
+	}
+
+	var iif, oif string
+	if dp.Spec.NPT.Interface != "" {
+		iif = fmt.Sprintf("iifname %q ", dp.Spec.NPT.Interface)
+		oif = fmt.Sprintf("oifname %q ", dp.Spec.NPT.Interface)
+	}
+	fmt.Fprintf(&b, "table ip6 %s {\n", table)
+	b.WriteString("\tchain prerouting {\n")
+	b.WriteString("\t\ttype nat hook prerouting priority dstnat; policy accept;\n")
+	fmt.Fprintf(&b, "\t\t%sip6 daddr %s dnat ip6 prefix to ip6 daddr map { %s : %s }\n",
+		iif, mapping.external, mapping.external, mapping.internal)
+	b.WriteString("\t}\n")
+	b.WriteString("\tchain postrouting {\n")
+	b.WriteString("\t\ttype nat hook postrouting priority srcnat; policy accept;\n")
+	fmt.Fprintf(&b, "\t\t%sip6 saddr %s snat ip6 prefix to ip6 saddr map { %s : %s }\n",
+		oif, mapping.internal, mapping.internal, mapping.external)
+	b.WriteString("\t}\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// renderNPTIP6Tables renders the mapping as ip6tables-restore input using the
+// stateless DNPT and SNPT targets. Restoring it with --noflush flushes and
+// refills the two chains; the agent jumps to them from PREROUTING and
+// POSTROUTING of the mangle table once.
+func renderNPTIP6Tables(dp *dynamicprefixiov1alpha1.DynamicPrefix, mapping nptMapping) string {
+	// Chain names are limited to 28 characters, so they carry a hash of the name
+	sum := sha1.Sum([]byte(dp.Name))
+	chain := "DPNPT-" + strings.ToUpper(hex.EncodeToString(sum[:4]))
+	pre, post := chain+"-PRE", chain+"-POST"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# NPTv6 mapping of DynamicPrefix %s, rendered by %s\n", dp.Name, LabelManagedByValue)
+	b.WriteString("*mangle\n")
+	fmt.Fprintf(&b, ":%s - [0:0]\n", pre)
+	fmt.Fprintf(&b, ":%s - [0:0]\n", post)
+	if mapping.internal.IsValid() && mapping.external.IsValid() {
+		var iif, oif string
+		if dp.Spec.NPT.Interface != "" {
+			iif = " -i " + dp.Spec.NPT.Interface
+			oif = " -o " + dp.Spec.NPT.Interface
+		}
+		fmt.Fprintf(&b, "-A %s%s -d %s -j DNPT --src-pfx %s --dst-pfx %s\n",
+			pre, iif, mapping.external, mapping.external, mapping.internal)
+		fmt.Fprintf(&b, "-A %s%s -s %s -j SNPT --src-pfx %s --dst-pfx %s\n",
+			post, oif, mapping.internal, mapping.internal, mapping.external)
+	}
+	b.WriteString("COMMIT\n")
+	return b.String()
+}
+
+// deleteOrphanedConfigMaps removes the NPTv6 ConfigMaps of dp other than keep,
+// e.g. after spec.npt.configMap was changed or spec.npt removed.
+func (r *NPTSyncReconciler) deleteOrphanedConfigMaps(
+	ctx context.Context,
+	dp *dynamicprefixiov1alpha1.DynamicPrefix,
+	keep types.NamespacedName,
+) error {
+	log := logf.FromContext(ctx)
+
+	var list corev1.ConfigMapList
+	if err := r.List(ctx, &list, client.MatchingLabels{
+		LabelManagedBy:         LabelManagedByValue,
+		LabelDynamicPrefixName: dp.Name,
+	}); err != nil {
+		return fmt.Errorf("failed to list ConfigMaps: %w", err)
+	}
+
+	for i := range list.Items {
+		cm := &list.Items[i]
+		if client.ObjectKeyFromObject(cm) == keep || !metav1.IsControlledBy(cm, dp) {
+			continue
+		}
+		if err := r.Delete(ctx, cm); client.IgnoreNotFound(err) != nil {
+			log.Error(err, "Failed to delete orphaned NPTv6 ConfigMap", "configMap", client.ObjectKeyFromObject(cm))
+			continue
+		}
+		log.Info("Deleted orphaned NPTv6 ConfigMap", "configMap", client.ObjectKeyFromObject(cm))
+	}
+	return nil
+}
+
+// updateStatus server-side applies status.npt and the NPTReady condition, the
+// only status fields this controller owns. A nil condition clears both.
+func (r *NPTSyncReconciler) updateStatus(
+	ctx context.Context,
+	dp *dynamicprefixiov1alpha1.DynamicPrefix,
+	npt *dynamicprefixiov1alpha1.NPTStatus,
+	condition *metav1.Condition,
+) error {
+	if condition == nil {
+		if dp.Status.NPT == nil && meta.FindStatusCondition(dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeNPTReady) == nil {
+			return nil
+		}
+		return applyDynamicPrefixStatus(ctx, r.Client, dp, map[string]interface{}{}, FieldManagerNPTSync)
+	}
+
+	// A failed render keeps the last reported mapping
+	if npt == nil {
+		npt = dp.Status.NPT
+	}
+	statusChanged := false
+	if npt != nil {
+		previous := dp.Status.NPT
+		if previous != nil && previous.InternalPrefix == npt.InternalPrefix && previous.ExternalPrefix == npt.ExternalPrefix {
+			npt.LastUpdated = previous.LastUpdated
+		} else {
+			now := metav1.Now()
+			npt.LastUpdated = &now
+		}
+		if previous == nil || *previous != *npt {
+			statusChanged = true
+		}
+		dp.Status.NPT = npt
+	}
+	if meta.SetStatusCondition(&dp.Status.Conditions, *condition) {
+		statusChanged = true
+	}
+	if !statusChanged {
+		return nil
+	}
+
+	cond, err := runtime.DefaultUnstructuredConverter.ToUnstructured(
+		meta.FindStatusCondition(dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeNPTReady))
+	if err != nil {
+		return fmt.Errorf("failed to convert condition: %w", err)
+	}
+	status := map[string]interface{}{
+		"conditions": []interface{}{cond},
+	}
+	if dp.Status.NPT != nil {
+		npt, err := runtime.DefaultUnstructuredConverter.ToUnstructured(dp.Status.NPT)
+		if err != nil {
+			return fmt.Errorf("failed to convert NPT status: %w", err)
+		}
+		status["npt"] = npt
+	}
+	return applyDynamicPrefixStatus(ctx, r.Client, dp, status, FieldManagerNPTSync)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NPTSyncReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("nptsync").
+		For(&dynamicprefixiov1alpha1.DynamicPrefix{}).
+		Owns(&corev1.ConfigMap{}).
+		Complete(r)
+}
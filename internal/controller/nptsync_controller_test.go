@@ -0,0 +1,303 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+)
+
+func TestNPTMappingFor(t *testing.T) {
+	tests := []struct {
+		name         string
+		npt          dynamicprefixiov1alpha1.NPTSpec
+		status       dynamicprefixiov1alpha1.DynamicPrefixStatus
+		wantInternal string
+		wantExternal string
+		wantErr      string
+	}{
+		{
+			name:         "ULA narrowed to the external length",
+			status:       dynamicprefixiov1alpha1.DynamicPrefixStatus{CurrentPrefix: "2001:db8:0:100::/56", ULA: &dynamicprefixiov1alpha1.ULAStatus{Prefix: "fd12:3456:789a::/48"}},
+			wantInternal: "fd12:3456:789a::/56",
+			wantExternal: "2001:db8:0:100::/56",
+		},
+		{
+			name:         "explicit internal prefix takes precedence",
+			npt:          dynamicprefixiov1alpha1.NPTSpec{InternalPrefix: "fd00:1:2:300::/56"},
+			status:       dynamicprefixiov1alpha1.DynamicPrefixStatus{CurrentPrefix: "2001:db8:0:100::/56", ULA: &dynamicprefixiov1alpha1.ULAStatus{Prefix: "fd12:3456:789a::/48"}},
+			wantInternal: "fd00:1:2:300::/56",
+			wantExternal: "2001:db8:0:100::/56",
+		},
+		{
+			name:         "primary uplink is the external prefix",
+			npt:          dynamicprefixiov1alpha1.NPTSpec{InternalPrefix: "fd00:1::/48"},
+			status:       dynamicprefixiov1alpha1.DynamicPrefixStatus{CurrentPrefix: "2001:db8:a::/48", Uplinks: []dynamicprefixiov1alpha1.UplinkStatus{{Name: "b", Prefix: "2001:db8:b::/48", Primary: true}}},
+			wantInternal: "fd00:1::/48",
+			wantExternal: "2001:db8:b::/48",
+		},
+		{
+			name:         "no active prefix",
+			npt:          dynamicprefixiov1alpha1.NPTSpec{InternalPrefix: "fd00:1::/48"},
+			wantInternal: "fd00:1::/48",
+		},
+		{
+			name:    "no internal prefix",
+			status:  dynamicprefixiov1alpha1.DynamicPrefixStatus{CurrentPrefix: "2001:db8::/48"},
+			wantErr: "no internal prefix",
+		},
+		{
+			name:    "IPv4 internal prefix",
+			npt:     dynamicprefixiov1alpha1.NPTSpec{InternalPrefix: "10.0.0.0/8"},
+			status:  dynamicprefixiov1alpha1.DynamicPrefixStatus{CurrentPrefix: "2001:db8::/48"},
+			wantErr: "not an IPv6 prefix",
+		},
+		{
+			name:         "internal prefix longer than external",
+			npt:          dynamicprefixiov1alpha1.NPTSpec{InternalPrefix: "fd00:1::/64"},
+			status:       dynamicprefixiov1alpha1.DynamicPrefixStatus{CurrentPrefix: "2001:db8::/56"},
+			wantInternal: "fd00:1::/64",
+			wantErr:      "longer than the external /56",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			npt := tt.npt
+			dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+				ObjectMeta: metav1.ObjectMeta{Name: "home"},
+				Spec:       dynamicprefixiov1alpha1.DynamicPrefixSpec{NPT: &npt},
+				Status:     tt.status,
+			}
+
+			mapping, err := nptMappingFor(dp)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("nptMappingFor() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("nptMappingFor() error = %v", err)
+			}
+			if got := prefixString(mapping.internal); got != tt.wantInternal {
+				t.Errorf("internal = %q, want %q", got, tt.wantInternal)
+			}
+			if got := prefixString(mapping.external); got != tt.wantExternal {
+				t.Errorf("external = %q, want %q", got, tt.wantExternal)
+			}
+		})
+	}
+}
+
+func TestRenderNPTRules(t *testing.T) {
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home-lab"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			NPT: &dynamicprefixiov1alpha1.NPTSpec{Interface: "wan0"},
+		},
+	}
+	dp.Status = dynamicprefixiov1alpha1.DynamicPrefixStatus{
+		CurrentPrefix: "2001:db8:0:100::/56",
+		ULA:           &dynamicprefixiov1alpha1.ULAStatus{Prefix: "fd12:3456:789a::/48"},
+	}
+	mapping, err := nptMappingFor(dp)
+	if err != nil {
+		t.Fatalf("nptMappingFor() error = %v", err)
+	}
+
+	nft := renderNPTNftables(dp, mapping)
+	for _, want := range []string{
+		"table ip6 dynamic_prefix_npt_home_lab\ndelete table ip6 dynamic_prefix_npt_home_lab\n",
+		`iifname "wan0" ip6 daddr 2001:db8:0:100::/56 dnat ip6 prefix to ip6 daddr map { 2001:db8:0:100::/56 : fd12:3456:789a::/56 }`,
+		`oifname "wan0" ip6 saddr fd12:3456:789a::/56 snat ip6 prefix to ip6 saddr map { fd12:3456:789a::/56 : 2001:db8:0:100::/56 }`,
+	} {
+		if !strings.Contains(nft, want) {
+			t.Errorf("nftables rules missing %q:\n%s", want, nft)
+		}
+	}
+
+	rules := renderNPTIP6Tables(dp, mapping)
+	for _, want := range []string{
+		"*mangle\n",
+		"-i wan0 -d 2001:db8:0:100::/56 -j DNPT --src-pfx 2001:db8:0:100::/56 --dst-pfx fd12:3456:789a::/56\n",
+		"-o wan0 -s fd12:3456:789a::/56 -j SNPT --src-pfx fd12:3456:789a::/56 --dst-pfx 2001:db8:0:100::/56\n",
+		"COMMIT\n",
+	} {
+		if !strings.Contains(rules, want) {
+			t.Errorf("ip6tables rules missing %q:\n%s", want, rules)
+		}
+	}
+	for _, line := range strings.Split(rules, "\n") {
+		if chain, ok := strings.CutPrefix(line, ":"); ok {
+			if name, _, _ := strings.Cut(chain, " "); len(name) > 28 {
+				t.Errorf("chain name %q exceeds 28 characters", name)
+			}
+		}
+	}
+
+	// Without an external prefix the rules only remove the previous translation
+	mapping.external = netip.Prefix{}
+	if nft := renderNPTNftables(dp, mapping); strings.Contains(nft, "snat") || !strings.Contains(nft, "delete table") {
+		t.Errorf("nftables rules without external prefix = %q, want only the table removal", nft)
+	}
+	if rules := renderNPTIP6Tables(dp, mapping); strings.Contains(rules, "NPT --") || !strings.Contains(rules, "COMMIT") {
+		t.Errorf("ip6tables rules without external prefix = %q, want only the flushed chains", rules)
+	}
+}
+
+func TestNPTSyncReconciler_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", UID: "uid-home"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			ULA: &dynamicprefixiov1alpha1.ULASpec{},
+			NPT: &dynamicprefixiov1alpha1.NPTSpec{
+				ConfigMap: dynamicprefixiov1alpha1.NPTConfigMapSpec{Namespace: "router"},
+			},
+		},
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			CurrentPrefix: "2001:db8:1::/48",
+			ULA:           &dynamicprefixiov1alpha1.ULAStatus{Prefix: "fd12:3456:789a::/48"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(dp).
+		WithStatusSubresource(dp).
+		WithTypeConverters(newDynamicPrefixTypeConverter(t)...).
+		Build()
+	reconciler := &NPTSyncReconciler{Client: fakeClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "home"}}
+
+	reconcile := func() {
+		t.Helper()
+		if _, err := reconciler.Reconcile(ctx, req); err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+	}
+	get := func() *dynamicprefixiov1alpha1.DynamicPrefix {
+		t.Helper()
+		var current dynamicprefixiov1alpha1.DynamicPrefix
+		if err := fakeClient.Get(ctx, req.NamespacedName, &current); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		return &current
+	}
+	getConfigMap := func(name string) (*corev1.ConfigMap, error) {
+		var cm corev1.ConfigMap
+		err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "router", Name: name}, &cm)
+		return &cm, err
+	}
+
+	reconcile()
+
+	cm, err := getConfigMap("home-npt")
+	if err != nil {
+		t.Fatalf("ConfigMap not rendered: %v", err)
+	}
+	if cm.Data[NPTKeyInternalPrefix] != "fd12:3456:789a::/48" || cm.Data[NPTKeyExternalPrefix] != "2001:db8:1::/48" {
+		t.Errorf("ConfigMap mapping = %s -> %s, want fd12:3456:789a::/48 -> 2001:db8:1::/48",
+			cm.Data[NPTKeyInternalPrefix], cm.Data[NPTKeyExternalPrefix])
+	}
+	if !strings.Contains(cm.Data[NPTKeyNftables], "snat ip6 prefix to") || !strings.Contains(cm.Data[NPTKeyIP6Tables], "-j SNPT") {
+		t.Errorf("ConfigMap rules not rendered: %v", cm.Data)
+	}
+	if cm.Labels[LabelManagedBy] != LabelManagedByValue || cm.Labels[LabelDynamicPrefixName] != "home" {
+		t.Errorf("ConfigMap labels = %v", cm.Labels)
+	}
+	if len(cm.OwnerReferences) != 1 || cm.OwnerReferences[0].Name != "home" {
+		t.Errorf("ConfigMap owner references = %v, want the DynamicPrefix", cm.OwnerReferences)
+	}
+
+	current := get()
+	if current.Status.NPT == nil || current.Status.NPT.ExternalPrefix != "2001:db8:1::/48" ||
+		current.Status.NPT.ConfigMap != "router/home-npt" || current.Status.NPT.LastUpdated == nil {
+		t.Fatalf("status.npt = %+v", current.Status.NPT)
+	}
+	if !meta.IsStatusConditionTrue(current.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeNPTReady) {
+		t.Errorf("NPTReady not true: %+v", current.Status.Conditions)
+	}
+	if current.Status.CurrentPrefix != "2001:db8:1::/48" {
+		t.Errorf("CurrentPrefix = %q, want it kept", current.Status.CurrentPrefix)
+	}
+
+	// A prefix change re-renders the rules
+	current.Status.CurrentPrefix = "2001:db8:2::/48"
+	if err := fakeClient.Status().Update(ctx, current); err != nil {
+		t.Fatalf("Status().Update() error = %v", err)
+	}
+	reconcile()
+	if cm, _ = getConfigMap("home-npt"); cm.Data[NPTKeyExternalPrefix] != "2001:db8:2::/48" ||
+		!strings.Contains(cm.Data[NPTKeyNftables], "map { fd12:3456:789a::/48 : 2001:db8:2::/48 }") {
+		t.Errorf("ConfigMap after prefix change = %v", cm.Data)
+	}
+	if got := get().Status.NPT.ExternalPrefix; got != "2001:db8:2::/48" {
+		t.Errorf("status.npt.externalPrefix = %q, want 2001:db8:2::/48", got)
+	}
+
+	// Renaming the ConfigMap removes the previous one
+	current = get()
+	current.Spec.NPT.ConfigMap.Name = "nptv6"
+	if err := fakeClient.Update(ctx, current); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	reconcile()
+	if _, err := getConfigMap("nptv6"); err != nil {
+		t.Errorf("renamed ConfigMap not rendered: %v", err)
+	}
+	if _, err := getConfigMap("home-npt"); err == nil {
+		t.Error("previous ConfigMap should have been deleted")
+	}
+
+	// Removing spec.npt removes the ConfigMap and the status it reported
+	current = get()
+	current.Spec.NPT = nil
+	if err := fakeClient.Update(ctx, current); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	reconcile()
+	if _, err := getConfigMap("nptv6"); err == nil {
+		t.Error("ConfigMap should have been deleted")
+	}
+	current = get()
+	if current.Status.NPT != nil || meta.FindStatusCondition(current.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeNPTReady) != nil {
+		t.Errorf("status still reports NPT: %+v %+v", current.Status.NPT, current.Status.Conditions)
+	}
+	if current.Status.CurrentPrefix != "2001:db8:2::/48" {
+		t.Errorf("CurrentPrefix = %q, want it kept", current.Status.CurrentPrefix)
+	}
+}
+
+// prefixString returns the CIDR of p, or an empty string if it is unset.
+func prefixString(p netip.Prefix) string {
+	if !p.IsValid() {
+		return ""
+	}
+	return p.String()
+}
@@ -0,0 +1,337 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+	"github.com/jr42/dynamic-prefix-operator/internal/pinhole"
+)
+
+const (
+	// AnnotationPinholes requests inbound pinholes on the router for a LoadBalancer Service
+	// when set to "true". The router is configured in the referenced DynamicPrefix.
+	AnnotationPinholes = "dynamic-prefix.io/pinholes"
+
+	// AnnotationPinholeLeases records the pinholes opened for a Service, so they can be
+	// refreshed and closed after restarts. It is managed by the operator.
+	AnnotationPinholeLeases = "dynamic-prefix.io/pinhole-leases"
+
+	// pinholeFinalizer keeps a Service until its pinholes are closed.
+	pinholeFinalizer = "dynamic-prefix.io/pinholes"
+
+	// pinholeRetryInterval is how soon failed pinhole operations are retried.
+	pinholeRetryInterval = 30 * time.Second
+)
+
+// PinholeReconciler maintains inbound firewall pinholes on the router for the
+// addresses and ports of annotated LoadBalancer Services. Pinholes are opened for
+// Service addresses within the current or a historical prefix of the referenced
+// DynamicPrefix, refreshed before they expire, and closed when the address, port,
+// prefix or Service goes away.
+type PinholeReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// NewClient creates the router client for a pinhole spec. Defaults to pinhole.NewClient.
+	NewClient func(spec *dynamicprefixiov1alpha1.PinholeSpec) (pinhole.Client, error)
+}
+
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;update;patch
+
+// Reconcile opens, refreshes and closes the pinholes of a Service.
+func (r *PinholeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var svc corev1.Service
+	if err := r.Get(ctx, req.NamespacedName, &svc); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	leases, err := pinholeLeases(&svc)
+	if err != nil {
+		log.Error(err, "Ignoring invalid pinhole leases annotation")
+	}
+
+	var dp dynamicprefixiov1alpha1.DynamicPrefix
+	dpName := svc.Annotations[AnnotationName]
+	if err := r.Get(ctx, types.NamespacedName{Name: dpName}, &dp); err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	var router pinhole.Client
+	if dp.Spec.Pinholes != nil {
+		if router, err = r.newClient(dp.Spec.Pinholes); err != nil {
+			log.Error(err, "Invalid pinhole configuration", "dynamicPrefix", dpName)
+		}
+	}
+	if router == nil {
+		// Without a router to talk to, the pinholes close when their lifetime ends
+		if len(leases) > 0 {
+			log.Info("No pinhole configuration, leaving pinholes to expire", "dynamicPrefix", dpName, "pinholes", len(leases))
+		}
+		return ctrl.Result{}, r.saveLeases(ctx, &svc, nil)
+	}
+
+	var desired []pinhole.Pinhole
+	if svc.DeletionTimestamp.IsZero() && wantsPinholes(&svc) {
+		desired = desiredPinholes(&svc, &dp)
+	}
+	kept, failed := r.syncPinholes(ctx, router, leases, desired, pinhole.Lifetime(dp.Spec.Pinholes))
+
+	if err := r.saveLeases(ctx, &svc, kept); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	result := ctrl.Result{}
+	for _, lease := range kept {
+		if after := time.Until(lease.RefreshAt()); result.RequeueAfter == 0 || after < result.RequeueAfter {
+			result.RequeueAfter = max(after, time.Second)
+		}
+	}
+	if failed && (result.RequeueAfter == 0 || result.RequeueAfter > pinholeRetryInterval) {
+		result.RequeueAfter = pinholeRetryInterval
+	}
+	return result, nil
+}
+
+// syncPinholes closes leases that are no longer desired and opens or refreshes the
+// desired pinholes. It returns the leases that remain open and whether any operation failed.
+func (r *PinholeReconciler) syncPinholes(
+	ctx context.Context,
+	router pinhole.Client,
+	leases []pinhole.Lease,
+	desired []pinhole.Pinhole,
+	lifetime time.Duration,
+) ([]pinhole.Lease, bool) {
+	log := logf.FromContext(ctx)
+	now := time.Now()
+	failed := false
+	var kept []pinhole.Lease
+
+	for _, lease := range leases {
+		if slices.Contains(desired, lease.Pinhole) {
+			continue
+		}
+		if err := router.Close(ctx, lease); err != nil {
+			failed = true
+			log.Error(err, "Failed to close pinhole", "pinhole", lease.Pinhole.String())
+			if now.Before(lease.Expires) {
+				// Retry later; it closes on its own when it expires
+				kept = append(kept, lease)
+			}
+			continue
+		}
+		log.Info("Closed pinhole", "pinhole", lease.Pinhole.String())
+	}
+
+	for _, p := range desired {
+		var prev *pinhole.Lease
+		for i := range leases {
+			if leases[i].Pinhole == p {
+				prev = &leases[i]
+				break
+			}
+		}
+		if prev != nil && now.Before(prev.RefreshAt()) {
+			kept = append(kept, *prev)
+			continue
+		}
+
+		lease, err := router.Open(ctx, p, lifetime, prev)
+		if err != nil {
+			failed = true
+			log.Error(err, "Failed to open pinhole", "pinhole", p.String())
+			if prev != nil && now.Before(prev.Expires) {
+				kept = append(kept, *prev)
+			}
+			continue
+		}
+		if prev == nil {
+			log.Info("Opened pinhole", "pinhole", p.String(), "expires", lease.Expires)
+		}
+		kept = append(kept, lease)
+	}
+
+	return kept, failed
+}
+
+// saveLeases records the open pinholes on the Service and keeps the finalizer
+// while there are any.
+func (r *PinholeReconciler) saveLeases(ctx context.Context, svc *corev1.Service, leases []pinhole.Lease) error {
+	original := svc.DeepCopy()
+
+	if len(leases) == 0 {
+		delete(svc.Annotations, AnnotationPinholeLeases)
+		controllerutil.RemoveFinalizer(svc, pinholeFinalizer)
+	} else {
+		data, err := json.Marshal(leases)
+		if err != nil {
+			return err
+		}
+		if svc.Annotations == nil {
+			svc.Annotations = make(map[string]string)
+		}
+		svc.Annotations[AnnotationPinholeLeases] = string(data)
+		if svc.DeletionTimestamp.IsZero() {
+			controllerutil.AddFinalizer(svc, pinholeFinalizer)
+		}
+	}
+
+	if maps.Equal(original.Annotations, svc.Annotations) && slices.Equal(original.Finalizers, svc.Finalizers) {
+		return nil
+	}
+	return client.IgnoreNotFound(r.Patch(ctx, svc, client.MergeFrom(original)))
+}
+
+// newClient creates the router client for a pinhole spec.
+func (r *PinholeReconciler) newClient(spec *dynamicprefixiov1alpha1.PinholeSpec) (pinhole.Client, error) {
+	if r.NewClient != nil {
+		return r.NewClient(spec)
+	}
+	return pinhole.NewClient(spec)
+}
+
+// wantsPinholes returns true for LoadBalancer Services that request pinholes.
+func wantsPinholes(svc *corev1.Service) bool {
+	return svc.Spec.Type == corev1.ServiceTypeLoadBalancer && svc.Annotations[AnnotationPinholes] == "true"
+}
+
+// pinholeLeases returns the pinholes recorded on a Service.
+func pinholeLeases(svc *corev1.Service) ([]pinhole.Lease, error) {
+	data, ok := svc.Annotations[AnnotationPinholeLeases]
+	if !ok {
+		return nil, nil
+	}
+	var leases []pinhole.Lease
+	if err := json.Unmarshal([]byte(data), &leases); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", AnnotationPinholeLeases, err)
+	}
+	return leases, nil
+}
+
+// desiredPinholes returns a pinhole for each TCP and UDP port of each IPv6 address
+// of the Service that lies within an active prefix (the current one, unless withdrawn,
+// and those of the uplinks) or a draining prefix of the DynamicPrefix.
+func desiredPinholes(svc *corev1.Service, dp *dynamicprefixiov1alpha1.DynamicPrefix) []pinhole.Pinhole {
+	var candidates []string
+	for _, active := range activePrefixes(dp) {
+		candidates = append(candidates, active.prefix)
+	}
+	candidates = append(candidates, historyPrefixes(dp)...)
+
+	var prefixes []netip.Prefix
+	for _, s := range candidates {
+		if p, err := netip.ParsePrefix(s); err == nil {
+			prefixes = append(prefixes, p)
+		}
+	}
+
+	var pinholes []pinhole.Pinhole
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		addr, err := netip.ParseAddr(ingress.IP)
+		if err != nil || !addr.Is6() || addr.Is4In6() {
+			continue
+		}
+		if !slices.ContainsFunc(prefixes, func(p netip.Prefix) bool { return p.Contains(addr) }) {
+			continue
+		}
+		for _, port := range svc.Spec.Ports {
+			var protocol pinhole.Protocol
+			switch port.Protocol {
+			case corev1.ProtocolTCP, "":
+				protocol = pinhole.ProtocolTCP
+			case corev1.ProtocolUDP:
+				protocol = pinhole.ProtocolUDP
+			default:
+				continue
+			}
+			p := pinhole.Pinhole{Address: addr, Port: uint16(port.Port), Protocol: protocol}
+			if !slices.Contains(pinholes, p) {
+				pinholes = append(pinholes, p)
+			}
+		}
+	}
+	return pinholes
+}
+
+// historyPrefixes returns the draining prefixes in the history of a DynamicPrefix.
+func historyPrefixes(dp *dynamicprefixiov1alpha1.DynamicPrefix) []string {
+	history := drainingHistory(dp)
+	prefixes := make([]string, 0, len(history))
+	for _, entry := range history {
+		prefixes = append(prefixes, entry.Prefix)
+	}
+	return prefixes
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PinholeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Services that request pinholes, or still have pinholes to close
+	relevant := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		_, requested := obj.GetAnnotations()[AnnotationPinholes]
+		_, open := obj.GetAnnotations()[AnnotationPinholeLeases]
+		return requested || open
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("pinhole").
+		For(&corev1.Service{}, builder.WithPredicates(relevant)).
+		Watches(&dynamicprefixiov1alpha1.DynamicPrefix{}, handler.EnqueueRequestsFromMapFunc(r.findPinholeServices)).
+		Complete(r)
+}
+
+// findPinholeServices finds the Services with pinholes that reference the given DynamicPrefix.
+func (r *PinholeReconciler) findPinholeServices(ctx context.Context, obj client.Object) []reconcile.Request {
+	var serviceList corev1.ServiceList
+	if err := r.List(ctx, &serviceList); err != nil {
+		logf.FromContext(ctx).V(1).Info("Failed to list Services", "error", err)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, svc := range serviceList.Items {
+		_, requested := svc.Annotations[AnnotationPinholes]
+		_, open := svc.Annotations[AnnotationPinholeLeases]
+		if (requested || open) && svc.Annotations[AnnotationName] == obj.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace},
+			})
+		}
+	}
+	return requests
+}
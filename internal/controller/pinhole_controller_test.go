@@ -0,0 +1,223 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+	"github.com/jr42/dynamic-prefix-operator/internal/pinhole"
+)
+
+// fakeRouter records the pinholes opened and closed through it.
+type fakeRouter struct {
+	open   map[pinhole.Pinhole]bool
+	opens  int
+	closes int
+}
+
+func (f *fakeRouter) Open(_ context.Context, p pinhole.Pinhole, lifetime time.Duration, _ *pinhole.Lease) (pinhole.Lease, error) {
+	f.open[p] = true
+	f.opens++
+	now := time.Now()
+	return pinhole.Lease{Pinhole: p, ID: p.String(), Granted: now, Expires: now.Add(lifetime)}, nil
+}
+
+func (f *fakeRouter) Close(_ context.Context, lease pinhole.Lease) error {
+	delete(f.open, lease.Pinhole)
+	f.closes++
+	return nil
+}
+
+func TestPinholeReconciler_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			Pinholes: &dynamicprefixiov1alpha1.PinholeSpec{
+				Protocol:  dynamicprefixiov1alpha1.PinholeProtocolPCP,
+				PCPServer: "fd00::1",
+			},
+		},
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{CurrentPrefix: "2001:db8:1::/48"},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+			Annotations: map[string]string{
+				AnnotationName:     "home",
+				AnnotationPinholes: "true",
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeLoadBalancer,
+			Ports: []corev1.ServicePort{
+				{Name: "https", Port: 443, Protocol: corev1.ProtocolTCP},
+				{Name: "quic", Port: 443, Protocol: corev1.ProtocolUDP},
+				{Name: "sctp", Port: 9999, Protocol: corev1.ProtocolSCTP},
+			},
+		},
+		Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{
+			{IP: "2001:db8:1::10"},
+			{IP: "192.0.2.10"},
+			{IP: "2001:db8:7::10"}, // outside the DynamicPrefix
+		}}},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(dp, svc).
+		WithStatusSubresource(dp, svc).
+		Build()
+	router := &fakeRouter{open: make(map[pinhole.Pinhole]bool)}
+	reconciler := &PinholeReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+		NewClient: func(*dynamicprefixiov1alpha1.PinholeSpec) (pinhole.Client, error) {
+			return router, nil
+		},
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "web", Namespace: "default"}}
+	addr := netip.MustParseAddr("2001:db8:1::10")
+
+	result, err := reconciler.Reconcile(ctx, req)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	want := map[pinhole.Pinhole]bool{
+		{Address: addr, Port: 443, Protocol: pinhole.ProtocolTCP}: true,
+		{Address: addr, Port: 443, Protocol: pinhole.ProtocolUDP}: true,
+	}
+	if len(router.open) != 2 || !router.open[pinhole.Pinhole{Address: addr, Port: 443, Protocol: pinhole.ProtocolTCP}] {
+		t.Fatalf("open pinholes = %v, want %v", router.open, want)
+	}
+	if result.RequeueAfter <= 29*time.Minute || result.RequeueAfter > 30*time.Minute {
+		t.Errorf("RequeueAfter = %v, want the refresh after half the default lifetime", result.RequeueAfter)
+	}
+
+	if err := fakeClient.Get(ctx, req.NamespacedName, svc); err != nil {
+		t.Fatal(err)
+	}
+	leases, err := pinholeLeases(svc)
+	if err != nil || len(leases) != 2 {
+		t.Fatalf("recorded leases = %v, %v; want 2", leases, err)
+	}
+	if !slices.Contains(svc.Finalizers, pinholeFinalizer) {
+		t.Error("Service has no pinhole finalizer")
+	}
+
+	// Leases are not refreshed before they are due
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if router.opens != 2 {
+		t.Errorf("opens = %d, want no refresh", router.opens)
+	}
+
+	// After a prefix change, the pinholes follow the Service address
+	dp.Status.CurrentPrefix = "2001:db8:2::/48"
+	dp.Status.History = []dynamicprefixiov1alpha1.PrefixHistoryEntry{{Prefix: "2001:db8:1::/48"}}
+	if err := fakeClient.Status().Update(ctx, dp); err != nil {
+		t.Fatal(err)
+	}
+	svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "2001:db8:2::10"}}
+	if err := fakeClient.Status().Update(ctx, svc); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	newAddr := netip.MustParseAddr("2001:db8:2::10")
+	if router.closes != 2 || len(router.open) != 2 || !router.open[pinhole.Pinhole{Address: newAddr, Port: 443, Protocol: pinhole.ProtocolUDP}] {
+		t.Errorf("after prefix change: closes = %d, open = %v", router.closes, router.open)
+	}
+
+	// Deleting the Service closes its pinholes and releases it
+	if err := fakeClient.Delete(ctx, svc); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if len(router.open) != 0 {
+		t.Errorf("open pinholes after deletion = %v, want none", router.open)
+	}
+	if err := fakeClient.Get(ctx, req.NamespacedName, svc); err == nil {
+		t.Errorf("Service still exists with finalizers %v", svc.Finalizers)
+	}
+}
+
+func TestDesiredPinholes_WithoutPrefix(t *testing.T) {
+	svc := &corev1.Service{
+		Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 80}}},
+		Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{
+			{IP: "2001:db8:1::10"},
+		}}},
+	}
+	if got := desiredPinholes(svc, &dynamicprefixiov1alpha1.DynamicPrefix{}); len(got) != 0 {
+		t.Errorf("desiredPinholes() = %v, want none without a prefix", got)
+	}
+}
+
+func TestDesiredPinholes_UplinksAndWithdrawn(t *testing.T) {
+	svc := &corev1.Service{
+		Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 80}}},
+		Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{
+			{IP: "2001:db8:1::10"},
+			{IP: "2001:db8:b::10"},
+			{IP: "2001:db8:f::10"},
+		}}},
+	}
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			CurrentPrefix: "2001:db8:1::/48",
+			Uplinks: []dynamicprefixiov1alpha1.UplinkStatus{
+				{Name: "isp-b", Prefix: "2001:db8:b::/48"},
+			},
+		},
+	}
+
+	// The address from the second uplink gets a pinhole too
+	got := desiredPinholes(svc, dp)
+	want := []pinhole.Pinhole{
+		{Address: netip.MustParseAddr("2001:db8:1::10"), Port: 80, Protocol: pinhole.ProtocolTCP},
+		{Address: netip.MustParseAddr("2001:db8:b::10"), Port: 80, Protocol: pinhole.ProtocolTCP},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("desiredPinholes() = %v, want %v", got, want)
+	}
+
+	// A withdrawn current prefix no longer counts
+	dp.Status.LostPrefix = &dynamicprefixiov1alpha1.LostPrefixStatus{Prefix: "2001:db8:1::/48", Withdrawn: true}
+	got = desiredPinholes(svc, dp)
+	if !slices.Equal(got, want[1:]) {
+		t.Errorf("desiredPinholes() with withdrawn prefix = %v, want %v", got, want[1:])
+	}
+}
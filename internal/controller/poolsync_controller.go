@@ -20,8 +20,10 @@ import (
 	"context"
 	"fmt"
 	"net/netip"
+	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -47,6 +49,20 @@ const (
 	AnnotationAddressRange = "dynamic-prefix.io/address-range"
 	// AnnotationLastSync is the timestamp set by operator after update.
 	AnnotationLastSync = "dynamic-prefix.io/last-sync"
+	// AnnotationIPFamilies lists the address families a pool follows: "IPv6" (default), "IPv4" or "IPv4,IPv6".
+	AnnotationIPFamilies = "dynamic-prefix.io/ip-families"
+	// AnnotationIPv4Block selects the IPv4 block of a pool: "address" (default) for the WAN address as /32,
+	// or "subnet" for the routed subnet the address was assigned in.
+	AnnotationIPv4Block = "dynamic-prefix.io/ipv4-block"
+	// AnnotationULA adds the blocks of the unique local companion prefix (spec.ula) when "true".
+	AnnotationULA = "dynamic-prefix.io/ula"
+)
+
+const (
+	// IPv4BlockAddress uses the WAN IPv4 address alone.
+	IPv4BlockAddress = "address"
+	// IPv4BlockSubnet uses the subnet the WAN IPv4 address was assigned in.
+	IPv4BlockSubnet = "subnet"
 )
 
 var (
@@ -128,20 +144,46 @@ func (r *PoolSyncReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 	}
 
-	// Build pool configurations for current prefix and historical prefixes
-	configs, err := r.buildPoolConfigurations(ctx, &dp, hasAddressRange, addressRangeName, hasSubnet, subnetName)
+	families, err := parseIPFamilies(annotations[AnnotationIPFamilies])
 	if err != nil {
-		log.Info("Failed to build pool configurations", "error", err.Error())
-		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		log.Info("Invalid annotation", "annotation", AnnotationIPFamilies, "error", err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	// Build pool configurations for current prefix and historical prefixes
+	var configs []poolConfiguration
+	if families.ipv6 {
+		configs, err = r.buildPoolConfigurations(ctx, &dp, hasAddressRange, addressRangeName, hasSubnet, subnetName)
+		if err != nil {
+			log.Info("Failed to build pool configurations", "error", err.Error())
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+	}
+	if families.ipv6 && includeULA(annotations) {
+		config, err := r.buildULAConfiguration(&dp, hasAddressRange, addressRangeName, hasSubnet, subnetName)
+		if err != nil {
+			log.Info("Failed to build ULA pool configuration", "error", err.Error())
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+		configs = append(configs, config)
+	}
+	if families.ipv4 {
+		config, err := buildIPv4PoolConfiguration(&dp, annotations[AnnotationIPv4Block])
+		if err != nil {
+			log.Info("Failed to build IPv4 pool configuration", "error", err.Error())
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+		configs = append(configs, config)
 	}
 
-	if len(configs) == 0 {
+	if len(configs) == 0 && !prefixWithdrawn(&dp) {
 		log.Info("No pool configurations generated")
 		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
 	}
 
 	// Update the pool based on its type
 	gvk := pool.GetObjectKind().GroupVersionKind()
+	previousSpec := pool.DeepCopy().Object["spec"]
 	var updateErr error
 
 	switch gvk.Kind {
@@ -161,10 +203,15 @@ func (r *PoolSyncReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	}
 
 	log.Info("Pool synced successfully", "pool", req.Name, "blockCount", len(configs))
+	if !equality.Semantic.DeepEqual(previousSpec, pool.Object["spec"]) {
+		if err := recordPrefixChangeUpdate(ctx, r.Client, &dp, gvk.Kind, pool.GetNamespace(), pool.GetName()); err != nil {
+			log.Error(err, "Failed to record pool update in PrefixChange")
+		}
+	}
 	return ctrl.Result{}, nil
 }
 
-// buildPoolConfigurations builds pool configurations for current prefix and historical prefixes.
+// buildPoolConfigurations builds pool configurations for the current prefix and the draining historical prefixes.
 func (r *PoolSyncReconciler) buildPoolConfigurations(
 	ctx context.Context,
 	dp *dynamicprefixiov1alpha1.DynamicPrefix,
@@ -173,29 +220,111 @@ func (r *PoolSyncReconciler) buildPoolConfigurations(
 	hasSubnet bool,
 	subnetName string,
 ) ([]poolConfiguration, error) {
-	if dp.Status.CurrentPrefix == "" {
+	if len(activePrefixes(dp)) == 0 {
+		if prefixWithdrawn(dp) {
+			// The prefix was lost and withdrawn: the pool carries no IPv6 blocks
+			return nil, nil
+		}
 		return nil, fmt.Errorf("DynamicPrefix has no current prefix")
 	}
 
-	maxHistory := r.getMaxHistory(dp)
+	if hasAddressRange && addressRangeName != "" {
+		return r.buildAddressRangeConfigs(ctx, dp, addressRangeName)
+	}
+
+	if hasSubnet && subnetName != "" {
+		return r.buildSubnetConfigs(ctx, dp, subnetName)
+	}
+
+	return r.buildRawPrefixConfigs(dp), nil
+}
+
+// buildULAConfiguration builds the pool configuration for the unique local
+// companion prefix. It never changes, so there is no history to carry.
+func (r *PoolSyncReconciler) buildULAConfiguration(
+	dp *dynamicprefixiov1alpha1.DynamicPrefix,
+	hasAddressRange bool,
+	addressRangeName string,
+	hasSubnet bool,
+	subnetName string,
+) (poolConfiguration, error) {
+	ula := dp.Status.ULA
+	if ula == nil || ula.Prefix == "" {
+		return poolConfiguration{}, fmt.Errorf("DynamicPrefix has no ULA prefix")
+	}
 
 	if hasAddressRange && addressRangeName != "" {
-		return r.buildAddressRangeConfigs(ctx, dp, addressRangeName, maxHistory)
+		if config := r.findAddressRangeInStatus(ula.AddressRanges, addressRangeName); config != nil {
+			return *config, nil
+		}
+		rangeSpec := r.findAddressRangeSpec(dp, addressRangeName)
+		if rangeSpec == nil {
+			return poolConfiguration{}, fmt.Errorf("address range %q not found in status or spec", addressRangeName)
+		}
+		return r.calculateAddressRangeConfig(ula.Prefix, rangeSpec)
 	}
 
 	if hasSubnet && subnetName != "" {
-		return r.buildSubnetConfigs(ctx, dp, subnetName, maxHistory)
+		if config := r.findSubnetInStatus(ula.Subnets, subnetName); config != nil {
+			return *config, nil
+		}
+		subnetSpec := r.findSubnetSpec(dp, subnetName)
+		if subnetSpec == nil {
+			return poolConfiguration{}, fmt.Errorf("subnet %q not found in status or spec", subnetName)
+		}
+		return r.calculateSubnetConfig(ula.Prefix, subnetSpec)
 	}
 
-	return r.buildRawPrefixConfigs(dp, maxHistory), nil
+	return poolConfiguration{useAddressRange: false, cidr: ula.Prefix}, nil
+}
+
+// ipFamilies are the address families a pool follows.
+type ipFamilies struct {
+	ipv4 bool
+	ipv6 bool
 }
 
-// getMaxHistory returns the maximum number of historical prefixes to retain.
-func (r *PoolSyncReconciler) getMaxHistory(dp *dynamicprefixiov1alpha1.DynamicPrefix) int {
-	if dp.Spec.Transition != nil && dp.Spec.Transition.MaxPrefixHistory > 0 {
-		return dp.Spec.Transition.MaxPrefixHistory
+// parseIPFamilies parses the ip-families annotation; an empty value means IPv6 only.
+func parseIPFamilies(value string) (ipFamilies, error) {
+	if strings.TrimSpace(value) == "" {
+		return ipFamilies{ipv6: true}, nil
+	}
+	var families ipFamilies
+	for _, family := range strings.Split(value, ",") {
+		switch strings.TrimSpace(family) {
+		case "IPv4":
+			families.ipv4 = true
+		case "IPv6":
+			families.ipv6 = true
+		default:
+			return ipFamilies{}, fmt.Errorf("unknown IP family %q, expected IPv4 or IPv6", family)
+		}
+	}
+	return families, nil
+}
+
+// buildIPv4PoolConfiguration builds the pool configuration for the WAN IPv4
+// address. Unlike IPv6 prefixes, IPv4 addresses are not kept in history.
+func buildIPv4PoolConfiguration(dp *dynamicprefixiov1alpha1.DynamicPrefix, block string) (poolConfiguration, error) {
+	if dp.Status.IPv4 == nil || dp.Status.IPv4.Address == "" {
+		return poolConfiguration{}, fmt.Errorf("DynamicPrefix has no IPv4 address")
+	}
+
+	switch block {
+	case "", IPv4BlockAddress:
+		addr, err := netip.ParseAddr(dp.Status.IPv4.Address)
+		if err != nil {
+			return poolConfiguration{}, fmt.Errorf("invalid IPv4 address %q: %w", dp.Status.IPv4.Address, err)
+		}
+		return poolConfiguration{cidr: netip.PrefixFrom(addr, addr.BitLen()).String()}, nil
+	case IPv4BlockSubnet:
+		if dp.Status.IPv4.Subnet == "" {
+			return poolConfiguration{}, fmt.Errorf("DynamicPrefix has no IPv4 subnet")
+		}
+		return poolConfiguration{cidr: dp.Status.IPv4.Subnet}, nil
+	default:
+		return poolConfiguration{}, fmt.Errorf("unknown IPv4 block %q, expected %s or %s", block, IPv4BlockAddress, IPv4BlockSubnet)
 	}
-	return 2 // Default
 }
 
 // buildAddressRangeConfigs builds configurations for address range mode.
@@ -203,7 +332,6 @@ func (r *PoolSyncReconciler) buildAddressRangeConfigs(
 	ctx context.Context,
 	dp *dynamicprefixiov1alpha1.DynamicPrefix,
 	addressRangeName string,
-	maxHistory int,
 ) ([]poolConfiguration, error) {
 	log := logf.FromContext(ctx)
 	var configs []poolConfiguration
@@ -211,26 +339,25 @@ func (r *PoolSyncReconciler) buildAddressRangeConfigs(
 	// Find the address range spec
 	rangeSpec := r.findAddressRangeSpec(dp, addressRangeName)
 
-	// Get current config from status or calculate from spec
-	currentConfig := r.findAddressRangeInStatus(dp, addressRangeName)
-	if currentConfig == nil {
-		if rangeSpec == nil {
-			return nil, fmt.Errorf("address range %q not found in status or spec", addressRangeName)
-		}
-		calculated, err := r.calculateAddressRangeConfig(dp.Status.CurrentPrefix, rangeSpec)
-		if err != nil {
-			return nil, fmt.Errorf("failed to calculate address range for current prefix: %w", err)
+	// Get the config of each active prefix from status or calculate from spec
+	for _, active := range activePrefixes(dp) {
+		activeConfig := r.findAddressRangeInStatus(active.addressRanges, addressRangeName)
+		if activeConfig == nil {
+			if rangeSpec == nil {
+				return nil, fmt.Errorf("address range %q not found in status or spec", addressRangeName)
+			}
+			calculated, err := r.calculateAddressRangeConfig(active.prefix, rangeSpec)
+			if err != nil {
+				return nil, fmt.Errorf("failed to calculate address range for prefix %s: %w", active.prefix, err)
+			}
+			activeConfig = &calculated
 		}
-		currentConfig = &calculated
+		configs = append(configs, *activeConfig)
 	}
-	configs = append(configs, *currentConfig)
 
 	// Calculate for historical prefixes
 	if rangeSpec != nil {
-		for i, histEntry := range dp.Status.History {
-			if i >= maxHistory {
-				break
-			}
+		for _, histEntry := range drainingHistory(dp) {
 			histConfig, err := r.calculateAddressRangeConfig(histEntry.Prefix, rangeSpec)
 			if err != nil {
 				log.V(1).Info("Failed to calculate address range for historical prefix",
@@ -249,7 +376,6 @@ func (r *PoolSyncReconciler) buildSubnetConfigs(
 	ctx context.Context,
 	dp *dynamicprefixiov1alpha1.DynamicPrefix,
 	subnetName string,
-	maxHistory int,
 ) ([]poolConfiguration, error) {
 	log := logf.FromContext(ctx)
 	var configs []poolConfiguration
@@ -257,26 +383,25 @@ func (r *PoolSyncReconciler) buildSubnetConfigs(
 	// Find the subnet spec
 	subnetSpec := r.findSubnetSpec(dp, subnetName)
 
-	// Get current config from status or calculate from spec
-	currentConfig := r.findSubnetInStatus(dp, subnetName)
-	if currentConfig == nil {
-		if subnetSpec == nil {
-			return nil, fmt.Errorf("subnet %q not found in status or spec", subnetName)
-		}
-		calculated, err := r.calculateSubnetConfig(dp.Status.CurrentPrefix, subnetSpec)
-		if err != nil {
-			return nil, fmt.Errorf("failed to calculate subnet for current prefix: %w", err)
+	// Get the config of each active prefix from status or calculate from spec
+	for _, active := range activePrefixes(dp) {
+		activeConfig := r.findSubnetInStatus(active.subnets, subnetName)
+		if activeConfig == nil {
+			if subnetSpec == nil {
+				return nil, fmt.Errorf("subnet %q not found in status or spec", subnetName)
+			}
+			calculated, err := r.calculateSubnetConfig(active.prefix, subnetSpec)
+			if err != nil {
+				return nil, fmt.Errorf("failed to calculate subnet for prefix %s: %w", active.prefix, err)
+			}
+			activeConfig = &calculated
 		}
-		currentConfig = &calculated
+		configs = append(configs, *activeConfig)
 	}
-	configs = append(configs, *currentConfig)
 
 	// Calculate for historical prefixes
 	if subnetSpec != nil {
-		for i, histEntry := range dp.Status.History {
-			if i >= maxHistory {
-				break
-			}
+		for _, histEntry := range drainingHistory(dp) {
 			histConfig, err := r.calculateSubnetConfig(histEntry.Prefix, subnetSpec)
 			if err != nil {
 				log.V(1).Info("Failed to calculate subnet for historical prefix",
@@ -293,17 +418,16 @@ func (r *PoolSyncReconciler) buildSubnetConfigs(
 // buildRawPrefixConfigs builds configurations using raw prefixes (no address range or subnet).
 func (r *PoolSyncReconciler) buildRawPrefixConfigs(
 	dp *dynamicprefixiov1alpha1.DynamicPrefix,
-	maxHistory int,
 ) []poolConfiguration {
-	configs := []poolConfiguration{{
-		useAddressRange: false,
-		cidr:            dp.Status.CurrentPrefix,
-	}}
+	var configs []poolConfiguration
+	for _, active := range activePrefixes(dp) {
+		configs = append(configs, poolConfiguration{
+			useAddressRange: false,
+			cidr:            active.prefix,
+		})
+	}
 
-	for i, histEntry := range dp.Status.History {
-		if i >= maxHistory {
-			break
-		}
+	for _, histEntry := range drainingHistory(dp) {
 		configs = append(configs, poolConfiguration{
 			useAddressRange: false,
 			cidr:            histEntry.Prefix,
@@ -328,10 +452,10 @@ func (r *PoolSyncReconciler) findAddressRangeSpec(
 
 // findAddressRangeInStatus finds an address range in status by name.
 func (r *PoolSyncReconciler) findAddressRangeInStatus(
-	dp *dynamicprefixiov1alpha1.DynamicPrefix,
+	addressRanges []dynamicprefixiov1alpha1.AddressRangeStatus,
 	name string,
 ) *poolConfiguration {
-	for _, ar := range dp.Status.AddressRanges {
+	for _, ar := range addressRanges {
 		if ar.Name == name {
 			return &poolConfiguration{
 				useAddressRange: true,
@@ -359,11 +483,12 @@ func (r *PoolSyncReconciler) findSubnetSpec(
 
 // findSubnetInStatus finds a subnet in status by name.
 func (r *PoolSyncReconciler) findSubnetInStatus(
-	dp *dynamicprefixiov1alpha1.DynamicPrefix,
+	subnets []dynamicprefixiov1alpha1.SubnetStatus,
 	name string,
 ) *poolConfiguration {
-	for _, s := range dp.Status.Subnets {
-		if s.Name == name {
+	for _, s := range subnets {
+		// An entry without a CIDR only holds a BGP advertisement name the BGP controller has yet to drop
+		if s.Name == name && s.CIDR != "" {
 			return &poolConfiguration{
 				useAddressRange: false,
 				cidr:            s.CIDR,
@@ -18,6 +18,7 @@ package controller
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -374,3 +375,179 @@ func TestGVKConstants(t *testing.T) {
 		t.Errorf("CiliumCIDRGroupGVK.Kind = %q, want %q", CiliumCIDRGroupGVK.Kind, "CiliumCIDRGroup")
 	}
 }
+
+func TestParseIPFamilies(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ipFamilies
+		wantErr bool
+	}{
+		{value: "", want: ipFamilies{ipv6: true}},
+		{value: "IPv6", want: ipFamilies{ipv6: true}},
+		{value: "IPv4", want: ipFamilies{ipv4: true}},
+		{value: "IPv4, IPv6", want: ipFamilies{ipv4: true, ipv6: true}},
+		{value: "ipv4", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseIPFamilies(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseIPFamilies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseIPFamilies() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildIPv4PoolConfiguration(t *testing.T) {
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			IPv4: &dynamicprefixiov1alpha1.IPv4Status{
+				Address: "203.0.113.7",
+				Subnet:  "203.0.113.0/29",
+			},
+		},
+	}
+
+	tests := []struct {
+		block   string
+		want    string
+		wantErr bool
+	}{
+		{block: "", want: "203.0.113.7/32"},
+		{block: IPv4BlockAddress, want: "203.0.113.7/32"},
+		{block: IPv4BlockSubnet, want: "203.0.113.0/29"},
+		{block: "range", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.block, func(t *testing.T) {
+			got, err := buildIPv4PoolConfiguration(dp, tt.block)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildIPv4PoolConfiguration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.cidr != tt.want {
+				t.Errorf("buildIPv4PoolConfiguration() cidr = %q, want %q", got.cidr, tt.want)
+			}
+		})
+	}
+
+	if _, err := buildIPv4PoolConfiguration(&dynamicprefixiov1alpha1.DynamicPrefix{}, ""); err == nil {
+		t.Error("buildIPv4PoolConfiguration() expected error without an IPv4 address")
+	}
+}
+
+func TestPoolSyncReconciler_buildRawPrefixConfigs_SkipsExpired(t *testing.T) {
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			CurrentPrefix: "2001:db8:3::/48",
+			History: []dynamicprefixiov1alpha1.PrefixHistoryEntry{
+				{Prefix: "2001:db8:1::/48", State: dynamicprefixiov1alpha1.PrefixStateExpired},
+				{Prefix: "2001:db8:2::/48", State: dynamicprefixiov1alpha1.PrefixStateDraining},
+			},
+		},
+	}
+
+	configs := (&PoolSyncReconciler{}).buildRawPrefixConfigs(dp)
+	var cidrs []string
+	for _, c := range configs {
+		cidrs = append(cidrs, c.cidr)
+	}
+	if len(cidrs) != 2 || cidrs[0] != "2001:db8:3::/48" || cidrs[1] != "2001:db8:2::/48" {
+		t.Errorf("buildRawPrefixConfigs() cidrs = %v, want current and draining prefixes", cidrs)
+	}
+}
+
+func TestPoolSyncReconciler_buildPoolConfigurations_Withdrawn(t *testing.T) {
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			LostPrefix: &dynamicprefixiov1alpha1.LostPrefixStatus{Prefix: "2001:db8:3::/48", Withdrawn: true},
+			History: []dynamicprefixiov1alpha1.PrefixHistoryEntry{
+				{Prefix: "2001:db8:3::/48", State: dynamicprefixiov1alpha1.PrefixStateExpired},
+			},
+		},
+	}
+
+	configs, err := (&PoolSyncReconciler{}).buildPoolConfigurations(context.Background(), dp, false, "", false, "")
+	if err != nil {
+		t.Fatalf("buildPoolConfigurations() error = %v", err)
+	}
+	if len(configs) != 0 {
+		t.Errorf("buildPoolConfigurations() = %+v, want no blocks for a withdrawn prefix", configs)
+	}
+}
+
+func TestPoolSyncReconciler_buildPoolConfigurations_Uplinks(t *testing.T) {
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			Subnets: []dynamicprefixiov1alpha1.SubnetSpec{{Name: "lb", Offset: 1, PrefixLength: 64}},
+		},
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			CurrentPrefix: "2001:db8:1::/48",
+			Subnets:       []dynamicprefixiov1alpha1.SubnetStatus{{Name: "lb", CIDR: "2001:db8:1:1::/64"}},
+			Uplinks: []dynamicprefixiov1alpha1.UplinkStatus{
+				{Name: "isp-a", Prefix: "2001:db8:a::/48", Subnets: []dynamicprefixiov1alpha1.SubnetStatus{{Name: "lb", CIDR: "2001:db8:a:1::/64"}}},
+				// Calculated from spec when missing in status
+				{Name: "isp-b", Prefix: "2001:db8:b::/48"},
+				{Name: "isp-c"},
+			},
+			History: []dynamicprefixiov1alpha1.PrefixHistoryEntry{
+				{Prefix: "2001:db8:2::/48", State: dynamicprefixiov1alpha1.PrefixStateDraining},
+			},
+		},
+	}
+
+	configs, err := (&PoolSyncReconciler{}).buildPoolConfigurations(context.Background(), dp, false, "", true, "lb")
+	if err != nil {
+		t.Fatalf("buildPoolConfigurations() error = %v", err)
+	}
+	var cidrs []string
+	for _, c := range configs {
+		cidrs = append(cidrs, c.cidr)
+	}
+	want := []string{"2001:db8:1:1::/64", "2001:db8:a:1::/64", "2001:db8:b:1::/64", "2001:db8:2:1::/64"}
+	if strings.Join(cidrs, ",") != strings.Join(want, ",") {
+		t.Errorf("buildPoolConfigurations() cidrs = %v, want %v", cidrs, want)
+	}
+
+	// Uplinks keep the pool populated after the main prefix was withdrawn
+	dp.Status.CurrentPrefix = ""
+	dp.Status.History = nil
+	dp.Status.LostPrefix = &dynamicprefixiov1alpha1.LostPrefixStatus{Prefix: "2001:db8:1::/48", Withdrawn: true}
+	configs = (&PoolSyncReconciler{}).buildRawPrefixConfigs(dp)
+	if len(configs) != 2 || configs[0].cidr != "2001:db8:a::/48" || configs[1].cidr != "2001:db8:b::/48" {
+		t.Errorf("buildRawPrefixConfigs() = %+v, want the uplink prefixes", configs)
+	}
+}
+
+func TestPoolSyncReconciler_buildULAConfiguration(t *testing.T) {
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			Subnets: []dynamicprefixiov1alpha1.SubnetSpec{{Name: "lb", Offset: 1, PrefixLength: 64}},
+		},
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			ULA: &dynamicprefixiov1alpha1.ULAStatus{
+				Prefix:  "fd12:3456:789a::/48",
+				Subnets: []dynamicprefixiov1alpha1.SubnetStatus{{Name: "lb", CIDR: "fd12:3456:789a:1::/64"}},
+			},
+		},
+	}
+	r := &PoolSyncReconciler{}
+
+	config, err := r.buildULAConfiguration(dp, false, "", true, "lb")
+	if err != nil || config.cidr != "fd12:3456:789a:1::/64" {
+		t.Errorf("buildULAConfiguration(subnet) = %+v, %v, want fd12:3456:789a:1::/64", config, err)
+	}
+	config, err = r.buildULAConfiguration(dp, false, "", false, "")
+	if err != nil || config.cidr != "fd12:3456:789a::/48" {
+		t.Errorf("buildULAConfiguration(raw) = %+v, %v, want fd12:3456:789a::/48", config, err)
+	}
+
+	dp.Status.ULA = nil
+	if _, err := r.buildULAConfiguration(dp, false, "", true, "lb"); err == nil {
+		t.Error("buildULAConfiguration() without a ULA prefix should fail")
+	}
+}
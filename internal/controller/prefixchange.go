@@ -0,0 +1,203 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+)
+
+const (
+	// defaultMaxChangeRecords is the number of PrefixChange records kept when spec.changeRecords is unset
+	defaultMaxChangeRecords = 50
+)
+
+// +kubebuilder:rbac:groups=dynamic-prefix.io,resources=prefixchanges,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=dynamic-prefix.io,resources=prefixchanges/status,verbs=get;update;patch
+
+// recordPrefixChange creates the PrefixChange record for a transition of dp from
+// oldPrefix to its new current prefix, then prunes records beyond the retention.
+// A retried reconcile finds its record as the latest one and does not create another.
+func (r *DynamicPrefixReconciler) recordPrefixChange(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix, oldPrefix string, detectedAt time.Time) error {
+	log := logf.FromContext(ctx)
+
+	changes, err := listPrefixChanges(ctx, r.Client, dp.Name)
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 {
+		latest := changes[0].Spec
+		if latest.OldPrefix == oldPrefix && latest.NewPrefix == dp.Status.CurrentPrefix {
+			return nil
+		}
+	}
+
+	change := &dynamicprefixiov1alpha1.PrefixChange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: prefixChangeName(dp.Name, oldPrefix, dp.Status.CurrentPrefix, detectedAt),
+			Labels: map[string]string{
+				LabelManagedBy:         LabelManagedByValue,
+				LabelDynamicPrefixName: dp.Name,
+			},
+		},
+		Spec: dynamicprefixiov1alpha1.PrefixChangeSpec{
+			DynamicPrefix: dp.Name,
+			OldPrefix:     oldPrefix,
+			NewPrefix:     dp.Status.CurrentPrefix,
+			Source:        dp.Status.PrefixSource,
+			DetectedAt:    metav1.NewTime(detectedAt),
+		},
+	}
+	// Records are garbage collected with their DynamicPrefix
+	if err := controllerutil.SetControllerReference(dp, change, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set owner reference: %w", err)
+	}
+	if err := r.Create(ctx, change); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create PrefixChange: %w", err)
+		}
+		// Only the same transition, recorded by a retry the cache has not caught up with, may exist
+		var existing dynamicprefixiov1alpha1.PrefixChange
+		if getErr := r.Get(ctx, client.ObjectKeyFromObject(change), &existing); getErr != nil {
+			return fmt.Errorf("failed to get existing PrefixChange %s: %w", change.Name, getErr)
+		}
+		if existing.Spec.OldPrefix != change.Spec.OldPrefix || existing.Spec.NewPrefix != change.Spec.NewPrefix {
+			return fmt.Errorf("PrefixChange %s already exists for %s -> %s: %w",
+				change.Name, existing.Spec.OldPrefix, existing.Spec.NewPrefix, err)
+		}
+		return nil
+	}
+	log.Info("Recorded PrefixChange", "name", change.Name, "oldPrefix", oldPrefix, "newPrefix", change.Spec.NewPrefix)
+
+	_, err = r.prunePrefixChanges(ctx, dp, append([]dynamicprefixiov1alpha1.PrefixChange{*change}, changes...), detectedAt)
+	return err
+}
+
+// expirePrefixChanges prunes the records of dp beyond the retention, also while the prefix is stable.
+// It returns when the oldest remaining record exceeds spec.changeRecords.maxAge, or zero if none will.
+func (r *DynamicPrefixReconciler) expirePrefixChanges(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix, now time.Time) (time.Time, error) {
+	changes, err := listPrefixChanges(ctx, r.Client, dp.Name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return r.prunePrefixChanges(ctx, dp, changes, now)
+}
+
+// prefixChangeName names the record of a transition. The suffix hashes the prefixes and
+// the detection time in nanoseconds, so that transitions within the same second do not collide.
+func prefixChangeName(dpName, oldPrefix, newPrefix string, detectedAt time.Time) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s/%s/%d", oldPrefix, newPrefix, detectedAt.UnixNano()))
+	return fmt.Sprintf("%s-%d-%s", dpName, detectedAt.Unix(), hex.EncodeToString(sum[:4]))
+}
+
+// prunePrefixChanges deletes the records, newest first, beyond spec.changeRecords.maxCount
+// or older than spec.changeRecords.maxAge. It returns when the oldest kept record expires.
+func (r *DynamicPrefixReconciler) prunePrefixChanges(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix, changes []dynamicprefixiov1alpha1.PrefixChange, now time.Time) (time.Time, error) {
+	maxCount := defaultMaxChangeRecords
+	var maxAge time.Duration
+	if spec := dp.Spec.ChangeRecords; spec != nil {
+		if spec.MaxCount > 0 {
+			maxCount = spec.MaxCount
+		}
+		if spec.MaxAge != nil {
+			maxAge = spec.MaxAge.Duration
+		}
+	}
+
+	var nextExpiry time.Time
+	for i := range changes {
+		change := &changes[i]
+		expiresAt := change.Spec.DetectedAt.Add(maxAge)
+		expired := maxAge > 0 && now.After(expiresAt)
+		if i < maxCount && !expired {
+			if maxAge > 0 && (nextExpiry.IsZero() || expiresAt.Before(nextExpiry)) {
+				nextExpiry = expiresAt
+			}
+			continue
+		}
+		if err := r.Delete(ctx, change); client.IgnoreNotFound(err) != nil {
+			return time.Time{}, fmt.Errorf("failed to delete PrefixChange %s: %w", change.Name, err)
+		}
+		logf.FromContext(ctx).V(1).Info("Pruned PrefixChange", "name", change.Name)
+	}
+	return nextExpiry, nil
+}
+
+// recordPrefixChangeUpdate notes in the latest PrefixChange of dp that a
+// resource has been updated for the current prefix. Only the first update
+// of each resource is recorded.
+func recordPrefixChangeUpdate(ctx context.Context, c client.Client, dp *dynamicprefixiov1alpha1.DynamicPrefix, kind, namespace, name string) error {
+	if dp.Status.CurrentPrefix == "" {
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		changes, err := listPrefixChanges(ctx, c, dp.Name)
+		if err != nil || len(changes) == 0 {
+			return err
+		}
+		change := &changes[0]
+		if change.Spec.NewPrefix != dp.Status.CurrentPrefix {
+			return nil
+		}
+		if slices.ContainsFunc(change.Status.Updates, func(u dynamicprefixiov1alpha1.PrefixChangeUpdate) bool {
+			return u.Kind == kind && u.Namespace == namespace && u.Name == name
+		}) {
+			return nil
+		}
+
+		now := metav1.Now()
+		change.Status.Updates = append(change.Status.Updates, dynamicprefixiov1alpha1.PrefixChangeUpdate{
+			Kind:        kind,
+			Namespace:   namespace,
+			Name:        name,
+			CompletedAt: now,
+		})
+		change.Status.LastUpdateAt = &now
+		if err := c.Status().Update(ctx, change); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// listPrefixChanges returns the PrefixChange records of a DynamicPrefix, newest first.
+func listPrefixChanges(ctx context.Context, c client.Client, dpName string) ([]dynamicprefixiov1alpha1.PrefixChange, error) {
+	var list dynamicprefixiov1alpha1.PrefixChangeList
+	if err := c.List(ctx, &list, client.MatchingLabels{LabelDynamicPrefixName: dpName}); err != nil {
+		return nil, fmt.Errorf("failed to list PrefixChanges: %w", err)
+	}
+	slices.SortFunc(list.Items, func(a, b dynamicprefixiov1alpha1.PrefixChange) int {
+		return b.Spec.DetectedAt.Compare(a.Spec.DetectedAt.Time)
+	})
+	return list.Items, nil
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+)
+
+func TestDynamicPrefixReconciler_recordPrefixChange(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+	now := time.Now()
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", UID: "dp-uid"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			ChangeRecords: &dynamicprefixiov1alpha1.ChangeRecordsSpec{MaxCount: 3, MaxAge: &metav1.Duration{Duration: 30 * 24 * time.Hour}},
+		},
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			CurrentPrefix: "2001:db8:9::/48",
+			PrefixSource:  dynamicprefixiov1alpha1.PrefixSourceDHCPv6PD,
+		},
+	}
+
+	// Existing records: one too old, and enough recent ones to exceed maxCount
+	var objs []client.Object
+	for i, age := range []time.Duration{40 * 24 * time.Hour, 3 * time.Hour, 2 * time.Hour, time.Hour} {
+		objs = append(objs, &dynamicprefixiov1alpha1.PrefixChange{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("home-%d", i),
+				Labels: map[string]string{LabelDynamicPrefixName: "home"},
+			},
+			Spec: dynamicprefixiov1alpha1.PrefixChangeSpec{
+				DynamicPrefix: "home",
+				OldPrefix:     fmt.Sprintf("2001:db8:%d::/48", i),
+				NewPrefix:     fmt.Sprintf("2001:db8:%d::/48", i+1),
+				DetectedAt:    metav1.NewTime(now.Add(-age)),
+			},
+		})
+	}
+	objs = append(objs, dp)
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	r := &DynamicPrefixReconciler{Client: c, Scheme: scheme}
+
+	if err := r.recordPrefixChange(ctx, dp, "2001:db8:4::/48", now); err != nil {
+		t.Fatalf("recordPrefixChange() error = %v", err)
+	}
+	// A retried reconcile must not record the transition twice
+	if err := r.recordPrefixChange(ctx, dp, "2001:db8:4::/48", now.Add(2*time.Second)); err != nil {
+		t.Fatalf("recordPrefixChange() retry error = %v", err)
+	}
+
+	changes, err := listPrefixChanges(ctx, c, "home")
+	if err != nil {
+		t.Fatalf("listPrefixChanges() error = %v", err)
+	}
+	var names []string
+	for _, change := range changes {
+		names = append(names, change.Name)
+	}
+	want := []string{prefixChangeName("home", "2001:db8:4::/48", "2001:db8:9::/48", now), "home-3", "home-2"}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Fatalf("PrefixChanges = %v, want %v", names, want)
+	}
+
+	latest := changes[0]
+	if latest.Spec.OldPrefix != "2001:db8:4::/48" || latest.Spec.NewPrefix != "2001:db8:9::/48" ||
+		latest.Spec.Source != dynamicprefixiov1alpha1.PrefixSourceDHCPv6PD {
+		t.Errorf("latest PrefixChange spec = %+v", latest.Spec)
+	}
+	if len(latest.OwnerReferences) != 1 || latest.OwnerReferences[0].UID != "dp-uid" {
+		t.Errorf("latest PrefixChange owner references = %+v", latest.OwnerReferences)
+	}
+}
+
+func TestDynamicPrefixReconciler_recordPrefixChange_SameSecond(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+	now := time.Unix(1760000000, 100)
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home"},
+		Status:     dynamicprefixiov1alpha1.DynamicPrefixStatus{CurrentPrefix: "2001:db8:2::/48"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dp).Build()
+	r := &DynamicPrefixReconciler{Client: c, Scheme: scheme}
+
+	// A failover and its failback within the same second are both recorded
+	if err := r.recordPrefixChange(ctx, dp, "2001:db8:1::/48", now); err != nil {
+		t.Fatalf("recordPrefixChange() error = %v", err)
+	}
+	back := dp.DeepCopy()
+	back.Status.CurrentPrefix = "2001:db8:1::/48"
+	if err := r.recordPrefixChange(ctx, back, "2001:db8:2::/48", now.Add(time.Millisecond)); err != nil {
+		t.Fatalf("recordPrefixChange() error = %v", err)
+	}
+
+	changes, err := listPrefixChanges(ctx, c, "home")
+	if err != nil {
+		t.Fatalf("listPrefixChanges() error = %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("PrefixChanges = %d, want 2", len(changes))
+	}
+
+	// A name taken by another transition is an error, not a silently dropped record
+	taken := &dynamicprefixiov1alpha1.PrefixChange{
+		ObjectMeta: metav1.ObjectMeta{Name: prefixChangeName("home", "2001:db8:1::/48", "2001:db8:3::/48", now)},
+		Spec: dynamicprefixiov1alpha1.PrefixChangeSpec{
+			DynamicPrefix: "home",
+			OldPrefix:     "2001:db8:7::/48",
+			NewPrefix:     "2001:db8:8::/48",
+			DetectedAt:    metav1.NewTime(now),
+		},
+	}
+	if err := c.Create(ctx, taken); err != nil {
+		t.Fatalf("Failed to create PrefixChange: %v", err)
+	}
+	third := dp.DeepCopy()
+	third.Status.CurrentPrefix = "2001:db8:3::/48"
+	if err := r.recordPrefixChange(ctx, third, "2001:db8:1::/48", now); err == nil {
+		t.Error("Expected an error when the record name is taken by another transition")
+	}
+}
+
+func TestDynamicPrefixReconciler_expirePrefixChanges(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+	now := time.Now()
+	maxAge := 24 * time.Hour
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			ChangeRecords: &dynamicprefixiov1alpha1.ChangeRecordsSpec{MaxAge: &metav1.Duration{Duration: maxAge}},
+		},
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{CurrentPrefix: "2001:db8:3::/48"},
+	}
+
+	// The prefix has been stable since the last record, which is still within maxAge
+	var objs []client.Object
+	ages := []time.Duration{48 * time.Hour, 30 * time.Hour, 5 * time.Hour}
+	for i, age := range ages {
+		objs = append(objs, &dynamicprefixiov1alpha1.PrefixChange{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("home-%d", i),
+				Labels: map[string]string{LabelDynamicPrefixName: "home"},
+			},
+			Spec: dynamicprefixiov1alpha1.PrefixChangeSpec{
+				DynamicPrefix: "home",
+				OldPrefix:     fmt.Sprintf("2001:db8:%d::/48", i),
+				NewPrefix:     fmt.Sprintf("2001:db8:%d::/48", i+1),
+				DetectedAt:    metav1.NewTime(now.Add(-age)),
+			},
+		})
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	r := &DynamicPrefixReconciler{Client: c, Scheme: scheme}
+
+	next, err := r.expirePrefixChanges(ctx, dp, now)
+	if err != nil {
+		t.Fatalf("expirePrefixChanges() error = %v", err)
+	}
+
+	changes, err := listPrefixChanges(ctx, c, "home")
+	if err != nil {
+		t.Fatalf("listPrefixChanges() error = %v", err)
+	}
+	if len(changes) != 1 || changes[0].Name != "home-2" {
+		t.Fatalf("PrefixChanges = %+v, want only home-2", changes)
+	}
+
+	// The reconcile is requeued when the remaining record ages out
+	want := now.Add(-ages[2]).Add(maxAge).Truncate(time.Second)
+	if !next.Equal(want) {
+		t.Errorf("next expiry = %v, want %v", next, want)
+	}
+}
+
+func TestRecordPrefixChangeUpdate(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home"},
+		Status:     dynamicprefixiov1alpha1.DynamicPrefixStatus{CurrentPrefix: "2001:db8:2::/48"},
+	}
+	change := &dynamicprefixiov1alpha1.PrefixChange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "home-1",
+			Labels: map[string]string{LabelDynamicPrefixName: "home"},
+		},
+		Spec: dynamicprefixiov1alpha1.PrefixChangeSpec{
+			DynamicPrefix: "home",
+			OldPrefix:     "2001:db8:1::/48",
+			NewPrefix:     "2001:db8:2::/48",
+			DetectedAt:    metav1.Now(),
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(change).
+		WithStatusSubresource(&dynamicprefixiov1alpha1.PrefixChange{}).Build()
+
+	for range 2 {
+		if err := recordPrefixChangeUpdate(ctx, c, dp, "CiliumLoadBalancerIPPool", "", "lb-pool"); err != nil {
+			t.Fatalf("recordPrefixChangeUpdate() error = %v", err)
+		}
+	}
+	if err := recordPrefixChangeUpdate(ctx, c, dp, "Service", "default", "web"); err != nil {
+		t.Fatalf("recordPrefixChangeUpdate() error = %v", err)
+	}
+
+	// Updates for another prefix belong to no record
+	other := dp.DeepCopy()
+	other.Status.CurrentPrefix = "2001:db8:3::/48"
+	if err := recordPrefixChangeUpdate(ctx, c, other, "Service", "default", "api"); err != nil {
+		t.Fatalf("recordPrefixChangeUpdate() error = %v", err)
+	}
+
+	var got dynamicprefixiov1alpha1.PrefixChange
+	if err := c.Get(ctx, types.NamespacedName{Name: "home-1"}, &got); err != nil {
+		t.Fatalf("Failed to get PrefixChange: %v", err)
+	}
+	if len(got.Status.Updates) != 2 {
+		t.Fatalf("updates = %+v, want the pool and the Service once each", got.Status.Updates)
+	}
+	if u := got.Status.Updates[1]; u.Kind != "Service" || u.Namespace != "default" || u.Name != "web" {
+		t.Errorf("updates[1] = %+v", u)
+	}
+	if got.Status.LastUpdateAt == nil {
+		t.Error("lastUpdateAt not set")
+	}
+}
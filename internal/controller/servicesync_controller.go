@@ -18,6 +18,8 @@ package controller
 
 import (
 	"context"
+	"encoding/binary"
+	"fmt"
 	"net/netip"
 	"strings"
 	"time"
@@ -104,20 +106,49 @@ func (r *ServiceSyncReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 	log.Info("Syncing Service for HA mode", "service", req.NamespacedName, "dynamicPrefix", dpName)
 
-	// Get current assigned IP from Service status
-	currentServiceIP := r.getCurrentServiceIP(&svc)
-	if currentServiceIP == "" {
-		// Service doesn't have an IP yet, let Cilium assign one
-		log.V(1).Info("Service has no IP assigned yet, skipping")
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	families, err := parseIPFamilies(annotations[AnnotationIPFamilies])
+	if err != nil {
+		log.Info("Invalid annotation", "annotation", AnnotationIPFamilies, "error", err.Error())
+		return ctrl.Result{}, nil
 	}
 
-	// Calculate all IPs (current + historical) based on the Service's current IP
-	allIPs, currentIP, err := r.calculateServiceIPs(ctx, &dp, &svc, currentServiceIP)
-	if err != nil {
-		log.Error(err, "Failed to calculate Service IPs")
-		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	var allIPs, dnsTargets []string
+	if families.ipv6 && (!prefixWithdrawn(&dp) || len(activePrefixes(&dp)) > 0) {
+		// Get current assigned IP from Service status
+		currentServiceIP := r.getCurrentServiceIP(&svc)
+		if addr, err := netip.ParseAddr(currentServiceIP); families.ipv4 && err == nil && !addr.Is6() {
+			// A dual-stack Service has only been given its IPv4 address so far
+			currentServiceIP = ""
+		}
+		if currentServiceIP == "" {
+			// Service doesn't have an IP yet, let Cilium assign one
+			log.V(1).Info("Service has no IP assigned yet, skipping")
+			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+
+		// Calculate all IPs (active prefixes + historical) based on the Service's current IP
+		ips, currentIP, err := r.calculateServiceIPs(ctx, &dp, &svc, currentServiceIP)
+		if err != nil {
+			log.Error(err, "Failed to calculate Service IPs")
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+		allIPs = append(allIPs, ips...)
+		dnsTargets = append(dnsTargets, currentIP)
+	}
+	if families.ipv4 {
+		ipv4, err := r.calculateServiceIPv4(&dp, &svc, annotations[AnnotationIPv4Block])
+		if err != nil {
+			log.Info("Failed to calculate Service IPv4 address", "error", err.Error())
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+		if ipv4 == "" {
+			log.V(1).Info("Service has no IPv4 address assigned yet, skipping")
+			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+		allIPs = append(allIPs, ipv4)
+		dnsTargets = append(dnsTargets, ipv4)
 	}
+	currentIP := strings.Join(dnsTargets, ",")
 
 	// Update Service annotations
 	updated := false
@@ -133,12 +164,18 @@ func (r *ServiceSyncReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		updated = true
 	}
 
-	// Set external-dns target to current IP only
+	// Set external-dns target to the current IP of each family only
 	if annotations[AnnotationExternalDNSTarget] != currentIP {
 		newAnnotations[AnnotationExternalDNSTarget] = currentIP
 		updated = true
 	}
 
+	// A withdrawn prefix leaves nothing to request or publish
+	if allIPsStr == "" {
+		delete(newAnnotations, AnnotationCiliumIPs)
+		delete(newAnnotations, AnnotationExternalDNSTarget)
+	}
+
 	// Update last-sync annotation
 	newAnnotations[AnnotationLastSync] = time.Now().UTC().Format(time.RFC3339)
 
@@ -149,6 +186,9 @@ func (r *ServiceSyncReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 		}
 		log.Info("Service annotations updated", "service", req.NamespacedName, "allIPs", allIPsStr, "dnsTarget", currentIP)
+		if err := recordPrefixChangeUpdate(ctx, r.Client, &dp, "Service", svc.Namespace, svc.Name); err != nil {
+			log.Error(err, "Failed to record Service update in PrefixChange")
+		}
 	}
 
 	return ctrl.Result{}, nil
@@ -174,8 +214,60 @@ func (r *ServiceSyncReconciler) getCurrentServiceIP(svc *corev1.Service) string
 	return ""
 }
 
-// calculateServiceIPs calculates all IPs for a Service based on current prefix and history.
-// Returns (allIPs, currentIP, error).
+// calculateServiceIPv4 returns the IPv4 address a Service should have for the
+// WAN IPv4 address of the DynamicPrefix. With the address block that is the
+// WAN address itself. With the subnet block the Service keeps its address
+// while it lies in the subnet; after the subnet changed, the address moves to
+// the same host bits in the new subnet. An empty result means the Service has
+// no IPv4 address yet to derive one from.
+func (r *ServiceSyncReconciler) calculateServiceIPv4(
+	dp *dynamicprefixiov1alpha1.DynamicPrefix,
+	svc *corev1.Service,
+	block string,
+) (string, error) {
+	if dp.Status.IPv4 == nil || dp.Status.IPv4.Address == "" {
+		return "", fmt.Errorf("DynamicPrefix has no IPv4 address")
+	}
+
+	switch block {
+	case "", IPv4BlockAddress:
+		return dp.Status.IPv4.Address, nil
+	case IPv4BlockSubnet:
+	default:
+		return "", fmt.Errorf("unknown IPv4 block %q, expected %s or %s", block, IPv4BlockAddress, IPv4BlockSubnet)
+	}
+
+	subnet, err := netip.ParsePrefix(dp.Status.IPv4.Subnet)
+	if err != nil || !subnet.Addr().Is4() {
+		return "", fmt.Errorf("invalid IPv4 subnet %q", dp.Status.IPv4.Subnet)
+	}
+
+	var current netip.Addr
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if addr, err := netip.ParseAddr(ingress.IP); err == nil && addr.Is4() {
+			current = addr
+			break
+		}
+	}
+	if !current.IsValid() {
+		return "", nil
+	}
+	if subnet.Contains(current) {
+		return current.String(), nil
+	}
+
+	// Keep the host bits of the old address under the new subnet's network bits
+	hostMask := ^uint32(0) >> subnet.Bits()
+	network := subnet.Masked().Addr().As4()
+	host := current.As4()
+	var mapped [4]byte
+	binary.BigEndian.PutUint32(mapped[:], binary.BigEndian.Uint32(network[:])|binary.BigEndian.Uint32(host[:])&hostMask)
+	return netip.AddrFrom4(mapped).String(), nil
+}
+
+// calculateServiceIPs calculates all IPs for a Service: one per active prefix, then one per
+// draining historical prefix. Returns (allIPs, primaryIP, error), where primaryIP lies in
+// the primary prefix and is the external-dns target.
 func (r *ServiceSyncReconciler) calculateServiceIPs(
 	ctx context.Context,
 	dp *dynamicprefixiov1alpha1.DynamicPrefix,
@@ -185,12 +277,6 @@ func (r *ServiceSyncReconciler) calculateServiceIPs(
 	log := logf.FromContext(ctx)
 	annotations := svc.GetAnnotations()
 
-	// Get max history count
-	maxHistory := 2 // Default
-	if dp.Spec.Transition != nil && dp.Spec.Transition.MaxPrefixHistory > 0 {
-		maxHistory = dp.Spec.Transition.MaxPrefixHistory
-	}
-
 	// Determine the IP offset within the prefix from the current Service IP
 	// This allows us to calculate corresponding IPs in historical prefixes
 	currentAddr, err := netip.ParseAddr(currentServiceIP)
@@ -213,7 +299,7 @@ func (r *ServiceSyncReconciler) calculateServiceIPs(
 
 	if addressRangeName != "" {
 		// Mode 1: Address ranges
-		currentPrefixIP, allIPs, err = r.calculateAddressRangeIPs(dp, currentAddr, addressRangeName, maxHistory)
+		currentPrefixIP, allIPs, err = r.calculateAddressRangeIPs(dp, currentAddr, addressRangeName, includeULA(annotations))
 		if err != nil {
 			log.Error(err, "Failed to calculate address range IPs")
 			// Fall back to current IP only
@@ -221,7 +307,7 @@ func (r *ServiceSyncReconciler) calculateServiceIPs(
 		}
 	} else if subnetName != "" {
 		// Mode 2: Subnets
-		currentPrefixIP, allIPs, err = r.calculateSubnetIPs(dp, currentAddr, subnetName, maxHistory)
+		currentPrefixIP, allIPs, err = r.calculateSubnetIPs(dp, currentAddr, subnetName, includeULA(annotations))
 		if err != nil {
 			log.Error(err, "Failed to calculate subnet IPs")
 			// Fall back to current IP only
@@ -240,7 +326,7 @@ func (r *ServiceSyncReconciler) calculateAddressRangeIPs(
 	dp *dynamicprefixiov1alpha1.DynamicPrefix,
 	currentAddr netip.Addr,
 	addressRangeName string,
-	maxHistory int,
+	withULA bool,
 ) (string, []string, error) {
 	// Find the address range spec
 	var rangeSpec *dynamicprefixiov1alpha1.AddressRangeSpec
@@ -254,55 +340,16 @@ func (r *ServiceSyncReconciler) calculateAddressRangeIPs(
 		return "", nil, nil
 	}
 
-	// Calculate offset of current IP within its range
-	currentPrefix, err := netip.ParsePrefix(dp.Status.CurrentPrefix)
-	if err != nil {
-		return "", nil, err
-	}
-
 	cfg := prefix.AddressRangeConfig{
 		Name:  rangeSpec.Name,
 		Start: rangeSpec.Start,
 		End:   rangeSpec.End,
 	}
 
-	currentRange, err := prefix.CalculateAddressRange(currentPrefix, cfg)
-	if err != nil {
-		return "", nil, err
-	}
-
-	// Calculate offset from start of range
-	offset := r.calculateIPOffset(currentRange.Start, currentAddr)
-
-	var allIPs []string
-	currentPrefixIP := currentAddr.String()
-
-	// Add current prefix IP
-	allIPs = append(allIPs, currentPrefixIP)
-
-	// Calculate IPs for historical prefixes
-	for i, histEntry := range dp.Status.History {
-		if i >= maxHistory {
-			break
-		}
-
-		histPrefix, err := netip.ParsePrefix(histEntry.Prefix)
-		if err != nil {
-			continue
-		}
-
-		histRange, err := prefix.CalculateAddressRange(histPrefix, cfg)
-		if err != nil {
-			continue
-		}
-
-		histIP := r.applyIPOffset(histRange.Start, offset)
-		if histIP.IsValid() {
-			allIPs = append(allIPs, histIP.String())
-		}
-	}
-
-	return currentPrefixIP, allIPs, nil
+	return r.mapServiceIP(dp, currentAddr, withULA, func(p netip.Prefix) (netip.Addr, error) {
+		ar, err := prefix.CalculateAddressRange(p, cfg)
+		return ar.Start, err
+	})
 }
 
 // calculateSubnetIPs calculates IPs for subnet mode.
@@ -310,7 +357,7 @@ func (r *ServiceSyncReconciler) calculateSubnetIPs(
 	dp *dynamicprefixiov1alpha1.DynamicPrefix,
 	currentAddr netip.Addr,
 	subnetName string,
-	maxHistory int,
+	withULA bool,
 ) (string, []string, error) {
 	// Find the subnet spec
 	var subnetSpec *dynamicprefixiov1alpha1.SubnetSpec
@@ -324,55 +371,117 @@ func (r *ServiceSyncReconciler) calculateSubnetIPs(
 		return "", nil, nil
 	}
 
-	// Calculate current subnet
-	currentPrefix, err := netip.ParsePrefix(dp.Status.CurrentPrefix)
-	if err != nil {
-		return "", nil, err
-	}
-
 	cfg := prefix.SubnetConfig{
 		Name:         subnetSpec.Name,
 		Offset:       subnetSpec.Offset,
 		PrefixLength: subnetSpec.PrefixLength,
 	}
 
-	currentSubnet, err := prefix.CalculateSubnet(currentPrefix, cfg)
+	return r.mapServiceIP(dp, currentAddr, withULA, func(p netip.Prefix) (netip.Addr, error) {
+		subnet, err := prefix.CalculateSubnet(p, cfg)
+		return subnet.CIDR.Addr(), err
+	})
+}
+
+// mapServiceIP maps the Service's current IP onto the block of every active prefix,
+// of the ULA prefix with withULA, and of the draining historical prefixes, keeping
+// its offset from the block start. blockStart returns the first address of the
+// block within a prefix. The current IP may lie in any of these, as the pool
+// hands out addresses from all of them.
+// During a phased transition, no address is requested from the new prefix until
+// the pools carry it, and the DNS target stays in the old prefix until the
+// UpdatingDNS phase.
+// Returns (primaryIP, allIPs, error).
+func (r *ServiceSyncReconciler) mapServiceIP(
+	dp *dynamicprefixiov1alpha1.DynamicPrefix,
+	currentAddr netip.Addr,
+	withULA bool,
+	blockStart func(netip.Prefix) (netip.Addr, error),
+) (string, []string, error) {
+	active := activePrefixes(dp)
+	if len(active) == 0 {
+		return "", nil, fmt.Errorf("DynamicPrefix has no active prefix")
+	}
+	if withULA && dp.Status.ULA != nil && dp.Status.ULA.Prefix != "" {
+		// The ULA address is carried along, but never the DNS target
+		active = append(active, activePrefix{prefix: dp.Status.ULA.Prefix})
+	}
+
+	// Calculate offset of current IP within its block
+	basePrefix, err := netip.ParsePrefix(active[0].prefix)
 	if err != nil {
 		return "", nil, err
 	}
-
-	// Calculate offset from start of subnet
-	offset := r.calculateIPOffset(currentSubnet.CIDR.Addr(), currentAddr)
+	candidates := append([]activePrefix{}, active[1:]...)
+	for _, histEntry := range drainingHistory(dp) {
+		candidates = append(candidates, activePrefix{prefix: histEntry.Prefix})
+	}
+	for _, a := range candidates {
+		if p, err := netip.ParsePrefix(a.prefix); err == nil && p.Contains(currentAddr) {
+			basePrefix = p
+			break
+		}
+	}
+	baseStart, err := blockStart(basePrefix)
+	if err != nil {
+		return "", nil, err
+	}
+	offset := r.calculateIPOffset(baseStart, currentAddr)
 
 	var allIPs []string
-	currentPrefixIP := currentAddr.String()
+	var primaryIP, primaryPrefix string
+	heldPrefix, newPrefix := transitionDNSHold(dp)
 
-	// Add current prefix IP
-	allIPs = append(allIPs, currentPrefixIP)
-
-	// Calculate IPs for historical prefixes
-	for i, histEntry := range dp.Status.History {
-		if i >= maxHistory {
-			break
+	// Add an IP for each active prefix
+	for _, a := range active {
+		if transitionAddingBlocks(dp, a.prefix) {
+			// The pools may not carry the new prefix yet
+			continue
 		}
+		p, err := netip.ParsePrefix(a.prefix)
+		if err != nil {
+			continue
+		}
+		start, err := blockStart(p)
+		if err != nil {
+			continue
+		}
+		ip := r.applyIPOffset(start, offset)
+		if !ip.IsValid() {
+			continue
+		}
+		allIPs = append(allIPs, ip.String())
+		if a.primary {
+			primaryIP, primaryPrefix = ip.String(), a.prefix
+		}
+	}
+	if primaryIP == "" {
+		primaryIP = currentAddr.String()
+	}
 
+	// Calculate IPs for draining historical prefixes
+	for _, histEntry := range drainingHistory(dp) {
 		histPrefix, err := netip.ParsePrefix(histEntry.Prefix)
 		if err != nil {
 			continue
 		}
 
-		histSubnet, err := prefix.CalculateSubnet(histPrefix, cfg)
+		histStart, err := blockStart(histPrefix)
 		if err != nil {
 			continue
 		}
 
-		histIP := r.applyIPOffset(histSubnet.CIDR.Addr(), offset)
+		histIP := r.applyIPOffset(histStart, offset)
 		if histIP.IsValid() {
 			allIPs = append(allIPs, histIP.String())
+			if histEntry.Prefix == heldPrefix && (primaryPrefix == newPrefix || primaryPrefix == "") {
+				// DNS keeps pointing at the old prefix until the Services have their new address
+				primaryIP = histIP.String()
+			}
 		}
 	}
 
-	return currentPrefixIP, allIPs, nil
+	return primaryIP, allIPs, nil
 }
 
 // calculateIPOffset calculates the offset between two IPv6 addresses.
@@ -26,6 +26,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
@@ -404,3 +405,381 @@ func TestServiceSyncAnnotationConstants(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceSyncReconciler_calculateServiceIPv4(t *testing.T) {
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			IPv4: &dynamicprefixiov1alpha1.IPv4Status{
+				Address: "198.51.100.1",
+				Subnet:  "198.51.100.0/28",
+			},
+		},
+	}
+	service := func(ips ...string) *corev1.Service {
+		svc := &corev1.Service{}
+		for _, ip := range ips {
+			svc.Status.LoadBalancer.Ingress = append(svc.Status.LoadBalancer.Ingress, corev1.LoadBalancerIngress{IP: ip})
+		}
+		return svc
+	}
+
+	tests := []struct {
+		name    string
+		block   string
+		svc     *corev1.Service
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "address block uses the WAN address",
+			svc:   service("2001:db8::10"),
+			want:  "198.51.100.1",
+			block: IPv4BlockAddress,
+		},
+		{
+			name:  "subnet block keeps an address in the subnet",
+			block: IPv4BlockSubnet,
+			svc:   service("2001:db8::10", "198.51.100.5"),
+			want:  "198.51.100.5",
+		},
+		{
+			name:  "subnet block maps host bits into the new subnet",
+			block: IPv4BlockSubnet,
+			svc:   service("203.0.113.5"),
+			want:  "198.51.100.5",
+		},
+		{
+			name:  "subnet block waits for an address",
+			block: IPv4BlockSubnet,
+			svc:   service("2001:db8::10"),
+			want:  "",
+		},
+		{
+			name:    "unknown block",
+			block:   "range",
+			svc:     service(),
+			wantErr: true,
+		},
+	}
+
+	r := &ServiceSyncReconciler{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.calculateServiceIPv4(dp, tt.svc, tt.block)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("calculateServiceIPv4() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("calculateServiceIPv4() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceSyncReconciler_Reconcile_DualStack(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "dual-stack"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			Transition: &dynamicprefixiov1alpha1.TransitionSpec{Mode: dynamicprefixiov1alpha1.TransitionModeHA},
+		},
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			CurrentPrefix: "2001:db8::/48",
+			IPv4: &dynamicprefixiov1alpha1.IPv4Status{
+				Address: "203.0.113.7",
+				Subnet:  "203.0.113.0/29",
+			},
+		},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+			Annotations: map[string]string{
+				AnnotationName:       "dual-stack",
+				AnnotationIPFamilies: "IPv4,IPv6",
+			},
+		},
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "203.0.113.7"}, {IP: "2001:db8::10"}},
+			},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dp, svc).Build()
+	r := &ServiceSyncReconciler{Client: c, Scheme: scheme}
+
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "web", Namespace: "default"}}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	var got corev1.Service
+	if err := c.Get(ctx, types.NamespacedName{Name: "web", Namespace: "default"}, &got); err != nil {
+		t.Fatalf("Failed to get Service: %v", err)
+	}
+	if ips := got.Annotations[AnnotationCiliumIPs]; ips != "2001:db8::10,203.0.113.7" {
+		t.Errorf("%s = %q", AnnotationCiliumIPs, ips)
+	}
+	if target := got.Annotations[AnnotationExternalDNSTarget]; target != "2001:db8::10,203.0.113.7" {
+		t.Errorf("%s = %q", AnnotationExternalDNSTarget, target)
+	}
+}
+
+func TestServiceSyncReconciler_Reconcile_Withdrawn(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "lost"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			Transition: &dynamicprefixiov1alpha1.TransitionSpec{Mode: dynamicprefixiov1alpha1.TransitionModeHA},
+		},
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			LostPrefix: &dynamicprefixiov1alpha1.LostPrefixStatus{Prefix: "2001:db8::/48", Withdrawn: true},
+		},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+			Annotations: map[string]string{
+				AnnotationName:              "lost",
+				AnnotationCiliumIPs:         "2001:db8::10",
+				AnnotationExternalDNSTarget: "2001:db8::10",
+			},
+		},
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "2001:db8::10"}},
+			},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dp, svc).Build()
+	r := &ServiceSyncReconciler{Client: c, Scheme: scheme}
+
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "web", Namespace: "default"}}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	var got corev1.Service
+	if err := c.Get(ctx, types.NamespacedName{Name: "web", Namespace: "default"}, &got); err != nil {
+		t.Fatalf("Failed to get Service: %v", err)
+	}
+	for _, key := range []string{AnnotationCiliumIPs, AnnotationExternalDNSTarget} {
+		if v, ok := got.Annotations[key]; ok {
+			t.Errorf("%s = %q, want it removed", key, v)
+		}
+	}
+}
+
+func TestServiceSyncReconciler_Reconcile_Uplinks(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "multihomed"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			Transition: &dynamicprefixiov1alpha1.TransitionSpec{Mode: dynamicprefixiov1alpha1.TransitionModeHA},
+			AddressRanges: []dynamicprefixiov1alpha1.AddressRangeSpec{
+				{Name: "lb", Start: "::f000:0:0:1", End: "::f000:0:0:ff"},
+			},
+			Uplinks:       []dynamicprefixiov1alpha1.UplinkSpec{{Name: "isp-b"}},
+			PrimaryUplink: "isp-b",
+		},
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			CurrentPrefix: "2001:db8:a::/64",
+			Uplinks: []dynamicprefixiov1alpha1.UplinkStatus{
+				{Name: "isp-b", Prefix: "2001:db8:b::/64", Primary: true},
+			},
+		},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+			Annotations: map[string]string{
+				AnnotationName:                "multihomed",
+				AnnotationServiceAddressRange: "lb",
+			},
+		},
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				// The pool handed out an address from the uplink's block
+				Ingress: []corev1.LoadBalancerIngress{{IP: "2001:db8:b:0:f000::10"}},
+			},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dp, svc).Build()
+	r := &ServiceSyncReconciler{Client: c, Scheme: scheme}
+
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "web", Namespace: "default"}}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	var got corev1.Service
+	if err := c.Get(ctx, types.NamespacedName{Name: "web", Namespace: "default"}, &got); err != nil {
+		t.Fatalf("Failed to get Service: %v", err)
+	}
+	if ips := got.Annotations[AnnotationCiliumIPs]; ips != "2001:db8:a:0:f000::10,2001:db8:b:0:f000::10" {
+		t.Errorf("%s = %q, want one address per active prefix", AnnotationCiliumIPs, ips)
+	}
+	if target := got.Annotations[AnnotationExternalDNSTarget]; target != "2001:db8:b:0:f000::10" {
+		t.Errorf("%s = %q, want the primary uplink's address", AnnotationExternalDNSTarget, target)
+	}
+}
+
+func TestServiceSyncReconciler_Reconcile_ULA(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			Transition: &dynamicprefixiov1alpha1.TransitionSpec{Mode: dynamicprefixiov1alpha1.TransitionModeHA},
+			Subnets:    []dynamicprefixiov1alpha1.SubnetSpec{{Name: "lb", Offset: 1, PrefixLength: 64}},
+			ULA:        &dynamicprefixiov1alpha1.ULASpec{},
+		},
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			CurrentPrefix: "2001:db8:a::/48",
+			ULA:           &dynamicprefixiov1alpha1.ULAStatus{Prefix: "fd12:3456:789a::/48"},
+		},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+			Annotations: map[string]string{
+				AnnotationName:          "home",
+				AnnotationServiceSubnet: "lb",
+				AnnotationULA:           "true",
+			},
+		},
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "2001:db8:a:1::10"}},
+			},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dp, svc).Build()
+	r := &ServiceSyncReconciler{Client: c, Scheme: scheme}
+
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "web", Namespace: "default"}}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	var got corev1.Service
+	if err := c.Get(ctx, types.NamespacedName{Name: "web", Namespace: "default"}, &got); err != nil {
+		t.Fatalf("Failed to get Service: %v", err)
+	}
+	if ips := got.Annotations[AnnotationCiliumIPs]; ips != "2001:db8:a:1::10,fd12:3456:789a:1::10" {
+		t.Errorf("%s = %q, want the GUA and ULA addresses", AnnotationCiliumIPs, ips)
+	}
+	if target := got.Annotations[AnnotationExternalDNSTarget]; target != "2001:db8:a:1::10" {
+		t.Errorf("%s = %q, want the GUA address only", AnnotationExternalDNSTarget, target)
+	}
+}
+
+func TestServiceSyncReconciler_Reconcile_PhasedTransition(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+
+	tests := []struct {
+		name       string
+		phase      dynamicprefixiov1alpha1.TransitionPhase
+		serviceIP  string
+		wantIPs    string
+		wantTarget string
+	}{
+		{
+			name:       "new prefix not requested while blocks are added",
+			phase:      dynamicprefixiov1alpha1.TransitionPhaseAddingBlocks,
+			serviceIP:  "2001:db8:a:1::10",
+			wantIPs:    "2001:db8:a:1::10",
+			wantTarget: "2001:db8:a:1::10",
+		},
+		{
+			name:       "DNS stays on the old prefix while Services get their new address",
+			phase:      dynamicprefixiov1alpha1.TransitionPhaseAwaitingServiceIPs,
+			serviceIP:  "2001:db8:a:1::10",
+			wantIPs:    "2001:db8:b:1::10,2001:db8:a:1::10",
+			wantTarget: "2001:db8:a:1::10",
+		},
+		{
+			name:       "DNS moves to the new prefix",
+			phase:      dynamicprefixiov1alpha1.TransitionPhaseUpdatingDNS,
+			serviceIP:  "2001:db8:b:1::10",
+			wantIPs:    "2001:db8:b:1::10,2001:db8:a:1::10",
+			wantTarget: "2001:db8:b:1::10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+				ObjectMeta: metav1.ObjectMeta{Name: "home"},
+				Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+					Transition: &dynamicprefixiov1alpha1.TransitionSpec{
+						Mode:   dynamicprefixiov1alpha1.TransitionModeHA,
+						Phases: &dynamicprefixiov1alpha1.TransitionPhasesSpec{},
+					},
+					Subnets: []dynamicprefixiov1alpha1.SubnetSpec{{Name: "lb", Offset: 1, PrefixLength: 64}},
+				},
+				Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+					CurrentPrefix: "2001:db8:b::/48",
+					History: []dynamicprefixiov1alpha1.PrefixHistoryEntry{
+						{Prefix: "2001:db8:a::/48", State: dynamicprefixiov1alpha1.PrefixStateDraining},
+					},
+					Transition: &dynamicprefixiov1alpha1.TransitionStatus{
+						OldPrefix: "2001:db8:a::/48",
+						NewPrefix: "2001:db8:b::/48",
+						Phase:     tt.phase,
+					},
+				},
+			}
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "web",
+					Namespace: "default",
+					Annotations: map[string]string{
+						AnnotationName:          "home",
+						AnnotationServiceSubnet: "lb",
+					},
+				},
+				Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+				Status: corev1.ServiceStatus{
+					LoadBalancer: corev1.LoadBalancerStatus{
+						Ingress: []corev1.LoadBalancerIngress{{IP: tt.serviceIP}},
+					},
+				},
+			}
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dp, svc).Build()
+			r := &ServiceSyncReconciler{Client: c, Scheme: scheme}
+
+			if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "web", Namespace: "default"}}); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+
+			var got corev1.Service
+			if err := c.Get(ctx, types.NamespacedName{Name: "web", Namespace: "default"}, &got); err != nil {
+				t.Fatalf("Failed to get Service: %v", err)
+			}
+			if ips := got.Annotations[AnnotationCiliumIPs]; ips != tt.wantIPs {
+				t.Errorf("%s = %q, want %q", AnnotationCiliumIPs, ips, tt.wantIPs)
+			}
+			if target := got.Annotations[AnnotationExternalDNSTarget]; target != tt.wantTarget {
+				t.Errorf("%s = %q, want %q", AnnotationExternalDNSTarget, target, tt.wantTarget)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+	"github.com/jr42/dynamic-prefix-operator/internal/prefix"
+)
+
+const (
+	// defaultStandbyResyncInterval is how often standby receivers follow DynamicPrefix changes
+	defaultStandbyResyncInterval = 30 * time.Second
+)
+
+// StandbyReceiverFactory creates the passive receivers kept running on every replica
+type StandbyReceiverFactory interface {
+	// CreateStandbyReceivers creates the receivers for the passive sources of the spec
+	CreateStandbyReceivers(spec dynamicprefixiov1alpha1.DynamicPrefixSpec) ([]prefix.StandbyReceiver, error)
+}
+
+// StandbyReceivers runs the passive receivers (Router Advertisement listeners,
+// DNS pollers and DHCPv4 observers) of every DynamicPrefix on every replica,
+// leader or not. They share their listeners with the receivers the leader creates
+// through the same factory, so a replica that takes over leadership finds the
+// prefix already known instead of waiting for the next RA. Active DHCPv6
+// exchanges only run in the leader's reconciler.
+type StandbyReceivers struct {
+	Client  client.Reader
+	Factory StandbyReceiverFactory
+
+	// ResyncInterval is how often the DynamicPrefixes are listed to follow
+	// spec changes and deletions; defaults to 30 seconds
+	ResyncInterval time.Duration
+
+	// receivers maps DynamicPrefix name to its running standby receivers
+	receivers map[string]*standbyReceiverSet
+}
+
+// standbyReceiverSet is the standby receivers of a DynamicPrefix and the acquisition spec they follow
+type standbyReceiverSet struct {
+	specHash  string
+	receivers []prefix.StandbyReceiver
+}
+
+var _ manager.LeaderElectionRunnable = &StandbyReceivers{}
+
+// NeedLeaderElection returns false, so that standby receivers run on every replica.
+func (s *StandbyReceivers) NeedLeaderElection() bool {
+	return false
+}
+
+// Start runs the standby receivers until the context is cancelled.
+func (s *StandbyReceivers) Start(ctx context.Context) error {
+	interval := s.ResyncInterval
+	if interval <= 0 {
+		interval = defaultStandbyResyncInterval
+	}
+	defer s.stopAll()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.sync(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// sync starts standby receivers for new or changed DynamicPrefixes and stops those of deleted ones.
+func (s *StandbyReceivers) sync(ctx context.Context) {
+	log := logf.FromContext(ctx).WithName("standby-receivers")
+
+	var list dynamicprefixiov1alpha1.DynamicPrefixList
+	if err := s.Client.List(ctx, &list); err != nil {
+		log.Error(err, "Failed to list DynamicPrefixes")
+		return
+	}
+	if s.receivers == nil {
+		s.receivers = make(map[string]*standbyReceiverSet)
+	}
+
+	seen := make(map[string]bool, len(list.Items))
+	for i := range list.Items {
+		dp := &list.Items[i]
+		if !dp.DeletionTimestamp.IsZero() {
+			continue
+		}
+		seen[dp.Name] = true
+
+		specHash, err := standbySpecHash(dp.Spec)
+		if err != nil {
+			log.Error(err, "Failed to hash standby spec", "dynamicPrefix", dp.Name)
+			continue
+		}
+		if set, ok := s.receivers[dp.Name]; ok && set.specHash == specHash {
+			continue
+		}
+		s.stop(dp.Name)
+
+		receivers, err := s.Factory.CreateStandbyReceivers(dp.Spec)
+		if err != nil {
+			log.Error(err, "Failed to create standby receivers", "dynamicPrefix", dp.Name)
+			continue
+		}
+		set := &standbyReceiverSet{specHash: specHash}
+		for _, receiver := range receivers {
+			if err := receiver.Start(ctx); err != nil {
+				log.Error(err, "Failed to start standby receiver", "dynamicPrefix", dp.Name)
+				continue
+			}
+			set.receivers = append(set.receivers, receiver)
+		}
+		s.receivers[dp.Name] = set
+		if len(set.receivers) > 0 {
+			log.Info("Started standby receivers", "dynamicPrefix", dp.Name, "count", len(set.receivers))
+		}
+	}
+
+	for name := range s.receivers {
+		if !seen[name] {
+			s.stop(name)
+		}
+	}
+}
+
+// standbySpecHash returns a hash of the parts of the spec the standby receivers are created from
+func standbySpecHash(spec dynamicprefixiov1alpha1.DynamicPrefixSpec) (string, error) {
+	data, err := json.Marshal(struct {
+		Acquisition dynamicprefixiov1alpha1.AcquisitionSpec `json:"acquisition"`
+		Uplinks     []dynamicprefixiov1alpha1.UplinkSpec    `json:"uplinks,omitempty"`
+		IPv4        *dynamicprefixiov1alpha1.IPv4Spec       `json:"ipv4,omitempty"`
+	}{spec.Acquisition, spec.Uplinks, spec.IPv4})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash standby spec: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// stop stops and forgets the standby receivers of a DynamicPrefix.
+func (s *StandbyReceivers) stop(name string) {
+	set, ok := s.receivers[name]
+	if !ok {
+		return
+	}
+	for _, receiver := range set.receivers {
+		if err := receiver.Stop(); err != nil {
+			logf.Log.Error(err, "Failed to stop standby receiver", "name", name)
+		}
+	}
+	delete(s.receivers, name)
+}
+
+// stopAll stops every standby receiver.
+func (s *StandbyReceivers) stopAll() {
+	for name := range s.receivers {
+		s.stop(name)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+	"github.com/jr42/dynamic-prefix-operator/internal/prefix"
+)
+
+// fakeStandbyFactory creates one mock receiver for each RA source and one for spec.ipv4
+type fakeStandbyFactory struct {
+	created []*prefix.MockReceiver
+}
+
+func (f *fakeStandbyFactory) CreateStandbyReceivers(spec dynamicprefixiov1alpha1.DynamicPrefixSpec) ([]prefix.StandbyReceiver, error) {
+	var receivers []prefix.StandbyReceiver
+	if spec.Acquisition.RouterAdvertisement != nil {
+		receiver := prefix.NewMockReceiver(prefix.SourceRouterAdvertisement)
+		f.created = append(f.created, receiver)
+		receivers = append(receivers, receiver)
+	}
+	for _, uplink := range spec.Uplinks {
+		if uplink.Acquisition.RouterAdvertisement != nil {
+			receiver := prefix.NewMockReceiver(prefix.SourceRouterAdvertisement)
+			f.created = append(f.created, receiver)
+			receivers = append(receivers, receiver)
+		}
+	}
+	if spec.IPv4 != nil {
+		receiver := prefix.NewMockReceiver(prefix.SourceUnknown)
+		f.created = append(f.created, receiver)
+		receivers = append(receivers, receiver)
+	}
+	return receivers, nil
+}
+
+func TestStandbyReceivers_sync(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			Acquisition: dynamicprefixiov1alpha1.AcquisitionSpec{
+				RouterAdvertisement: &dynamicprefixiov1alpha1.RouterAdvertisementSpec{Interface: "eth0", Enabled: true},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dp).Build()
+	factory := &fakeStandbyFactory{}
+	s := &StandbyReceivers{Client: c, Factory: factory}
+
+	s.sync(ctx)
+	if len(factory.created) != 1 || !factory.created[0].IsStarted() {
+		t.Fatalf("expected one started standby receiver, got %d", len(factory.created))
+	}
+
+	// An unchanged spec keeps the receiver running
+	s.sync(ctx)
+	if len(factory.created) != 1 {
+		t.Fatalf("standby receiver recreated for an unchanged spec")
+	}
+
+	// A changed spec replaces it
+	dp.Spec.Acquisition.RouterAdvertisement.Interface = "eth1"
+	if err := c.Update(ctx, dp); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	s.sync(ctx)
+	if len(factory.created) != 2 || factory.created[0].IsStarted() || !factory.created[1].IsStarted() {
+		t.Fatalf("standby receiver not replaced after a spec change")
+	}
+
+	// Deleting the DynamicPrefix stops it
+	if err := c.Delete(ctx, dp); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	s.sync(ctx)
+	if factory.created[1].IsStarted() {
+		t.Error("standby receiver still running after the DynamicPrefix was deleted")
+	}
+	if len(s.receivers) != 0 {
+		t.Errorf("receivers = %v, want none", s.receivers)
+	}
+}
+
+func TestStandbyReceivers_sync_IPv4(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			Acquisition: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{Interface: "eth0"},
+			},
+			IPv4: &dynamicprefixiov1alpha1.IPv4Spec{DHCPv4: dynamicprefixiov1alpha1.DHCPv4Spec{Interface: "eth0"}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dp).Build()
+	factory := &fakeStandbyFactory{}
+	s := &StandbyReceivers{Client: c, Factory: factory}
+
+	// The DHCPv4 observer is warmed even though the prefix comes from DHCPv6-PD only
+	s.sync(ctx)
+	if len(factory.created) != 1 || !factory.created[0].IsStarted() {
+		t.Fatalf("expected a started standby DHCPv4 observer, got %d receivers", len(factory.created))
+	}
+
+	// A changed spec.ipv4 replaces it
+	dp.Spec.IPv4.DHCPv4.Interface = "eth1"
+	if err := c.Update(ctx, dp); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	s.sync(ctx)
+	if len(factory.created) != 2 || factory.created[0].IsStarted() || !factory.created[1].IsStarted() {
+		t.Fatalf("standby DHCPv4 observer not replaced after a spec.ipv4 change")
+	}
+}
+
+func TestStandbyReceivers_sync_Uplinks(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			Acquisition: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{Interface: "eth0"},
+			},
+			Uplinks: []dynamicprefixiov1alpha1.UplinkSpec{{
+				Name: "isp-b",
+				Acquisition: dynamicprefixiov1alpha1.AcquisitionSpec{
+					RouterAdvertisement: &dynamicprefixiov1alpha1.RouterAdvertisementSpec{Interface: "eth1", Enabled: true},
+				},
+			}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dp).Build()
+	factory := &fakeStandbyFactory{}
+	s := &StandbyReceivers{Client: c, Factory: factory}
+
+	s.sync(ctx)
+	if len(factory.created) != 1 || !factory.created[0].IsStarted() {
+		t.Fatalf("expected a started standby receiver for the uplink, got %d receivers", len(factory.created))
+	}
+
+	// A changed uplink replaces it
+	dp.Spec.Uplinks[0].Acquisition.RouterAdvertisement.Interface = "eth2"
+	if err := c.Update(ctx, dp); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	s.sync(ctx)
+	if len(factory.created) != 2 || factory.created[0].IsStarted() || !factory.created[1].IsStarted() {
+		t.Fatalf("standby uplink receiver not replaced after a spec.uplinks change")
+	}
+}
+
+func TestStandbyReceivers_NeedLeaderElection(t *testing.T) {
+	if (&StandbyReceivers{}).NeedLeaderElection() {
+		t.Error("standby receivers must run on every replica")
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+)
+
+// Field managers for the server-side apply of DynamicPrefix status. Each
+// controller applies only the fields it owns, so neither can wipe the other's.
+const (
+	// FieldManagerDynamicPrefix owns the prefix, history and all conditions except
+	// BGPAdvertisementReady and NPTReady
+	FieldManagerDynamicPrefix = "dynamic-prefix-operator/dynamicprefix"
+
+	// FieldManagerBGPSync owns subnets[].bgpAdvertisement and the BGPAdvertisementReady condition
+	FieldManagerBGPSync = "dynamic-prefix-operator/bgpsync"
+
+	// FieldManagerNPTSync owns npt and the NPTReady condition
+	FieldManagerNPTSync = "dynamic-prefix-operator/nptsync"
+)
+
+// applyDynamicPrefixStatus server-side applies the given status fields as fieldManager.
+// Fields the manager applied before and leaves out are removed; fields owned by
+// other managers are kept.
+func applyDynamicPrefixStatus(ctx context.Context, c client.Client, dp *dynamicprefixiov1alpha1.DynamicPrefix, status map[string]interface{}, fieldManager string) error {
+	if err := migrateLegacyStatusManagers(ctx, c, dp); err != nil {
+		return err
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": dynamicprefixiov1alpha1.GroupVersion.String(),
+		"kind":       "DynamicPrefix",
+		"metadata": map[string]interface{}{
+			"name": dp.Name,
+		},
+		"status": status,
+	}}
+	if err := c.Status().Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership); err != nil {
+		return fmt.Errorf("failed to apply status: %w", err)
+	}
+	return nil
+}
+
+// migrateLegacyStatusManagers hands the status fields still owned by Update field
+// managers, such as the Status().Update calls of releases before server-side apply,
+// over to FieldManagerDynamicPrefix. Otherwise those managers would keep fields alive
+// that no controller applies anymore. Once migrated, there is nothing left to do.
+func migrateLegacyStatusManagers(ctx context.Context, c client.Client, dp *dynamicprefixiov1alpha1.DynamicPrefix) error {
+	// Legacy managers by the subresource they wrote through
+	legacy := map[string]sets.Set[string]{}
+	for _, entry := range dp.ManagedFields {
+		if entry.Operation != metav1.ManagedFieldsOperationUpdate || entry.FieldsV1 == nil ||
+			!bytes.Contains(entry.FieldsV1.Raw, []byte(`"f:status"`)) {
+			continue
+		}
+		if legacy[entry.Subresource] == nil {
+			legacy[entry.Subresource] = sets.New[string]()
+		}
+		legacy[entry.Subresource].Insert(entry.Manager)
+	}
+	if len(legacy) == 0 {
+		return nil
+	}
+
+	migrated := dp.DeepCopy()
+	for subresource, managers := range legacy {
+		if err := csaupgrade.UpgradeManagedFields(migrated, managers, FieldManagerDynamicPrefix, csaupgrade.Subresource(subresource)); err != nil {
+			return fmt.Errorf("failed to migrate status field managers: %w", err)
+		}
+	}
+	// The optimistic lock makes the patch fail on a stale object instead of dropping newer managers
+	patch := client.MergeFromWithOptions(dp, client.MergeFromWithOptimisticLock{})
+	if err := c.Patch(ctx, migrated, patch); err != nil {
+		return fmt.Errorf("failed to migrate status field managers: %w", err)
+	}
+	logf.FromContext(ctx).Info("Migrated status field managers to server-side apply", "managers", legacy)
+	return nil
+}
+
+// dynamicPrefixOwnedStatus returns the part of the status the DynamicPrefix
+// controller applies: everything except the fields owned by the BGP and NPT controllers.
+func dynamicPrefixOwnedStatus(status *dynamicprefixiov1alpha1.DynamicPrefixStatus) (map[string]interface{}, error) {
+	owned := status.DeepCopy()
+	owned.Conditions = nil
+	for _, cond := range status.Conditions {
+		if cond.Type != dynamicprefixiov1alpha1.ConditionTypeBGPAdvertisementReady &&
+			cond.Type != dynamicprefixiov1alpha1.ConditionTypeNPTReady {
+			owned.Conditions = append(owned.Conditions, cond)
+		}
+	}
+	owned.NPT = nil
+	for i := range owned.Subnets {
+		owned.Subnets[i].BGPAdvertisement = ""
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(owned)
+}
@@ -0,0 +1,421 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/managedfields"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+)
+
+// newDynamicPrefixTypeConverter builds a type converter from the generated CRD,
+// so the fake client merges server-side applies with the same list semantics as
+// the API server. The deduced fallback covers all other types.
+func newDynamicPrefixTypeConverter(t *testing.T) []managedfields.TypeConverter {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "config", "crd", "bases", "dynamic-prefix.io_dynamicprefixes.yaml"))
+	if err != nil {
+		t.Fatalf("failed to read CRD: %v", err)
+	}
+	data, err = yaml.ToJSON(data)
+	if err != nil {
+		t.Fatalf("failed to convert CRD: %v", err)
+	}
+	var crd struct {
+		Spec struct {
+			Group    string `json:"group"`
+			Versions []struct {
+				Name   string `json:"name"`
+				Schema struct {
+					OpenAPIV3Schema json.RawMessage `json:"openAPIV3Schema"`
+				} `json:"schema"`
+			} `json:"versions"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(data, &crd); err != nil {
+		t.Fatalf("failed to parse CRD: %v", err)
+	}
+
+	schemas := map[string]*spec.Schema{}
+	for _, version := range crd.Spec.Versions {
+		schema := &spec.Schema{}
+		if err := json.Unmarshal(version.Schema.OpenAPIV3Schema, schema); err != nil {
+			t.Fatalf("failed to parse schema: %v", err)
+		}
+		schema.AddExtension("x-kubernetes-group-version-kind", []interface{}{
+			map[string]interface{}{"group": crd.Spec.Group, "version": version.Name, "kind": "DynamicPrefix"},
+		})
+		schemas[crd.Spec.Group+"."+version.Name+".DynamicPrefix"] = schema
+	}
+	converter, err := managedfields.NewTypeConverter(schemas, false)
+	if err != nil {
+		t.Fatalf("failed to build type converter: %v", err)
+	}
+	return []managedfields.TypeConverter{converter, managedfields.NewDeducedTypeConverter()}
+}
+
+func TestDynamicPrefixOwnedStatus(t *testing.T) {
+	status := &dynamicprefixiov1alpha1.DynamicPrefixStatus{
+		CurrentPrefix: "2001:db8::/48",
+		Subnets: []dynamicprefixiov1alpha1.SubnetStatus{
+			{Name: "lb", CIDR: "2001:db8::/64", BGPAdvertisement: "dp-home-lb"},
+		},
+		Conditions: []metav1.Condition{
+			{Type: dynamicprefixiov1alpha1.ConditionTypePrefixAcquired, Status: metav1.ConditionTrue, Reason: "PrefixAcquired"},
+			{Type: dynamicprefixiov1alpha1.ConditionTypeBGPAdvertisementReady, Status: metav1.ConditionTrue, Reason: "AdvertisementsReady"},
+			{Type: dynamicprefixiov1alpha1.ConditionTypeNPTReady, Status: metav1.ConditionTrue, Reason: "RulesRendered"},
+		},
+		NPT: &dynamicprefixiov1alpha1.NPTStatus{InternalPrefix: "fd00:1::/48", ExternalPrefix: "2001:db8::/48"},
+	}
+
+	owned, err := dynamicPrefixOwnedStatus(status)
+	if err != nil {
+		t.Fatalf("dynamicPrefixOwnedStatus() error = %v", err)
+	}
+
+	if owned["currentPrefix"] != "2001:db8::/48" {
+		t.Errorf("currentPrefix = %v, want 2001:db8::/48", owned["currentPrefix"])
+	}
+	subnet := owned["subnets"].([]interface{})[0].(map[string]interface{})
+	if _, ok := subnet["bgpAdvertisement"]; ok {
+		t.Errorf("subnet %v should not carry bgpAdvertisement", subnet)
+	}
+	if subnet["cidr"] != "2001:db8::/64" {
+		t.Errorf("cidr = %v, want 2001:db8::/64", subnet["cidr"])
+	}
+	conditions := owned["conditions"].([]interface{})
+	if len(conditions) != 1 || conditions[0].(map[string]interface{})["type"] != dynamicprefixiov1alpha1.ConditionTypePrefixAcquired {
+		t.Errorf("conditions = %v, want only PrefixAcquired", conditions)
+	}
+	if _, ok := owned["npt"]; ok {
+		t.Errorf("owned status should not carry npt, got %v", owned["npt"])
+	}
+
+	// The caller's status is left untouched
+	if status.Subnets[0].BGPAdvertisement != "dp-home-lb" || len(status.Conditions) != 3 || status.NPT == nil {
+		t.Errorf("dynamicPrefixOwnedStatus() modified its input: %+v", status)
+	}
+}
+
+func TestApplyDynamicPrefixStatus_FieldOwnership(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			Subnets: []dynamicprefixiov1alpha1.SubnetSpec{
+				{Name: "lb", Offset: 0, PrefixLength: 64, BGP: &dynamicprefixiov1alpha1.SubnetBGPSpec{Advertise: true}},
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(dp).
+		WithStatusSubresource(dp).
+		WithTypeConverters(newDynamicPrefixTypeConverter(t)...).
+		Build()
+	reconciler := &DynamicPrefixReconciler{Client: fakeClient, Scheme: scheme}
+	bgpReconciler := &BGPSyncReconciler{Client: fakeClient, Scheme: scheme}
+
+	get := func() *dynamicprefixiov1alpha1.DynamicPrefix {
+		t.Helper()
+		var current dynamicprefixiov1alpha1.DynamicPrefix
+		if err := fakeClient.Get(ctx, types.NamespacedName{Name: "home"}, &current); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		return &current
+	}
+
+	// The prefix controller writes the prefix and subnets
+	current := get()
+	original := current.Status.DeepCopy()
+	current.Status.CurrentPrefix = "2001:db8:1::/48"
+	current.Status.Subnets = []dynamicprefixiov1alpha1.SubnetStatus{{Name: "lb", CIDR: "2001:db8:1::/64"}}
+	reconciler.setCondition(current, dynamicprefixiov1alpha1.ConditionTypePrefixAcquired, metav1.ConditionTrue, "PrefixAcquired", "acquired")
+	if err := reconciler.updateStatus(ctx, current, original); err != nil {
+		t.Fatalf("updateStatus() error = %v", err)
+	}
+
+	// The BGP controller adds its advertisement name and condition
+	if err := bgpReconciler.updateStatus(ctx, get(), dp.Spec.Subnets); err != nil {
+		t.Fatalf("BGP updateStatus() error = %v", err)
+	}
+
+	// A prefix change from the prefix controller keeps the BGP controller's fields
+	current = get()
+	original = current.Status.DeepCopy()
+	current.Status.CurrentPrefix = "2001:db8:2::/48"
+	current.Status.Subnets = []dynamicprefixiov1alpha1.SubnetStatus{{Name: "lb", CIDR: "2001:db8:2::/64"}}
+	if err := reconciler.updateStatus(ctx, current, original); err != nil {
+		t.Fatalf("updateStatus() error = %v", err)
+	}
+
+	current = get()
+	if current.Status.CurrentPrefix != "2001:db8:2::/48" {
+		t.Errorf("CurrentPrefix = %q, want 2001:db8:2::/48", current.Status.CurrentPrefix)
+	}
+	if len(current.Status.Subnets) != 1 || current.Status.Subnets[0].CIDR != "2001:db8:2::/64" ||
+		current.Status.Subnets[0].BGPAdvertisement != "dp-home-lb" {
+		t.Errorf("Subnets = %+v, want CIDR 2001:db8:2::/64 with advertisement dp-home-lb", current.Status.Subnets)
+	}
+	for _, condType := range []string{dynamicprefixiov1alpha1.ConditionTypePrefixAcquired, dynamicprefixiov1alpha1.ConditionTypeBGPAdvertisementReady} {
+		if meta.FindStatusCondition(current.Status.Conditions, condType) == nil {
+			t.Errorf("condition %s missing, got %+v", condType, current.Status.Conditions)
+		}
+	}
+
+	// Dropping BGP removes only the advertisement name
+	if err := bgpReconciler.updateStatus(ctx, current, nil); err != nil {
+		t.Fatalf("BGP updateStatus() error = %v", err)
+	}
+	current = get()
+	if len(current.Status.Subnets) != 1 || current.Status.Subnets[0].CIDR != "2001:db8:2::/64" ||
+		current.Status.Subnets[0].BGPAdvertisement != "" {
+		t.Errorf("Subnets = %+v, want CIDR 2001:db8:2::/64 without advertisement", current.Status.Subnets)
+	}
+	if current.Status.CurrentPrefix != "2001:db8:2::/48" {
+		t.Errorf("CurrentPrefix = %q, want it kept by the BGP controller", current.Status.CurrentPrefix)
+	}
+}
+
+func TestApplyDynamicPrefixStatus_DroppedSubnet(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			Subnets: []dynamicprefixiov1alpha1.SubnetSpec{
+				{Name: "lb", Offset: 0, PrefixLength: 64, BGP: &dynamicprefixiov1alpha1.SubnetBGPSpec{Advertise: true}},
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(dp).
+		WithStatusSubresource(dp).
+		WithTypeConverters(newDynamicPrefixTypeConverter(t)...).
+		Build()
+	reconciler := &DynamicPrefixReconciler{Client: fakeClient, Scheme: scheme}
+	bgpReconciler := &BGPSyncReconciler{Client: fakeClient, Scheme: scheme}
+
+	get := func() *dynamicprefixiov1alpha1.DynamicPrefix {
+		t.Helper()
+		var current dynamicprefixiov1alpha1.DynamicPrefix
+		if err := fakeClient.Get(ctx, types.NamespacedName{Name: "home"}, &current); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		return &current
+	}
+
+	current := get()
+	original := current.Status.DeepCopy()
+	current.Status.CurrentPrefix = "2001:db8:1::/48"
+	current.Status.Subnets = []dynamicprefixiov1alpha1.SubnetStatus{{Name: "lb", CIDR: "2001:db8:1::/64"}}
+	if err := reconciler.updateStatus(ctx, current, original); err != nil {
+		t.Fatalf("updateStatus() error = %v", err)
+	}
+	if err := bgpReconciler.updateStatus(ctx, get(), dp.Spec.Subnets); err != nil {
+		t.Fatalf("BGP updateStatus() error = %v", err)
+	}
+
+	// The prefix controller stops listing the subnet: only the BGP controller's field is left
+	current = get()
+	original = current.Status.DeepCopy()
+	current.Status.CurrentPrefix = ""
+	current.Status.Subnets = nil
+	if err := reconciler.updateStatus(ctx, current, original); err != nil {
+		t.Fatalf("updateStatus() error = %v", err)
+	}
+	current = get()
+	if len(current.Status.Subnets) != 1 || current.Status.Subnets[0].CIDR != "" {
+		t.Fatalf("Subnets = %+v, want the leftover advertisement entry", current.Status.Subnets)
+	}
+
+	// The BGP controller drops its name for a subnet the prefix controller no longer lists,
+	// even while the subnet still advertises in spec
+	if err := bgpReconciler.updateStatus(ctx, current, dp.Spec.Subnets); err != nil {
+		t.Fatalf("BGP updateStatus() error = %v", err)
+	}
+	if current = get(); len(current.Status.Subnets) != 0 {
+		t.Errorf("Subnets = %+v, want none", current.Status.Subnets)
+	}
+}
+
+func TestApplyDynamicPrefixStatus_MigratesUpdateManager(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+
+	// A release before server-side apply wrote the whole status with Status().Update.
+	// The entry has no subresource, like the ones the fake client records for status writes.
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "home",
+			ManagedFields: []metav1.ManagedFieldsEntry{{
+				Manager:    "manager",
+				Operation:  metav1.ManagedFieldsOperationUpdate,
+				APIVersion: dynamicprefixiov1alpha1.GroupVersion.String(),
+				FieldsType: "FieldsV1",
+				FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:currentPrefix":{},"f:subnets":{".":{},` +
+					`"k:{\"name\":\"lb\"}":{".":{},"f:bgpAdvertisement":{},"f:cidr":{},"f:name":{}}}}}`)},
+			}},
+		},
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			CurrentPrefix: "2001:db8:1::/48",
+			Subnets:       []dynamicprefixiov1alpha1.SubnetStatus{{Name: "lb", CIDR: "2001:db8:1::/64", BGPAdvertisement: "dp-home-lb"}},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(dp).
+		WithStatusSubresource(dp).
+		WithTypeConverters(newDynamicPrefixTypeConverter(t)...).
+		WithReturnManagedFields().
+		Build()
+	reconciler := &DynamicPrefixReconciler{Client: fakeClient, Scheme: scheme}
+	bgpReconciler := &BGPSyncReconciler{Client: fakeClient, Scheme: scheme}
+
+	get := func() *dynamicprefixiov1alpha1.DynamicPrefix {
+		t.Helper()
+		var current dynamicprefixiov1alpha1.DynamicPrefix
+		if err := fakeClient.Get(ctx, types.NamespacedName{Name: "home"}, &current); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		return &current
+	}
+
+	// The subnet is no longer advertised: both controllers apply their status without it
+	current := get()
+	original := current.Status.DeepCopy()
+	current.Status.Subnets[0].BGPAdvertisement = ""
+	reconciler.setCondition(current, dynamicprefixiov1alpha1.ConditionTypePrefixAcquired, metav1.ConditionTrue, "PrefixAcquired", "acquired")
+	if err := reconciler.updateStatus(ctx, current, original); err != nil {
+		t.Fatalf("updateStatus() error = %v", err)
+	}
+	if err := bgpReconciler.updateStatus(ctx, get(), nil); err != nil {
+		t.Fatalf("BGP updateStatus() error = %v", err)
+	}
+
+	current = get()
+	for _, entry := range current.ManagedFields {
+		if entry.Manager == "manager" {
+			t.Errorf("legacy field manager still present: %+v", entry)
+		}
+	}
+	if len(current.Status.Subnets) != 1 || current.Status.Subnets[0].CIDR != "2001:db8:1::/64" ||
+		current.Status.Subnets[0].BGPAdvertisement != "" {
+		t.Errorf("Subnets = %+v, want CIDR 2001:db8:1::/64 without advertisement", current.Status.Subnets)
+	}
+	if current.Status.CurrentPrefix != "2001:db8:1::/48" {
+		t.Errorf("CurrentPrefix = %q, want 2001:db8:1::/48", current.Status.CurrentPrefix)
+	}
+}
+
+func TestStatusUpdate_SkipsUnchangedConditions(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+	transitioned := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home"},
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			CurrentPrefix: "2001:db8::/48",
+			Conditions: []metav1.Condition{
+				{
+					Type:               dynamicprefixiov1alpha1.ConditionTypePrefixAcquired,
+					Status:             metav1.ConditionTrue,
+					Reason:             "PrefixAcquired",
+					Message:            "Prefix 2001:db8::/48 acquired via ra",
+					LastTransitionTime: transitioned,
+				},
+				{
+					Type:               dynamicprefixiov1alpha1.ConditionTypeBGPAdvertisementReady,
+					Status:             metav1.ConditionFalse,
+					Reason:             "NoBGPSubnets",
+					Message:            "No subnets have BGP advertisement enabled",
+					LastTransitionTime: transitioned,
+				},
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(dp).
+		WithStatusSubresource(dp).
+		WithTypeConverters(newDynamicPrefixTypeConverter(t)...).
+		Build()
+	reconciler := &DynamicPrefixReconciler{Client: fakeClient, Scheme: scheme}
+	bgpReconciler := &BGPSyncReconciler{Client: fakeClient, Scheme: scheme}
+
+	var current dynamicprefixiov1alpha1.DynamicPrefix
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: "home"}, &current); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resourceVersion := current.ResourceVersion
+
+	// Setting the same conditions again writes nothing
+	original := current.Status.DeepCopy()
+	reconciler.setCondition(&current, dynamicprefixiov1alpha1.ConditionTypePrefixAcquired, metav1.ConditionTrue,
+		"PrefixAcquired", "Prefix 2001:db8::/48 acquired via ra")
+	if err := reconciler.updateStatus(ctx, &current, original); err != nil {
+		t.Fatalf("updateStatus() error = %v", err)
+	}
+	if err := bgpReconciler.updateStatus(ctx, &current, nil); err != nil {
+		t.Fatalf("BGP updateStatus() error = %v", err)
+	}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: "home"}, &current); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if current.ResourceVersion != resourceVersion {
+		t.Errorf("ResourceVersion = %s, want %s: unchanged status was written", current.ResourceVersion, resourceVersion)
+	}
+
+	// A new message is written, but the transition time stays
+	original = current.Status.DeepCopy()
+	reconciler.setCondition(&current, dynamicprefixiov1alpha1.ConditionTypePrefixAcquired, metav1.ConditionTrue,
+		"PrefixOverridden", "Prefix 2001:db8::/48 held by freeze override")
+	if err := reconciler.updateStatus(ctx, &current, original); err != nil {
+		t.Fatalf("updateStatus() error = %v", err)
+	}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: "home"}, &current); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	cond := meta.FindStatusCondition(current.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypePrefixAcquired)
+	if cond == nil || cond.Reason != "PrefixOverridden" {
+		t.Fatalf("PrefixAcquired = %+v, want reason PrefixOverridden", cond)
+	}
+	if !cond.LastTransitionTime.Equal(&transitioned) {
+		t.Errorf("LastTransitionTime = %v, want %v", cond.LastTransitionTime, transitioned)
+	}
+	if meta.FindStatusCondition(current.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeBGPAdvertisementReady) == nil {
+		t.Error("BGPAdvertisementReady condition was removed by the prefix controller")
+	}
+}
@@ -0,0 +1,426 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+)
+
+const (
+	// defaultPhasedDrainPeriod is the drain period of a phased transition when spec.transition.drainPeriod is unset
+	defaultPhasedDrainPeriod = time.Hour
+
+	// Default phase timeouts, matching the CRD defaults
+	defaultAddBlocksTimeout    = 5 * time.Minute
+	defaultServiceIPsTimeout   = 10 * time.Minute
+	defaultDNSTimeout          = 5 * time.Minute
+	defaultRemoveBlocksTimeout = 5 * time.Minute
+
+	// transitionPollInterval is how often a waiting phase is checked again;
+	// pool and Service changes do not trigger a reconcile of the DynamicPrefix
+	transitionPollInterval = 5 * time.Second
+)
+
+// +kubebuilder:rbac:groups=cilium.io,resources=ciliumloadbalancerippools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cilium.io,resources=ciliumcidrgroups,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+
+// phasedTransitions reports whether prefix changes run through the phased transition.
+func phasedTransitions(dp *dynamicprefixiov1alpha1.DynamicPrefix) bool {
+	return dp.Spec.Transition != nil && dp.Spec.Transition.Phases != nil
+}
+
+// haMode reports whether the operator manages the addresses of annotated Services.
+func haMode(dp *dynamicprefixiov1alpha1.DynamicPrefix) bool {
+	return dp.Spec.Transition != nil && dp.Spec.Transition.Mode == dynamicprefixiov1alpha1.TransitionModeHA
+}
+
+// phasedDrainPeriod returns how long the old prefix drains once the DNS targets moved.
+func phasedDrainPeriod(dp *dynamicprefixiov1alpha1.DynamicPrefix) time.Duration {
+	if dp.Spec.Transition != nil && dp.Spec.Transition.DrainPeriod != nil {
+		return dp.Spec.Transition.DrainPeriod.Duration
+	}
+	return defaultPhasedDrainPeriod
+}
+
+// phaseTimeout returns the timeout of a waiting phase.
+func phaseTimeout(dp *dynamicprefixiov1alpha1.DynamicPrefix, phase dynamicprefixiov1alpha1.TransitionPhase) time.Duration {
+	phases := dp.Spec.Transition.Phases
+	timeout := func(d *metav1.Duration, fallback time.Duration) time.Duration {
+		if d != nil {
+			return d.Duration
+		}
+		return fallback
+	}
+	switch phase {
+	case dynamicprefixiov1alpha1.TransitionPhaseAddingBlocks:
+		return timeout(phases.AddBlocksTimeout, defaultAddBlocksTimeout)
+	case dynamicprefixiov1alpha1.TransitionPhaseAwaitingServiceIPs:
+		return timeout(phases.ServiceIPsTimeout, defaultServiceIPsTimeout)
+	case dynamicprefixiov1alpha1.TransitionPhaseUpdatingDNS:
+		return timeout(phases.DNSTimeout, defaultDNSTimeout)
+	case dynamicprefixiov1alpha1.TransitionPhaseRemovingOldBlocks:
+		return timeout(phases.RemoveBlocksTimeout, defaultRemoveBlocksTimeout)
+	}
+	return 0
+}
+
+// nextTransitionPhase returns the phase that follows phase.
+func nextTransitionPhase(phase dynamicprefixiov1alpha1.TransitionPhase) dynamicprefixiov1alpha1.TransitionPhase {
+	switch phase {
+	case dynamicprefixiov1alpha1.TransitionPhaseAddingBlocks:
+		return dynamicprefixiov1alpha1.TransitionPhaseAwaitingServiceIPs
+	case dynamicprefixiov1alpha1.TransitionPhaseAwaitingServiceIPs:
+		return dynamicprefixiov1alpha1.TransitionPhaseUpdatingDNS
+	case dynamicprefixiov1alpha1.TransitionPhaseUpdatingDNS:
+		return dynamicprefixiov1alpha1.TransitionPhaseDraining
+	case dynamicprefixiov1alpha1.TransitionPhaseDraining:
+		return dynamicprefixiov1alpha1.TransitionPhaseRemovingOldBlocks
+	}
+	return dynamicprefixiov1alpha1.TransitionPhaseComplete
+}
+
+// startTransition starts the phased transition from oldPrefix to newPrefix.
+// The old prefix of a transition still in progress drains for the drain
+// period from now, as the new transition takes over the DNS targets.
+func (r *DynamicPrefixReconciler) startTransition(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix, oldPrefix, newPrefix string, now time.Time) {
+	log := logf.FromContext(ctx)
+
+	if previous := dp.Status.Transition; previous != nil && previous.Phase != dynamicprefixiov1alpha1.TransitionPhaseComplete {
+		log.Info("Transition superseded", "oldPrefix", previous.OldPrefix, "newPrefix", previous.NewPrefix, "phase", previous.Phase)
+		r.recordEvent(dp, corev1.EventTypeWarning, "TransitionSuperseded",
+			"Transition from %s to %s superseded in phase %s", previous.OldPrefix, previous.NewPrefix, previous.Phase)
+		scheduleDrain(dp, previous.OldPrefix, now.Add(phasedDrainPeriod(dp)))
+	}
+
+	dp.Status.Transition = &dynamicprefixiov1alpha1.TransitionStatus{
+		OldPrefix: oldPrefix,
+		NewPrefix: newPrefix,
+		StartedAt: metav1.NewTime(now),
+	}
+	enterTransitionPhase(dp, dynamicprefixiov1alpha1.TransitionPhaseAddingBlocks, now)
+	log.Info("Transition started", "oldPrefix", oldPrefix, "newPrefix", newPrefix)
+}
+
+// enterTransitionPhase closes the current phase and enters phase at now.
+func enterTransitionPhase(dp *dynamicprefixiov1alpha1.DynamicPrefix, phase dynamicprefixiov1alpha1.TransitionPhase, now time.Time) {
+	t := dp.Status.Transition
+	at := metav1.NewTime(now)
+	if n := len(t.Phases); n > 0 && t.Phases[n-1].CompletedAt == nil {
+		t.Phases[n-1].CompletedAt = &at
+	}
+
+	t.Phase = phase
+	t.PhaseDeadline = nil
+	entry := dynamicprefixiov1alpha1.TransitionPhaseStatus{Phase: phase, StartedAt: at}
+	switch phase {
+	case dynamicprefixiov1alpha1.TransitionPhaseDraining:
+		t.PhaseDeadline = scheduleDrain(dp, t.OldPrefix, now.Add(phasedDrainPeriod(dp)))
+	case dynamicprefixiov1alpha1.TransitionPhaseComplete:
+		entry.CompletedAt = &at
+	default:
+		deadline := metav1.NewTime(now.Add(phaseTimeout(dp, phase)))
+		t.PhaseDeadline = &deadline
+	}
+	t.Phases = append(t.Phases, entry)
+}
+
+// scheduleDrain makes the draining history entry of p expire by deadline at
+// the latest, and returns when it expires. It returns nil when p no longer drains.
+func scheduleDrain(dp *dynamicprefixiov1alpha1.DynamicPrefix, p string, deadline time.Time) *metav1.Time {
+	for i := range dp.Status.History {
+		entry := &dp.Status.History[i]
+		if entry.Prefix != p || entry.State == dynamicprefixiov1alpha1.PrefixStateExpired {
+			continue
+		}
+		if entry.ExpiresAt == nil || deadline.Before(entry.ExpiresAt.Time) {
+			expiresAt := metav1.NewTime(deadline)
+			entry.ExpiresAt = &expiresAt
+		}
+		expiresAt := *entry.ExpiresAt
+		return &expiresAt
+	}
+	return nil
+}
+
+// advanceTransition moves the phased transition on as far as the pools and
+// Services allow, or past phases whose timeout passed, and sets the
+// TransitionComplete condition. It returns when to check again, or the zero time.
+func (r *DynamicPrefixReconciler) advanceTransition(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix, now time.Time) time.Time {
+	log := logf.FromContext(ctx)
+	t := dp.Status.Transition
+
+	if t == nil {
+		return time.Time{}
+	}
+	if !phasedTransitions(dp) || t.NewPrefix != dp.Status.CurrentPrefix {
+		if t.Phase != dynamicprefixiov1alpha1.TransitionPhaseComplete {
+			log.Info("Transition abandoned", "oldPrefix", t.OldPrefix, "newPrefix", t.NewPrefix, "phase", t.Phase)
+			r.recordEvent(dp, corev1.EventTypeWarning, "TransitionAbandoned",
+				"Transition from %s to %s abandoned in phase %s", t.OldPrefix, t.NewPrefix, t.Phase)
+			// The old prefix still drains, for the drain period from now
+			scheduleDrain(dp, t.OldPrefix, now.Add(phasedDrainPeriod(dp)))
+		}
+		dp.Status.Transition = nil
+		meta.RemoveStatusCondition(&dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeTransitionComplete)
+		return time.Time{}
+	}
+	if t.Phase == dynamicprefixiov1alpha1.TransitionPhaseComplete {
+		return time.Time{}
+	}
+
+	for t.Phase != dynamicprefixiov1alpha1.TransitionPhaseComplete {
+		done, message := r.checkTransitionPhase(ctx, dp, now)
+		current := &t.Phases[len(t.Phases)-1]
+		current.Message = message
+
+		if !done {
+			if t.Phase == dynamicprefixiov1alpha1.TransitionPhaseDraining || t.PhaseDeadline == nil || t.PhaseDeadline.After(now) {
+				r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypeTransitionComplete, metav1.ConditionFalse,
+					string(t.Phase), message)
+				// Draining only ends at its deadline; the other phases poll the pools and Services
+				next := now.Add(transitionPollInterval)
+				if t.PhaseDeadline != nil && (t.Phase == dynamicprefixiov1alpha1.TransitionPhaseDraining || t.PhaseDeadline.Time.Before(next)) {
+					next = t.PhaseDeadline.Time
+				}
+				return next
+			}
+			current.TimedOut = true
+			log.Info("Transition phase timed out", "phase", t.Phase, "newPrefix", t.NewPrefix, "reason", message)
+			r.recordEvent(dp, corev1.EventTypeWarning, "TransitionPhaseTimedOut",
+				"Phase %s of the transition to %s timed out: %s", t.Phase, t.NewPrefix, message)
+		}
+
+		next := nextTransitionPhase(t.Phase)
+		log.Info("Transition phase completed", "phase", t.Phase, "next", next, "newPrefix", t.NewPrefix)
+		enterTransitionPhase(dp, next, now)
+	}
+
+	r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypeTransitionComplete, metav1.ConditionTrue,
+		string(dynamicprefixiov1alpha1.TransitionPhaseComplete), fmt.Sprintf("Transition from %s to %s complete", t.OldPrefix, t.NewPrefix))
+	r.recordEvent(dp, corev1.EventTypeNormal, "TransitionComplete",
+		"Transition from %s to %s complete", t.OldPrefix, t.NewPrefix)
+	return time.Time{}
+}
+
+// checkTransitionPhase reports whether the current phase is done, with a
+// message describing what it waits for.
+func (r *DynamicPrefixReconciler) checkTransitionPhase(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix, now time.Time) (bool, string) {
+	t := dp.Status.Transition
+	newPrefix, _ := netip.ParsePrefix(t.NewPrefix)
+	oldPrefix, _ := netip.ParsePrefix(t.OldPrefix)
+
+	switch t.Phase {
+	case dynamicprefixiov1alpha1.TransitionPhaseAddingBlocks:
+		pools, waiting := r.poolsWaiting(ctx, dp, func(pool *unstructured.Unstructured) bool {
+			return !poolCarries(pool, newPrefix)
+		})
+		return waitingFor(waiting, pools, "pools", "to carry "+t.NewPrefix)
+
+	case dynamicprefixiov1alpha1.TransitionPhaseAwaitingServiceIPs:
+		if !haMode(dp) {
+			return true, "Services are not managed outside HA mode"
+		}
+		services, waiting := r.servicesWaiting(ctx, dp, func(svc *corev1.Service) bool {
+			for _, ingress := range svc.Status.LoadBalancer.Ingress {
+				if addr, err := netip.ParseAddr(ingress.IP); err == nil && newPrefix.Contains(addr) {
+					return false
+				}
+			}
+			// Services without any address yet get one from the new prefix directly
+			return len(svc.Status.LoadBalancer.Ingress) > 0
+		})
+		return waitingFor(waiting, services, "Services", "to get an address from "+t.NewPrefix)
+
+	case dynamicprefixiov1alpha1.TransitionPhaseUpdatingDNS:
+		if !haMode(dp) {
+			return true, "Services are not managed outside HA mode"
+		}
+		if !transitionPrefixIsPrimary(dp) {
+			return true, "DNS targets follow the primary uplink"
+		}
+		services, waiting := r.servicesWaiting(ctx, dp, func(svc *corev1.Service) bool {
+			for _, target := range strings.Split(svc.Annotations[AnnotationExternalDNSTarget], ",") {
+				if addr, err := netip.ParseAddr(strings.TrimSpace(target)); err == nil && newPrefix.Contains(addr) {
+					return false
+				}
+			}
+			return len(svc.Status.LoadBalancer.Ingress) > 0
+		})
+		return waitingFor(waiting, services, "Services", "to publish their address in "+t.NewPrefix+" to DNS")
+
+	case dynamicprefixiov1alpha1.TransitionPhaseDraining:
+		if expiresAt := scheduleDrain(dp, t.OldPrefix, now.Add(phasedDrainPeriod(dp))); expiresAt != nil && expiresAt.After(now) {
+			t.PhaseDeadline = expiresAt
+			return false, fmt.Sprintf("Draining %s until %s", t.OldPrefix, expiresAt.UTC().Format(time.RFC3339))
+		}
+		return true, fmt.Sprintf("%s drained", t.OldPrefix)
+
+	case dynamicprefixiov1alpha1.TransitionPhaseRemovingOldBlocks:
+		pools, waiting := r.poolsWaiting(ctx, dp, func(pool *unstructured.Unstructured) bool {
+			return poolCarries(pool, oldPrefix)
+		})
+		return waitingFor(waiting, pools, "pools", "to drop "+t.OldPrefix)
+	}
+	return true, ""
+}
+
+// waitingFor builds the result of a phase check that waits for objects.
+func waitingFor(waiting []string, total int, kind, what string) (bool, string) {
+	if len(waiting) == 0 {
+		return true, fmt.Sprintf("All %d %s %s", total, kind, strings.TrimPrefix(what, "to "))
+	}
+	return false, fmt.Sprintf("Waiting for %d of %d %s %s: %s", len(waiting), total, kind, what, strings.Join(waiting, ", "))
+}
+
+// transitionPrefixIsPrimary reports whether the new prefix of the transition
+// is the primary prefix, whose addresses are the DNS targets.
+func transitionPrefixIsPrimary(dp *dynamicprefixiov1alpha1.DynamicPrefix) bool {
+	for _, active := range activePrefixes(dp) {
+		if active.primary {
+			return active.prefix == dp.Status.Transition.NewPrefix
+		}
+	}
+	return false
+}
+
+// poolsWaiting lists the IPv6 pools of dp and returns their number and the
+// names of those waiting reports true for. Pool kinds that cannot be listed,
+// e.g. because Cilium does not install them, count as having no pools.
+func (r *DynamicPrefixReconciler) poolsWaiting(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix, waiting func(*unstructured.Unstructured) bool) (int, []string) {
+	log := logf.FromContext(ctx)
+
+	total := 0
+	var names []string
+	for _, gvk := range []schema.GroupVersionKind{CiliumLBIPPoolGVK, CiliumCIDRGroupGVK} {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := r.List(ctx, list); err != nil {
+			log.V(1).Info("Failed to list pools", "kind", gvk.Kind, "error", err.Error())
+			continue
+		}
+		for i := range list.Items {
+			pool := &list.Items[i]
+			annotations := pool.GetAnnotations()
+			if annotations[AnnotationName] != dp.Name {
+				continue
+			}
+			if families, err := parseIPFamilies(annotations[AnnotationIPFamilies]); err != nil || !families.ipv6 {
+				continue
+			}
+			total++
+			if waiting(pool) {
+				names = append(names, pool.GetName())
+			}
+		}
+	}
+	return total, names
+}
+
+// servicesWaiting lists the Services of dp and returns their number and the
+// namespace/names of those waiting reports true for.
+func (r *DynamicPrefixReconciler) servicesWaiting(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix, waiting func(*corev1.Service) bool) (int, []string) {
+	log := logf.FromContext(ctx)
+
+	var list corev1.ServiceList
+	if err := r.List(ctx, &list); err != nil {
+		log.V(1).Info("Failed to list Services", "error", err.Error())
+		return 0, nil
+	}
+	total := 0
+	var names []string
+	for i := range list.Items {
+		svc := &list.Items[i]
+		if svc.Spec.Type != corev1.ServiceTypeLoadBalancer || svc.Annotations[AnnotationName] != dp.Name {
+			continue
+		}
+		if families, err := parseIPFamilies(svc.Annotations[AnnotationIPFamilies]); err != nil || !families.ipv6 {
+			continue
+		}
+		total++
+		if waiting(svc) {
+			names = append(names, svc.Namespace+"/"+svc.Name)
+		}
+	}
+	return total, names
+}
+
+// poolCarries reports whether a CiliumLoadBalancerIPPool or CiliumCIDRGroup
+// has a block within p.
+func poolCarries(pool *unstructured.Unstructured, p netip.Prefix) bool {
+	if !p.IsValid() {
+		return false
+	}
+	blocks, _, _ := unstructured.NestedSlice(pool.Object, "spec", "blocks")
+	for _, b := range blocks {
+		block, ok := b.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if cidr, ok := block["cidr"].(string); ok {
+			if blockPrefix, err := netip.ParsePrefix(cidr); err == nil && p.Overlaps(blockPrefix) {
+				return true
+			}
+		}
+		if start, ok := block["start"].(string); ok {
+			if addr, err := netip.ParseAddr(start); err == nil && p.Contains(addr) {
+				return true
+			}
+		}
+	}
+	cidrs, _, _ := unstructured.NestedStringSlice(pool.Object, "spec", "externalCIDRs")
+	for _, cidr := range cidrs {
+		if blockPrefix, err := netip.ParsePrefix(cidr); err == nil && p.Overlaps(blockPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionAddingBlocks reports whether p is the new prefix of a transition
+// whose pools may not carry it yet, so Services must not request addresses from it.
+func transitionAddingBlocks(dp *dynamicprefixiov1alpha1.DynamicPrefix, p string) bool {
+	t := dp.Status.Transition
+	return t != nil && t.Phase == dynamicprefixiov1alpha1.TransitionPhaseAddingBlocks && t.NewPrefix == p
+}
+
+// transitionDNSHold returns the old prefix the DNS targets stay on until the
+// transition reaches UpdatingDNS, or an empty string.
+func transitionDNSHold(dp *dynamicprefixiov1alpha1.DynamicPrefix) (oldPrefix, newPrefix string) {
+	t := dp.Status.Transition
+	if t == nil {
+		return "", ""
+	}
+	switch t.Phase {
+	case dynamicprefixiov1alpha1.TransitionPhaseAddingBlocks, dynamicprefixiov1alpha1.TransitionPhaseAwaitingServiceIPs:
+		return t.OldPrefix, t.NewPrefix
+	}
+	return "", ""
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+	"github.com/jr42/dynamic-prefix-operator/internal/prefix"
+)
+
+// newTransitionPrefix returns a DynamicPrefix that just changed from
+// 2001:db8:a::/48 to 2001:db8:b::/48 with phased transitions.
+func newTransitionPrefix(mode dynamicprefixiov1alpha1.TransitionMode) *dynamicprefixiov1alpha1.DynamicPrefix {
+	return &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			Transition: &dynamicprefixiov1alpha1.TransitionSpec{
+				Mode:        mode,
+				DrainPeriod: &metav1.Duration{Duration: time.Hour},
+				Phases:      &dynamicprefixiov1alpha1.TransitionPhasesSpec{},
+			},
+		},
+		Status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+			CurrentPrefix: "2001:db8:b::/48",
+			History: []dynamicprefixiov1alpha1.PrefixHistoryEntry{
+				{Prefix: "2001:db8:a::/48", State: dynamicprefixiov1alpha1.PrefixStateDraining},
+			},
+		},
+	}
+}
+
+func newTransitionPool(cidrs ...string) *unstructured.Unstructured {
+	pool := &unstructured.Unstructured{}
+	pool.SetGroupVersionKind(CiliumLBIPPoolGVK)
+	pool.SetName("lb-pool")
+	pool.SetAnnotations(map[string]string{AnnotationName: "home"})
+	setTransitionPoolBlocks(pool, cidrs...)
+	return pool
+}
+
+func setTransitionPoolBlocks(pool *unstructured.Unstructured, cidrs ...string) {
+	var blocks []interface{}
+	for _, cidr := range cidrs {
+		blocks = append(blocks, map[string]interface{}{"cidr": cidr})
+	}
+	_ = unstructured.SetNestedSlice(pool.Object, blocks, "spec", "blocks")
+}
+
+func TestDynamicPrefixReconciler_advanceTransition(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+	now := time.Now()
+
+	dp := newTransitionPrefix(dynamicprefixiov1alpha1.TransitionModeHA)
+	pool := newTransitionPool("2001:db8:a:1::/64")
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "default",
+			Annotations: map[string]string{AnnotationName: "home"},
+		},
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "2001:db8:a:1::10"}},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pool, svc).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &DynamicPrefixReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+
+	update := func(obj client.Object) {
+		t.Helper()
+		if err := c.Update(ctx, obj); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	}
+	expectPhase := func(at time.Time, want dynamicprefixiov1alpha1.TransitionPhase) time.Time {
+		t.Helper()
+		next := r.advanceTransition(ctx, dp, at)
+		if got := dp.Status.Transition.Phase; got != want {
+			t.Fatalf("phase = %s, want %s (%+v)", got, want, dp.Status.Transition.Phases)
+		}
+		cond := meta.FindStatusCondition(dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeTransitionComplete)
+		if cond == nil || cond.Reason != string(want) {
+			t.Fatalf("TransitionComplete = %+v, want reason %s", cond, want)
+		}
+		return next
+	}
+
+	r.startTransition(ctx, dp, "2001:db8:a::/48", "2001:db8:b::/48", now)
+
+	// The pool does not carry the new prefix yet
+	next := expectPhase(now, dynamicprefixiov1alpha1.TransitionPhaseAddingBlocks)
+	if !next.Equal(now.Add(transitionPollInterval)) {
+		t.Errorf("advanceTransition() = %v, want a poll after %v", next, transitionPollInterval)
+	}
+	if msg := dp.Status.Transition.Phases[0].Message; !strings.Contains(msg, "lb-pool") {
+		t.Errorf("message = %q, want the waiting pool named", msg)
+	}
+
+	// The Service still has only its old address
+	setTransitionPoolBlocks(pool, "2001:db8:b:1::/64", "2001:db8:a:1::/64")
+	update(pool)
+	expectPhase(now, dynamicprefixiov1alpha1.TransitionPhaseAwaitingServiceIPs)
+
+	// Its DNS target is still in the old prefix
+	svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "2001:db8:b:1::10"}, {IP: "2001:db8:a:1::10"}}
+	if err := c.Status().Update(ctx, svc); err != nil {
+		t.Fatalf("Status().Update() error = %v", err)
+	}
+	expectPhase(now, dynamicprefixiov1alpha1.TransitionPhaseUpdatingDNS)
+
+	// Once DNS moved, the old prefix drains for the drain period
+	svc.Annotations[AnnotationExternalDNSTarget] = "2001:db8:b:1::10"
+	update(svc)
+	next = expectPhase(now, dynamicprefixiov1alpha1.TransitionPhaseDraining)
+	if entry := dp.Status.History[0]; entry.ExpiresAt == nil || !entry.ExpiresAt.Time.Equal(now.Add(time.Hour)) {
+		t.Errorf("history ExpiresAt = %v, want the drain period from now", entry.ExpiresAt)
+	}
+	if !next.Equal(now.Add(time.Hour)) {
+		t.Errorf("advanceTransition() = %v, want the end of the drain period", next)
+	}
+
+	// After the drain period the old blocks are removed
+	later := now.Add(time.Hour + time.Second)
+	r.expireHistory(ctx, dp, later)
+	expectPhase(later, dynamicprefixiov1alpha1.TransitionPhaseRemovingOldBlocks)
+
+	setTransitionPoolBlocks(pool, "2001:db8:b:1::/64")
+	update(pool)
+	if next := expectPhase(later, dynamicprefixiov1alpha1.TransitionPhaseComplete); !next.IsZero() {
+		t.Errorf("advanceTransition() = %v, want no requeue when complete", next)
+	}
+	if cond := meta.FindStatusCondition(dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeTransitionComplete); cond.Status != metav1.ConditionTrue {
+		t.Errorf("TransitionComplete = %+v, want True", cond)
+	}
+
+	phases := dp.Status.Transition.Phases
+	if len(phases) != 6 {
+		t.Fatalf("phases = %+v, want all six recorded", phases)
+	}
+	for _, phase := range phases {
+		if phase.CompletedAt == nil || phase.TimedOut {
+			t.Errorf("phase %+v, want completed without timeout", phase)
+		}
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "TransitionComplete") {
+		t.Errorf("event = %q, want TransitionComplete", event)
+	}
+}
+
+func TestDynamicPrefixReconciler_advanceTransition_Timeout(t *testing.T) {
+	ctx := context.Background()
+	scheme := newTestScheme()
+	now := time.Now()
+
+	dp := newTransitionPrefix(dynamicprefixiov1alpha1.TransitionModeSimple)
+	dp.Spec.Transition.Phases.AddBlocksTimeout = &metav1.Duration{Duration: time.Minute}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newTransitionPool("2001:db8:a:1::/64")).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &DynamicPrefixReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+
+	r.startTransition(ctx, dp, "2001:db8:a::/48", "2001:db8:b::/48", now)
+	if next := r.advanceTransition(ctx, dp, now.Add(59*time.Second)); !next.Equal(now.Add(time.Minute)) {
+		t.Errorf("advanceTransition() = %v, want the phase deadline", next)
+	}
+
+	// Past the timeout the transition moves on; outside HA mode there are no Services to wait for
+	r.advanceTransition(ctx, dp, now.Add(time.Minute))
+	transition := dp.Status.Transition
+	if transition.Phase != dynamicprefixiov1alpha1.TransitionPhaseDraining {
+		t.Fatalf("phase = %s, want Draining", transition.Phase)
+	}
+	if !transition.Phases[0].TimedOut || transition.Phases[1].TimedOut {
+		t.Errorf("phases = %+v, want only AddingBlocks timed out", transition.Phases)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "TransitionPhaseTimedOut") {
+		t.Errorf("event = %q, want TransitionPhaseTimedOut", event)
+	}
+}
+
+func TestDynamicPrefixReconciler_startTransition_Supersedes(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	r := &DynamicPrefixReconciler{Recorder: record.NewFakeRecorder(10)}
+
+	dp := newTransitionPrefix(dynamicprefixiov1alpha1.TransitionModeHA)
+	r.startTransition(ctx, dp, "2001:db8:a::/48", "2001:db8:b::/48", now)
+
+	// Another change arrives before the first transition finished
+	dp.Status.History = append(dp.Status.History, dynamicprefixiov1alpha1.PrefixHistoryEntry{
+		Prefix: "2001:db8:b::/48", State: dynamicprefixiov1alpha1.PrefixStateDraining,
+	})
+	dp.Status.CurrentPrefix = "2001:db8:c::/48"
+	r.startTransition(ctx, dp, "2001:db8:b::/48", "2001:db8:c::/48", now)
+
+	if entry := dp.Status.History[0]; entry.ExpiresAt == nil || !entry.ExpiresAt.Time.Equal(now.Add(time.Hour)) {
+		t.Errorf("superseded prefix ExpiresAt = %v, want the drain period from now", entry.ExpiresAt)
+	}
+	if entry := dp.Status.History[1]; entry.ExpiresAt != nil {
+		t.Errorf("prefix being replaced ExpiresAt = %v, want it to wait for its own transition", entry.ExpiresAt)
+	}
+	if transition := dp.Status.Transition; transition.OldPrefix != "2001:db8:b::/48" || transition.Phase != dynamicprefixiov1alpha1.TransitionPhaseAddingBlocks {
+		t.Errorf("transition = %+v, want a new one from 2001:db8:b::/48", transition)
+	}
+}
+
+func TestDrainDeadline_PhasedTransition(t *testing.T) {
+	now := metav1.Now()
+	dp := newTransitionPrefix(dynamicprefixiov1alpha1.TransitionModeHA)
+	if deadline := drainDeadline(dp, now); deadline != nil {
+		t.Errorf("drainDeadline() = %v, want the drain period left to the Draining phase", deadline)
+	}
+
+	lease := metav1.NewTime(now.Add(30 * time.Minute))
+	dp.Status.LeaseExpiresAt = &lease
+	if deadline := drainDeadline(dp, now); deadline == nil || !deadline.Equal(&lease) {
+		t.Errorf("drainDeadline() = %v, want the lease expiry", deadline)
+	}
+}
+
+func TestPoolCarries(t *testing.T) {
+	p := netip.MustParsePrefix("2001:db8:b::/48")
+	tests := []struct {
+		name string
+		spec map[string]interface{}
+		want bool
+	}{
+		{"cidr block", map[string]interface{}{"blocks": []interface{}{map[string]interface{}{"cidr": "2001:db8:b:1::/64"}}}, true},
+		{"range block", map[string]interface{}{"blocks": []interface{}{map[string]interface{}{"start": "2001:db8:b::f000:0:0:0", "stop": "2001:db8:b::ffff:ffff:ffff:ffff"}}}, true},
+		{"other prefix", map[string]interface{}{"blocks": []interface{}{map[string]interface{}{"cidr": "2001:db8:a:1::/64"}}}, false},
+		{"cidr group", map[string]interface{}{"externalCIDRs": []interface{}{"2001:db8:b:1::/64"}}, true},
+		{"empty", map[string]interface{}{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := &unstructured.Unstructured{Object: map[string]interface{}{"spec": tt.spec}}
+			if got := poolCarries(pool, p); got != tt.want {
+				t.Errorf("poolCarries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDynamicPrefixReconciler_handlePrefixChange_StartsTransition(t *testing.T) {
+	ctx := context.Background()
+	r := &DynamicPrefixReconciler{}
+
+	dp := newTransitionPrefix(dynamicprefixiov1alpha1.TransitionModeHA)
+	dp.Status.CurrentPrefix = "2001:db8:a::/48"
+	dp.Status.History = nil
+	r.handlePrefixChange(ctx, dp, &prefix.Prefix{Network: netip.MustParsePrefix("2001:db8:b::/48")})
+
+	transition := dp.Status.Transition
+	if transition == nil || transition.OldPrefix != "2001:db8:a::/48" || transition.NewPrefix != "2001:db8:b::/48" ||
+		transition.Phase != dynamicprefixiov1alpha1.TransitionPhaseAddingBlocks || transition.PhaseDeadline == nil {
+		t.Fatalf("transition = %+v, want AddingBlocks from 2001:db8:a::/48 to 2001:db8:b::/48", transition)
+	}
+	if len(dp.Status.History) != 1 || dp.Status.History[0].ExpiresAt != nil {
+		t.Errorf("history = %+v, want the old prefix draining without a deadline", dp.Status.History)
+	}
+
+	// Without phases no transition is tracked
+	dp = newTransitionPrefix(dynamicprefixiov1alpha1.TransitionModeHA)
+	dp.Spec.Transition.Phases = nil
+	dp.Status.CurrentPrefix = "2001:db8:a::/48"
+	r.handlePrefixChange(ctx, dp, &prefix.Prefix{Network: netip.MustParsePrefix("2001:db8:b::/48")})
+	if dp.Status.Transition != nil {
+		t.Errorf("transition = %+v, want none without spec.transition.phases", dp.Status.Transition)
+	}
+}
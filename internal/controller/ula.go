@@ -0,0 +1,79 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/netip"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+	"github.com/jr42/dynamic-prefix-operator/internal/prefix"
+)
+
+// updateULAStatus maintains the unique local companion prefix in status.ula.
+// The prefix comes from spec.ula.prefix, else from status, else it is generated
+// from the object's UID, so it stays the same for the lifetime of the object.
+// Address ranges and subnets are calculated on it like on the dynamic prefix.
+func (r *DynamicPrefixReconciler) updateULAStatus(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix) {
+	log := logf.FromContext(ctx)
+
+	if dp.Spec.ULA == nil {
+		dp.Status.ULA = nil
+		return
+	}
+
+	var ula netip.Prefix
+	if dp.Spec.ULA.Prefix != "" {
+		pinned, err := prefix.ParseULAPrefix(dp.Spec.ULA.Prefix)
+		if err != nil {
+			log.Error(err, "Ignoring spec.ula.prefix")
+			r.recordEvent(dp, corev1.EventTypeWarning, "InvalidULAPrefix", "Ignoring spec.ula.prefix: %v", err)
+		}
+		ula = pinned
+	}
+	if !ula.IsValid() && dp.Status.ULA != nil {
+		ula, _ = prefix.ParseULAPrefix(dp.Status.ULA.Prefix)
+	}
+	if !ula.IsValid() {
+		ula = prefix.GenerateULAPrefix([]byte(dp.UID))
+		log.Info("Generated ULA prefix", "prefix", ula.String())
+	}
+
+	status := &dynamicprefixiov1alpha1.ULAStatus{Prefix: ula.String()}
+	if subnets, err := r.calculateSubnets(ula, dp.Spec.Subnets); err != nil {
+		log.Error(err, "Failed to calculate ULA subnets")
+	} else {
+		status.Subnets = subnets
+	}
+	if addressRanges, err := r.calculateAddressRanges(ula, dp.Spec.AddressRanges); err != nil {
+		log.Error(err, "Failed to calculate ULA address ranges")
+	} else {
+		status.AddressRanges = addressRanges
+	}
+	dp.Status.ULA = status
+}
+
+// includeULA reports whether a pool or Service asked for the ULA blocks and
+// addresses with the dynamic-prefix.io/ula annotation.
+func includeULA(annotations map[string]string) bool {
+	include, _ := strconv.ParseBool(annotations[AnnotationULA])
+	return include
+}
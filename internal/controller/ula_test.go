@@ -0,0 +1,93 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+	"github.com/jr42/dynamic-prefix-operator/internal/prefix"
+)
+
+func TestDynamicPrefixReconciler_updateULAStatus(t *testing.T) {
+	ctx := context.Background()
+	recorder := record.NewFakeRecorder(10)
+	r := &DynamicPrefixReconciler{Recorder: recorder}
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", UID: "8c5b1d6e-4f1a-4c2e-9b7a-0d3e5f6a7b8c"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			ULA:           &dynamicprefixiov1alpha1.ULASpec{},
+			Subnets:       []dynamicprefixiov1alpha1.SubnetSpec{{Name: "lb", Offset: 1, PrefixLength: 64}},
+			AddressRanges: []dynamicprefixiov1alpha1.AddressRangeSpec{{Name: "web", Start: "::f000:0:0:0", End: "::ffff:ffff:ffff:ffff"}},
+		},
+	}
+
+	// Generated from the UID
+	r.updateULAStatus(ctx, dp)
+	generated := prefix.GenerateULAPrefix([]byte(dp.UID)).String()
+	if dp.Status.ULA == nil || dp.Status.ULA.Prefix != generated {
+		t.Fatalf("ULA = %+v, want generated prefix %s", dp.Status.ULA, generated)
+	}
+	if len(dp.Status.ULA.Subnets) != 1 || len(dp.Status.ULA.AddressRanges) != 1 {
+		t.Errorf("ULA = %+v, want one subnet and one address range", dp.Status.ULA)
+	}
+
+	// A persisted prefix is kept, whatever the UID
+	dp.Status.ULA.Prefix = "fd12:3456:789a::/48"
+	r.updateULAStatus(ctx, dp)
+	if dp.Status.ULA.Prefix != "fd12:3456:789a::/48" {
+		t.Errorf("ULA prefix = %s, want the persisted fd12:3456:789a::/48", dp.Status.ULA.Prefix)
+	}
+	if got := dp.Status.ULA.Subnets[0].CIDR; got != "fd12:3456:789a:1::/64" {
+		t.Errorf("ULA subnet = %s, want fd12:3456:789a:1::/64", got)
+	}
+
+	// A pinned prefix wins
+	dp.Spec.ULA.Prefix = "fdab:cdef:1::/48"
+	r.updateULAStatus(ctx, dp)
+	if dp.Status.ULA.Prefix != "fdab:cdef:1::/48" {
+		t.Errorf("ULA prefix = %s, want the pinned fdab:cdef:1::/48", dp.Status.ULA.Prefix)
+	}
+
+	// An invalid pin is reported and ignored
+	dp.Spec.ULA.Prefix = "fdab:cdef:1::/56"
+	r.updateULAStatus(ctx, dp)
+	if dp.Status.ULA.Prefix != "fdab:cdef:1::/48" {
+		t.Errorf("ULA prefix = %s, want fdab:cdef:1::/48 kept", dp.Status.ULA.Prefix)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.HasPrefix(event, "Warning InvalidULAPrefix") {
+			t.Errorf("event = %q, want InvalidULAPrefix warning", event)
+		}
+	default:
+		t.Error("no event for the invalid spec.ula.prefix")
+	}
+
+	// Without spec.ula the status goes away
+	dp.Spec.ULA = nil
+	r.updateULAStatus(ctx, dp)
+	if dp.Status.ULA != nil {
+		t.Errorf("ULA = %+v, want nil without spec.ula", dp.Status.ULA)
+	}
+}
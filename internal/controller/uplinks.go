@@ -0,0 +1,279 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+	"github.com/jr42/dynamic-prefix-operator/internal/prefix"
+)
+
+// uplinkReceiver is a running receiver for one of spec.uplinks
+type uplinkReceiver struct {
+	receiver    prefix.Receiver
+	specHash    string
+	cancelWatch context.CancelFunc
+}
+
+// uplinkOwner is the receiver owner of an uplink. The owner derives the DHCPv6
+// IAID and macvlan defaults, and a slash never occurs in a DynamicPrefix name.
+func uplinkOwner(dpName, uplink string) string {
+	return dpName + "/" + uplink
+}
+
+// updateUplinkStatus reports the prefix of each of spec.uplinks in status.uplinks,
+// with address ranges and subnets calculated from it, and the UplinksAcquired
+// condition. An uplink keeps its last prefix until the lease expires, so that a
+// restarting receiver does not pull it from pools and Services. It returns the
+// earliest lease expiry, or the zero time.
+func (r *DynamicPrefixReconciler) updateUplinkStatus(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix, now time.Time) time.Time {
+	log := logf.FromContext(ctx)
+
+	var statuses []dynamicprefixiov1alpha1.UplinkStatus
+	var waiting, failed []string
+	var nextExpiry time.Time
+	keep := make(map[string]bool, len(dp.Spec.Uplinks))
+	for i := range dp.Spec.Uplinks {
+		uplink := &dp.Spec.Uplinks[i]
+		keep[uplink.Name] = true
+		previous := findUplinkStatus(dp.Status.Uplinks, uplink.Name)
+		status := dynamicprefixiov1alpha1.UplinkStatus{
+			Name:    uplink.Name,
+			Primary: uplink.Name == dp.Spec.PrimaryUplink,
+		}
+
+		var hint netip.Prefix
+		if previous != nil {
+			hint, _ = netip.ParsePrefix(previous.Prefix)
+		}
+		receiver, err := r.getOrCreateUplinkReceiver(ctx, dp, uplink, hint)
+		var current *prefix.Prefix
+		if err != nil {
+			log.Error(err, "Failed to create uplink receiver", "uplink", uplink.Name)
+			failed = append(failed, fmt.Sprintf("%s: %v", uplink.Name, err))
+		} else {
+			current = receiver.CurrentPrefix()
+		}
+
+		switch {
+		case current != nil:
+			status.Prefix = current.Network.String()
+			status.PrefixSource = prefixSourceOf(current, receiver)
+			status.PrefixAcquiredAt = &metav1.Time{Time: now}
+			if previous != nil && previous.Prefix == status.Prefix && previous.PrefixAcquiredAt != nil {
+				status.PrefixAcquiredAt = previous.PrefixAcquiredAt
+			}
+			if current.ValidLifetime > 0 {
+				expiresAt := metav1.NewTime(current.ReceivedAt.Add(current.ValidLifetime))
+				status.LeaseExpiresAt = &expiresAt
+			}
+		case previous != nil && previous.Prefix != "" && previous.LeaseExpiresAt != nil && previous.LeaseExpiresAt.After(now):
+			status.Prefix = previous.Prefix
+			status.PrefixSource = previous.PrefixSource
+			status.PrefixAcquiredAt = previous.PrefixAcquiredAt
+			status.LeaseExpiresAt = previous.LeaseExpiresAt
+		default:
+			if err == nil {
+				waiting = append(waiting, uplink.Name)
+			}
+		}
+
+		if status.Prefix != "" {
+			base := netip.MustParsePrefix(status.Prefix)
+			if subnets, err := r.calculateSubnets(base, dp.Spec.Subnets); err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %v", uplink.Name, err))
+			} else {
+				status.Subnets = subnets
+			}
+			if addressRanges, err := r.calculateAddressRanges(base, dp.Spec.AddressRanges); err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %v", uplink.Name, err))
+			} else {
+				status.AddressRanges = addressRanges
+			}
+			if status.LeaseExpiresAt != nil && (nextExpiry.IsZero() || status.LeaseExpiresAt.Time.Before(nextExpiry)) {
+				nextExpiry = status.LeaseExpiresAt.Time
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	r.receiversMu.Lock()
+	r.stopUplinkReceivers(dp.Name, keep)
+	r.receiversMu.Unlock()
+	dp.Status.Uplinks = statuses
+
+	switch {
+	case len(dp.Spec.Uplinks) == 0:
+		meta.RemoveStatusCondition(&dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeUplinksAcquired)
+	case len(failed) > 0:
+		r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypeUplinksAcquired, metav1.ConditionFalse,
+			"UplinkFailed", strings.Join(failed, "; "))
+	case len(waiting) > 0:
+		r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypeUplinksAcquired, metav1.ConditionFalse,
+			"WaitingForPrefix", fmt.Sprintf("No prefix on uplink %s", strings.Join(waiting, ", ")))
+	default:
+		r.setCondition(dp, dynamicprefixiov1alpha1.ConditionTypeUplinksAcquired, metav1.ConditionTrue,
+			"PrefixesAcquired", fmt.Sprintf("Prefixes acquired on %d uplink(s)", len(dp.Spec.Uplinks)))
+	}
+	return nextExpiry
+}
+
+// getOrCreateUplinkReceiver returns the receiver for an uplink, replacing it
+// when the uplink's acquisition spec changed. Receivers that support it are
+// asked to request the given hint, if valid.
+func (r *DynamicPrefixReconciler) getOrCreateUplinkReceiver(ctx context.Context, dp *dynamicprefixiov1alpha1.DynamicPrefix, uplink *dynamicprefixiov1alpha1.UplinkSpec, hint netip.Prefix) (prefix.Receiver, error) {
+	spec, err := r.resolveAcquisitionSecrets(ctx, uplink.Acquisition)
+	if err != nil {
+		return nil, err
+	}
+	specHash, err := acquisitionSpecHash(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	r.receiversMu.Lock()
+	defer r.receiversMu.Unlock()
+
+	if existing, ok := r.uplinkReceivers[dp.Name][uplink.Name]; ok {
+		if existing.specHash == specHash {
+			return existing.receiver, nil
+		}
+		logf.FromContext(ctx).Info("Uplink acquisition spec changed, recreating receiver", "uplink", uplink.Name)
+		r.stopUplinkReceiver(dp.Name, uplink.Name)
+	}
+
+	var receiver prefix.Receiver
+	if r.ReceiverFactory == nil {
+		// Use mock receiver for testing
+		receiver = prefix.NewMockReceiver(prefix.SourceDHCPv6PD)
+	} else {
+		receiver, err = r.ReceiverFactory.CreateReceiver(uplinkOwner(dp.Name, uplink.Name), spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create receiver: %w", err)
+		}
+	}
+	// Uplink status does not record which source delegated the prefix,
+	// so uplinks with several sources are not hinted
+	setPrefixHint(receiver, hint, "")
+	if err := receiver.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start receiver: %w", err)
+	}
+
+	entry := &uplinkReceiver{receiver: receiver, specHash: specHash}
+	if r.receiverEvents != nil {
+		var watchCtx context.Context
+		watchCtx, entry.cancelWatch = context.WithCancel(ctx)
+		go r.watchReceiverEvents(watchCtx, dp.Name, receiver.Events())
+	}
+	if r.uplinkReceivers == nil {
+		r.uplinkReceivers = make(map[string]map[string]*uplinkReceiver)
+	}
+	if r.uplinkReceivers[dp.Name] == nil {
+		r.uplinkReceivers[dp.Name] = make(map[string]*uplinkReceiver)
+	}
+	r.uplinkReceivers[dp.Name][uplink.Name] = entry
+	return receiver, nil
+}
+
+// stopUplinkReceivers stops the uplink receivers of a DynamicPrefix that are not in keep.
+// Caller must hold receiversMu.
+func (r *DynamicPrefixReconciler) stopUplinkReceivers(name string, keep map[string]bool) {
+	for uplink := range r.uplinkReceivers[name] {
+		if !keep[uplink] {
+			r.stopUplinkReceiver(name, uplink)
+		}
+	}
+	if len(r.uplinkReceivers[name]) == 0 {
+		delete(r.uplinkReceivers, name)
+	}
+}
+
+// stopUplinkReceiver stops and forgets the receiver of one uplink.
+// Caller must hold receiversMu.
+func (r *DynamicPrefixReconciler) stopUplinkReceiver(name, uplink string) {
+	entry, ok := r.uplinkReceivers[name][uplink]
+	if !ok {
+		return
+	}
+	if entry.cancelWatch != nil {
+		entry.cancelWatch()
+	}
+	if err := entry.receiver.Stop(); err != nil {
+		logf.Log.Error(err, "Failed to stop uplink receiver", "name", name, "uplink", uplink)
+	}
+	delete(r.uplinkReceivers[name], uplink)
+}
+
+// findUplinkStatus returns the status of the named uplink, or nil.
+func findUplinkStatus(statuses []dynamicprefixiov1alpha1.UplinkStatus, name string) *dynamicprefixiov1alpha1.UplinkStatus {
+	for i := range statuses {
+		if statuses[i].Name == name {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+// activePrefix is a prefix in use, with the address ranges and subnets calculated from it
+type activePrefix struct {
+	prefix        string
+	primary       bool
+	addressRanges []dynamicprefixiov1alpha1.AddressRangeStatus
+	subnets       []dynamicprefixiov1alpha1.SubnetStatus
+}
+
+// activePrefixes returns the prefixes pools and Services carry: the current
+// prefix, unless there is none or it was withdrawn, followed by the prefix of
+// each uplink that has one. Exactly one is primary, the DNS target of HA Services:
+// the prefix of spec.primaryUplink while it has one, otherwise the first.
+func activePrefixes(dp *dynamicprefixiov1alpha1.DynamicPrefix) []activePrefix {
+	var active []activePrefix
+	if dp.Status.CurrentPrefix != "" && !prefixWithdrawn(dp) {
+		active = append(active, activePrefix{
+			prefix:        dp.Status.CurrentPrefix,
+			addressRanges: dp.Status.AddressRanges,
+			subnets:       dp.Status.Subnets,
+		})
+	}
+	primary := -1
+	for _, uplink := range dp.Status.Uplinks {
+		if uplink.Prefix == "" {
+			continue
+		}
+		if uplink.Primary {
+			primary = len(active)
+		}
+		active = append(active, activePrefix{
+			prefix:        uplink.Prefix,
+			addressRanges: uplink.AddressRanges,
+			subnets:       uplink.Subnets,
+		})
+	}
+	if len(active) > 0 {
+		active[max(primary, 0)].primary = true
+	}
+	return active
+}
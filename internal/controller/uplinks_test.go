@@ -0,0 +1,204 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+	"github.com/jr42/dynamic-prefix-operator/internal/prefix"
+)
+
+func TestDynamicPrefixReconciler_updateUplinkStatus(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	r := &DynamicPrefixReconciler{}
+
+	dp := &dynamicprefixiov1alpha1.DynamicPrefix{
+		ObjectMeta: metav1.ObjectMeta{Name: "home"},
+		Spec: dynamicprefixiov1alpha1.DynamicPrefixSpec{
+			Uplinks: []dynamicprefixiov1alpha1.UplinkSpec{
+				{Name: "isp-a"},
+				{Name: "isp-b"},
+			},
+			PrimaryUplink: "isp-b",
+			Subnets: []dynamicprefixiov1alpha1.SubnetSpec{
+				{Name: "lb", Offset: 1, PrefixLength: 64},
+			},
+		},
+	}
+	mock := func(uplink string) *prefix.MockReceiver {
+		t.Helper()
+		entry, ok := r.uplinkReceivers["home"][uplink]
+		if !ok {
+			t.Fatalf("no receiver for uplink %s", uplink)
+		}
+		return entry.receiver.(*prefix.MockReceiver)
+	}
+
+	// No prefixes yet
+	if expiry := r.updateUplinkStatus(ctx, dp, now); !expiry.IsZero() {
+		t.Errorf("updateUplinkStatus() = %v, want no expiry without prefixes", expiry)
+	}
+	if len(dp.Status.Uplinks) != 2 || dp.Status.Uplinks[0].Prefix != "" || !dp.Status.Uplinks[1].Primary {
+		t.Errorf("Uplinks = %+v, want two without prefix, isp-b primary", dp.Status.Uplinks)
+	}
+	cond := meta.FindStatusCondition(dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeUplinksAcquired)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "WaitingForPrefix" {
+		t.Errorf("UplinksAcquired = %+v, want False/WaitingForPrefix", cond)
+	}
+
+	// Both uplinks report a prefix
+	mock("isp-a").SimulatePrefix(netip.MustParsePrefix("2001:db8:a::/48"), 0)
+	mock("isp-b").SimulatePrefix(netip.MustParsePrefix("2001:db8:b::/48"), time.Hour)
+	expiry := r.updateUplinkStatus(ctx, dp, now)
+	if expiry.Before(now.Add(59*time.Minute)) || expiry.After(time.Now().Add(time.Hour)) {
+		t.Errorf("updateUplinkStatus() = %v, want the isp-b lease expiry", expiry)
+	}
+	b := findUplinkStatus(dp.Status.Uplinks, "isp-b")
+	if b == nil || b.Prefix != "2001:db8:b::/48" || b.LeaseExpiresAt == nil {
+		t.Fatalf("isp-b = %+v, want 2001:db8:b::/48 with a lease", b)
+	}
+	if len(b.Subnets) != 1 || b.Subnets[0].CIDR != "2001:db8:b:1::/64" {
+		t.Errorf("isp-b subnets = %+v, want 2001:db8:b:1::/64", b.Subnets)
+	}
+	cond = meta.FindStatusCondition(dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeUplinksAcquired)
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Errorf("UplinksAcquired = %+v, want True", cond)
+	}
+
+	// A receiver losing its prefix keeps it until the lease expires
+	mock("isp-b").SimulatePrefixExpiry()
+	r.updateUplinkStatus(ctx, dp, now)
+	if b := findUplinkStatus(dp.Status.Uplinks, "isp-b"); b.Prefix != "2001:db8:b::/48" {
+		t.Errorf("isp-b prefix = %q, want it kept within the lease", b.Prefix)
+	}
+	r.updateUplinkStatus(ctx, dp, now.Add(2*time.Hour))
+	if b := findUplinkStatus(dp.Status.Uplinks, "isp-b"); b.Prefix != "" || b.Subnets != nil {
+		t.Errorf("isp-b = %+v, want no prefix after the lease expired", b)
+	}
+
+	// Removing an uplink stops its receiver
+	removed := mock("isp-a")
+	dp.Spec.Uplinks = dp.Spec.Uplinks[1:]
+	r.updateUplinkStatus(ctx, dp, now)
+	if removed.IsStarted() {
+		t.Error("receiver of removed uplink isp-a still running")
+	}
+	if len(dp.Status.Uplinks) != 1 || dp.Status.Uplinks[0].Name != "isp-b" {
+		t.Errorf("Uplinks = %+v, want only isp-b", dp.Status.Uplinks)
+	}
+
+	// Without uplinks the condition goes away
+	dp.Spec.Uplinks = nil
+	dp.Spec.PrimaryUplink = ""
+	r.updateUplinkStatus(ctx, dp, now)
+	if dp.Status.Uplinks != nil || len(r.uplinkReceivers) != 0 {
+		t.Errorf("Uplinks = %+v, receivers = %v, want none", dp.Status.Uplinks, r.uplinkReceivers)
+	}
+	if meta.FindStatusCondition(dp.Status.Conditions, dynamicprefixiov1alpha1.ConditionTypeUplinksAcquired) != nil {
+		t.Error("UplinksAcquired condition kept without uplinks")
+	}
+}
+
+func TestActivePrefixes(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      dynamicprefixiov1alpha1.DynamicPrefixStatus
+		wantPrefix  []string
+		wantPrimary string
+	}{
+		{
+			name:        "current prefix only",
+			status:      dynamicprefixiov1alpha1.DynamicPrefixStatus{CurrentPrefix: "2001:db8:1::/48"},
+			wantPrefix:  []string{"2001:db8:1::/48"},
+			wantPrimary: "2001:db8:1::/48",
+		},
+		{
+			name: "primary uplink",
+			status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+				CurrentPrefix: "2001:db8:1::/48",
+				Uplinks: []dynamicprefixiov1alpha1.UplinkStatus{
+					{Name: "isp-a", Prefix: "2001:db8:a::/48"},
+					{Name: "isp-b", Prefix: "2001:db8:b::/48", Primary: true},
+				},
+			},
+			wantPrefix:  []string{"2001:db8:1::/48", "2001:db8:a::/48", "2001:db8:b::/48"},
+			wantPrimary: "2001:db8:b::/48",
+		},
+		{
+			name: "primary uplink without prefix",
+			status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+				CurrentPrefix: "2001:db8:1::/48",
+				Uplinks: []dynamicprefixiov1alpha1.UplinkStatus{
+					{Name: "isp-a", Prefix: "2001:db8:a::/48"},
+					{Name: "isp-b", Primary: true},
+				},
+			},
+			wantPrefix:  []string{"2001:db8:1::/48", "2001:db8:a::/48"},
+			wantPrimary: "2001:db8:1::/48",
+		},
+		{
+			name: "withdrawn current prefix",
+			status: dynamicprefixiov1alpha1.DynamicPrefixStatus{
+				LostPrefix: &dynamicprefixiov1alpha1.LostPrefixStatus{Prefix: "2001:db8:1::/48", Withdrawn: true},
+				Uplinks: []dynamicprefixiov1alpha1.UplinkStatus{
+					{Name: "isp-a", Prefix: "2001:db8:a::/48"},
+				},
+			},
+			wantPrefix:  []string{"2001:db8:a::/48"},
+			wantPrimary: "2001:db8:a::/48",
+		},
+		{
+			name: "nothing active",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active := activePrefixes(&dynamicprefixiov1alpha1.DynamicPrefix{Status: tt.status})
+			var prefixes []string
+			var primary string
+			for _, a := range active {
+				prefixes = append(prefixes, a.prefix)
+				if a.primary {
+					if primary != "" {
+						t.Errorf("both %s and %s primary", primary, a.prefix)
+					}
+					primary = a.prefix
+				}
+			}
+			if len(prefixes) != len(tt.wantPrefix) {
+				t.Fatalf("activePrefixes() = %v, want %v", prefixes, tt.wantPrefix)
+			}
+			for i := range prefixes {
+				if prefixes[i] != tt.wantPrefix[i] {
+					t.Errorf("activePrefixes() = %v, want %v", prefixes, tt.wantPrefix)
+				}
+			}
+			if primary != tt.wantPrimary {
+				t.Errorf("primary = %q, want %q", primary, tt.wantPrimary)
+			}
+		})
+	}
+}
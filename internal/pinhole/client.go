@@ -0,0 +1,143 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pinhole opens inbound firewall pinholes on a home router, using the
+// Port Control Protocol (RFC 6887) or UPnP IGDv2 WANIPv6FirewallControl.
+package pinhole
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"time"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+)
+
+// DefaultLifetime is how long pinholes are requested for if not configured.
+const DefaultLifetime = time.Hour
+
+// Protocol is the transport protocol of a pinhole.
+type Protocol string
+
+const (
+	ProtocolTCP Protocol = "TCP"
+	ProtocolUDP Protocol = "UDP"
+)
+
+// number returns the IANA protocol number.
+func (p Protocol) number() uint8 {
+	if p == ProtocolUDP {
+		return 17
+	}
+	return 6
+}
+
+// Pinhole allows inbound traffic from anywhere to a port of an address.
+type Pinhole struct {
+	// Address is the internal address traffic is allowed to
+	Address netip.Addr `json:"address"`
+
+	// Port is the internal port traffic is allowed to
+	Port uint16 `json:"port"`
+
+	// Protocol is the transport protocol
+	Protocol Protocol `json:"protocol"`
+}
+
+// String returns the pinhole as [address]:port/protocol.
+func (p Pinhole) String() string {
+	return netip.AddrPortFrom(p.Address, p.Port).String() + "/" + string(p.Protocol)
+}
+
+// Lease is a pinhole opened on the router.
+type Lease struct {
+	Pinhole
+
+	// ID identifies the pinhole on the router: the PCP mapping nonce or the
+	// UPnP UniqueID. It is needed to refresh or close the pinhole.
+	ID string `json:"id"`
+
+	// Granted is when the router last opened or refreshed the pinhole
+	Granted time.Time `json:"granted"`
+
+	// Expires is when the router closes the pinhole unless it is refreshed
+	Expires time.Time `json:"expires"`
+}
+
+// RefreshAt returns when the lease should be refreshed: halfway through its lifetime.
+func (l Lease) RefreshAt() time.Time {
+	return l.Granted.Add(l.Expires.Sub(l.Granted) / 2)
+}
+
+// Client opens and closes pinholes on a router.
+type Client interface {
+	// Open opens the pinhole for the lifetime, or refreshes it if prev is the
+	// lease it was opened with before.
+	Open(ctx context.Context, p Pinhole, lifetime time.Duration, prev *Lease) (Lease, error)
+
+	// Close closes the pinhole of a lease. Closing a pinhole the router no
+	// longer knows is not an error.
+	Close(ctx context.Context, lease Lease) error
+}
+
+// NewClient creates the client for the pinhole spec of a DynamicPrefix.
+func NewClient(spec *dynamicprefixiov1alpha1.PinholeSpec) (Client, error) {
+	switch spec.Protocol {
+	case dynamicprefixiov1alpha1.PinholeProtocolPCP:
+		if spec.PCPServer == "" {
+			return nil, fmt.Errorf("pcpServer is required for PCP")
+		}
+		server, err := parseServer(spec.PCPServer, PCPPort)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pcpServer: %w", err)
+		}
+		return NewPCPClient(server), nil
+	case dynamicprefixiov1alpha1.PinholeProtocolUPnP:
+		if spec.UPnPDescriptionURL == "" {
+			return nil, fmt.Errorf("upnpDescriptionURL is required for UPnP")
+		}
+		return NewUPnPClient(spec.UPnPDescriptionURL), nil
+	default:
+		return nil, fmt.Errorf("unsupported pinhole protocol %q", spec.Protocol)
+	}
+}
+
+// Lifetime returns the configured pinhole lifetime or the default.
+func Lifetime(spec *dynamicprefixiov1alpha1.PinholeSpec) time.Duration {
+	if spec.Lifetime != nil && spec.Lifetime.Duration > 0 {
+		return spec.Lifetime.Duration
+	}
+	return DefaultLifetime
+}
+
+// parseServer parses an address with an optional port.
+func parseServer(s string, defaultPort uint16) (netip.AddrPort, error) {
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return netip.AddrPortFrom(addr, defaultPort), nil
+	}
+	addrPort, err := netip.ParseAddrPort(s)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("%q is neither an address nor an address and port", s)
+	}
+	return addrPort, nil
+}
+
+// seconds returns the duration in whole seconds, at least one.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(max(int64(d/time.Second), 1), 10)
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pinhole
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"time"
+)
+
+// PCPPort is the UDP port PCP servers listen on.
+const PCPPort = 5351
+
+const (
+	pcpVersion        = 2
+	pcpOpcodeMap      = 1
+	pcpResponseBit    = 0x80
+	pcpOptionThirdPty = 1
+	pcpHeaderLen      = 24
+	pcpMapLen         = 36
+	pcpNonceLen       = 12
+
+	// pcpInitialTimeout and pcpAttempts bound a request to about 15 seconds,
+	// shorter than RFC 6887's retransmission schedule, to keep reconciles short
+	pcpInitialTimeout = time.Second
+	pcpAttempts       = 4
+)
+
+// pcpResultCodes names the PCP result codes, from RFC 6887 section 7.4.
+var pcpResultCodes = map[uint8]string{
+	1:  "UNSUPP_VERSION",
+	2:  "NOT_AUTHORIZED",
+	3:  "MALFORMED_REQUEST",
+	4:  "UNSUPP_OPCODE",
+	5:  "UNSUPP_OPTION",
+	6:  "MALFORMED_OPTION",
+	7:  "NETWORK_FAILURE",
+	8:  "NO_RESOURCES",
+	9:  "UNSUPP_PROTOCOL",
+	10: "USER_EX_QUOTA",
+	11: "CANNOT_PROVIDE_EXTERNAL",
+	12: "ADDRESS_MISMATCH",
+	13: "EXCESSIVE_REMOTE_PEERS",
+}
+
+// PCPClient opens pinholes with PCP MAP requests. Since the pinholes are for
+// other hosts' addresses, every request carries a THIRD_PARTY option, which
+// the PCP server must be configured to accept.
+type PCPClient struct {
+	server netip.AddrPort
+}
+
+// NewPCPClient creates a PCP client for the server.
+func NewPCPClient(server netip.AddrPort) *PCPClient {
+	return &PCPClient{server: server}
+}
+
+// Open sends a MAP request for the pinhole, reusing the nonce of prev to refresh it.
+func (c *PCPClient) Open(ctx context.Context, p Pinhole, lifetime time.Duration, prev *Lease) (Lease, error) {
+	var nonce [pcpNonceLen]byte
+	if prev != nil {
+		var err error
+		if nonce, err = parseNonce(prev.ID); err != nil {
+			return Lease{}, err
+		}
+	} else if _, err := rand.Read(nonce[:]); err != nil {
+		return Lease{}, fmt.Errorf("failed to generate PCP nonce: %w", err)
+	}
+
+	granted, err := c.mapRequest(ctx, p, nonce, uint32(lifetime/time.Second))
+	if err != nil {
+		return Lease{}, err
+	}
+	now := time.Now()
+	return Lease{
+		Pinhole: p,
+		ID:      hex.EncodeToString(nonce[:]),
+		Granted: now,
+		Expires: now.Add(granted),
+	}, nil
+}
+
+// Close sends a MAP request with a lifetime of zero, which deletes the mapping.
+func (c *PCPClient) Close(ctx context.Context, lease Lease) error {
+	nonce, err := parseNonce(lease.ID)
+	if err != nil {
+		return err
+	}
+	_, err = c.mapRequest(ctx, lease.Pinhole, nonce, 0)
+	return err
+}
+
+// mapRequest sends a MAP request and returns the lifetime granted by the server.
+func (c *PCPClient) mapRequest(ctx context.Context, p Pinhole, nonce [pcpNonceLen]byte, lifetime uint32) (time.Duration, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", c.server.String())
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to PCP server %s: %w", c.server, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	local := conn.LocalAddr().(*net.UDPAddr).AddrPort().Addr()
+	req := encodePCPMapRequest(local, p, nonce, lifetime)
+
+	buf := make([]byte, 1100) // maximum PCP message size
+	timeout := pcpInitialTimeout
+	for attempt := 1; ; attempt++ {
+		if _, err := conn.Write(req); err != nil {
+			return 0, fmt.Errorf("failed to send PCP request: %w", err)
+		}
+
+		deadline := time.Now().Add(timeout)
+		if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+			deadline = d
+		}
+		_ = conn.SetReadDeadline(deadline)
+
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					break
+				}
+				return 0, fmt.Errorf("failed to receive PCP response: %w", err)
+			}
+			granted, err := decodePCPMapResponse(buf[:n], p, nonce)
+			if errors.Is(err, errPCPUnrelated) {
+				continue
+			}
+			return granted, err
+		}
+
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		if attempt == pcpAttempts {
+			return 0, fmt.Errorf("no response from PCP server %s", c.server)
+		}
+		timeout *= 2
+	}
+}
+
+// errPCPUnrelated is returned for responses to other requests.
+var errPCPUnrelated = errors.New("unrelated PCP response")
+
+// encodePCPMapRequest encodes a MAP request with a THIRD_PARTY option for the
+// pinhole's address. The suggested external address and port are the internal
+// ones, as there is no address translation for IPv6.
+func encodePCPMapRequest(client netip.Addr, p Pinhole, nonce [pcpNonceLen]byte, lifetime uint32) []byte {
+	msg := make([]byte, 0, pcpHeaderLen+pcpMapLen+20)
+
+	clientAddr := client.As16()
+	msg = append(msg, pcpVersion, pcpOpcodeMap, 0, 0)
+	msg = binary.BigEndian.AppendUint32(msg, lifetime)
+	msg = append(msg, clientAddr[:]...)
+
+	internal := p.Address.As16()
+	msg = append(msg, nonce[:]...)
+	msg = append(msg, p.Protocol.number(), 0, 0, 0)
+	msg = binary.BigEndian.AppendUint16(msg, p.Port)
+	msg = binary.BigEndian.AppendUint16(msg, p.Port)
+	msg = append(msg, internal[:]...)
+
+	msg = append(msg, pcpOptionThirdPty, 0)
+	msg = binary.BigEndian.AppendUint16(msg, 16)
+	msg = append(msg, internal[:]...)
+	return msg
+}
+
+// decodePCPMapResponse checks a MAP response to the request for the pinhole
+// and returns the granted lifetime.
+func decodePCPMapResponse(msg []byte, p Pinhole, nonce [pcpNonceLen]byte) (time.Duration, error) {
+	if len(msg) < pcpHeaderLen {
+		return 0, errPCPUnrelated
+	}
+	if msg[1] != pcpResponseBit|pcpOpcodeMap {
+		return 0, errPCPUnrelated
+	}
+	result := msg[3]
+	lifetime := binary.BigEndian.Uint32(msg[4:8])
+
+	// Errors before the opcode was understood may come without MAP data
+	if len(msg) >= pcpHeaderLen+pcpMapLen {
+		data := msg[pcpHeaderLen:]
+		if !bytes.Equal(data[:pcpNonceLen], nonce[:]) ||
+			data[12] != p.Protocol.number() ||
+			binary.BigEndian.Uint16(data[16:18]) != p.Port {
+			return 0, errPCPUnrelated
+		}
+	} else if result == 0 {
+		return 0, fmt.Errorf("truncated PCP MAP response")
+	}
+
+	if msg[0] != pcpVersion {
+		return 0, fmt.Errorf("PCP server does not support version %d", pcpVersion)
+	}
+	if result != 0 {
+		name, ok := pcpResultCodes[result]
+		if !ok {
+			name = fmt.Sprintf("result code %d", result)
+		}
+		return 0, fmt.Errorf("PCP server refused %s: %s", p, name)
+	}
+	return time.Duration(lifetime) * time.Second, nil
+}
+
+// parseNonce decodes the nonce stored as the ID of a PCP lease.
+func parseNonce(id string) ([pcpNonceLen]byte, error) {
+	var nonce [pcpNonceLen]byte
+	b, err := hex.DecodeString(id)
+	if err != nil || len(b) != pcpNonceLen {
+		return nonce, fmt.Errorf("invalid PCP lease ID %q", id)
+	}
+	copy(nonce[:], b)
+	return nonce, nil
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pinhole
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pcpStandIn is a local PCP server that answers MAP requests with a fixed result.
+type pcpStandIn struct {
+	conn     *net.UDPConn
+	result   uint8
+	requests chan []byte
+}
+
+func newPCPStandIn(t *testing.T, result uint8) *pcpStandIn {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv6loopback})
+	if err != nil {
+		t.Skipf("IPv6 loopback not available: %v", err)
+	}
+	s := &pcpStandIn{conn: conn, result: result, requests: make(chan []byte, 10)}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1100)
+		for {
+			n, from, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			req := append([]byte(nil), buf[:n]...)
+			s.requests <- req
+
+			resp := make([]byte, pcpHeaderLen+pcpMapLen)
+			resp[0] = pcpVersion
+			resp[1] = pcpResponseBit | req[1]
+			resp[3] = s.result
+			lifetime := binary.BigEndian.Uint32(req[4:8])
+			binary.BigEndian.PutUint32(resp[4:8], min(lifetime, 1800))
+			copy(resp[pcpHeaderLen:], req[pcpHeaderLen:pcpHeaderLen+pcpMapLen])
+			_, _ = conn.WriteToUDP(resp, from)
+		}
+	}()
+	return s
+}
+
+func (s *pcpStandIn) server() netip.AddrPort {
+	return s.conn.LocalAddr().(*net.UDPAddr).AddrPort()
+}
+
+func TestPCPClient(t *testing.T) {
+	standIn := newPCPStandIn(t, 0)
+	c := NewPCPClient(standIn.server())
+	p := Pinhole{Address: netip.MustParseAddr("2001:db8:1::10"), Port: 443, Protocol: ProtocolTCP}
+	ctx := context.Background()
+
+	lease, err := c.Open(ctx, p, time.Hour, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got := lease.Expires.Sub(lease.Granted); got != 30*time.Minute {
+		t.Errorf("lease lifetime = %v, want the granted 30m", got)
+	}
+
+	req := <-standIn.requests
+	if req[0] != pcpVersion || req[1] != pcpOpcodeMap {
+		t.Fatalf("request version/opcode = %d/%d, want MAP", req[0], req[1])
+	}
+	if got := binary.BigEndian.Uint32(req[4:8]); got != 3600 {
+		t.Errorf("requested lifetime = %d, want 3600", got)
+	}
+	data := req[pcpHeaderLen:]
+	if data[12] != 6 || binary.BigEndian.Uint16(data[16:18]) != 443 || binary.BigEndian.Uint16(data[18:20]) != 443 {
+		t.Errorf("MAP protocol/ports = %d/%d/%d, want 6/443/443", data[12], binary.BigEndian.Uint16(data[16:18]), binary.BigEndian.Uint16(data[18:20]))
+	}
+	internal := p.Address.As16()
+	if !bytes.Equal(data[20:36], internal[:]) {
+		t.Error("suggested external address is not the pinhole address")
+	}
+	option := req[pcpHeaderLen+pcpMapLen:]
+	if len(option) != 20 || option[0] != pcpOptionThirdPty || !bytes.Equal(option[4:], internal[:]) {
+		t.Errorf("THIRD_PARTY option = %x, want the pinhole address", option)
+	}
+
+	// A refresh reuses the nonce
+	refreshed, err := c.Open(ctx, p, time.Hour, &lease)
+	if err != nil {
+		t.Fatalf("Open() refresh error = %v", err)
+	}
+	if refreshed.ID != lease.ID {
+		t.Errorf("refresh ID = %s, want %s", refreshed.ID, lease.ID)
+	}
+	if req := <-standIn.requests; !bytes.Equal(req[pcpHeaderLen:pcpHeaderLen+pcpNonceLen], data[:pcpNonceLen]) {
+		t.Error("refresh uses another nonce")
+	}
+
+	// Closing sends a lifetime of zero with the same nonce
+	if err := c.Close(ctx, lease); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	req = <-standIn.requests
+	if got := binary.BigEndian.Uint32(req[4:8]); got != 0 {
+		t.Errorf("close lifetime = %d, want 0", got)
+	}
+	if !bytes.Equal(req[pcpHeaderLen:pcpHeaderLen+pcpNonceLen], data[:pcpNonceLen]) {
+		t.Error("close uses another nonce")
+	}
+}
+
+func TestPCPClientRefused(t *testing.T) {
+	standIn := newPCPStandIn(t, 2) // NOT_AUTHORIZED
+	c := NewPCPClient(standIn.server())
+	p := Pinhole{Address: netip.MustParseAddr("2001:db8:1::10"), Port: 53, Protocol: ProtocolUDP}
+
+	_, err := c.Open(context.Background(), p, time.Hour, nil)
+	if err == nil {
+		t.Fatal("Open() succeeded, want NOT_AUTHORIZED")
+	}
+	if want := "NOT_AUTHORIZED"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Open() error = %v, want %s", err, want)
+	}
+}
+
+func TestPCPClientNoResponse(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv6loopback})
+	if err != nil {
+		t.Skipf("IPv6 loopback not available: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	c := NewPCPClient(conn.LocalAddr().(*net.UDPAddr).AddrPort())
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	p := Pinhole{Address: netip.MustParseAddr("2001:db8:1::10"), Port: 443, Protocol: ProtocolTCP}
+	if _, err := c.Open(ctx, p, time.Hour, nil); err == nil {
+		t.Fatal("Open() succeeded without a server response")
+	}
+}
+
+func TestParseServer(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "fd00::1", want: "[fd00::1]:5351"},
+		{in: "[fd00::1]:15351", want: "[fd00::1]:15351"},
+		{in: "192.168.1.1", want: "192.168.1.1:5351"},
+		{in: "router.lan", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseServer(tt.in, PCPPort)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseServer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("parseServer() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
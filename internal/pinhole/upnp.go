@@ -0,0 +1,276 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pinhole
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// firewallControlService is the UPnP service type, without its version
+	firewallControlService = "urn:schemas-upnp-org:service:WANIPv6FirewallControl:"
+
+	// upnpMaxLeaseTime is the longest lease WANIPv6FirewallControl accepts
+	upnpMaxLeaseTime = 24 * time.Hour
+
+	// upnpNoSuchEntry is the error code for an unknown pinhole UniqueID
+	upnpNoSuchEntry = "704"
+
+	upnpTimeout = 10 * time.Second
+)
+
+// UPnPClient opens pinholes with the WANIPv6FirewallControl service of a UPnP
+// Internet Gateway Device.
+type UPnPClient struct {
+	descriptionURL string
+	httpClient     *http.Client
+
+	mu          sync.Mutex
+	controlURL  string
+	serviceType string
+}
+
+// NewUPnPClient creates a UPnP client for the device whose description is at descriptionURL.
+func NewUPnPClient(descriptionURL string) *UPnPClient {
+	return &UPnPClient{
+		descriptionURL: descriptionURL,
+		httpClient:     &http.Client{Timeout: upnpTimeout},
+	}
+}
+
+// Open refreshes the pinhole of prev with UpdatePinhole, or adds a new one with
+// AddPinhole if there is none or the router no longer knows it.
+func (c *UPnPClient) Open(ctx context.Context, p Pinhole, lifetime time.Duration, prev *Lease) (Lease, error) {
+	lifetime = min(lifetime, upnpMaxLeaseTime)
+
+	id := ""
+	if prev != nil && prev.ID != "" {
+		_, err := c.call(ctx, "UpdatePinhole", [][2]string{
+			{"UniqueID", prev.ID},
+			{"NewLeaseTime", seconds(lifetime)},
+		})
+		var upnpErr *UPnPError
+		switch {
+		case err == nil:
+			id = prev.ID
+		case errors.As(err, &upnpErr) && upnpErr.Code == upnpNoSuchEntry:
+			// Removed by the router, e.g. after a reboot
+		default:
+			return Lease{}, err
+		}
+	}
+
+	if id == "" {
+		out, err := c.call(ctx, "AddPinhole", [][2]string{
+			{"RemoteHost", ""},
+			{"RemotePort", "0"},
+			{"InternalClient", p.Address.String()},
+			{"InternalPort", fmt.Sprint(p.Port)},
+			{"Protocol", fmt.Sprint(p.Protocol.number())},
+			{"LeaseTime", seconds(lifetime)},
+		})
+		if err != nil {
+			return Lease{}, err
+		}
+		if id = out["UniqueID"]; id == "" {
+			return Lease{}, fmt.Errorf("AddPinhole response has no UniqueID")
+		}
+	}
+
+	now := time.Now()
+	return Lease{Pinhole: p, ID: id, Granted: now, Expires: now.Add(lifetime)}, nil
+}
+
+// Close deletes the pinhole with DeletePinhole.
+func (c *UPnPClient) Close(ctx context.Context, lease Lease) error {
+	_, err := c.call(ctx, "DeletePinhole", [][2]string{{"UniqueID", lease.ID}})
+	var upnpErr *UPnPError
+	if errors.As(err, &upnpErr) && upnpErr.Code == upnpNoSuchEntry {
+		return nil
+	}
+	return err
+}
+
+// UPnPError is an error returned by a UPnP action.
+type UPnPError struct {
+	Action      string
+	Code        string
+	Description string
+}
+
+func (e *UPnPError) Error() string {
+	return fmt.Sprintf("UPnP %s failed with error %s: %s", e.Action, e.Code, e.Description)
+}
+
+// call invokes an action of the firewall control service and returns the output arguments.
+func (c *UPnPClient) call(ctx context.Context, action string, args [][2]string) (map[string]string, error) {
+	controlURL, serviceType, err := c.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0"?>` +
+		`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/">` +
+		`<s:Body>`)
+	fmt.Fprintf(&body, `<u:%s xmlns:u="%s">`, action, serviceType)
+	for _, arg := range args {
+		fmt.Fprintf(&body, "<%s>%s</%s>", arg[0], html.EscapeString(arg[1]), arg[0])
+	}
+	fmt.Fprintf(&body, `</u:%s></s:Body></s:Envelope>`, action)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, controlURL, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+	req.Header.Set("SOAPAction", fmt.Sprintf(`"%s#%s"`, serviceType, action))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("UPnP %s failed: %w", action, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	values, err := soapValues(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, fmt.Errorf("invalid UPnP %s response: %w", action, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if code := values["errorCode"]; code != "" {
+			return nil, &UPnPError{Action: action, Code: code, Description: values["errorDescription"]}
+		}
+		return nil, fmt.Errorf("UPnP %s failed: %s", action, resp.Status)
+	}
+	return values, nil
+}
+
+// soapValues returns the text of the leaf elements of a SOAP response by local name.
+func soapValues(r io.Reader) (map[string]string, error) {
+	values := make(map[string]string)
+	decoder := xml.NewDecoder(r)
+	var name string
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			name = t.Name.Local
+			values[name] = ""
+		case xml.CharData:
+			if name != "" {
+				values[name] += strings.TrimSpace(string(t))
+			}
+		case xml.EndElement:
+			name = ""
+		}
+	}
+}
+
+// upnpDevice is a device in a UPnP device description.
+type upnpDevice struct {
+	Services []struct {
+		ServiceType string `xml:"serviceType"`
+		ControlURL  string `xml:"controlURL"`
+	} `xml:"serviceList>service"`
+	Devices []upnpDevice `xml:"deviceList>device"`
+}
+
+// service returns the control URL and type of the firewall control service,
+// fetching the device description on first use.
+func (c *UPnPClient) service(ctx context.Context) (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.controlURL != "" {
+		return c.controlURL, c.serviceType, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.descriptionURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch UPnP device description: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("failed to fetch UPnP device description: %s", resp.Status)
+	}
+
+	var root struct {
+		URLBase string     `xml:"URLBase"`
+		Device  upnpDevice `xml:"device"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 1024*1024)).Decode(&root); err != nil {
+		return "", "", fmt.Errorf("invalid UPnP device description: %w", err)
+	}
+
+	controlPath, serviceType := findFirewallControl(root.Device)
+	if controlPath == "" {
+		return "", "", fmt.Errorf("UPnP device does not offer WANIPv6FirewallControl")
+	}
+
+	base, err := url.Parse(c.descriptionURL)
+	if err != nil {
+		return "", "", err
+	}
+	if root.URLBase != "" {
+		if base, err = base.Parse(root.URLBase); err != nil {
+			return "", "", fmt.Errorf("invalid UPnP URLBase: %w", err)
+		}
+	}
+	control, err := base.Parse(controlPath)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid UPnP controlURL: %w", err)
+	}
+
+	c.controlURL, c.serviceType = control.String(), serviceType
+	return c.controlURL, c.serviceType, nil
+}
+
+// findFirewallControl searches the device tree for the firewall control service.
+func findFirewallControl(device upnpDevice) (string, string) {
+	for _, service := range device.Services {
+		if strings.HasPrefix(service.ServiceType, firewallControlService) {
+			return service.ControlURL, service.ServiceType
+		}
+	}
+	for _, child := range device.Devices {
+		if controlURL, serviceType := findFirewallControl(child); controlURL != "" {
+			return controlURL, serviceType
+		}
+	}
+	return "", ""
+}
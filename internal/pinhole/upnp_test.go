@@ -0,0 +1,194 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pinhole
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const upnpDescription = `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+  <device>
+    <deviceType>urn:schemas-upnp-org:device:InternetGatewayDevice:2</deviceType>
+    <serviceList>
+      <service>
+        <serviceType>urn:schemas-upnp-org:service:Layer3Forwarding:1</serviceType>
+        <controlURL>/ctl/L3F</controlURL>
+      </service>
+    </serviceList>
+    <deviceList>
+      <device>
+        <deviceType>urn:schemas-upnp-org:device:WANDevice:2</deviceType>
+        <deviceList>
+          <device>
+            <deviceType>urn:schemas-upnp-org:device:WANConnectionDevice:2</deviceType>
+            <serviceList>
+              <service>
+                <serviceType>urn:schemas-upnp-org:service:WANIPv6FirewallControl:1</serviceType>
+                <controlURL>/ctl/IP6FCtl</controlURL>
+              </service>
+            </serviceList>
+          </device>
+        </deviceList>
+      </device>
+    </deviceList>
+  </device>
+</root>`
+
+// upnpStandIn is a local Internet Gateway Device with a WANIPv6FirewallControl service.
+type upnpStandIn struct {
+	mu       sync.Mutex
+	pinholes map[string]string // UniqueID -> InternalClient
+	nextID   int
+	actions  []string
+}
+
+func newUPnPStandIn(t *testing.T) (*upnpStandIn, *httptest.Server) {
+	s := &upnpStandIn{pinholes: make(map[string]string)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rootDesc.xml", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, upnpDescription)
+	})
+	mux.HandleFunc("/ctl/IP6FCtl", s.control)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return s, server
+}
+
+func (s *upnpStandIn) control(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	action := r.Header.Get("SOAPAction")
+	action = strings.Trim(action[strings.Index(action, "#")+1:], `"`)
+	s.actions = append(s.actions, action)
+	args, _ := soapValues(r.Body)
+
+	respond := func(body string) {
+		fmt.Fprintf(w, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>`+
+			`<u:%sResponse xmlns:u="urn:schemas-upnp-org:service:WANIPv6FirewallControl:1">%s</u:%sResponse></s:Body></s:Envelope>`,
+			action, body, action)
+	}
+	fault := func(code, description string) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><s:Fault>`+
+			`<faultcode>s:Client</faultcode><faultstring>UPnPError</faultstring><detail>`+
+			`<UPnPError xmlns="urn:schemas-upnp-org:control-1-0"><errorCode>%s</errorCode><errorDescription>%s</errorDescription></UPnPError>`+
+			`</detail></s:Fault></s:Body></s:Envelope>`, code, description)
+	}
+
+	switch action {
+	case "AddPinhole":
+		if args["Protocol"] != "6" || args["InternalPort"] != "443" || args["LeaseTime"] != "3600" {
+			fault("402", "Invalid Args")
+			return
+		}
+		s.nextID++
+		id := fmt.Sprint(s.nextID)
+		s.pinholes[id] = args["InternalClient"]
+		respond("<UniqueID>" + id + "</UniqueID>")
+	case "UpdatePinhole", "DeletePinhole":
+		if _, ok := s.pinholes[args["UniqueID"]]; !ok {
+			fault("704", "NoSuchEntry")
+			return
+		}
+		if action == "DeletePinhole" {
+			delete(s.pinholes, args["UniqueID"])
+		}
+		respond("")
+	default:
+		fault("401", "Invalid Action")
+	}
+}
+
+func TestUPnPClient(t *testing.T) {
+	standIn, server := newUPnPStandIn(t)
+	c := NewUPnPClient(server.URL + "/rootDesc.xml")
+	p := Pinhole{Address: netip.MustParseAddr("2001:db8:1::10"), Port: 443, Protocol: ProtocolTCP}
+	ctx := context.Background()
+
+	lease, err := c.Open(ctx, p, time.Hour, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if lease.ID != "1" || standIn.pinholes["1"] != "2001:db8:1::10" {
+		t.Fatalf("Open() lease = %+v, pinholes = %v", lease, standIn.pinholes)
+	}
+
+	// A refresh updates the existing pinhole
+	if lease, err = c.Open(ctx, p, time.Hour, &lease); err != nil || lease.ID != "1" {
+		t.Fatalf("Open() refresh = %+v, %v; want ID 1", lease, err)
+	}
+
+	// A pinhole the router lost is added again
+	delete(standIn.pinholes, "1")
+	if lease, err = c.Open(ctx, p, time.Hour, &lease); err != nil || lease.ID != "2" {
+		t.Fatalf("Open() after router reboot = %+v, %v; want ID 2", lease, err)
+	}
+
+	if err := c.Close(ctx, lease); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if len(standIn.pinholes) != 0 {
+		t.Errorf("pinholes after Close() = %v, want none", standIn.pinholes)
+	}
+	// Closing an unknown pinhole is not an error
+	if err := c.Close(ctx, lease); err != nil {
+		t.Errorf("Close() of closed pinhole error = %v", err)
+	}
+
+	want := []string{"AddPinhole", "UpdatePinhole", "UpdatePinhole", "AddPinhole", "DeletePinhole", "DeletePinhole"}
+	if strings.Join(standIn.actions, ",") != strings.Join(want, ",") {
+		t.Errorf("actions = %v, want %v", standIn.actions, want)
+	}
+}
+
+func TestUPnPClientError(t *testing.T) {
+	_, server := newUPnPStandIn(t)
+	c := NewUPnPClient(server.URL + "/rootDesc.xml")
+	p := Pinhole{Address: netip.MustParseAddr("2001:db8:1::10"), Port: 80, Protocol: ProtocolTCP}
+
+	_, err := c.Open(context.Background(), p, time.Hour, nil)
+	upnpErr, ok := err.(*UPnPError)
+	if !ok || upnpErr.Code != "402" {
+		t.Fatalf("Open() error = %v, want UPnP error 402", err)
+	}
+}
+
+func TestUPnPClientWithoutFirewallControl(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `<root><device><serviceList><service>`+
+			`<serviceType>urn:schemas-upnp-org:service:WANIPConnection:1</serviceType><controlURL>/ctl</controlURL>`+
+			`</service></serviceList></device></root>`)
+	}))
+	defer server.Close()
+
+	c := NewUPnPClient(server.URL)
+	p := Pinhole{Address: netip.MustParseAddr("2001:db8:1::10"), Port: 443, Protocol: ProtocolTCP}
+	if _, err := c.Open(context.Background(), p, time.Hour, nil); err == nil || !strings.Contains(err.Error(), "WANIPv6FirewallControl") {
+		t.Errorf("Open() error = %v, want missing WANIPv6FirewallControl", err)
+	}
+}
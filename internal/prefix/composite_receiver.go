@@ -18,39 +18,151 @@ package prefix
 
 import (
 	"context"
+	"fmt"
+	"net/netip"
+	"sort"
 	"sync"
+	"time"
 )
 
-// CompositeReceiver runs DHCPv6-PD as primary with RA as fallback.
-// It prefers the DHCPv6-PD prefix when available, switching to RA
-// after consecutive DHCPv6-PD failures.
+// DefaultFailureThreshold is the number of consecutive failures after which
+// a source is skipped when no explicit threshold is configured.
+const DefaultFailureThreshold = 3
+
+// compositeReselectInterval is how often a running composite re-evaluates its selection without member events.
+const compositeReselectInterval = time.Second
+
+// SourcePolicy controls how a source takes part in failover.
+type SourcePolicy struct {
+	// Name identifies the source in status and cross-checks
+	Name string
+
+	// Priority orders the sources; lower values are preferred
+	Priority int
+
+	// FailureThreshold is the number of consecutive failures before the source is skipped
+	FailureThreshold int
+
+	// FailbackDelay is how long a recovered source must stay healthy before it takes over again
+	FailbackDelay time.Duration
+
+	// Within names another source whose prefix must contain this source's prefix
+	Within string
+}
+
+// CompositeSource is a receiver together with its failover policy.
+type CompositeSource struct {
+	Receiver Receiver
+	Policy   SourcePolicy
+}
+
+// compositeMember tracks the runtime health of a single source.
+type compositeMember struct {
+	CompositeSource
+	failures failureTracker
+	// eligibleSince is when the member last became eligible, zero while ineligible
+	eligibleSince time.Time
+}
+
+// memberEvent is an event tagged with the member that produced it.
+type memberEvent struct {
+	member *compositeMember
+	event  Event
+}
+
+// CompositeReceiver runs an ordered list of sources and selects the first
+// eligible one in priority order. A source is eligible when it has a prefix,
+// has not exceeded its failure threshold and passes its cross-check.
+// A recovered higher-priority source only takes over again after its failback delay.
 type CompositeReceiver struct {
-	mu                  sync.RWMutex
-	primary             Receiver // DHCPv6-PD
-	fallback            Receiver // RA
-	active              Receiver
-	events              chan Event
-	stopCh              chan struct{}
-	started             bool
-	consecutiveFailures int
-	maxFailures         int
-	ctx                 context.Context
-	cancel              context.CancelFunc
+	mu             sync.RWMutex
+	members        []*compositeMember
+	byName         map[string]*compositeMember
+	active         *compositeMember
+	failoverReason string
+	lastFailover   time.Time
+	memberEvents   chan memberEvent
+	events         chan Event
+	stopCh         chan struct{}
+	started        bool
+	ctx            context.Context
+	cancel         context.CancelFunc
+	now            func() time.Time
+	// reselectEvery is how often the running receiver re-evaluates its selection
+	reselectEvery time.Duration
 }
 
 // NewCompositeReceiver creates a new composite receiver with the given primary and fallback receivers.
+// The fallback takes over after DefaultFailureThreshold consecutive primary failures
+// and the primary is preferred again as soon as it recovers.
 func NewCompositeReceiver(primary, fallback Receiver) *CompositeReceiver {
-	return &CompositeReceiver{
-		primary:     primary,
-		fallback:    fallback,
-		active:      primary, // Start with primary
-		events:      make(chan Event, 10),
-		stopCh:      make(chan struct{}),
-		maxFailures: 3, // Switch to fallback after 3 consecutive failures
+	primaryName := string(primary.Source())
+	fallbackName := string(fallback.Source())
+	if fallbackName == primaryName {
+		fallbackName += "-fallback"
+	}
+
+	c, _ := NewCompositeReceiverFromSources([]CompositeSource{
+		{Receiver: primary, Policy: SourcePolicy{Name: primaryName, Priority: 0}},
+		{Receiver: fallback, Policy: SourcePolicy{Name: fallbackName, Priority: 1}},
+	})
+	return c
+}
+
+// NewCompositeReceiverFromSources creates a composite receiver from an ordered list of sources.
+// Sources are sorted by priority; sources with equal priority keep their order.
+func NewCompositeReceiverFromSources(sources []CompositeSource) (*CompositeReceiver, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("at least one source is required")
+	}
+
+	c := &CompositeReceiver{
+		byName:        make(map[string]*compositeMember, len(sources)),
+		memberEvents:  make(chan memberEvent, 10),
+		events:        make(chan Event, 10),
+		stopCh:        make(chan struct{}),
+		now:           time.Now,
+		reselectEvery: compositeReselectInterval,
+	}
+
+	for _, src := range sources {
+		if src.Receiver == nil {
+			return nil, fmt.Errorf("source %q has no receiver", src.Policy.Name)
+		}
+		if src.Policy.Name == "" {
+			return nil, fmt.Errorf("source name is required")
+		}
+		if _, exists := c.byName[src.Policy.Name]; exists {
+			return nil, fmt.Errorf("duplicate source name %q", src.Policy.Name)
+		}
+		if src.Policy.FailureThreshold <= 0 {
+			src.Policy.FailureThreshold = DefaultFailureThreshold
+		}
+		m := &compositeMember{CompositeSource: src}
+		c.members = append(c.members, m)
+		c.byName[src.Policy.Name] = m
+	}
+
+	for _, m := range c.members {
+		if m.Policy.Within == "" {
+			continue
+		}
+		if m.Policy.Within == m.Policy.Name {
+			return nil, fmt.Errorf("source %q cannot cross-check against itself", m.Policy.Name)
+		}
+		if _, ok := c.byName[m.Policy.Within]; !ok {
+			return nil, fmt.Errorf("source %q cross-checks against unknown source %q", m.Policy.Name, m.Policy.Within)
+		}
 	}
+
+	sort.SliceStable(c.members, func(i, j int) bool {
+		return c.members[i].Policy.Priority < c.members[j].Policy.Priority
+	})
+
+	return c, nil
 }
 
-// Start begins both receivers and merges their events.
+// Start begins all receivers and merges their events.
 func (c *CompositeReceiver) Start(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -61,26 +173,27 @@ func (c *CompositeReceiver) Start(ctx context.Context) error {
 
 	c.ctx, c.cancel = context.WithCancel(ctx)
 
-	// Start primary receiver
-	if err := c.primary.Start(c.ctx); err != nil {
-		return err
-	}
-
-	// Start fallback receiver
-	if err := c.fallback.Start(c.ctx); err != nil {
-		_ = c.primary.Stop()
-		return err
+	for i, m := range c.members {
+		if err := m.Receiver.Start(c.ctx); err != nil {
+			for _, started := range c.members[:i] {
+				_ = started.Receiver.Stop()
+			}
+			c.cancel()
+			return fmt.Errorf("failed to start source %q: %w", m.Policy.Name, err)
+		}
 	}
 
 	c.started = true
 
-	// Start event merging goroutine
+	for _, m := range c.members {
+		go c.forwardEvents(m)
+	}
 	go c.mergeEvents()
 
 	return nil
 }
 
-// Stop stops both receivers.
+// Stop stops all receivers.
 func (c *CompositeReceiver) Stop() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -95,15 +208,13 @@ func (c *CompositeReceiver) Stop() error {
 	}
 	close(c.stopCh)
 
-	// Stop both receivers
-	var primaryErr, fallbackErr error
-	primaryErr = c.primary.Stop()
-	fallbackErr = c.fallback.Stop()
-
-	if primaryErr != nil {
-		return primaryErr
+	var firstErr error
+	for _, m := range c.members {
+		if err := m.Receiver.Stop(); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
-	return fallbackErr
+	return firstErr
 }
 
 // Events returns the merged event channel.
@@ -111,16 +222,17 @@ func (c *CompositeReceiver) Events() <-chan Event {
 	return c.events
 }
 
-// CurrentPrefix returns the current prefix from the active receiver.
+// CurrentPrefix returns the current prefix from the active source.
+// The prefix carries the source it came from, which is the one to report with it:
+// the selection may change between this call and a later call to Source.
 func (c *CompositeReceiver) CurrentPrefix() *Prefix {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	// Prefer primary if it has a prefix
-	if prefix := c.primary.CurrentPrefix(); prefix != nil {
-		return prefix
+	if c.active == nil {
+		return nil
 	}
-	return c.fallback.CurrentPrefix()
+	return c.active.Receiver.CurrentPrefix()
 }
 
 // Source returns the source of the active receiver.
@@ -128,93 +240,242 @@ func (c *CompositeReceiver) Source() Source {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	// Return source based on which receiver has the current prefix
-	if c.primary.CurrentPrefix() != nil {
-		return c.primary.Source()
+	if c.active != nil {
+		return c.active.Receiver.Source()
+	}
+	return c.members[0].Receiver.Source()
+}
+
+// FailoverStatus returns which source is active and why it was last switched.
+func (c *CompositeReceiver) FailoverStatus() FailoverStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	status := FailoverStatus{
+		Reason:       c.failoverReason,
+		LastFailover: c.lastFailover,
 	}
-	if c.fallback.CurrentPrefix() != nil {
-		return c.fallback.Source()
+	if c.active != nil {
+		status.ActiveSource = c.active.Policy.Name
 	}
-	return c.primary.Source() // Default to primary
+	return status
 }
 
-// mergeEvents reads from both receivers' event channels and forwards to the composite channel.
-func (c *CompositeReceiver) mergeEvents() {
-	primaryEvents := c.primary.Events()
-	fallbackEvents := c.fallback.Events()
+// Diagnostics returns the diagnostics of every source, marking the active one.
+func (c *CompositeReceiver) Diagnostics() []Diagnostics {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := make([]Diagnostics, 0, len(c.members))
+	for _, m := range c.members {
+		d := Diagnostics{Source: m.Receiver.Source()}
+		if reporter, ok := m.Receiver.(DiagnosticsReporter); ok {
+			if inner := reporter.Diagnostics(); len(inner) > 0 {
+				d = inner[0]
+			}
+		}
+		d.Name = m.Policy.Name
+		d.Active = m == c.active
+		// Report the failures that drive failover decisions
+		m.failures.fill(&d)
+		result = append(result, d)
+	}
+	return result
+}
+
+// SetSourcePrefixHint passes the hint on to the named source, if it can use it.
+// Other sources are left without a hint: asking another ISP's server for the
+// prefix the named source delegated would never succeed.
+func (c *CompositeReceiver) SetSourcePrefixHint(name string, hint netip.Prefix) {
+	m, ok := c.byName[name]
+	if !ok {
+		return
+	}
+	if hinter, ok := m.Receiver.(PrefixHinter); ok {
+		hinter.SetPrefixHint(hint)
+	}
+}
+
+// IsUsingFallback returns true if a source other than the highest-priority one is active.
+func (c *CompositeReceiver) IsUsingFallback() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.active != nil && c.active != c.members[0]
+}
 
+// forwardEvents copies events from a single member into the merged member channel.
+func (c *CompositeReceiver) forwardEvents(m *compositeMember) {
+	events := m.Receiver.Events()
 	for {
 		select {
 		case <-c.stopCh:
 			return
 		case <-c.ctx.Done():
 			return
-
-		case event, ok := <-primaryEvents:
+		case event, ok := <-events:
 			if !ok {
-				continue
+				return
 			}
-			c.handlePrimaryEvent(event)
-
-		case event, ok := <-fallbackEvents:
-			if !ok {
-				continue
+			select {
+			case c.memberEvents <- memberEvent{member: m, event: event}:
+			case <-c.stopCh:
+				return
+			case <-c.ctx.Done():
+				return
 			}
-			c.handleFallbackEvent(event)
 		}
 	}
 }
 
-// handlePrimaryEvent processes an event from the primary (DHCPv6-PD) receiver.
-func (c *CompositeReceiver) handlePrimaryEvent(event Event) {
+// mergeEvents processes member events and forwards those of the active source.
+// The selection is also re-evaluated periodically, so that failback delays expire
+// and members whose prefix lapsed without an event are dropped.
+func (c *CompositeReceiver) mergeEvents() {
+	ticker := time.NewTicker(c.reselectEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-c.ctx.Done():
+			return
+		case me := <-c.memberEvents:
+			c.handleMemberEvent(me.member, me.event)
+		case <-ticker.C:
+			c.reselect()
+		}
+	}
+}
+
+// reselect re-evaluates the active source.
+func (c *CompositeReceiver) reselect() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.selectActive()
+}
+
+// handleMemberEvent updates member health and re-selects the active source.
+func (c *CompositeReceiver) handleMemberEvent(m *compositeMember, event Event) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	wasActive := c.active == m
+
 	switch event.Type {
 	case EventTypeFailed:
-		c.consecutiveFailures++
-		if c.consecutiveFailures >= c.maxFailures {
-			// Switch to fallback
-			c.active = c.fallback
-			// If fallback has a prefix, emit it
-			if fallbackPrefix := c.fallback.CurrentPrefix(); fallbackPrefix != nil {
-				c.sendEvent(Event{Type: EventTypeAcquired, Prefix: fallbackPrefix})
-			}
+		m.failures.recordFailure(event.Error)
+		// Forward the failure of the active source before any switch
+		if wasActive {
+			c.sendEvent(event)
 		}
-		// Always forward the failure event
-		c.sendEvent(event)
 
 	case EventTypeAcquired, EventTypeRenewed, EventTypeChanged:
-		// Primary succeeded, reset failure count
-		c.consecutiveFailures = 0
-		c.active = c.primary
-		// Forward the event
+		// Source succeeded, reset failure count
+		m.failures.recordSuccess()
+	}
+
+	if c.selectActive() {
+		return
+	}
+
+	// No switch happened: forward prefix events of the active source only
+	if event.Type != EventTypeFailed && c.active == m {
 		c.sendEvent(event)
+	}
+}
+
+// selectActive chooses the active source and emits an event when it changes.
+// It returns true if the active source was switched. Must be called with lock held.
+func (c *CompositeReceiver) selectActive() bool {
+	now := c.now()
+
+	// First pass: refresh eligibility of every member
+	reasons := make(map[*compositeMember]string, len(c.members))
+	for _, m := range c.members {
+		if reason := c.ineligibleReason(m); reason != "" {
+			reasons[m] = reason
+			m.eligibleSince = time.Time{}
+		} else if m.eligibleSince.IsZero() {
+			m.eligibleSince = now
+		}
+	}
+
+	activeEligible := c.active != nil && reasons[c.active] == ""
+
+	var chosen *compositeMember
+	for _, m := range c.members {
+		if reasons[m] != "" {
+			continue
+		}
+		if m == c.active {
+			chosen = m
+			break
+		}
+		// A higher-priority source must wait out its failback delay before
+		// taking over from a healthy active source.
+		if activeEligible && now.Sub(m.eligibleSince) < m.Policy.FailbackDelay {
+			continue
+		}
+		chosen = m
+		break
+	}
+
+	// Nothing eligible: keep the current selection rather than flapping
+	if chosen == nil || chosen == c.active {
+		return false
+	}
+
+	previous := c.active
+	c.active = chosen
+
+	// The initial selection is not a failover
+	if previous == nil {
+		c.sendEvent(Event{Type: EventTypeAcquired, Prefix: chosen.Receiver.CurrentPrefix()})
+		return true
+	}
 
-	case EventTypeExpired:
-		// Primary expired, switch to fallback if available
-		if fallbackPrefix := c.fallback.CurrentPrefix(); fallbackPrefix != nil {
-			c.active = c.fallback
-			c.sendEvent(Event{Type: EventTypeAcquired, Prefix: fallbackPrefix})
+	if activeEligible {
+		c.failoverReason = fmt.Sprintf("failback to %s: source is healthy again", chosen.Policy.Name)
+	} else {
+		c.failoverReason = fmt.Sprintf("failover from %s to %s: %s", previous.Policy.Name, chosen.Policy.Name, reasons[previous])
+	}
+	c.lastFailover = now
+
+	event := Event{Type: EventTypeAcquired, Prefix: chosen.Receiver.CurrentPrefix()}
+	if oldPrefix := previous.Receiver.CurrentPrefix(); oldPrefix != nil {
+		if oldPrefix.Network != event.Prefix.Network {
+			event.Type = EventTypeChanged
+			event.PreviousPrefix = oldPrefix
 		} else {
-			c.sendEvent(event)
+			event.Type = EventTypeRenewed
 		}
 	}
+	c.sendEvent(event)
+	return true
 }
 
-// handleFallbackEvent processes an event from the fallback (RA) receiver.
-func (c *CompositeReceiver) handleFallbackEvent(event Event) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// ineligibleReason returns why a member cannot provide the prefix, or "" if it can.
+// Must be called with lock held.
+func (c *CompositeReceiver) ineligibleReason(m *compositeMember) string {
+	current := m.Receiver.CurrentPrefix()
+	if current == nil {
+		return "no prefix"
+	}
 
-	// Only forward fallback events if we're using the fallback
-	if c.active != c.fallback {
-		// But track the prefix in case we need to switch
-		return
+	if m.failures.consecutiveFailures >= m.Policy.FailureThreshold {
+		return fmt.Sprintf("%d consecutive failures: %v", m.failures.consecutiveFailures, m.failures.lastError)
 	}
 
-	// Forward the event
-	c.sendEvent(event)
+	if m.Policy.Within != "" {
+		other := c.byName[m.Policy.Within].Receiver.CurrentPrefix()
+		if other != nil && !prefixWithin(current.Network, other.Network) {
+			return fmt.Sprintf("cross-check failed: %s is not within %s prefix %s",
+				current.Network, m.Policy.Within, other.Network)
+		}
+	}
+
+	return ""
 }
 
 // sendEvent sends an event to the events channel (must be called with lock held).
@@ -226,9 +487,7 @@ func (c *CompositeReceiver) sendEvent(event Event) {
 	}
 }
 
-// IsUsingFallback returns true if the composite receiver is currently using the fallback.
-func (c *CompositeReceiver) IsUsingFallback() bool {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return c.active == c.fallback
+// prefixWithin returns true if inner is fully contained in outer.
+func prefixWithin(inner, outer netip.Prefix) bool {
+	return inner.Bits() >= outer.Bits() && outer.Contains(inner.Addr())
 }
@@ -18,7 +18,9 @@ package prefix
 
 import (
 	"context"
+	"errors"
 	"net/netip"
+	"strings"
 	"testing"
 	"time"
 )
@@ -47,9 +49,10 @@ func TestCompositeReceiver_CurrentPrefix(t *testing.T) {
 	// Simulate primary getting a prefix
 	primaryPrefix := netip.MustParsePrefix("2001:db8:1::/48")
 	primary.SimulatePrefix(primaryPrefix, time.Hour)
+	composite.reselect()
 
 	if composite.CurrentPrefix() == nil {
-		t.Error("Expected non-nil prefix after primary acquisition")
+		t.Fatal("Expected non-nil prefix after primary acquisition")
 	}
 
 	if composite.CurrentPrefix().Network != primaryPrefix {
@@ -59,6 +62,7 @@ func TestCompositeReceiver_CurrentPrefix(t *testing.T) {
 	// Simulate fallback getting a different prefix
 	fallbackPrefix := netip.MustParsePrefix("2001:db8:2::/48")
 	fallback.SimulatePrefix(fallbackPrefix, time.Hour)
+	composite.reselect()
 
 	// Should still prefer primary
 	if composite.CurrentPrefix().Network != primaryPrefix {
@@ -67,10 +71,11 @@ func TestCompositeReceiver_CurrentPrefix(t *testing.T) {
 
 	// Clear primary prefix
 	primary.SimulatePrefixExpiry()
+	composite.reselect()
 
 	// Should now return fallback
 	if composite.CurrentPrefix() == nil {
-		t.Error("Expected non-nil prefix from fallback")
+		t.Fatal("Expected non-nil prefix from fallback")
 	}
 
 	if composite.CurrentPrefix().Network != fallbackPrefix {
@@ -137,3 +142,281 @@ func TestCompositeReceiver_EventChannel(t *testing.T) {
 		t.Errorf("Events channel capacity = %d, want 10", cap(events))
 	}
 }
+
+func TestCompositeReceiver_FailoverAfterThreshold(t *testing.T) {
+	primary := NewMockReceiver(SourceDHCPv6PD)
+	fallback := NewMockReceiver(SourceRouterAdvertisement)
+	composite, err := NewCompositeReceiverFromSources([]CompositeSource{
+		{Receiver: primary, Policy: SourcePolicy{Name: "pd", FailureThreshold: 2}},
+		{Receiver: fallback, Policy: SourcePolicy{Name: "ra", Priority: 1}},
+	})
+	if err != nil {
+		t.Fatalf("NewCompositeReceiverFromSources() error = %v", err)
+	}
+
+	primaryPrefix := netip.MustParsePrefix("2001:db8:1::/56")
+	fallbackPrefix := netip.MustParsePrefix("2001:db8:2::/64")
+	primary.SimulatePrefix(primaryPrefix, time.Hour)
+	fallback.SimulatePrefix(fallbackPrefix, time.Hour)
+	composite.reselect()
+
+	if got := composite.CurrentPrefix().Network; got != primaryPrefix {
+		t.Fatalf("CurrentPrefix().Network = %v, want %v", got, primaryPrefix)
+	}
+
+	// One failure stays below the threshold
+	composite.handleMemberEvent(composite.byName["pd"], Event{Type: EventTypeFailed, Error: errors.New("timeout")})
+	if composite.IsUsingFallback() {
+		t.Fatal("Should not fail over below the failure threshold")
+	}
+
+	composite.handleMemberEvent(composite.byName["pd"], Event{Type: EventTypeFailed, Error: errors.New("timeout")})
+	if got := composite.CurrentPrefix().Network; got != fallbackPrefix {
+		t.Errorf("CurrentPrefix().Network = %v, want %v after failover", got, fallbackPrefix)
+	}
+
+	status := composite.FailoverStatus()
+	if status.ActiveSource != "ra" {
+		t.Errorf("ActiveSource = %q, want %q", status.ActiveSource, "ra")
+	}
+	if !strings.Contains(status.Reason, "2 consecutive failures") {
+		t.Errorf("Reason = %q, want it to mention the failures", status.Reason)
+	}
+	if status.LastFailover.IsZero() {
+		t.Error("LastFailover should be set")
+	}
+
+	// A successful renewal makes the primary eligible again
+	composite.handleMemberEvent(composite.byName["pd"], Event{Type: EventTypeRenewed})
+	if composite.IsUsingFallback() {
+		t.Error("Should fail back immediately without a failback delay")
+	}
+}
+
+func TestCompositeReceiver_FailbackDelay(t *testing.T) {
+	primary := NewMockReceiver(SourceDHCPv6PD)
+	fallback := NewMockReceiver(SourceRouterAdvertisement)
+	composite, err := NewCompositeReceiverFromSources([]CompositeSource{
+		{Receiver: primary, Policy: SourcePolicy{Name: "pd", FailbackDelay: 5 * time.Minute}},
+		{Receiver: fallback, Policy: SourcePolicy{Name: "ra", Priority: 1}},
+	})
+	if err != nil {
+		t.Fatalf("NewCompositeReceiverFromSources() error = %v", err)
+	}
+
+	now := time.Now()
+	composite.now = func() time.Time { return now }
+
+	fallbackPrefix := netip.MustParsePrefix("2001:db8:2::/64")
+	fallback.SimulatePrefix(fallbackPrefix, time.Hour)
+	composite.reselect()
+	if got := composite.CurrentPrefix().Network; got != fallbackPrefix {
+		t.Fatalf("CurrentPrefix().Network = %v, want %v", got, fallbackPrefix)
+	}
+
+	// Primary recovers but must wait out the failback delay
+	primaryPrefix := netip.MustParsePrefix("2001:db8:1::/56")
+	primary.SimulatePrefix(primaryPrefix, time.Hour)
+	composite.reselect()
+	if got := composite.CurrentPrefix().Network; got != fallbackPrefix {
+		t.Errorf("CurrentPrefix().Network = %v, want %v during failback delay", got, fallbackPrefix)
+	}
+
+	now = now.Add(5 * time.Minute)
+	composite.reselect()
+	if got := composite.CurrentPrefix().Network; got != primaryPrefix {
+		t.Errorf("CurrentPrefix().Network = %v, want %v after failback delay", got, primaryPrefix)
+	}
+	if reason := composite.FailoverStatus().Reason; !strings.Contains(reason, "failback to pd") {
+		t.Errorf("Reason = %q, want failback reason", reason)
+	}
+}
+
+func TestCompositeReceiver_GettersDoNotSwitch(t *testing.T) {
+	primary := NewMockReceiver(SourceDHCPv6PD)
+	fallback := NewMockReceiver(SourceRouterAdvertisement)
+	composite, err := NewCompositeReceiverFromSources([]CompositeSource{
+		{Receiver: primary, Policy: SourcePolicy{Name: "pd", FailbackDelay: time.Minute}},
+		{Receiver: fallback, Policy: SourcePolicy{Name: "ra", Priority: 1}},
+	})
+	if err != nil {
+		t.Fatalf("NewCompositeReceiverFromSources() error = %v", err)
+	}
+
+	now := time.Now()
+	composite.now = func() time.Time { return now }
+
+	fallbackPrefix := netip.MustParsePrefix("2001:db8:2::/64")
+	fallback.SimulatePrefix(fallbackPrefix, time.Hour)
+	composite.reselect()
+	primary.SimulatePrefix(netip.MustParsePrefix("2001:db8:1::/56"), time.Hour)
+	composite.reselect()
+
+	// The failback delay expires between the two getters: both still report the fallback
+	now = now.Add(time.Minute)
+	current := composite.CurrentPrefix()
+	source := composite.Source()
+	if current.Network != fallbackPrefix || current.Source != SourceRouterAdvertisement || source != SourceRouterAdvertisement {
+		t.Errorf("CurrentPrefix() = %v from %s, Source() = %s, want %v from %s",
+			current.Network, current.Source, source, fallbackPrefix, SourceRouterAdvertisement)
+	}
+}
+
+func TestCompositeReceiver_ReselectsWhileRunning(t *testing.T) {
+	primary := NewMockReceiver(SourceDHCPv6PD)
+	fallback := NewMockReceiver(SourceRouterAdvertisement)
+	composite, err := NewCompositeReceiverFromSources([]CompositeSource{
+		{Receiver: primary, Policy: SourcePolicy{Name: "pd", FailbackDelay: 50 * time.Millisecond}},
+		{Receiver: fallback, Policy: SourcePolicy{Name: "ra", Priority: 1}},
+	})
+	if err != nil {
+		t.Fatalf("NewCompositeReceiverFromSources() error = %v", err)
+	}
+	composite.reselectEvery = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := composite.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer func() { _ = composite.Stop() }()
+
+	fallback.SimulatePrefix(netip.MustParsePrefix("2001:db8:2::/64"), time.Hour)
+	primaryPrefix := netip.MustParsePrefix("2001:db8:1::/56")
+	primary.SimulatePrefix(primaryPrefix, time.Hour)
+
+	// The failback happens once the delay expires, without further member events
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if current := composite.CurrentPrefix(); current != nil && current.Network == primaryPrefix {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("CurrentPrefix() = %v, want %v after the failback delay", composite.CurrentPrefix(), primaryPrefix)
+}
+
+func TestCompositeReceiver_CrossCheck(t *testing.T) {
+	pd := NewMockReceiver(SourceDHCPv6PD)
+	ra := NewMockReceiver(SourceRouterAdvertisement)
+	static := NewStaticReceiver(netip.MustParsePrefix("2001:db8:ffff::/64"))
+	composite, err := NewCompositeReceiverFromSources([]CompositeSource{
+		{Receiver: ra, Policy: SourcePolicy{Name: "ra", Within: "pd"}},
+		{Receiver: pd, Policy: SourcePolicy{Name: "pd", Priority: 1}},
+		{Receiver: static, Policy: SourcePolicy{Name: "static", Priority: 2}},
+	})
+	if err != nil {
+		t.Fatalf("NewCompositeReceiverFromSources() error = %v", err)
+	}
+	if err := static.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	pd.SimulatePrefix(netip.MustParsePrefix("2001:db8:1::/56"), time.Hour)
+
+	// RA /64 inside the delegated /56 passes the cross-check
+	inside := netip.MustParsePrefix("2001:db8:1:5::/64")
+	ra.SimulatePrefix(inside, time.Hour)
+	composite.reselect()
+	if got := composite.CurrentPrefix().Network; got != inside {
+		t.Fatalf("CurrentPrefix().Network = %v, want %v", got, inside)
+	}
+
+	// RA /64 outside the delegated /56 fails the cross-check
+	ra.SimulatePrefix(netip.MustParsePrefix("2001:db8:9:5::/64"), time.Hour)
+	composite.reselect()
+	if got := composite.CurrentPrefix().Network; got != netip.MustParsePrefix("2001:db8:1::/56") {
+		t.Errorf("CurrentPrefix().Network = %v, want the DHCPv6-PD prefix", got)
+	}
+	status := composite.FailoverStatus()
+	if status.ActiveSource != "pd" || !strings.Contains(status.Reason, "cross-check failed") {
+		t.Errorf("FailoverStatus() = %+v, want pd active with cross-check reason", status)
+	}
+}
+
+func TestNewCompositeReceiverFromSources_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources []CompositeSource
+	}{
+		{
+			name: "No sources",
+		},
+		{
+			name: "Duplicate names",
+			sources: []CompositeSource{
+				{Receiver: NewMockReceiver(SourceStatic), Policy: SourcePolicy{Name: "a"}},
+				{Receiver: NewMockReceiver(SourceStatic), Policy: SourcePolicy{Name: "a"}},
+			},
+		},
+		{
+			name: "Cross-check against itself",
+			sources: []CompositeSource{
+				{Receiver: NewMockReceiver(SourceStatic), Policy: SourcePolicy{Name: "a", Within: "a"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewCompositeReceiverFromSources(tt.sources); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestCompositeReceiver_SetSourcePrefixHint(t *testing.T) {
+	primary := NewDHCPv6PDReceiver("eth0", 56)
+	standby := NewDHCPv6PDReceiver("eth1", 56)
+	c, err := NewCompositeReceiverFromSources([]CompositeSource{
+		{Receiver: primary, Policy: SourcePolicy{Name: "isp-a", Priority: 0}},
+		{Receiver: standby, Policy: SourcePolicy{Name: "isp-b", Priority: 1}},
+	})
+	if err != nil {
+		t.Fatalf("NewCompositeReceiverFromSources() error = %v", err)
+	}
+
+	// Only the source that delegated the prefix asks for it again
+	c.SetSourcePrefixHint("isp-b", netip.MustParsePrefix("2001:db8:b00::/56"))
+	if got := primary.solicitedPrefix().String(); got != "::/56" {
+		t.Errorf("isp-a solicits %s, want no prefix hint", got)
+	}
+	if got := standby.solicitedPrefix().String(); got != "2001:db8:b00::/56" {
+		t.Errorf("isp-b solicits %s, want its previous prefix", got)
+	}
+
+	// An unknown source name hints nobody
+	c.SetSourcePrefixHint("removed", netip.MustParsePrefix("2001:db8:c00::/56"))
+	if got := primary.solicitedPrefix().String(); got != "::/56" {
+		t.Errorf("isp-a solicits %s after hint for unknown source, want no prefix hint", got)
+	}
+}
+
+func TestCompositeReceiver_Diagnostics(t *testing.T) {
+	primary := NewMockReceiver(SourceDHCPv6PD)
+	fallback := NewRAReceiver("eth0")
+	composite := NewCompositeReceiver(primary, fallback)
+
+	fallback.recordRouterAdvertisement(netip.MustParseAddr("fe80::1"))
+	fallback.updatePrefix(netip.MustParsePrefix("2001:db8:2::/64"), time.Hour, time.Hour)
+	composite.handleMemberEvent(composite.byName["dhcpv6-pd"], Event{Type: EventTypeFailed, Error: errors.New("timeout")})
+
+	if composite.CurrentPrefix() == nil {
+		t.Fatal("Expected prefix from fallback")
+	}
+
+	diag := composite.Diagnostics()
+	if len(diag) != 2 {
+		t.Fatalf("Diagnostics() returned %d entries, want 2", len(diag))
+	}
+	if diag[0].Name != "dhcpv6-pd" || diag[0].Active || diag[0].ConsecutiveFailures != 1 {
+		t.Errorf("primary diagnostics = %+v", diag[0])
+	}
+	if diag[1].Name != "router-advertisement" || !diag[1].Active || diag[1].Router != "fe80::1" {
+		t.Errorf("fallback diagnostics = %+v", diag[1])
+	}
+	if !composite.IsUsingFallback() {
+		t.Error("Should be using fallback while primary has no prefix")
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+)
+
+// requestedOptions are the configuration options requested in the Option Request option.
+var requestedOptions = []dhcpv6.OptionCode{
+	dhcpv6.OptionDNSRecursiveNameServer,
+	dhcpv6.OptionDomainSearchList,
+	dhcpv6.OptionNTPServer,
+	dhcpv6.OptionSNTPServerList,
+	dhcpv6.OptionSolMaxRT,
+}
+
+// networkConfigFrom extracts configuration data from DHCPv6 messages.
+// Each field is taken from the first message that carries it, so a REPLY
+// followed by its ADVERTISE fills gaps in the REPLY.
+// Returns nil if none of the messages carries configuration data.
+func networkConfigFrom(msgs ...*dhcpv6.Message) *NetworkConfig {
+	config := &NetworkConfig{}
+	found := false
+
+	for _, msg := range msgs {
+		if msg == nil {
+			continue
+		}
+
+		if config.DNSServers == nil {
+			if servers := addrsFromIPs(msg.Options.DNS()); len(servers) > 0 {
+				config.DNSServers = servers
+				found = true
+			}
+		}
+
+		if config.DomainSearch == nil {
+			if labels := msg.Options.DomainSearchList(); labels != nil && len(labels.Labels) > 0 {
+				config.DomainSearch = labels.Labels
+				found = true
+			}
+		}
+
+		if config.NTPServers == nil {
+			if servers := ntpServers(msg); len(servers) > 0 {
+				config.NTPServers = servers
+				found = true
+			}
+		}
+
+		if config.SNTPServers == nil {
+			if opt := msg.GetOneOption(dhcpv6.OptionSNTPServerList); opt != nil {
+				if servers := addrsFromBytes(opt.ToBytes()); len(servers) > 0 {
+					config.SNTPServers = servers
+					found = true
+				}
+			}
+		}
+
+		if config.SolMaxRT == 0 {
+			if opt := msg.GetOneOption(dhcpv6.OptionSolMaxRT); opt != nil {
+				if data := opt.ToBytes(); len(data) == 4 {
+					config.SolMaxRT = time.Duration(binary.BigEndian.Uint32(data)) * time.Second
+					found = true
+				}
+			}
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return config
+}
+
+// ntpServers returns the server addresses and FQDNs from the NTP Server options of a message.
+func ntpServers(msg *dhcpv6.Message) []string {
+	var servers []string
+	for _, opt := range msg.Options.Get(dhcpv6.OptionNTPServer) {
+		ntp, ok := opt.(*dhcpv6.OptNTPServer)
+		if !ok {
+			continue
+		}
+		for _, subopt := range ntp.Suboptions {
+			switch so := subopt.(type) {
+			case *dhcpv6.NTPSuboptionSrvAddr:
+				if addr, ok := netip.AddrFromSlice(*so); ok {
+					servers = append(servers, addr.String())
+				}
+			case *dhcpv6.NTPSuboptionMCAddr:
+				if addr, ok := netip.AddrFromSlice(*so); ok {
+					servers = append(servers, addr.String())
+				}
+			case *dhcpv6.NTPSuboptionSrvFQDN:
+				servers = append(servers, so.Labels.Labels...)
+			}
+		}
+	}
+	return servers
+}
+
+// addrsFromIPs converts IPv6 addresses, skipping anything else.
+func addrsFromIPs(ips []net.IP) []netip.Addr {
+	var addrs []netip.Addr
+	for _, ip := range ips {
+		if addr, ok := netip.AddrFromSlice(ip); ok && addr.Is6() {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// addrsFromBytes splits an option payload into IPv6 addresses.
+func addrsFromBytes(data []byte) []netip.Addr {
+	var addrs []netip.Addr
+	for len(data) >= 16 {
+		addrs = append(addrs, netip.AddrFrom16([16]byte(data[:16])))
+		data = data[16:]
+	}
+	return addrs
+}
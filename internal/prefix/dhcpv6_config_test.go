@@ -0,0 +1,75 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+)
+
+func TestNetworkConfigFrom(t *testing.T) {
+	reply, err := dhcpv6.NewMessage()
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+	dhcpv6.WithDNS(net.ParseIP("2001:db8::53"), net.ParseIP("2001:db8::54"))(reply)
+	dhcpv6.WithDomainSearchList("home.arpa", "example.net")(reply)
+	srv := dhcpv6.NTPSuboptionSrvAddr(net.ParseIP("2001:db8::123"))
+	reply.AddOption(&dhcpv6.OptNTPServer{Suboptions: dhcpv6.Options{&srv}})
+	reply.AddOption(&dhcpv6.OptionGeneric{
+		OptionCode: dhcpv6.OptionSolMaxRT,
+		OptionData: []byte{0, 0, 0x0e, 0x10}, // 3600 seconds
+	})
+
+	// The ADVERTISE only fills what the REPLY leaves out
+	advertise, err := dhcpv6.NewMessage()
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+	dhcpv6.WithDNS(net.ParseIP("2001:db8::99"))(advertise)
+	advertise.AddOption(&dhcpv6.OptionGeneric{
+		OptionCode: dhcpv6.OptionSNTPServerList,
+		OptionData: netip.MustParseAddr("2001:db8::124").AsSlice(),
+	})
+
+	got := networkConfigFrom(reply, advertise)
+	want := &NetworkConfig{
+		DNSServers:   []netip.Addr{netip.MustParseAddr("2001:db8::53"), netip.MustParseAddr("2001:db8::54")},
+		DomainSearch: []string{"home.arpa", "example.net"},
+		NTPServers:   []string{"2001:db8::123"},
+		SNTPServers:  []netip.Addr{netip.MustParseAddr("2001:db8::124")},
+		SolMaxRT:     time.Hour,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("networkConfigFrom() = %+v, want %+v", got, want)
+	}
+}
+
+func TestNetworkConfigFrom_Empty(t *testing.T) {
+	msg, err := dhcpv6.NewMessage()
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+	if got := networkConfigFrom(msg, nil); got != nil {
+		t.Errorf("networkConfigFrom() = %+v, want nil", got)
+	}
+}
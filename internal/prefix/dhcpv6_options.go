@@ -0,0 +1,174 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+)
+
+// DHCPv6AuthProtocol selects how DHCPv6 messages are authenticated.
+type DHCPv6AuthProtocol string
+
+const (
+	// DHCPv6AuthDelayed signs messages with a shared key (RFC 3315 delayed authentication).
+	DHCPv6AuthDelayed DHCPv6AuthProtocol = "delayed"
+)
+
+// Authentication option fields, RFC 8415 section 21.11.
+const (
+	authProtocolDelayed  = 2
+	authAlgorithmHMACMD5 = 1
+	authRDMMonotonic     = 0
+	authHeaderLen        = 11 // protocol, algorithm, RDM and replay detection
+	authKeyIDLen         = 4
+	authHMACLen          = md5.Size
+)
+
+// DHCPv6AuthConfig configures DHCPv6 authentication.
+type DHCPv6AuthConfig struct {
+	// Protocol is the authentication protocol.
+	Protocol DHCPv6AuthProtocol
+	// Realm is the DHCP realm of the delayed authentication key.
+	Realm string
+	// KeyID identifies the delayed authentication key within the realm.
+	KeyID uint32
+	// Key is the delayed authentication key.
+	Key []byte
+}
+
+// applyClientOptions adds the configured custom options and authentication to an outgoing message.
+// Authentication is applied last, as it covers the whole message.
+func (r *DHCPv6PDReceiver) applyClientOptions(msg *dhcpv6.Message) {
+	for _, opt := range r.options {
+		switch opt.Code() {
+		case dhcpv6.OptionVendorClass, dhcpv6.OptionUserClass, dhcpv6.OptionVendorOpts:
+			// May already be copied from the ADVERTISE
+			msg.UpdateOption(opt)
+		default:
+			msg.AddOption(opt)
+		}
+	}
+
+	if r.auth != nil && r.auth.Protocol == DHCPv6AuthDelayed {
+		r.signDelayed(msg)
+	}
+}
+
+// signDelayed adds a delayed authentication option.
+// A SOLICIT only announces that the client wants delayed authentication;
+// all other messages carry the realm, key ID and HMAC-MD5 of the message.
+func (r *DHCPv6PDReceiver) signDelayed(msg *dhcpv6.Message) {
+	data := make([]byte, authHeaderLen)
+	data[0] = authProtocolDelayed
+	data[1] = authAlgorithmHMACMD5
+	data[2] = authRDMMonotonic
+	binary.BigEndian.PutUint64(data[3:], r.nextReplayCounter())
+
+	if msg.MessageType != dhcpv6.MessageTypeSolicit {
+		data = append(data, r.auth.Realm...)
+		data = binary.BigEndian.AppendUint32(data, r.auth.KeyID)
+		data = append(data, make([]byte, authHMACLen)...)
+	}
+
+	opt := &dhcpv6.OptionGeneric{OptionCode: dhcpv6.OptionAuth, OptionData: data}
+	msg.Options.Del(dhcpv6.OptionAuth)
+	msg.AddOption(opt)
+
+	if msg.MessageType != dhcpv6.MessageTypeSolicit {
+		// The HMAC is computed with the HMAC field zeroed
+		mac := hmac.New(md5.New, r.auth.Key)
+		mac.Write(msg.ToBytes())
+		copy(data[len(data)-authHMACLen:], mac.Sum(nil))
+	}
+}
+
+// verifyAuthentication checks the authentication option of a server message.
+// With delayed authentication, every message must carry a delayed authentication option with an HMAC
+// that matches the key and a replay detection value greater than any previously accepted one.
+func (r *DHCPv6PDReceiver) verifyAuthentication(msg *dhcpv6.Message) error {
+	if r.auth == nil || r.auth.Protocol != DHCPv6AuthDelayed {
+		return nil
+	}
+
+	var data []byte
+	if opt, ok := msg.GetOneOption(dhcpv6.OptionAuth).(*dhcpv6.OptionGeneric); ok && len(opt.OptionData) >= authHeaderLen {
+		data = opt.OptionData
+	}
+	if data == nil {
+		return fmt.Errorf("%s carries no delayed authentication option", msg.MessageType)
+	}
+	if data[0] != authProtocolDelayed {
+		return fmt.Errorf("%s uses authentication protocol %d, want delayed authentication", msg.MessageType, data[0])
+	}
+	if len(data) < authHeaderLen+authKeyIDLen+authHMACLen {
+		return fmt.Errorf("%s authentication option is too short", msg.MessageType)
+	}
+	if data[1] != authAlgorithmHMACMD5 {
+		return fmt.Errorf("%s uses unsupported authentication algorithm %d", msg.MessageType, data[1])
+	}
+	if data[2] != authRDMMonotonic {
+		return fmt.Errorf("%s uses unsupported replay detection method %d", msg.MessageType, data[2])
+	}
+
+	macStart := len(data) - authHMACLen
+	realm := string(data[authHeaderLen : macStart-authKeyIDLen])
+	keyID := binary.BigEndian.Uint32(data[macStart-authKeyIDLen : macStart])
+	if realm != r.auth.Realm || keyID != r.auth.KeyID {
+		return fmt.Errorf("%s is signed with unknown key %q/%d", msg.MessageType, realm, keyID)
+	}
+
+	// Recompute the HMAC with the HMAC field zeroed, then restore it
+	received := append([]byte(nil), data[macStart:]...)
+	clear(data[macStart:])
+	mac := hmac.New(md5.New, r.auth.Key)
+	mac.Write(msg.ToBytes())
+	copy(data[macStart:], received)
+
+	if !hmac.Equal(mac.Sum(nil), received) {
+		return fmt.Errorf("%s failed authentication", msg.MessageType)
+	}
+
+	// Only an authentic message advances the server's replay detection value
+	replay := binary.BigEndian.Uint64(data[3:authHeaderLen])
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.serverReplaySeen && replay <= r.serverReplayCounter {
+		return fmt.Errorf("%s replay detection value %d is not greater than %d", msg.MessageType, replay, r.serverReplayCounter)
+	}
+	r.serverReplayCounter = replay
+	r.serverReplaySeen = true
+	return nil
+}
+
+// nextReplayCounter returns a strictly increasing replay detection value.
+func (r *DHCPv6PDReceiver) nextReplayCounter() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counter := uint64(time.Now().UnixNano())
+	if counter <= r.replayCounter {
+		counter = r.replayCounter + 1
+	}
+	r.replayCounter = counter
+	return counter
+}
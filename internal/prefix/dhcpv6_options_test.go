@@ -0,0 +1,145 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"testing"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+)
+
+func newTestMessage(t *testing.T, messageType dhcpv6.MessageType) *dhcpv6.Message {
+	t.Helper()
+	msg, err := dhcpv6.NewMessage()
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+	msg.MessageType = messageType
+	return msg
+}
+
+func TestApplyClientOptions(t *testing.T) {
+	r := NewDHCPv6PDReceiverWithConfig(DHCPv6PDConfig{
+		Interface: "eth0",
+		Options: []dhcpv6.Option{
+			&dhcpv6.OptVendorClass{EnterpriseNumber: 1038, Data: [][]byte{[]byte("sagem")}},
+			&dhcpv6.OptionGeneric{OptionCode: 250, OptionData: []byte{0x01}},
+		},
+	})
+
+	msg := newTestMessage(t, dhcpv6.MessageTypeRequest)
+	// A vendor class copied from the ADVERTISE is replaced, not duplicated
+	msg.AddOption(&dhcpv6.OptVendorClass{EnterpriseNumber: 1, Data: [][]byte{[]byte("other")}})
+	r.applyClientOptions(msg)
+
+	if classes := msg.Options.VendorClasses(); len(classes) != 1 || classes[0].EnterpriseNumber != 1038 {
+		t.Errorf("VendorClasses() = %v, want enterprise 1038 only", classes)
+	}
+	if msg.GetOneOption(250) == nil {
+		t.Error("Expected raw option 250")
+	}
+	if msg.GetOneOption(dhcpv6.OptionReconfAccept) != nil {
+		t.Error("Unexpected Reconfigure Accept option: RECONFIGURE is not supported")
+	}
+}
+
+func TestDelayedAuthentication(t *testing.T) {
+	auth := &DHCPv6AuthConfig{Protocol: DHCPv6AuthDelayed, Realm: "isp.example", KeyID: 7, Key: []byte("secret")}
+	r := NewDHCPv6PDReceiverWithConfig(DHCPv6PDConfig{Interface: "eth0", Auth: auth})
+
+	// SOLICIT only announces delayed authentication
+	solicit := newTestMessage(t, dhcpv6.MessageTypeSolicit)
+	r.applyClientOptions(solicit)
+	if opt := solicit.GetOneOption(dhcpv6.OptionAuth); opt == nil || len(opt.ToBytes()) != authHeaderLen {
+		t.Fatalf("SOLICIT auth option = %v, want header only", opt)
+	}
+
+	// A message signed with the shared key verifies
+	reply := newTestMessage(t, dhcpv6.MessageTypeReply)
+	r.signDelayed(reply)
+	if err := r.verifyAuthentication(reply); err != nil {
+		t.Fatalf("verifyAuthentication() error = %v", err)
+	}
+
+	// A tampered message does not
+	reply.AddOption(&dhcpv6.OptionGeneric{OptionCode: 250, OptionData: []byte{0x01}})
+	if err := r.verifyAuthentication(reply); err == nil {
+		t.Error("Expected tampered message to fail authentication")
+	}
+
+	// A message signed with another key does not
+	other := NewDHCPv6PDReceiverWithConfig(DHCPv6PDConfig{
+		Interface: "eth0",
+		Auth:      &DHCPv6AuthConfig{Protocol: DHCPv6AuthDelayed, Realm: "isp.example", KeyID: 7, Key: []byte("wrong")},
+	})
+	forged := newTestMessage(t, dhcpv6.MessageTypeReply)
+	other.signDelayed(forged)
+	if err := r.verifyAuthentication(forged); err == nil {
+		t.Error("Expected message signed with another key to fail authentication")
+	}
+}
+
+func TestDelayedAuthenticationRequired(t *testing.T) {
+	auth := &DHCPv6AuthConfig{Protocol: DHCPv6AuthDelayed, Realm: "isp.example", KeyID: 7, Key: []byte("secret")}
+	r := NewDHCPv6PDReceiverWithConfig(DHCPv6PDConfig{Interface: "eth0", Auth: auth})
+
+	// An unsigned REPLY is rejected
+	unsigned := newTestMessage(t, dhcpv6.MessageTypeReply)
+	if err := r.verifyAuthentication(unsigned); err == nil {
+		t.Error("Expected unsigned REPLY to be rejected")
+	}
+
+	// A REPLY with a truncated authentication option is rejected
+	truncated := newTestMessage(t, dhcpv6.MessageTypeReply)
+	truncated.AddOption(&dhcpv6.OptionGeneric{OptionCode: dhcpv6.OptionAuth, OptionData: []byte{authProtocolDelayed}})
+	if err := r.verifyAuthentication(truncated); err == nil {
+		t.Error("Expected REPLY with truncated authentication option to be rejected")
+	}
+
+	// A REPLY using another authentication protocol is rejected
+	otherProtocol := newTestMessage(t, dhcpv6.MessageTypeReply)
+	data := make([]byte, authHeaderLen)
+	data[0] = 3 // reconfigure key protocol
+	otherProtocol.AddOption(&dhcpv6.OptionGeneric{OptionCode: dhcpv6.OptionAuth, OptionData: data})
+	if err := r.verifyAuthentication(otherProtocol); err == nil {
+		t.Error("Expected REPLY using reconfigure-key authentication to be rejected")
+	}
+}
+
+func TestDelayedAuthenticationReplay(t *testing.T) {
+	auth := &DHCPv6AuthConfig{Protocol: DHCPv6AuthDelayed, Realm: "isp.example", KeyID: 7, Key: []byte("secret")}
+	r := NewDHCPv6PDReceiverWithConfig(DHCPv6PDConfig{Interface: "eth0", Auth: auth})
+	server := NewDHCPv6PDReceiverWithConfig(DHCPv6PDConfig{Interface: "eth0", Auth: auth})
+
+	first := newTestMessage(t, dhcpv6.MessageTypeReply)
+	server.signDelayed(first)
+	second := newTestMessage(t, dhcpv6.MessageTypeReply)
+	server.signDelayed(second)
+
+	if err := r.verifyAuthentication(first); err != nil {
+		t.Fatalf("verifyAuthentication(first) error = %v", err)
+	}
+	if err := r.verifyAuthentication(first); err == nil {
+		t.Error("Expected replayed REPLY to be rejected")
+	}
+	if err := r.verifyAuthentication(second); err != nil {
+		t.Fatalf("verifyAuthentication(second) error = %v", err)
+	}
+	if err := r.verifyAuthentication(first); err == nil {
+		t.Error("Expected REPLY with an older replay detection value to be rejected")
+	}
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/insomniacslk/dhcp/dhcpv6/nclient6"
+	"github.com/insomniacslk/dhcp/iana"
+)
+
+// DHCPv6RelayConfig configures a client that relays its own messages.
+type DHCPv6RelayConfig struct {
+	// Destination is the upstream relay agent or server.
+	Destination netip.Addr
+	// LinkAddress identifies the client link; defaults to the first global address of the interface.
+	LinkAddress netip.Addr
+}
+
+// roundTrip sends a message and waits for the matching answer.
+// Messages go to the unicast address if valid, otherwise to the All_DHCP_Relay_Agents_and_Servers
+// multicast group. A server answering a unicast message with UseMulticast is asked again via multicast.
+// In relay mode, every message is relayed to the configured destination.
+func (r *DHCPv6PDReceiver) roundTrip(ctx context.Context, msg *dhcpv6.Message, unicast netip.Addr, match nclient6.Matcher) (*dhcpv6.Message, error) {
+	if r.relay != nil {
+		return r.relayRoundTrip(ctx, msg, match)
+	}
+
+	if !unicast.IsValid() {
+		return r.multicastRoundTrip(ctx, msg, match)
+	}
+
+	reply, err := r.unicastRoundTrip(ctx, msg, unicast, match)
+	if err != nil {
+		return nil, err
+	}
+	if status := reply.Options.Status(); status != nil && status.StatusCode == iana.StatusUseMulticast {
+		return r.multicastRoundTrip(ctx, msg, match)
+	}
+	return reply, nil
+}
+
+// multicastRoundTrip sends a message to All_DHCP_Relay_Agents_and_Servers on the link.
+func (r *DHCPv6PDReceiver) multicastRoundTrip(ctx context.Context, msg *dhcpv6.Message, match nclient6.Matcher) (*dhcpv6.Message, error) {
+	ifi, err := net.InterfaceByName(r.iface)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interface %s: %w", r.iface, err)
+	}
+
+	conn, err := net.ListenUDP("udp6", &net.UDPAddr{Port: dhcpv6.DefaultClientPort, Zone: r.zone(ifi)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind DHCPv6 client port: %w", err)
+	}
+
+	client, err := nclient6.NewWithConn(conn, ifi.HardwareAddr)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to create DHCPv6 client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	return client.SendAndRead(ctx, nclient6.AllDHCPRelayAgentsAndServers, msg, match)
+}
+
+// unicastRoundTrip sends a message directly to a server.
+// Off-link servers are reached from a global address of the interface.
+func (r *DHCPv6PDReceiver) unicastRoundTrip(ctx context.Context, msg *dhcpv6.Message, server netip.Addr, match nclient6.Matcher) (*dhcpv6.Message, error) {
+	ifi, err := net.InterfaceByName(r.iface)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interface %s: %w", r.iface, err)
+	}
+
+	var local net.IP
+	if server.IsLinkLocalUnicast() {
+		local, err = dhcpv6.GetLinkLocalAddr(r.iface)
+	} else {
+		local, err = dhcpv6.GetGlobalAddr(r.iface)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source address on %s: %w", r.iface, err)
+	}
+
+	conn, err := net.ListenUDP("udp6", &net.UDPAddr{IP: local, Port: dhcpv6.DefaultClientPort, Zone: r.zone(ifi)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind DHCPv6 client port: %w", err)
+	}
+
+	client, err := nclient6.NewWithConn(conn, ifi.HardwareAddr)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to create DHCPv6 client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	return client.SendAndRead(ctx, serverUDPAddr(server, r.zone(ifi)), msg, match)
+}
+
+// relayRoundTrip relays a message to the configured destination.
+// Relay agents receive on the server port, so replies arrive there.
+func (r *DHCPv6PDReceiver) relayRoundTrip(ctx context.Context, msg *dhcpv6.Message, match nclient6.Matcher) (*dhcpv6.Message, error) {
+	ifi, err := net.InterfaceByName(r.iface)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interface %s: %w", r.iface, err)
+	}
+
+	linkAddr := net.IP(r.relay.LinkAddress.AsSlice())
+	if !r.relay.LinkAddress.IsValid() {
+		if linkAddr, err = dhcpv6.GetGlobalAddr(r.iface); err != nil {
+			return nil, fmt.Errorf("failed to get link address on %s: %w", r.iface, err)
+		}
+	}
+	peerAddr, err := dhcpv6.GetLinkLocalAddr(r.iface)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link-local address on %s: %w", r.iface, err)
+	}
+
+	conn, err := net.ListenUDP("udp6", &net.UDPAddr{IP: linkAddr, Port: dhcpv6.DefaultServerPort})
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind DHCPv6 relay port: %w", err)
+	}
+
+	relayed := newRelayConn(conn, serverUDPAddr(r.relay.Destination, r.zone(ifi)), linkAddr, peerAddr, []byte(r.iface))
+	client, err := nclient6.NewWithConn(relayed, ifi.HardwareAddr)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to create DHCPv6 client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	return client.SendAndRead(ctx, relayed.destination, msg, match)
+}
+
+// zone returns the IPv6 zone of the interface for the receiver's sockets.
+func (r *DHCPv6PDReceiver) zone(ifi *net.Interface) string {
+	return zonedInterface(r.netns, ifi).Name
+}
+
+// serverUDPAddr returns the DHCPv6 server port on the given address.
+func serverUDPAddr(addr netip.Addr, zone string) *net.UDPAddr {
+	udpAddr := &net.UDPAddr{IP: addr.AsSlice(), Port: dhcpv6.DefaultServerPort}
+	if addr.IsLinkLocalUnicast() {
+		udpAddr.Zone = zone
+	}
+	return udpAddr
+}
+
+// relayConn wraps outgoing client messages in RELAY-FORW and unwraps incoming RELAY-REPL messages,
+// so that an unmodified client can talk to a server through a relay agent.
+type relayConn struct {
+	net.PacketConn
+	destination *net.UDPAddr
+	linkAddr    net.IP
+	peerAddr    net.IP
+	interfaceID []byte
+}
+
+// newRelayConn creates a relaying connection on top of conn.
+func newRelayConn(conn net.PacketConn, destination *net.UDPAddr, linkAddr, peerAddr net.IP, interfaceID []byte) *relayConn {
+	return &relayConn{
+		PacketConn:  conn,
+		destination: destination,
+		linkAddr:    linkAddr,
+		peerAddr:    peerAddr,
+		interfaceID: interfaceID,
+	}
+}
+
+// WriteTo relays the client message to the destination, ignoring addr.
+func (c *relayConn) WriteTo(b []byte, _ net.Addr) (int, error) {
+	msg, err := dhcpv6.FromBytes(b)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse message to relay: %w", err)
+	}
+
+	relay, err := dhcpv6.EncapsulateRelay(msg, dhcpv6.MessageTypeRelayForward, c.linkAddr, c.peerAddr)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create RELAY-FORW: %w", err)
+	}
+	if len(c.interfaceID) > 0 {
+		relay.AddOption(dhcpv6.OptInterfaceID(c.interfaceID))
+	}
+
+	if _, err := c.PacketConn.WriteTo(relay.ToBytes(), c.destination); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// ReadFrom returns the client message carried by the next RELAY-REPL.
+// Anything else is dropped.
+func (c *relayConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := c.PacketConn.ReadFrom(buf)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		msg, err := dhcpv6.FromBytes(buf[:n])
+		if err != nil || msg.Type() != dhcpv6.MessageTypeRelayReply {
+			continue
+		}
+
+		inner, err := dhcpv6.DecapsulateRelay(msg)
+		if err != nil {
+			continue
+		}
+		return copy(b, inner.ToBytes()), addr, nil
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+)
+
+func TestRelayConn(t *testing.T) {
+	server, err := net.ListenUDP("udp6", &net.UDPAddr{IP: net.IPv6loopback})
+	if err != nil {
+		t.Skipf("IPv6 loopback not available: %v", err)
+	}
+	defer func() { _ = server.Close() }()
+
+	local, err := net.ListenUDP("udp6", &net.UDPAddr{IP: net.IPv6loopback})
+	if err != nil {
+		t.Fatalf("ListenUDP() error = %v", err)
+	}
+
+	linkAddr := net.ParseIP("2001:db8::10")
+	peerAddr := net.ParseIP("fe80::10")
+	conn := newRelayConn(local, server.LocalAddr().(*net.UDPAddr), linkAddr, peerAddr, []byte("eth0"))
+	defer func() { _ = conn.Close() }()
+
+	solicit, err := dhcpv6.NewMessage()
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+	solicit.MessageType = dhcpv6.MessageTypeSolicit
+
+	// The destination passed by the client is ignored
+	if _, err := conn.WriteTo(solicit.ToBytes(), &net.UDPAddr{IP: net.ParseIP("ff02::1:2"), Port: 547}); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+
+	_ = server.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 1500)
+	n, from, err := server.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("server ReadFrom() error = %v", err)
+	}
+	received, err := dhcpv6.FromBytes(buf[:n])
+	if err != nil {
+		t.Fatalf("FromBytes() error = %v", err)
+	}
+	forward, ok := received.(*dhcpv6.RelayMessage)
+	if !ok || forward.MessageType != dhcpv6.MessageTypeRelayForward {
+		t.Fatalf("server received %s, want RELAY-FORW", received.Type())
+	}
+	if !forward.LinkAddr.Equal(linkAddr) || !forward.PeerAddr.Equal(peerAddr) {
+		t.Errorf("link/peer = %s/%s, want %s/%s", forward.LinkAddr, forward.PeerAddr, linkAddr, peerAddr)
+	}
+	if forward.GetOneOption(dhcpv6.OptionInterfaceID) == nil {
+		t.Error("Expected Interface-ID option in RELAY-FORW")
+	}
+
+	// Answer with a RELAY-REPL carrying an ADVERTISE
+	advertise, err := dhcpv6.NewMessage()
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+	advertise.MessageType = dhcpv6.MessageTypeAdvertise
+	advertise.TransactionID = solicit.TransactionID
+	reply, err := dhcpv6.NewRelayReplFromRelayForw(forward, advertise)
+	if err != nil {
+		t.Fatalf("NewRelayReplFromRelayForw() error = %v", err)
+	}
+	// Non-relay packets are dropped
+	if _, err := server.WriteTo(advertise.ToBytes(), from); err != nil {
+		t.Fatalf("server WriteTo() error = %v", err)
+	}
+	if _, err := server.WriteTo(reply.ToBytes(), from); err != nil {
+		t.Fatalf("server WriteTo() error = %v", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err = conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+	msg, err := dhcpv6.MessageFromBytes(buf[:n])
+	if err != nil {
+		t.Fatalf("MessageFromBytes() error = %v", err)
+	}
+	if msg.MessageType != dhcpv6.MessageTypeAdvertise || msg.TransactionID != solicit.TransactionID {
+		t.Errorf("got %s (xid %s), want ADVERTISE (xid %s)", msg.MessageType, msg.TransactionID, solicit.TransactionID)
+	}
+}
+
+func TestServerUnicastAddr(t *testing.T) {
+	msg, err := dhcpv6.NewMessage()
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+	if addr := serverUnicastAddr(msg); addr.IsValid() {
+		t.Errorf("serverUnicastAddr() = %v, want invalid", addr)
+	}
+
+	want := netip.MustParseAddr("2001:db8::547")
+	msg.AddOption(&dhcpv6.OptionGeneric{OptionCode: dhcpv6.OptionUnicast, OptionData: want.AsSlice()})
+	if addr := serverUnicastAddr(msg); addr != want {
+		t.Errorf("serverUnicastAddr() = %v, want %v", addr, want)
+	}
+}
+
+func TestDHCPv6PDReceiver_unicastTarget(t *testing.T) {
+	fromOption := netip.MustParseAddr("2001:db8::1")
+	configured := netip.MustParseAddr("2001:db8::2")
+
+	r := NewDHCPv6PDReceiver("eth0", 56)
+	if got := r.unicastTarget(fromOption); got != fromOption {
+		t.Errorf("unicastTarget() = %v, want Server Unicast address %v", got, fromOption)
+	}
+	if got := r.unicastTarget(netip.Addr{}); got.IsValid() {
+		t.Errorf("unicastTarget() = %v, want multicast", got)
+	}
+
+	r = NewDHCPv6PDReceiverWithConfig(DHCPv6PDConfig{Interface: "eth0", ServerAddress: configured})
+	if got := r.unicastTarget(fromOption); got != configured {
+		t.Errorf("unicastTarget() = %v, want configured address %v", got, configured)
+	}
+}
@@ -18,6 +18,7 @@ package prefix
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/netip"
@@ -29,20 +30,65 @@ import (
 	"github.com/insomniacslk/dhcp/iana"
 )
 
+const (
+	// solicitRetryDelay is the delay between SOLICITs while no lease is held
+	solicitRetryDelay = 10 * time.Second
+	// maxSolicitBackoff caps the delay after NoPrefixAvail (SOL_MAX_RT, RFC 8415 section 7.6)
+	maxSolicitBackoff = time.Hour
+)
+
+var (
+	// errNoPrefixAvail is returned when the server has no prefix for the IA_PD
+	errNoPrefixAvail = errors.New("no prefix available")
+	// errNoBinding is returned when the server does not know the IA_PD being renewed
+	errNoBinding = errors.New("no binding")
+	// errPrefixWithdrawn is returned when the server withdraws every prefix with a zero valid lifetime
+	errPrefixWithdrawn = errors.New("prefix withdrawn")
+)
+
 // DHCPv6PDReceiver implements a DHCPv6 Prefix Delegation client.
 // It actively requests prefix delegation from an upstream DHCPv6 server
 // and handles lease renewals.
 type DHCPv6PDReceiver struct {
-	mu                    sync.RWMutex
-	iface                 string
+	mu    sync.RWMutex
+	iface string
+	netns string
+	// macvlan, if set, is the sub-interface of parent that iface names
+	macvlan               *MacvlanConfig
+	parent                string
 	requestedPrefixLength int
-	currentPrefix         *Prefix
-	lease                 *dhcpv6Lease
-	events                chan Event
-	stopCh                chan struct{}
-	started               bool
-	ctx                   context.Context
-	cancel                context.CancelFunc
+	serverAddress         netip.Addr
+	relay                 *DHCPv6RelayConfig
+	options               []dhcpv6.Option
+	auth                  *DHCPv6AuthConfig
+	replayCounter         uint64
+	// serverReplayCounter is the highest replay detection value accepted from the server
+	serverReplayCounter uint64
+	serverReplaySeen    bool
+	// prefixHint is asked for in the SOLICIT, updated with every delegated prefix
+	prefixHint         netip.Prefix
+	currentPrefix      *Prefix
+	lease              *dhcpv6Lease
+	events             chan Event
+	stopCh             chan struct{}
+	started            bool
+	ctx                context.Context
+	cancel             context.CancelFunc
+	lastExchange       string
+	lastExchangeTime   time.Time
+	lastExchangeResult string
+	failures           failureTracker
+	// solicitBackoff is the delay before the next SOLICIT after NoPrefixAvail, zero otherwise
+	solicitBackoff time.Duration
+	// resolicit is set when the lease can no longer be extended: its prefix stays
+	// in use until the valid lifetime ends, while SOLICITs look for a new lease
+	resolicit bool
+
+	// registry, if set, provides the client shared with other receivers on the interface
+	registry *Registry
+	owner    string
+	client   *dhcpv6Client
+	iaid     [4]byte
 }
 
 // dhcpv6Lease contains DHCPv6-PD lease information.
@@ -55,20 +101,64 @@ type dhcpv6Lease struct {
 	PreferredLifetime time.Duration
 	ReceivedAt        time.Time
 	ServerID          dhcpv6.DUID
+	// ServerUnicast is the address from the server's Server Unicast option, if any
+	ServerUnicast netip.Addr
+	// Config is the configuration data the server sent with the lease, if any
+	Config *NetworkConfig
+}
+
+// DHCPv6PDConfig configures a DHCPv6-PD receiver.
+type DHCPv6PDConfig struct {
+	// Interface is the interface to request the prefix on.
+	Interface string
+	// NetNS is the path of the network namespace of Interface, empty for the operator's own.
+	NetNS string
+	// RequestedPrefixLength is a hint to the server (typically 48-64).
+	RequestedPrefixLength int
+	// ServerAddress, if valid, receives all messages instead of the multicast group.
+	ServerAddress netip.Addr
+	// Relay, if set, relays all messages to a server or relay agent on another link.
+	Relay *DHCPv6RelayConfig
+	// Options are added to every SOLICIT, REQUEST, RENEW and REBIND.
+	Options []dhcpv6.Option
+	// Auth, if set, authenticates the exchanges.
+	Auth *DHCPv6AuthConfig
+	// Macvlan, if set, runs the client on a macvlan sub-interface of Interface.
+	Macvlan *MacvlanConfig
 }
 
 // NewDHCPv6PDReceiver creates a new DHCPv6-PD receiver for the given interface.
 // The requestedPrefixLength is a hint to the server (typically 48-64).
 func NewDHCPv6PDReceiver(iface string, requestedPrefixLength int) *DHCPv6PDReceiver {
-	if requestedPrefixLength == 0 {
-		requestedPrefixLength = 56 // Common default
-	}
-	return &DHCPv6PDReceiver{
-		iface:                 iface,
-		requestedPrefixLength: requestedPrefixLength,
+	return NewDHCPv6PDReceiverWithConfig(DHCPv6PDConfig{
+		Interface:             iface,
+		RequestedPrefixLength: requestedPrefixLength,
+	})
+}
+
+// NewDHCPv6PDReceiverWithConfig creates a new DHCPv6-PD receiver from the given configuration.
+func NewDHCPv6PDReceiverWithConfig(cfg DHCPv6PDConfig) *DHCPv6PDReceiver {
+	if cfg.RequestedPrefixLength == 0 {
+		cfg.RequestedPrefixLength = 56 // Common default
+	}
+	r := &DHCPv6PDReceiver{
+		iface:                 cfg.Interface,
+		netns:                 cfg.NetNS,
+		requestedPrefixLength: cfg.RequestedPrefixLength,
+		serverAddress:         cfg.ServerAddress,
+		relay:                 cfg.Relay,
+		options:               cfg.Options,
+		auth:                  cfg.Auth,
 		events:                make(chan Event, 10),
 		stopCh:                make(chan struct{}),
 	}
+	if cfg.Macvlan != nil {
+		// All sockets use the sub-interface
+		r.macvlan = cfg.Macvlan
+		r.parent = cfg.Interface
+		r.iface = cfg.Macvlan.Name
+	}
+	return r
 }
 
 // Start begins the DHCPv6-PD client, acquiring a prefix and managing renewals.
@@ -80,6 +170,17 @@ func (r *DHCPv6PDReceiver) Start(ctx context.Context) error {
 		return nil
 	}
 
+	if r.registry != nil {
+		client, iaid, err := r.registry.acquireDHCPv6Client(linkKey(r.netns, r.iface), r.owner)
+		if err != nil {
+			return err
+		}
+		r.client, r.iaid = client, iaid
+		if r.macvlan != nil {
+			r.registry.acquireMacvlan(linkKey(r.netns, r.macvlan.Name))
+		}
+	}
+
 	r.ctx, r.cancel = context.WithCancel(ctx)
 	r.started = true
 
@@ -104,6 +205,17 @@ func (r *DHCPv6PDReceiver) Stop() error {
 	}
 	close(r.stopCh)
 
+	if r.client != nil {
+		r.registry.releaseDHCPv6Client(linkKey(r.netns, r.iface), r.owner)
+		r.client = nil
+	}
+
+	if r.macvlan != nil && (r.registry == nil || r.registry.releaseMacvlan(linkKey(r.netns, r.macvlan.Name))) {
+		return inNetNS(r.netns, func() error {
+			return deleteMacvlan(r.macvlan.Name)
+		})
+	}
+
 	return nil
 }
 
@@ -124,12 +236,79 @@ func (r *DHCPv6PDReceiver) Source() Source {
 	return SourceDHCPv6PD
 }
 
+// Diagnostics returns the outcome of the last DHCPv6 exchange and the current lease timers.
+func (r *DHCPv6PDReceiver) Diagnostics() []Diagnostics {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d := Diagnostics{
+		Name:               string(SourceDHCPv6PD),
+		Source:             SourceDHCPv6PD,
+		Active:             r.currentPrefix != nil,
+		Interface:          r.iface,
+		LastExchange:       r.lastExchange,
+		LastExchangeTime:   r.lastExchangeTime,
+		LastExchangeResult: r.lastExchangeResult,
+	}
+	if r.lease != nil {
+		if r.lease.ServerID != nil {
+			d.ServerDUID = r.lease.ServerID.String()
+		}
+		d.T1 = r.lease.T1
+		d.T2 = r.lease.T2
+	}
+	r.failures.fill(&d)
+	return []Diagnostics{d}
+}
+
+// exchange runs a DHCPv6 exchange and records its outcome for diagnostics.
+// Exchanges on a shared client are serialized with the other IA_PDs on the interface.
+func (r *DHCPv6PDReceiver) exchange(kind string, fn func() error) error {
+	r.mu.RLock()
+	client := r.client
+	r.mu.RUnlock()
+
+	if client != nil {
+		client.exchangeMu.Lock()
+	}
+	// Interface lookups and sockets belong to the receiver's namespace
+	err := inNetNS(r.netns, func() error {
+		if err := r.ensureInterface(); err != nil {
+			return err
+		}
+		return fn()
+	})
+	if client != nil {
+		client.exchangeMu.Unlock()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastExchange = kind
+	r.lastExchangeTime = time.Now()
+	if err != nil {
+		r.lastExchangeResult = err.Error()
+	} else {
+		r.lastExchangeResult = "success"
+		r.failures.recordSuccess()
+	}
+	return err
+}
+
+// ensureInterface recreates the macvlan sub-interface, if one is configured
+// and it was removed or brought down since the last exchange.
+func (r *DHCPv6PDReceiver) ensureInterface() error {
+	if r.macvlan == nil {
+		return nil
+	}
+	return ensureMacvlan(r.parent, *r.macvlan)
+}
+
 // runLoop handles prefix acquisition and renewal.
 func (r *DHCPv6PDReceiver) runLoop() {
 	// Initial acquisition
-	if err := r.acquirePrefix(); err != nil {
-		r.sendError(fmt.Errorf("initial prefix acquisition failed: %w", err))
-	}
+	r.acquire()
 
 	for {
 		select {
@@ -142,14 +321,32 @@ func (r *DHCPv6PDReceiver) runLoop() {
 
 		r.mu.RLock()
 		lease := r.lease
+		resolicit := r.resolicit
 		r.mu.RUnlock()
 
-		if lease == nil {
-			// No lease, try to acquire
-			time.Sleep(10 * time.Second)
-			if err := r.acquirePrefix(); err != nil {
-				r.sendError(fmt.Errorf("prefix acquisition failed: %w", err))
+		if lease == nil || resolicit {
+			// No lease, or one that cannot be extended: try to acquire,
+			// but give up a held prefix when its valid lifetime ends
+			delay := max(r.solicitBackoff, solicitRetryDelay)
+			if lease != nil {
+				remaining := time.Until(lease.ReceivedAt.Add(lease.ValidLifetime))
+				if remaining <= 0 {
+					r.dropLease()
+					continue
+				}
+				delay = min(delay, remaining)
+			}
+			select {
+			case <-r.stopCh:
+				return
+			case <-r.ctx.Done():
+				return
+			case <-time.After(delay):
 			}
+			if lease != nil && !time.Now().Before(lease.ReceivedAt.Add(lease.ValidLifetime)) {
+				continue
+			}
+			r.acquire()
 			continue
 		}
 
@@ -159,21 +356,8 @@ func (r *DHCPv6PDReceiver) runLoop() {
 
 		// Renew at T1 (typically 50% of valid lifetime)
 		if elapsed >= lease.T1 {
-			if err := r.renewPrefix(); err != nil {
-				r.sendError(fmt.Errorf("prefix renewal failed: %w", err))
-				// If T2 has passed, try rebind
-				if elapsed >= lease.T2 {
-					if err := r.rebindPrefix(); err != nil {
-						r.sendError(fmt.Errorf("prefix rebind failed: %w", err))
-						// Lease expired, clear and reacquire
-						r.mu.Lock()
-						r.currentPrefix = nil
-						r.lease = nil
-						r.mu.Unlock()
-						r.sendEvent(EventTypeExpired, nil)
-					}
-				}
-			}
+			// If T2 has passed, rebind when the renewal fails
+			r.extendLease(elapsed >= lease.T2)
 			continue
 		}
 
@@ -193,6 +377,81 @@ func (r *DHCPv6PDReceiver) runLoop() {
 	}
 }
 
+// acquire runs a SOLICIT exchange. While servers answer NoPrefixAvail,
+// the delay before the next SOLICIT doubles up to maxSolicitBackoff.
+func (r *DHCPv6PDReceiver) acquire() {
+	err := r.exchange("solicit", r.acquirePrefix)
+	if err == nil {
+		r.solicitBackoff = 0
+		return
+	}
+	if noPrefixDelegated(err) {
+		r.backOff()
+	}
+	r.sendError(fmt.Errorf("prefix acquisition failed: %w", err))
+}
+
+// extendLease renews the lease, and rebinds it if rebind is set and the renewal failed.
+// If the lease cannot be extended or the server has no prefix left for us,
+// the run loop starts over with a SOLICIT; see leaseNotExtended.
+func (r *DHCPv6PDReceiver) extendLease(rebind bool) {
+	err := r.exchange("renew", r.renewPrefix)
+	if err == nil {
+		return
+	}
+	r.sendError(fmt.Errorf("prefix renewal failed: %w", err))
+
+	if !noPrefixDelegated(err) {
+		if !rebind {
+			return
+		}
+		if err = r.exchange("rebind", r.rebindPrefix); err == nil {
+			return
+		}
+		r.sendError(fmt.Errorf("prefix rebind failed: %w", err))
+	}
+
+	r.leaseNotExtended(err)
+}
+
+// leaseNotExtended handles a lease that RENEW and REBIND could not extend.
+// A prefix the server withdrew with a zero valid lifetime is dropped at once.
+// Otherwise, such as on a NoPrefixAvail status while the server is temporarily
+// out of prefixes, the prefix stays in use until its valid lifetime ends, while
+// the run loop SOLICITs for a new lease.
+func (r *DHCPv6PDReceiver) leaseNotExtended(err error) {
+	if noPrefixDelegated(err) {
+		r.backOff()
+	}
+	if errors.Is(err, errPrefixWithdrawn) {
+		r.dropLease()
+		return
+	}
+	r.mu.Lock()
+	r.resolicit = true
+	r.mu.Unlock()
+}
+
+// dropLease forgets the lease and its prefix, so that the run loop starts over with a SOLICIT.
+func (r *DHCPv6PDReceiver) dropLease() {
+	r.mu.Lock()
+	r.currentPrefix = nil
+	r.lease = nil
+	r.resolicit = false
+	r.mu.Unlock()
+	r.sendEvent(EventTypeExpired, nil)
+}
+
+// noPrefixDelegated returns true if the server answered but delegated no prefix.
+func noPrefixDelegated(err error) bool {
+	return errors.Is(err, errNoPrefixAvail) || errors.Is(err, errPrefixWithdrawn)
+}
+
+// backOff doubles the delay before the next SOLICIT.
+func (r *DHCPv6PDReceiver) backOff() {
+	r.solicitBackoff = min(max(2*r.solicitBackoff, solicitRetryDelay), maxSolicitBackoff)
+}
+
 // acquirePrefix performs initial prefix acquisition using SOLICIT-ADVERTISE-REQUEST-REPLY.
 func (r *DHCPv6PDReceiver) acquirePrefix() error {
 	ifi, err := net.InterfaceByName(r.iface)
@@ -200,22 +459,9 @@ func (r *DHCPv6PDReceiver) acquirePrefix() error {
 		return fmt.Errorf("failed to get interface %s: %w", r.iface, err)
 	}
 
-	// Create a new DHCPv6 client
-	client, err := nclient6.New(r.iface)
-	if err != nil {
-		return fmt.Errorf("failed to create DHCPv6 client: %w", err)
-	}
-	defer func() { _ = client.Close() }()
-
-	// Generate IAID from interface index
-	iaid := [4]byte{
-		byte(ifi.Index >> 24),
-		byte(ifi.Index >> 16),
-		byte(ifi.Index >> 8),
-		byte(ifi.Index),
-	}
+	iaid := r.identityAssociationID(ifi)
 
-	// Create IA_PD option with prefix hint
+	// Create IA_PD option with prefix hint, asking for the previous prefix if known
 	iaPD := &dhcpv6.OptIAPD{
 		IaId: iaid,
 		Options: dhcpv6.PDOptions{
@@ -223,10 +469,7 @@ func (r *DHCPv6PDReceiver) acquirePrefix() error {
 				&dhcpv6.OptIAPrefix{
 					PreferredLifetime: 0,
 					ValidLifetime:     0,
-					Prefix: &net.IPNet{
-						IP:   net.IPv6zero,
-						Mask: net.CIDRMask(r.requestedPrefixLength, 128),
-					},
+					Prefix:            r.solicitedPrefix(),
 				},
 			},
 		},
@@ -235,9 +478,7 @@ func (r *DHCPv6PDReceiver) acquirePrefix() error {
 	// Build SOLICIT message with IA_PD
 	solicitMods := []dhcpv6.Modifier{
 		dhcpv6.WithClientID(r.generateDUID(ifi)),
-		dhcpv6.WithRequestedOptions(
-			dhcpv6.OptionDNSRecursiveNameServer,
-		),
+		dhcpv6.WithRequestedOptions(requestedOptions...),
 	}
 
 	// Perform 4-message exchange
@@ -250,18 +491,26 @@ func (r *DHCPv6PDReceiver) acquirePrefix() error {
 		return fmt.Errorf("failed to create SOLICIT: %w", err)
 	}
 	solicit.AddOption(iaPD)
+	r.applyClientOptions(solicit)
 
 	// Send SOLICIT and receive ADVERTISE
-	advertise, err := client.SendAndRead(ctx, nclient6.AllDHCPRelayAgentsAndServers, solicit, nclient6.IsMessageType(dhcpv6.MessageTypeAdvertise))
+	advertise, err := r.roundTrip(ctx, solicit, r.serverAddress, nclient6.IsMessageType(dhcpv6.MessageTypeAdvertise))
 	if err != nil {
 		return fmt.Errorf("failed to receive ADVERTISE: %w", err)
 	}
 
+	if err := r.verifyAuthentication(advertise); err != nil {
+		return err
+	}
+
 	// Check for IA_PD in ADVERTISE
-	advIAPD := advertise.GetOneOption(dhcpv6.OptionIAPD)
+	advIAPD := advertise.Options.OneIAPD()
 	if advIAPD == nil {
 		return fmt.Errorf("ADVERTISE did not contain IA_PD")
 	}
+	if err := iaPDStatusError(advIAPD); err != nil {
+		return fmt.Errorf("ADVERTISE: %w", err)
+	}
 
 	// Get Server ID
 	serverID := advertise.Options.ServerID()
@@ -269,20 +518,46 @@ func (r *DHCPv6PDReceiver) acquirePrefix() error {
 		return fmt.Errorf("ADVERTISE did not contain Server ID")
 	}
 
-	// Build REQUEST message
-	request, err := dhcpv6.NewRequestFromAdvertise(advertise)
+	// Build REQUEST message for the advertised IA_PD
+	request, err := r.newRequest(ifi, serverID, advIAPD)
 	if err != nil {
-		return fmt.Errorf("failed to create REQUEST: %w", err)
+		return err
 	}
 
 	// Send REQUEST and receive REPLY
-	reply, err := client.SendAndRead(ctx, nclient6.AllDHCPRelayAgentsAndServers, request, nclient6.IsMessageType(dhcpv6.MessageTypeReply))
+	reply, err := r.roundTrip(ctx, request, r.unicastTarget(serverUnicastAddr(advertise)), nclient6.IsMessageType(dhcpv6.MessageTypeReply))
 	if err != nil {
 		return fmt.Errorf("failed to receive REPLY: %w", err)
 	}
 
 	// Extract IA_PD from REPLY
-	return r.processIAPDReply(reply, iaid, serverID)
+	return r.processIAPDReply(reply, iaid, serverID, advertise)
+}
+
+// SetPrefixHint sets the prefix to ask for in the SOLICIT.
+func (r *DHCPv6PDReceiver) SetPrefixHint(hint netip.Prefix) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prefixHint = hint
+}
+
+// solicitedPrefix returns the IA_Prefix hint for a SOLICIT.
+// The previous prefix is asked for at the requested length; without one, only the length is sent.
+func (r *DHCPv6PDReceiver) solicitedPrefix() *net.IPNet {
+	r.mu.RLock()
+	hint := r.prefixHint
+	r.mu.RUnlock()
+
+	ip := net.IPv6zero
+	if hint.IsValid() && hint.Addr().Is6() {
+		if p, err := hint.Addr().Prefix(r.requestedPrefixLength); err == nil {
+			ip = p.Addr().AsSlice()
+		}
+	}
+	return &net.IPNet{
+		IP:   ip,
+		Mask: net.CIDRMask(r.requestedPrefixLength, 128),
+	}
 }
 
 // renewPrefix sends a RENEW message to extend the lease.
@@ -300,12 +575,6 @@ func (r *DHCPv6PDReceiver) renewPrefix() error {
 		return fmt.Errorf("failed to get interface %s: %w", r.iface, err)
 	}
 
-	client, err := nclient6.New(r.iface)
-	if err != nil {
-		return fmt.Errorf("failed to create DHCPv6 client: %w", err)
-	}
-	defer func() { _ = client.Close() }()
-
 	// Build RENEW message
 	renew, err := dhcpv6.NewMessage()
 	if err != nil {
@@ -315,37 +584,27 @@ func (r *DHCPv6PDReceiver) renewPrefix() error {
 
 	renew.AddOption(dhcpv6.OptClientID(r.generateDUID(ifi)))
 	renew.AddOption(dhcpv6.OptServerID(lease.ServerID))
+	dhcpv6.WithRequestedOptions(requestedOptions...)(renew)
 
 	// Add current IA_PD
-	ip := lease.Prefix.Addr().AsSlice()
-	bits := lease.Prefix.Bits()
-	iaPD := &dhcpv6.OptIAPD{
-		IaId: lease.IAID,
-		Options: dhcpv6.PDOptions{
-			Options: dhcpv6.Options{
-				&dhcpv6.OptIAPrefix{
-					PreferredLifetime: lease.PreferredLifetime,
-					ValidLifetime:     lease.ValidLifetime,
-					Prefix: &net.IPNet{
-						IP:   ip,
-						Mask: net.CIDRMask(bits, 128),
-					},
-				},
-			},
-		},
-	}
-	renew.AddOption(iaPD)
+	renew.AddOption(lease.iaPD())
+	r.applyClientOptions(renew)
 
 	// Send RENEW and receive REPLY
 	ctx, cancel := context.WithTimeout(r.ctx, 30*time.Second)
 	defer cancel()
 
-	reply, err := client.SendAndRead(ctx, nclient6.AllDHCPRelayAgentsAndServers, renew, nclient6.IsMessageType(dhcpv6.MessageTypeReply))
+	reply, err := r.roundTrip(ctx, renew, r.unicastTarget(lease.ServerUnicast), nclient6.IsMessageType(dhcpv6.MessageTypeReply))
 	if err != nil {
 		return fmt.Errorf("failed to receive REPLY for RENEW: %w", err)
 	}
 
-	return r.processIAPDReply(reply, lease.IAID, lease.ServerID)
+	err = r.processIAPDReply(reply, lease.IAID, lease.ServerID, nil)
+	if errors.Is(err, errNoBinding) {
+		// The server lost our binding: ask for the same prefix again (RFC 8415 section 18.2.10.1)
+		return r.requestBinding(ifi, lease, lease.ServerID, r.unicastTarget(lease.ServerUnicast))
+	}
+	return err
 }
 
 // rebindPrefix sends a REBIND message when the server is unreachable.
@@ -363,12 +622,6 @@ func (r *DHCPv6PDReceiver) rebindPrefix() error {
 		return fmt.Errorf("failed to get interface %s: %w", r.iface, err)
 	}
 
-	client, err := nclient6.New(r.iface)
-	if err != nil {
-		return fmt.Errorf("failed to create DHCPv6 client: %w", err)
-	}
-	defer func() { _ = client.Close() }()
-
 	// Build REBIND message (no server ID)
 	rebind, err := dhcpv6.NewMessage()
 	if err != nil {
@@ -377,32 +630,18 @@ func (r *DHCPv6PDReceiver) rebindPrefix() error {
 	rebind.MessageType = dhcpv6.MessageTypeRebind
 
 	rebind.AddOption(dhcpv6.OptClientID(r.generateDUID(ifi)))
+	dhcpv6.WithRequestedOptions(requestedOptions...)(rebind)
 
 	// Add current IA_PD
-	ip := lease.Prefix.Addr().AsSlice()
-	bits := lease.Prefix.Bits()
-	iaPD := &dhcpv6.OptIAPD{
-		IaId: lease.IAID,
-		Options: dhcpv6.PDOptions{
-			Options: dhcpv6.Options{
-				&dhcpv6.OptIAPrefix{
-					PreferredLifetime: lease.PreferredLifetime,
-					ValidLifetime:     lease.ValidLifetime,
-					Prefix: &net.IPNet{
-						IP:   ip,
-						Mask: net.CIDRMask(bits, 128),
-					},
-				},
-			},
-		},
-	}
-	rebind.AddOption(iaPD)
+	rebind.AddOption(lease.iaPD())
+	r.applyClientOptions(rebind)
 
 	// Send REBIND and receive REPLY
 	ctx, cancel := context.WithTimeout(r.ctx, 30*time.Second)
 	defer cancel()
 
-	reply, err := client.SendAndRead(ctx, nclient6.AllDHCPRelayAgentsAndServers, rebind, nclient6.IsMessageType(dhcpv6.MessageTypeReply))
+	// REBIND goes to any server, so the Server Unicast option no longer applies
+	reply, err := r.roundTrip(ctx, rebind, r.serverAddress, nclient6.IsMessageType(dhcpv6.MessageTypeReply))
 	if err != nil {
 		return fmt.Errorf("failed to receive REPLY for REBIND: %w", err)
 	}
@@ -413,11 +652,93 @@ func (r *DHCPv6PDReceiver) rebindPrefix() error {
 		return fmt.Errorf("REPLY did not contain Server ID")
 	}
 
-	return r.processIAPDReply(reply, lease.IAID, serverID)
+	err = r.processIAPDReply(reply, lease.IAID, serverID, nil)
+	if errors.Is(err, errNoBinding) {
+		// The answering server has no binding: ask it for the same prefix (RFC 8415 section 18.2.10.1)
+		return r.requestBinding(ifi, lease, serverID, r.unicastTarget(serverUnicastAddr(reply)))
+	}
+	return err
 }
 
-// processIAPDReply extracts the delegated prefix from a DHCPv6 REPLY.
-func (r *DHCPv6PDReceiver) processIAPDReply(reply *dhcpv6.Message, expectedIAID [4]byte, serverID dhcpv6.DUID) error {
+// requestBinding sends a REQUEST for the prefix of the lease to a server that has no binding for it.
+func (r *DHCPv6PDReceiver) requestBinding(ifi *net.Interface, lease *dhcpv6Lease, serverID dhcpv6.DUID, target netip.Addr) error {
+	request, err := r.newRequest(ifi, serverID, lease.iaPD())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, 30*time.Second)
+	defer cancel()
+
+	reply, err := r.roundTrip(ctx, request, target, nclient6.IsMessageType(dhcpv6.MessageTypeReply))
+	if err != nil {
+		return fmt.Errorf("failed to receive REPLY for REQUEST: %w", err)
+	}
+
+	return r.processIAPDReply(reply, lease.IAID, serverID, nil)
+}
+
+// newRequest builds a REQUEST for the given IA_PD, addressed to the given server.
+func (r *DHCPv6PDReceiver) newRequest(ifi *net.Interface, serverID dhcpv6.DUID, iaPD *dhcpv6.OptIAPD) (*dhcpv6.Message, error) {
+	request, err := dhcpv6.NewMessage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create REQUEST: %w", err)
+	}
+	request.MessageType = dhcpv6.MessageTypeRequest
+
+	request.AddOption(dhcpv6.OptClientID(r.generateDUID(ifi)))
+	request.AddOption(dhcpv6.OptServerID(serverID))
+	request.AddOption(dhcpv6.OptElapsedTime(0))
+	dhcpv6.WithRequestedOptions(requestedOptions...)(request)
+	request.AddOption(iaPD)
+	r.applyClientOptions(request)
+
+	return request, nil
+}
+
+// iaPD returns an IA_PD option carrying the lease's prefix.
+func (l *dhcpv6Lease) iaPD() *dhcpv6.OptIAPD {
+	return &dhcpv6.OptIAPD{
+		IaId: l.IAID,
+		Options: dhcpv6.PDOptions{
+			Options: dhcpv6.Options{
+				&dhcpv6.OptIAPrefix{
+					PreferredLifetime: l.PreferredLifetime,
+					ValidLifetime:     l.ValidLifetime,
+					Prefix: &net.IPNet{
+						IP:   l.Prefix.Addr().AsSlice(),
+						Mask: net.CIDRMask(l.Prefix.Bits(), 128),
+					},
+				},
+			},
+		},
+	}
+}
+
+// iaPDStatusError returns the error reported by the status code of an IA_PD, if any.
+// NoPrefixAvail and NoBinding wrap errNoPrefixAvail and errNoBinding.
+func iaPDStatusError(iaPD *dhcpv6.OptIAPD) error {
+	status := iaPD.Options.Status()
+	if status == nil || status.StatusCode == iana.StatusSuccess {
+		return nil
+	}
+	switch status.StatusCode {
+	case iana.StatusNoPrefixAvail:
+		return fmt.Errorf("IA_PD status: %w: %s", errNoPrefixAvail, status.StatusMessage)
+	case iana.StatusNoBinding:
+		return fmt.Errorf("IA_PD status: %w: %s", errNoBinding, status.StatusMessage)
+	default:
+		return fmt.Errorf("IA_PD status error: %s - %s", status.StatusCode, status.StatusMessage)
+	}
+}
+
+// processIAPDReply extracts the delegated prefix and configuration data from a DHCPv6 REPLY.
+// The ADVERTISE that preceded the REPLY, if any, supplies configuration data missing from the REPLY.
+func (r *DHCPv6PDReceiver) processIAPDReply(reply *dhcpv6.Message, expectedIAID [4]byte, serverID dhcpv6.DUID, advertise *dhcpv6.Message) error {
+	if err := r.verifyAuthentication(reply); err != nil {
+		return err
+	}
+
 	// Find IA_PD option
 	var iaPD *dhcpv6.OptIAPD
 	for _, opt := range reply.Options.Get(dhcpv6.OptionIAPD) {
@@ -433,8 +754,8 @@ func (r *DHCPv6PDReceiver) processIAPDReply(reply *dhcpv6.Message, expectedIAID
 	}
 
 	// Check for status code indicating error
-	if status := iaPD.Options.Status(); status != nil && status.StatusCode != iana.StatusSuccess {
-		return fmt.Errorf("IA_PD status error: %s - %s", status.StatusCode, status.StatusMessage)
+	if err := iaPDStatusError(iaPD); err != nil {
+		return err
 	}
 
 	// Extract prefix information
@@ -443,27 +764,32 @@ func (r *DHCPv6PDReceiver) processIAPDReply(reply *dhcpv6.Message, expectedIAID
 		return fmt.Errorf("IA_PD did not contain any prefixes")
 	}
 
-	// Use the first valid prefix
+	r.mu.RLock()
+	var held netip.Prefix
+	if r.lease != nil {
+		held = r.lease.Prefix
+	}
+	r.mu.RUnlock()
+
+	// Prefixes with a zero valid lifetime are withdrawn (RFC 8415 section 18.2.10.1),
+	// e.g. when the ISP rotates the prefix during RENEW. Of the others, keep the held
+	// prefix if it is still delegated, else take the first.
 	var bestPrefix *dhcpv6.OptIAPrefix
+	var prefix netip.Prefix
 	for _, p := range prefixes {
-		if p.ValidLifetime > 0 {
-			bestPrefix = p
-			break
+		network, ok := iaPrefixNetwork(p)
+		if !ok || p.ValidLifetime == 0 {
+			continue
+		}
+		if bestPrefix == nil || network == held {
+			bestPrefix, prefix = p, network
 		}
 	}
 
 	if bestPrefix == nil {
-		return fmt.Errorf("no valid prefix in IA_PD")
+		return fmt.Errorf("%w: all prefixes in IA_PD have a zero valid lifetime", errPrefixWithdrawn)
 	}
 
-	// Convert to netip.Prefix
-	addr, ok := netip.AddrFromSlice(bestPrefix.Prefix.IP)
-	if !ok {
-		return fmt.Errorf("invalid prefix address")
-	}
-	ones, _ := bestPrefix.Prefix.Mask.Size()
-	prefix := netip.PrefixFrom(addr, ones)
-
 	// Calculate T1/T2 from IA_PD or use defaults
 	t1 := iaPD.T1
 	t2 := iaPD.T2
@@ -474,6 +800,8 @@ func (r *DHCPv6PDReceiver) processIAPDReply(reply *dhcpv6.Message, expectedIAID
 		t2 = bestPrefix.ValidLifetime * 4 / 5 // Default: 80%
 	}
 
+	config := networkConfigFrom(reply, advertise)
+
 	now := time.Now()
 	newLease := &dhcpv6Lease{
 		IAID:              expectedIAID,
@@ -484,9 +812,15 @@ func (r *DHCPv6PDReceiver) processIAPDReply(reply *dhcpv6.Message, expectedIAID
 		PreferredLifetime: bestPrefix.PreferredLifetime,
 		ReceivedAt:        now,
 		ServerID:          serverID,
+		ServerUnicast:     serverUnicastAddr(reply),
 	}
 
 	r.mu.Lock()
+	if config == nil && r.lease != nil {
+		// Servers may leave configuration out of RENEW replies; keep what we have
+		config = r.lease.Config
+	}
+	newLease.Config = config
 	oldPrefix := r.currentPrefix
 	r.currentPrefix = &Prefix{
 		Network:           prefix,
@@ -494,8 +828,11 @@ func (r *DHCPv6PDReceiver) processIAPDReply(reply *dhcpv6.Message, expectedIAID
 		PreferredLifetime: bestPrefix.PreferredLifetime,
 		Source:            SourceDHCPv6PD,
 		ReceivedAt:        now,
+		Config:            config,
 	}
 	r.lease = newLease
+	r.resolicit = false
+	r.prefixHint = newLease.Prefix
 	r.mu.Unlock()
 
 	// Determine event type
@@ -508,10 +845,67 @@ func (r *DHCPv6PDReceiver) processIAPDReply(reply *dhcpv6.Message, expectedIAID
 		eventType = EventTypeRenewed
 	}
 
-	r.sendEvent(eventType, r.currentPrefix)
+	event := Event{Type: eventType, Prefix: r.currentPrefix}
+	if eventType == EventTypeChanged {
+		event.PreviousPrefix = oldPrefix
+	}
+	r.emit(event)
 	return nil
 }
 
+// iaPrefixNetwork converts the prefix of an IA_Prefix option.
+func iaPrefixNetwork(p *dhcpv6.OptIAPrefix) (netip.Prefix, bool) {
+	if p.Prefix == nil {
+		return netip.Prefix{}, false
+	}
+	addr, ok := netip.AddrFromSlice(p.Prefix.IP)
+	if !ok {
+		return netip.Prefix{}, false
+	}
+	ones, _ := p.Prefix.Mask.Size()
+	return netip.PrefixFrom(addr, ones), true
+}
+
+// unicastTarget returns where to send messages addressed to the lease's server:
+// the configured server address, else the address from the Server Unicast option.
+func (r *DHCPv6PDReceiver) unicastTarget(serverUnicast netip.Addr) netip.Addr {
+	if r.serverAddress.IsValid() {
+		return r.serverAddress
+	}
+	return serverUnicast
+}
+
+// serverUnicastAddr returns the address from the Server Unicast option of a message, if present.
+func serverUnicastAddr(msg *dhcpv6.Message) netip.Addr {
+	opt := msg.GetOneOption(dhcpv6.OptionUnicast)
+	if opt == nil {
+		return netip.Addr{}
+	}
+	addr, ok := netip.AddrFromSlice(opt.ToBytes())
+	if !ok || !addr.Is6() {
+		return netip.Addr{}
+	}
+	return addr
+}
+
+// identityAssociationID returns the IAID of this receiver's IA_PD.
+// Receivers on a shared client use the IAID reserved in the registry,
+// standalone receivers derive it from the interface index.
+func (r *DHCPv6PDReceiver) identityAssociationID(ifi *net.Interface) [4]byte {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.client != nil {
+		return r.iaid
+	}
+	return [4]byte{
+		byte(ifi.Index >> 24),
+		byte(ifi.Index >> 16),
+		byte(ifi.Index >> 8),
+		byte(ifi.Index),
+	}
+}
+
 // generateDUID generates a DUID-LL based on the interface's hardware address.
 func (r *DHCPv6PDReceiver) generateDUID(ifi *net.Interface) dhcpv6.DUID {
 	return &dhcpv6.DUIDLL{
@@ -522,15 +916,24 @@ func (r *DHCPv6PDReceiver) generateDUID(ifi *net.Interface) dhcpv6.DUID {
 
 // sendEvent sends a prefix event.
 func (r *DHCPv6PDReceiver) sendEvent(eventType EventType, prefix *Prefix) {
+	r.emit(Event{Type: eventType, Prefix: prefix})
+}
+
+// emit sends an event without blocking.
+func (r *DHCPv6PDReceiver) emit(event Event) {
 	select {
-	case r.events <- Event{Type: eventType, Prefix: prefix}:
+	case r.events <- event:
 	default:
 		// Channel full, event dropped
 	}
 }
 
-// sendError sends a failed event.
+// sendError records the failure and sends a failed event.
 func (r *DHCPv6PDReceiver) sendError(err error) {
+	r.mu.Lock()
+	r.failures.recordFailure(err)
+	r.mu.Unlock()
+
 	select {
 	case r.events <- Event{Type: EventTypeFailed, Error: err}:
 	default:
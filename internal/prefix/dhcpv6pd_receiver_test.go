@@ -17,7 +17,14 @@ limitations under the License.
 package prefix
 
 import (
+	"errors"
+	"net"
+	"net/netip"
 	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/insomniacslk/dhcp/iana"
 )
 
 func TestNewDHCPv6PDReceiver(t *testing.T) {
@@ -107,3 +114,276 @@ func TestDHCPv6PDReceiverStopWithoutStart(t *testing.T) {
 		t.Errorf("Stop() returned error: %v", err)
 	}
 }
+
+func TestDHCPv6PDReceiverDiagnostics(t *testing.T) {
+	r := NewDHCPv6PDReceiver("eth0", 56)
+
+	err := r.exchange("solicit", func() error { return errors.New("no ADVERTISE") })
+	r.sendError(err)
+
+	diag := r.Diagnostics()
+	if len(diag) != 1 {
+		t.Fatalf("Diagnostics() returned %d entries, want 1", len(diag))
+	}
+	if diag[0].LastExchange != "solicit" || diag[0].LastExchangeResult != "no ADVERTISE" {
+		t.Errorf("LastExchange = %q, LastExchangeResult = %q", diag[0].LastExchange, diag[0].LastExchangeResult)
+	}
+	if diag[0].ConsecutiveFailures != 1 {
+		t.Errorf("ConsecutiveFailures = %d, want 1", diag[0].ConsecutiveFailures)
+	}
+
+	_ = r.exchange("renew", func() error { return nil })
+
+	diag = r.Diagnostics()
+	if diag[0].LastExchange != "renew" || diag[0].LastExchangeResult != "success" {
+		t.Errorf("LastExchange = %q, LastExchangeResult = %q", diag[0].LastExchange, diag[0].LastExchangeResult)
+	}
+	if diag[0].ConsecutiveFailures != 0 {
+		t.Errorf("ConsecutiveFailures = %d, want 0 after success", diag[0].ConsecutiveFailures)
+	}
+	if diag[0].LastError != "no ADVERTISE" {
+		t.Errorf("LastError = %q, want previous error to remain visible", diag[0].LastError)
+	}
+}
+
+func TestDHCPv6PDReceiverSolicitedPrefix(t *testing.T) {
+	tests := []struct {
+		name string
+		hint string
+		want string
+	}{
+		{name: "No hint", want: "::/56"},
+		{name: "Previous prefix", hint: "2001:db8:1200::/56", want: "2001:db8:1200::/56"},
+		{name: "Longer previous prefix", hint: "2001:db8:1234:5600::/64", want: "2001:db8:1234:5600::/56"},
+		{name: "IPv4 hint is ignored", hint: "192.0.2.0/24", want: "::/56"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewDHCPv6PDReceiver("eth0", 56)
+			if tt.hint != "" {
+				r.SetPrefixHint(netip.MustParsePrefix(tt.hint))
+			}
+			if got := r.solicitedPrefix().String(); got != tt.want {
+				t.Errorf("solicitedPrefix() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// newIAPDReply builds a REPLY with one IA_PD holding the given prefixes and, optionally, a status code.
+func newIAPDReply(t *testing.T, iaid [4]byte, status *dhcpv6.OptStatusCode, prefixes map[string]time.Duration) *dhcpv6.Message {
+	t.Helper()
+	reply, err := dhcpv6.NewMessage()
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+	reply.MessageType = dhcpv6.MessageTypeReply
+
+	iaPD := &dhcpv6.OptIAPD{IaId: iaid}
+	if status != nil {
+		iaPD.Options.Add(status)
+	}
+	for p, valid := range prefixes {
+		_, network, err := net.ParseCIDR(p)
+		if err != nil {
+			t.Fatalf("ParseCIDR(%s) error = %v", p, err)
+		}
+		iaPD.Options.Add(&dhcpv6.OptIAPrefix{PreferredLifetime: valid, ValidLifetime: valid, Prefix: network})
+	}
+	reply.AddOption(iaPD)
+	return reply
+}
+
+func TestDHCPv6PDReceiverProcessIAPDReply(t *testing.T) {
+	iaid := [4]byte{0, 0, 0, 1}
+	oldPrefix := netip.MustParsePrefix("2001:db8:1::/56")
+
+	tests := []struct {
+		name          string
+		status        *dhcpv6.OptStatusCode
+		prefixes      map[string]time.Duration
+		wantErr       error
+		wantEvent     EventType
+		wantPrefix    string
+		wantPrevious  string
+		wantLeaseKept bool
+	}{
+		{
+			name:       "Renewed",
+			prefixes:   map[string]time.Duration{"2001:db8:1::/56": time.Hour},
+			wantEvent:  EventTypeRenewed,
+			wantPrefix: "2001:db8:1::/56",
+		},
+		{
+			name: "Withdrawn prefix replaced by a new one",
+			prefixes: map[string]time.Duration{
+				"2001:db8:1::/56": 0,
+				"2001:db8:2::/56": time.Hour,
+			},
+			wantEvent:    EventTypeChanged,
+			wantPrefix:   "2001:db8:2::/56",
+			wantPrevious: "2001:db8:1::/56",
+		},
+		{
+			name: "Held prefix preferred",
+			prefixes: map[string]time.Duration{
+				"2001:db8:2::/56": time.Hour,
+				"2001:db8:1::/56": time.Hour,
+			},
+			wantEvent:  EventTypeRenewed,
+			wantPrefix: "2001:db8:1::/56",
+		},
+		{
+			name:          "All prefixes withdrawn",
+			prefixes:      map[string]time.Duration{"2001:db8:1::/56": 0},
+			wantErr:       errPrefixWithdrawn,
+			wantLeaseKept: true,
+		},
+		{
+			name:          "NoPrefixAvail",
+			status:        &dhcpv6.OptStatusCode{StatusCode: iana.StatusNoPrefixAvail},
+			wantErr:       errNoPrefixAvail,
+			wantLeaseKept: true,
+		},
+		{
+			name:          "NoBinding",
+			status:        &dhcpv6.OptStatusCode{StatusCode: iana.StatusNoBinding},
+			wantErr:       errNoBinding,
+			wantLeaseKept: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewDHCPv6PDReceiver("eth0", 56)
+			r.lease = &dhcpv6Lease{IAID: iaid, Prefix: oldPrefix}
+			r.currentPrefix = &Prefix{Network: oldPrefix, Source: SourceDHCPv6PD}
+
+			err := r.processIAPDReply(newIAPDReply(t, iaid, tt.status, tt.prefixes), iaid, nil, nil)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("processIAPDReply() error = %v, want %v", err, tt.wantErr)
+				}
+				if tt.wantLeaseKept && r.lease.Prefix != oldPrefix {
+					t.Errorf("lease prefix = %s, want %s", r.lease.Prefix, oldPrefix)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("processIAPDReply() error = %v", err)
+			}
+
+			event := <-r.Events()
+			if event.Type != tt.wantEvent {
+				t.Errorf("event type = %s, want %s", event.Type, tt.wantEvent)
+			}
+			if got := event.Prefix.Network.String(); got != tt.wantPrefix {
+				t.Errorf("event prefix = %s, want %s", got, tt.wantPrefix)
+			}
+			if tt.wantPrevious == "" {
+				if event.PreviousPrefix != nil {
+					t.Errorf("event previous prefix = %s, want none", event.PreviousPrefix.Network)
+				}
+			} else if event.PreviousPrefix == nil || event.PreviousPrefix.Network.String() != tt.wantPrevious {
+				t.Errorf("event previous prefix = %v, want %s", event.PreviousPrefix, tt.wantPrevious)
+			}
+		})
+	}
+}
+
+func TestDHCPv6PDReceiverLeaseNotExtended(t *testing.T) {
+	iaid := [4]byte{0, 0, 0, 1}
+	held := netip.MustParsePrefix("2001:db8:1::/56")
+
+	tests := []struct {
+		name        string
+		status      *dhcpv6.OptStatusCode
+		prefixes    map[string]time.Duration
+		wantKept    bool
+		wantBackOff bool
+	}{
+		{
+			name:        "NoPrefixAvail on RENEW keeps the prefix until it expires",
+			status:      &dhcpv6.OptStatusCode{StatusCode: iana.StatusNoPrefixAvail},
+			wantKept:    true,
+			wantBackOff: true,
+		},
+		{
+			name:     "NoBinding keeps the prefix until it expires",
+			status:   &dhcpv6.OptStatusCode{StatusCode: iana.StatusNoBinding},
+			wantKept: true,
+		},
+		{
+			name:        "Prefix withdrawn with a zero valid lifetime is dropped",
+			prefixes:    map[string]time.Duration{"2001:db8:1::/56": 0},
+			wantBackOff: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewDHCPv6PDReceiver("eth0", 56)
+			r.lease = &dhcpv6Lease{IAID: iaid, Prefix: held, ValidLifetime: time.Hour, ReceivedAt: time.Now()}
+			r.currentPrefix = &Prefix{Network: held, Source: SourceDHCPv6PD}
+
+			err := r.processIAPDReply(newIAPDReply(t, iaid, tt.status, tt.prefixes), iaid, nil, nil)
+			if err == nil {
+				t.Fatal("processIAPDReply() succeeded, want an error")
+			}
+			r.leaseNotExtended(err)
+
+			if got := r.solicitBackoff > 0; got != tt.wantBackOff {
+				t.Errorf("backed off = %v, want %v", got, tt.wantBackOff)
+			}
+			if !tt.wantKept {
+				if r.CurrentPrefix() != nil || r.lease != nil {
+					t.Errorf("CurrentPrefix() = %v, want the withdrawn prefix dropped", r.CurrentPrefix())
+				}
+				if event := <-r.Events(); event.Type != EventTypeExpired {
+					t.Errorf("event type = %s, want %s", event.Type, EventTypeExpired)
+				}
+				return
+			}
+
+			if p := r.CurrentPrefix(); p == nil || p.Network != held {
+				t.Fatalf("CurrentPrefix() = %v, want %s kept", p, held)
+			}
+			if !r.resolicit {
+				t.Error("Expected the receiver to SOLICIT for a new lease")
+			}
+			select {
+			case event := <-r.Events():
+				t.Errorf("unexpected %s event while the prefix is still valid", event.Type)
+			default:
+			}
+
+			// A new lease from the SOLICIT ends the resolicitation
+			if err := r.processIAPDReply(newIAPDReply(t, iaid, nil, map[string]time.Duration{"2001:db8:1::/56": time.Hour}), iaid, nil, nil); err != nil {
+				t.Fatalf("processIAPDReply() error = %v", err)
+			}
+			if r.resolicit {
+				t.Error("Expected a new lease to end the resolicitation")
+			}
+		})
+	}
+}
+
+func TestDHCPv6PDReceiverBackOff(t *testing.T) {
+	r := NewDHCPv6PDReceiver("eth0", 56)
+
+	want := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second}
+	for i, w := range want {
+		r.backOff()
+		if r.solicitBackoff != w {
+			t.Errorf("backoff %d = %s, want %s", i+1, r.solicitBackoff, w)
+		}
+	}
+
+	for range 20 {
+		r.backOff()
+	}
+	if r.solicitBackoff != maxSolicitBackoff {
+		t.Errorf("backoff = %s, want cap %s", r.solicitBackoff, maxSolicitBackoff)
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import "time"
+
+// failureTracker records consecutive failures of a receiver for diagnostics.
+// It is not safe for concurrent use; callers must hold the receiver's lock.
+type failureTracker struct {
+	consecutiveFailures int
+	lastError           error
+	lastErrorTime       time.Time
+}
+
+// recordFailure counts a failure and remembers the error.
+func (f *failureTracker) recordFailure(err error) {
+	f.consecutiveFailures++
+	f.lastError = err
+	f.lastErrorTime = time.Now()
+}
+
+// recordSuccess resets the consecutive failure count.
+// The last error is kept so that intermittent problems remain visible.
+func (f *failureTracker) recordSuccess() {
+	f.consecutiveFailures = 0
+}
+
+// fill copies the failure information into the diagnostics.
+func (f *failureTracker) fill(d *Diagnostics) {
+	d.ConsecutiveFailures = f.consecutiveFailures
+	if f.lastError != nil {
+		d.LastError = f.lastError.Error()
+		d.LastErrorTime = f.lastErrorTime
+	}
+}
@@ -0,0 +1,427 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultDNSInterval is how often a hostname is resolved by default
+	DefaultDNSInterval = time.Minute
+
+	// dnsRetryInterval is how soon a failed query is retried, unless the
+	// configured interval is shorter
+	dnsRetryInterval = 30 * time.Second
+
+	// dnsQueryTimeout bounds a single query including the TCP fallback
+	dnsQueryTimeout = 5 * time.Second
+)
+
+// DNSReceiver derives the prefix from the AAAA record of a dynamic DNS
+// hostname, such as one the router updates whenever its prefix changes. It
+// needs no network privileges and works from outside the home network.
+type DNSReceiver struct {
+	mu            sync.RWMutex
+	hostname      string
+	resolver      string
+	prefixLength  int
+	interval      time.Duration
+	currentPrefix *Prefix
+	events        chan Event
+	ctx           context.Context
+	cancel        context.CancelFunc
+	started       bool
+	failures      failureTracker
+	lastQuery     time.Time
+	lastAddress   netip.Addr
+	lastTTL       time.Duration
+
+	// lookup resolves the hostname; a test seam
+	lookup func(ctx context.Context) ([]netip.Addr, time.Duration, error)
+}
+
+// NewDNSReceiver creates a receiver that resolves hostname against resolver
+// (host:port) every interval and truncates the address to prefixLength bits.
+func NewDNSReceiver(hostname, resolver string, prefixLength int, interval time.Duration) *DNSReceiver {
+	if interval <= 0 {
+		interval = DefaultDNSInterval
+	}
+	r := &DNSReceiver{
+		hostname:     hostname,
+		resolver:     resolver,
+		prefixLength: prefixLength,
+		interval:     interval,
+		events:       make(chan Event, 10),
+	}
+	r.lookup = r.queryAAAA
+	return r
+}
+
+// Start begins resolving the hostname.
+func (r *DNSReceiver) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started {
+		return nil
+	}
+	r.ctx, r.cancel = context.WithCancel(ctx)
+	r.started = true
+
+	go r.run()
+	return nil
+}
+
+// Stop stops resolving the hostname.
+func (r *DNSReceiver) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.started {
+		return nil
+	}
+	r.started = false
+	r.cancel()
+	return nil
+}
+
+// Events returns the channel of prefix events.
+func (r *DNSReceiver) Events() <-chan Event {
+	return r.events
+}
+
+// CurrentPrefix returns the prefix derived from the last answer, if any.
+func (r *DNSReceiver) CurrentPrefix() *Prefix {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.currentPrefix
+}
+
+// Source returns SourceDNS.
+func (r *DNSReceiver) Source() Source {
+	return SourceDNS
+}
+
+// Diagnostics reports the last query and its answer.
+func (r *DNSReceiver) Diagnostics() []Diagnostics {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d := Diagnostics{
+		Name:          string(SourceDNS),
+		Source:        SourceDNS,
+		Active:        r.currentPrefix != nil,
+		LastQueryTime: r.lastQuery,
+		DNSTTL:        r.lastTTL,
+	}
+	if r.lastAddress.IsValid() {
+		d.DNSAddress = r.lastAddress.String()
+	}
+	r.failures.fill(&d)
+	return []Diagnostics{d}
+}
+
+// run resolves the hostname until the receiver is stopped
+func (r *DNSReceiver) run() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-timer.C:
+			timer.Reset(r.poll())
+		}
+	}
+}
+
+// poll resolves the hostname once and returns how long to wait before the
+// next query. A failed query keeps the last prefix: the DNS server being
+// unreachable says nothing about the prefix itself.
+func (r *DNSReceiver) poll() time.Duration {
+	log := logf.FromContext(r.ctx).WithName("dns-receiver")
+
+	addrs, ttl, err := r.lookup(r.ctx)
+	now := time.Now()
+	if err == nil {
+		addrs = slices.DeleteFunc(addrs, func(a netip.Addr) bool { return !isGlobalUnicast(a) })
+		if len(addrs) == 0 {
+			err = fmt.Errorf("no global IPv6 address for %s", r.hostname)
+		}
+	}
+	if err != nil {
+		if r.ctx.Err() != nil {
+			return dnsRetryInterval
+		}
+		log.Info("Failed to resolve hostname", "hostname", r.hostname, "resolver", r.resolver, "error", err.Error())
+		r.mu.Lock()
+		r.lastQuery = now
+		r.failures.recordFailure(err)
+		r.mu.Unlock()
+		r.send(Event{Type: EventTypeFailed, Error: err})
+		return min(r.interval, dnsRetryInterval)
+	}
+
+	// Sort so that several records select the same address on every query
+	slices.SortFunc(addrs, netip.Addr.Compare)
+	addr := addrs[0]
+	network := netip.PrefixFrom(addr, r.prefixLength).Masked()
+
+	r.mu.Lock()
+	r.lastQuery = now
+	r.lastAddress = addr
+	r.lastTTL = ttl
+	r.failures.recordSuccess()
+
+	newPrefix := &Prefix{
+		Network:    network,
+		Source:     SourceDNS,
+		ReceivedAt: now,
+	}
+	event := Event{Prefix: newPrefix}
+	switch {
+	case r.currentPrefix == nil:
+		event.Type = EventTypeAcquired
+	case r.currentPrefix.Network != network:
+		event.Type = EventTypeChanged
+		event.PreviousPrefix = r.currentPrefix
+	default:
+		event.Type = EventTypeRenewed
+	}
+	r.currentPrefix = newPrefix
+	r.mu.Unlock()
+
+	if event.Type != EventTypeRenewed {
+		log.Info("Prefix resolved", "hostname", r.hostname, "address", addr, "prefix", network, "eventType", event.Type)
+	}
+	r.send(event)
+
+	// The answer may be cached until its TTL expires
+	return max(r.interval, ttl)
+}
+
+// send delivers an event without blocking
+func (r *DNSReceiver) send(event Event) {
+	select {
+	case r.events <- event:
+	default:
+		// Channel full, event dropped
+	}
+}
+
+// queryAAAA asks the resolver for the AAAA records of the hostname, over UDP
+// and again over TCP if the answer was truncated. It returns the addresses
+// and the lowest TTL among them.
+func (r *DNSReceiver) queryAAAA(ctx context.Context) ([]netip.Addr, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, dnsQueryTimeout)
+	defer cancel()
+
+	name, err := dnsmessage.NewName(fqdn(r.hostname))
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid hostname %q: %w", r.hostname, err)
+	}
+	var idBytes [2]byte
+	if _, err := rand.Read(idBytes[:]); err != nil {
+		return nil, 0, fmt.Errorf("failed to generate query ID: %w", err)
+	}
+	id := binary.BigEndian.Uint16(idBytes[:])
+	query, err := encodeAAAAQuery(id, name)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var dialer net.Dialer
+	resp, err := exchangeDNS(ctx, &dialer, "udp", r.resolver, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	addrs, ttl, err := decodeAAAAResponse(resp, id, name)
+	if errors.Is(err, errDNSTruncated) {
+		resp, err = exchangeDNS(ctx, &dialer, "tcp", r.resolver, query)
+		if err != nil {
+			return nil, 0, err
+		}
+		addrs, ttl, err = decodeAAAAResponse(resp, id, name)
+	}
+	return addrs, ttl, err
+}
+
+// errDNSTruncated reports a UDP answer that did not fit into one datagram
+var errDNSTruncated = errors.New("DNS response truncated")
+
+// exchangeDNS sends a query and reads the response. TCP messages carry a
+// two-byte length prefix (RFC 1035 section 4.2.2).
+func exchangeDNS(ctx context.Context, dialer *net.Dialer, network, server string, query []byte) ([]byte, error) {
+	conn, err := dialer.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to resolver %s: %w", server, err)
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if network == "tcp" {
+		framed := binary.BigEndian.AppendUint16(nil, uint16(len(query)))
+		if _, err := conn.Write(append(framed, query...)); err != nil {
+			return nil, fmt.Errorf("failed to send DNS query: %w", err)
+		}
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, fmt.Errorf("failed to read DNS response: %w", err)
+		}
+		resp := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, resp); err != nil {
+			return nil, fmt.Errorf("failed to read DNS response: %w", err)
+		}
+		return resp, nil
+	}
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, fmt.Errorf("failed to send DNS query: %w", err)
+	}
+	buf := make([]byte, 1232)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DNS response: %w", err)
+	}
+	return buf[:n], nil
+}
+
+// encodeAAAAQuery builds a recursive AAAA query
+func encodeAAAAQuery(id uint16, name dnsmessage.Name) ([]byte, error) {
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  name,
+			Type:  dnsmessage.TypeAAAA,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	query, err := msg.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode DNS query: %w", err)
+	}
+	return query, nil
+}
+
+// decodeAAAAResponse returns the AAAA addresses of a response to the query
+// with the given ID, and the lowest TTL among them. CNAME records in the
+// answer are followed implicitly, since a recursive resolver includes the
+// records of the target.
+func decodeAAAAResponse(resp []byte, id uint16, name dnsmessage.Name) ([]netip.Addr, time.Duration, error) {
+	var p dnsmessage.Parser
+	header, err := p.Start(resp)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse DNS response: %w", err)
+	}
+	if header.ID != id || !header.Response {
+		return nil, 0, fmt.Errorf("unrelated DNS response")
+	}
+	if header.Truncated {
+		return nil, 0, errDNSTruncated
+	}
+	if header.RCode != dnsmessage.RCodeSuccess {
+		return nil, 0, fmt.Errorf("resolving %s: %s", strings.TrimSuffix(name.String(), "."), header.RCode)
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse DNS response: %w", err)
+	}
+
+	var (
+		addrs []netip.Addr
+		ttl   uint32
+	)
+	for {
+		h, err := p.AnswerHeader()
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to parse DNS answer: %w", err)
+		}
+		if h.Type != dnsmessage.TypeAAAA || h.Class != dnsmessage.ClassINET {
+			if err := p.SkipAnswer(); err != nil {
+				return nil, 0, fmt.Errorf("failed to parse DNS answer: %w", err)
+			}
+			continue
+		}
+		rr, err := p.AAAAResource()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to parse AAAA record: %w", err)
+		}
+		addrs = append(addrs, netip.AddrFrom16(rr.AAAA))
+		if len(addrs) == 1 || h.TTL < ttl {
+			ttl = h.TTL
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, 0, fmt.Errorf("no AAAA record for %s", strings.TrimSuffix(name.String(), "."))
+	}
+	return addrs, time.Duration(ttl) * time.Second, nil
+}
+
+// fqdn returns the hostname with a trailing dot
+func fqdn(hostname string) string {
+	if strings.HasSuffix(hostname, ".") {
+		return hostname
+	}
+	return hostname + "."
+}
+
+// validHostname reports whether s is a DNS name of non-empty labels of up to
+// 63 octets, optionally fully qualified
+func validHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 {
+			return false
+		}
+	}
+	return true
+}
+
+// parseResolver returns the resolver as host:port, adding port 53 if missing
+func parseResolver(s string) (string, error) {
+	if addr, err := netip.ParseAddr(strings.Trim(s, "[]")); err == nil {
+		return netip.AddrPortFrom(addr, 53).String(), nil
+	}
+	addrPort, err := netip.ParseAddrPort(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid resolver %q: expected an IP address with an optional port", s)
+	}
+	return addrPort.String(), nil
+}
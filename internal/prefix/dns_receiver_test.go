@@ -0,0 +1,268 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsStandIn is a local resolver that answers AAAA queries with fixed
+// records over UDP and TCP on the same port.
+type dnsStandIn struct {
+	udp      *net.UDPConn
+	tcp      *net.TCPListener
+	answers  []netip.Addr
+	ttl      uint32
+	truncate bool
+}
+
+func newDNSStandIn(t *testing.T, truncate bool, ttl uint32, answers ...string) *dnsStandIn {
+	t.Helper()
+	s := &dnsStandIn{ttl: ttl, truncate: truncate}
+	for _, a := range answers {
+		s.answers = append(s.answers, netip.MustParseAddr(a))
+	}
+
+	tcp, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.IPv6loopback})
+	if err != nil {
+		t.Skipf("IPv6 loopback not available: %v", err)
+	}
+	udp, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv6loopback, Port: tcp.Addr().(*net.TCPAddr).Port})
+	if err != nil {
+		_ = tcp.Close()
+		t.Skipf("UDP port of the TCP listener is taken: %v", err)
+	}
+	s.udp, s.tcp = udp, tcp
+	t.Cleanup(func() {
+		_ = udp.Close()
+		_ = tcp.Close()
+	})
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, from, err := udp.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			_, _ = udp.WriteToUDP(s.answer(t, buf[:n], s.truncate), from)
+		}
+	}()
+	go func() {
+		for {
+			conn, err := tcp.Accept()
+			if err != nil {
+				return
+			}
+			var length [2]byte
+			if _, err := io.ReadFull(conn, length[:]); err == nil {
+				req := make([]byte, binary.BigEndian.Uint16(length[:]))
+				if _, err := io.ReadFull(conn, req); err == nil {
+					resp := s.answer(t, req, false)
+					_, _ = conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(resp))), resp...))
+				}
+			}
+			_ = conn.Close()
+		}
+	}()
+	return s
+}
+
+func (s *dnsStandIn) answer(t *testing.T, req []byte, truncate bool) []byte {
+	var query dnsmessage.Message
+	if err := query.Unpack(req); err != nil {
+		t.Errorf("stand-in failed to parse query: %v", err)
+		return nil
+	}
+	resp := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: query.ID, Response: true, RecursionAvailable: true, Truncated: truncate},
+		Questions: query.Questions,
+	}
+	if !truncate {
+		for _, a := range s.answers {
+			resp.Answers = append(resp.Answers, dnsmessage.Resource{
+				Header: dnsmessage.ResourceHeader{
+					Name:  query.Questions[0].Name,
+					Type:  dnsmessage.TypeAAAA,
+					Class: dnsmessage.ClassINET,
+					TTL:   s.ttl,
+				},
+				Body: &dnsmessage.AAAAResource{AAAA: a.As16()},
+			})
+		}
+		if len(s.answers) == 0 {
+			resp.RCode = dnsmessage.RCodeNameError
+		}
+	}
+	packed, err := resp.Pack()
+	if err != nil {
+		t.Errorf("stand-in failed to encode response: %v", err)
+	}
+	return packed
+}
+
+func (s *dnsStandIn) server() string {
+	return s.udp.LocalAddr().(*net.UDPAddr).AddrPort().String()
+}
+
+func TestDNSReceiverQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		truncate bool
+		ttl      uint32
+		answers  []string
+		wantTTL  time.Duration
+		wantErr  bool
+	}{
+		{
+			name:    "udp",
+			ttl:     60,
+			answers: []string{"2001:db8:1234:5600::1"},
+			wantTTL: time.Minute,
+		},
+		{
+			name:     "truncated answer retried over tcp",
+			truncate: true,
+			ttl:      300,
+			answers:  []string{"2001:db8:1234:5600::1", "2001:db8:1234:5600::2"},
+			wantTTL:  5 * time.Minute,
+		},
+		{
+			name:    "nxdomain",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			standIn := newDNSStandIn(t, tt.truncate, tt.ttl, tt.answers...)
+			r := NewDNSReceiver("home.example.org", standIn.server(), 56, time.Minute)
+
+			addrs, ttl, err := r.queryAAAA(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("queryAAAA() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(addrs) != len(tt.answers) {
+				t.Errorf("queryAAAA() = %v, want %v", addrs, tt.answers)
+			}
+			if ttl != tt.wantTTL {
+				t.Errorf("queryAAAA() ttl = %v, want %v", ttl, tt.wantTTL)
+			}
+		})
+	}
+}
+
+func TestDNSReceiverPoll(t *testing.T) {
+	var (
+		addrs []netip.Addr
+		ttl   time.Duration
+		err   error
+	)
+	r := NewDNSReceiver("home.example.org", "[::1]:53", 56, time.Minute)
+	r.lookup = func(context.Context) ([]netip.Addr, time.Duration, error) {
+		return addrs, ttl, err
+	}
+	r.ctx = context.Background()
+
+	expect := func(want EventType) Event {
+		t.Helper()
+		select {
+		case ev := <-r.Events():
+			if ev.Type != want {
+				t.Fatalf("event = %s (%v), want %s", ev.Type, ev.Error, want)
+			}
+			return ev
+		default:
+			t.Fatalf("no event, want %s", want)
+			return Event{}
+		}
+	}
+
+	addrs, ttl = []netip.Addr{netip.MustParseAddr("2001:db8:1234:56ff::1")}, 30*time.Second
+	if wait := r.poll(); wait != time.Minute {
+		t.Errorf("poll() wait = %v, want the interval for a shorter TTL", wait)
+	}
+	ev := expect(EventTypeAcquired)
+	if got := ev.Prefix.Network.String(); got != "2001:db8:1234:5600::/56" {
+		t.Errorf("prefix = %s, want 2001:db8:1234:5600::/56", got)
+	}
+
+	ttl = time.Hour
+	if wait := r.poll(); wait != time.Hour {
+		t.Errorf("poll() wait = %v, want the TTL", wait)
+	}
+	expect(EventTypeRenewed)
+
+	// Non-global addresses are ignored
+	addrs = []netip.Addr{netip.MustParseAddr("fd00::1"), netip.MustParseAddr("2001:db8:abcd:100::1")}
+	r.poll()
+	ev = expect(EventTypeChanged)
+	if ev.PreviousPrefix == nil || ev.Prefix.Network.String() != "2001:db8:abcd:100::/56" {
+		t.Errorf("changed event = %+v", ev)
+	}
+
+	// A failed query keeps the prefix and retries sooner
+	err = io.EOF
+	if wait := r.poll(); wait != dnsRetryInterval {
+		t.Errorf("poll() wait = %v, want %v", wait, dnsRetryInterval)
+	}
+	expect(EventTypeFailed)
+	if r.CurrentPrefix() == nil {
+		t.Error("Expected the prefix to be kept after a failed query")
+	}
+	d := r.Diagnostics()[0]
+	if d.ConsecutiveFailures != 1 || d.DNSAddress != "2001:db8:abcd:100::1" || d.DNSTTL != time.Hour {
+		t.Errorf("Diagnostics() = %+v", d)
+	}
+}
+
+func TestParseResolver(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "2001:4860:4860::8888", want: "[2001:4860:4860::8888]:53"},
+		{in: "[2001:db8::53]", want: "[2001:db8::53]:53"},
+		{in: "[2001:db8::53]:5353", want: "[2001:db8::53]:5353"},
+		{in: "192.0.2.53", want: "192.0.2.53:53"},
+		{in: "dns.example.org", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseResolver(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseResolver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseResolver() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -17,41 +17,59 @@ limitations under the License.
 package prefix
 
 import (
+	"encoding/hex"
 	"fmt"
+	"net"
+	"net/netip"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
 
 	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
 )
 
 // ReceiverFactory creates Receiver instances based on AcquisitionSpec.
 type ReceiverFactory interface {
-	// CreateReceiver creates a Receiver based on the given acquisition spec.
-	CreateReceiver(spec dynamicprefixiov1alpha1.AcquisitionSpec) (Receiver, error)
+	// CreateReceiver creates a Receiver for the named owner based on the given acquisition spec.
+	CreateReceiver(owner string, spec dynamicprefixiov1alpha1.AcquisitionSpec) (Receiver, error)
 }
 
 // DefaultReceiverFactory is the default implementation of ReceiverFactory.
-type DefaultReceiverFactory struct{}
+// Receivers it creates share per-interface sockets and clients through its registry.
+type DefaultReceiverFactory struct {
+	registry *Registry
+}
 
 // NewReceiverFactory creates a new DefaultReceiverFactory.
 func NewReceiverFactory() *DefaultReceiverFactory {
-	return &DefaultReceiverFactory{}
+	return &DefaultReceiverFactory{registry: NewRegistry()}
 }
 
 // CreateReceiver creates a Receiver based on the AcquisitionSpec.
 // Decision logic:
-// 1. If only DHCPv6PD configured → DHCPv6PDReceiver
-// 2. If only RouterAdvertisement configured → RAReceiver
-// 3. If both configured → CompositeReceiver (DHCPv6-PD primary, RA fallback)
-func (f *DefaultReceiverFactory) CreateReceiver(spec dynamicprefixiov1alpha1.AcquisitionSpec) (Receiver, error) {
+// 1. If Sources configured → CompositeReceiver over the ordered sources
+// 2. If only DHCPv6PD configured → DHCPv6PDReceiver
+// 3. If only RouterAdvertisement configured → RAReceiver
+// 4. If both configured → CompositeReceiver (DHCPv6-PD primary, RA fallback)
+//
+// The owner identifies the DynamicPrefix and selects its IA_PD on a shared DHCPv6 client.
+func (f *DefaultReceiverFactory) CreateReceiver(owner string, spec dynamicprefixiov1alpha1.AcquisitionSpec) (Receiver, error) {
+	if len(spec.Sources) > 0 {
+		return f.createSourcesReceiver(owner, spec.Sources)
+	}
+
 	hasDHCPv6 := spec.DHCPv6PD != nil
 	hasRA := spec.RouterAdvertisement != nil && spec.RouterAdvertisement.Enabled
 
 	switch {
 	case hasDHCPv6 && hasRA:
 		// Both configured - use composite receiver
-		return f.createCompositeReceiver(spec)
+		return f.createCompositeReceiver(owner, spec)
 	case hasDHCPv6:
 		// Only DHCPv6-PD configured
-		return f.createDHCPv6PDReceiver(spec.DHCPv6PD)
+		return f.createDHCPv6PDReceiver(owner, spec.DHCPv6PD)
 	case hasRA:
 		// Only RA configured
 		return f.createRAReceiver(spec.RouterAdvertisement)
@@ -61,31 +79,189 @@ func (f *DefaultReceiverFactory) CreateReceiver(spec dynamicprefixiov1alpha1.Acq
 }
 
 // createDHCPv6PDReceiver creates a DHCPv6-PD receiver from the spec.
-func (f *DefaultReceiverFactory) createDHCPv6PDReceiver(spec *dynamicprefixiov1alpha1.DHCPv6PDSpec) (*DHCPv6PDReceiver, error) {
-	if spec.Interface == "" {
-		return nil, fmt.Errorf("DHCPv6-PD interface is required")
+func (f *DefaultReceiverFactory) createDHCPv6PDReceiver(owner string, spec *dynamicprefixiov1alpha1.DHCPv6PDSpec) (Receiver, error) {
+	selector, err := interfaceSelector(spec.Interface, spec.InterfaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DHCPv6-PD interface: %w", err)
 	}
 
-	prefixLength := 56 // Default
+	cfg := DHCPv6PDConfig{
+		Interface:             spec.Interface,
+		NetNS:                 spec.NetworkNamespace,
+		RequestedPrefixLength: 56, // Default
+	}
 	if spec.RequestedPrefixLength != nil {
-		prefixLength = *spec.RequestedPrefixLength
+		cfg.RequestedPrefixLength = *spec.RequestedPrefixLength
+	}
+
+	if spec.ServerAddress != "" && spec.Relay != nil {
+		return nil, fmt.Errorf("DHCPv6-PD serverAddress and relay are mutually exclusive")
+	}
+	if spec.ServerAddress != "" {
+		addr, err := parseUnicastIPv6(spec.ServerAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DHCPv6-PD serverAddress: %w", err)
+		}
+		cfg.ServerAddress = addr
+	}
+	if spec.Relay != nil {
+		destination, err := parseUnicastIPv6(spec.Relay.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DHCPv6-PD relay destination: %w", err)
+		}
+		cfg.Relay = &DHCPv6RelayConfig{Destination: destination}
+		if spec.Relay.LinkAddress != "" {
+			linkAddr, err := parseUnicastIPv6(spec.Relay.LinkAddress)
+			if err != nil {
+				return nil, fmt.Errorf("invalid DHCPv6-PD relay linkAddress: %w", err)
+			}
+			cfg.Relay.LinkAddress = linkAddr
+		}
+	}
+
+	if spec.Options != nil {
+		options, err := dhcpv6ClientOptions(spec.Options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DHCPv6-PD options: %w", err)
+		}
+		cfg.Options = options
+	}
+	if spec.Authentication != nil {
+		auth, err := dhcpv6AuthConfig(spec.Authentication)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DHCPv6-PD authentication: %w", err)
+		}
+		cfg.Auth = auth
+	}
+
+	if spec.Macvlan != nil {
+		macvlan, err := macvlanConfig(owner, spec.Macvlan)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DHCPv6-PD macvlan: %w", err)
+		}
+		cfg.Macvlan = macvlan
 	}
 
-	return NewDHCPv6PDReceiver(spec.Interface, prefixLength), nil
+	if selector != nil {
+		return newSelectingReceiver(*selector, cfg.NetNS, SourceDHCPv6PD, func(iface string) Receiver {
+			cfg := cfg
+			cfg.Interface = iface
+			return f.newDHCPv6PDReceiver(cfg, owner)
+		}), nil
+	}
+	return f.newDHCPv6PDReceiver(cfg, owner), nil
+}
+
+// newDHCPv6PDReceiver creates a DHCPv6-PD receiver, shared through the registry if there is one.
+func (f *DefaultReceiverFactory) newDHCPv6PDReceiver(cfg DHCPv6PDConfig, owner string) Receiver {
+	if f.registry != nil {
+		return f.registry.DHCPv6PDReceiver(cfg, owner)
+	}
+	return NewDHCPv6PDReceiverWithConfig(cfg)
+}
+
+// interfaceSelector validates that exactly one of an interface name and a
+// selector is set and converts the selector. It returns nil for a plain name.
+func interfaceSelector(name string, spec *dynamicprefixiov1alpha1.InterfaceSelector) (*InterfaceSelector, error) {
+	if (name == "") == (spec == nil) {
+		return nil, fmt.Errorf("exactly one of interface and interfaceSelector must be set")
+	}
+	if spec == nil {
+		return nil, nil
+	}
+
+	configured := 0
+	for _, set := range []bool{spec.MACAddress != "", spec.NamePattern != "", spec.NameRegex != "", spec.DefaultRoute} {
+		if set {
+			configured++
+		}
+	}
+	if configured != 1 {
+		return nil, fmt.Errorf("exactly one of macAddress, namePattern, nameRegex or defaultRoute must be set")
+	}
+
+	selector := &InterfaceSelector{NamePattern: spec.NamePattern, DefaultRoute: spec.DefaultRoute}
+	if spec.MACAddress != "" {
+		mac, err := net.ParseMAC(spec.MACAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid macAddress: %w", err)
+		}
+		selector.MAC = mac
+	}
+	if spec.NamePattern != "" {
+		if _, err := path.Match(spec.NamePattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid namePattern %q: %w", spec.NamePattern, err)
+		}
+	}
+	if spec.NameRegex != "" {
+		re, err := regexp.Compile(spec.NameRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid nameRegex: %w", err)
+		}
+		selector.NameRegex = re
+	}
+	return selector, nil
+}
+
+// macvlanConfig converts a macvlan spec, deriving the name and MAC address from the owner if unset.
+func macvlanConfig(owner string, spec *dynamicprefixiov1alpha1.MacvlanSpec) (*MacvlanConfig, error) {
+	cfg := defaultMacvlanConfig(owner)
+	if spec.Name != "" {
+		cfg.Name = spec.Name
+	}
+	if spec.MACAddress != "" {
+		mac, err := net.ParseMAC(spec.MACAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid macAddress: %w", err)
+		}
+		if len(mac) != 6 || mac[0]&0x01 != 0 {
+			return nil, fmt.Errorf("macAddress %s is not a unicast Ethernet address", spec.MACAddress)
+		}
+		cfg.MAC = mac
+	}
+	if spec.Mode != "" {
+		cfg.Mode = MacvlanMode(spec.Mode)
+	}
+	return &cfg, nil
 }
 
 // createRAReceiver creates a Router Advertisement receiver from the spec.
-func (f *DefaultReceiverFactory) createRAReceiver(spec *dynamicprefixiov1alpha1.RouterAdvertisementSpec) (*RAReceiver, error) {
-	if spec.Interface == "" {
-		return nil, fmt.Errorf("router advertisement interface is required")
+func (f *DefaultReceiverFactory) createRAReceiver(spec *dynamicprefixiov1alpha1.RouterAdvertisementSpec) (Receiver, error) {
+	selector, err := interfaceSelector(spec.Interface, spec.InterfaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid router advertisement interface: %w", err)
 	}
 
-	return NewRAReceiver(spec.Interface), nil
+	if selector != nil {
+		return newSelectingReceiver(*selector, spec.NetworkNamespace, SourceRouterAdvertisement, func(iface string) Receiver {
+			return f.newRAReceiver(iface, spec.NetworkNamespace)
+		}), nil
+	}
+	return f.newRAReceiver(spec.Interface, spec.NetworkNamespace), nil
+}
+
+// newRAReceiver creates a Router Advertisement receiver, shared through the registry if there is one.
+func (f *DefaultReceiverFactory) newRAReceiver(iface, netns string) Receiver {
+	if f.registry != nil {
+		return f.registry.RAReceiver(iface, netns)
+	}
+	return NewRAReceiverInNamespace(iface, netns)
+}
+
+// CreateIPv4Receiver creates a receiver for the WAN IPv4 address from the spec.
+func (f *DefaultReceiverFactory) CreateIPv4Receiver(spec *dynamicprefixiov1alpha1.IPv4Spec) (IPv4Receiver, error) {
+	if spec == nil || spec.DHCPv4.Interface == "" {
+		return nil, fmt.Errorf("dhcpv4.interface is required")
+	}
+	if f.registry != nil {
+		return f.registry.IPv4Receiver(spec.DHCPv4.Interface, spec.DHCPv4.NetworkNamespace), nil
+	}
+	return NewDHCPv4Observer(spec.DHCPv4.Interface, spec.DHCPv4.NetworkNamespace), nil
 }
 
 // createCompositeReceiver creates a composite receiver with DHCPv6-PD as primary and RA as fallback.
-func (f *DefaultReceiverFactory) createCompositeReceiver(spec dynamicprefixiov1alpha1.AcquisitionSpec) (*CompositeReceiver, error) {
-	primary, err := f.createDHCPv6PDReceiver(spec.DHCPv6PD)
+func (f *DefaultReceiverFactory) createCompositeReceiver(owner string, spec dynamicprefixiov1alpha1.AcquisitionSpec) (*CompositeReceiver, error) {
+	primary, err := f.createDHCPv6PDReceiver(owner, spec.DHCPv6PD)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create primary DHCPv6-PD receiver: %w", err)
 	}
@@ -97,3 +273,283 @@ func (f *DefaultReceiverFactory) createCompositeReceiver(spec dynamicprefixiov1a
 
 	return NewCompositeReceiver(primary, fallback), nil
 }
+
+// createSourcesReceiver creates a composite receiver from an ordered list of sources.
+func (f *DefaultReceiverFactory) createSourcesReceiver(owner string, specs []dynamicprefixiov1alpha1.AcquisitionSourceSpec) (*CompositeReceiver, error) {
+	sources := make([]CompositeSource, 0, len(specs))
+	for _, spec := range specs {
+		// Each DHCPv6 source of an owner needs its own IA_PD
+		receiver, err := f.createSourceReceiver(owner+"/"+spec.Name, spec)
+		if err != nil {
+			return nil, fmt.Errorf("source %q: %w", spec.Name, err)
+		}
+
+		policy := SourcePolicy{
+			Name:             spec.Name,
+			Priority:         spec.Priority,
+			FailureThreshold: spec.FailureThreshold,
+		}
+		if spec.FailbackDelay != nil {
+			policy.FailbackDelay = spec.FailbackDelay.Duration
+		}
+		if spec.CrossCheck != nil {
+			policy.Within = spec.CrossCheck.Within
+		}
+
+		sources = append(sources, CompositeSource{Receiver: receiver, Policy: policy})
+	}
+
+	return NewCompositeReceiverFromSources(sources)
+}
+
+// createSourceReceiver creates the receiver for a single acquisition source.
+// Exactly one receiver type must be configured.
+func (f *DefaultReceiverFactory) createSourceReceiver(owner string, spec dynamicprefixiov1alpha1.AcquisitionSourceSpec) (Receiver, error) {
+	configured := 0
+	for _, set := range []bool{spec.DHCPv6PD != nil, spec.RouterAdvertisement != nil, spec.Static != nil, spec.DNS != nil} {
+		if set {
+			configured++
+		}
+	}
+	if configured != 1 {
+		return nil, fmt.Errorf("exactly one of dhcpv6pd, routerAdvertisement, static or dns must be set")
+	}
+
+	switch {
+	case spec.DHCPv6PD != nil:
+		return f.createDHCPv6PDReceiver(owner, spec.DHCPv6PD)
+	case spec.RouterAdvertisement != nil:
+		if !spec.RouterAdvertisement.Enabled {
+			return nil, fmt.Errorf("router advertisement source is disabled")
+		}
+		return f.createRAReceiver(spec.RouterAdvertisement)
+	case spec.DNS != nil:
+		return f.createDNSReceiver(spec.DNS)
+	default:
+		return f.createStaticReceiver(spec.Static)
+	}
+}
+
+// createDNSReceiver creates a dynamic DNS receiver from the spec.
+func (f *DefaultReceiverFactory) createDNSReceiver(spec *dynamicprefixiov1alpha1.DNSSourceSpec) (Receiver, error) {
+	if !validHostname(spec.Hostname) {
+		return nil, fmt.Errorf("invalid dns hostname %q", spec.Hostname)
+	}
+	resolver, err := parseResolver(spec.Resolver)
+	if err != nil {
+		return nil, err
+	}
+	if spec.PrefixLength < 1 || spec.PrefixLength > 128 {
+		return nil, fmt.Errorf("dns prefixLength %d is out of range 1-128", spec.PrefixLength)
+	}
+
+	interval := DefaultDNSInterval
+	if spec.Interval != nil && spec.Interval.Duration > 0 {
+		interval = spec.Interval.Duration
+	}
+	if f.registry != nil {
+		return f.registry.DNSReceiver(spec.Hostname, resolver, spec.PrefixLength, interval), nil
+	}
+	return NewDNSReceiver(spec.Hostname, resolver, spec.PrefixLength, interval), nil
+}
+
+// CreateStandbyReceivers creates receivers for the passive sources of the spec and
+// its uplinks: Router Advertisements, DNS and the DHCPv4 observer of spec.ipv4. Running on every
+// replica, they keep the shared listeners warm, so that a receiver the leader creates
+// for the same spec learns the current prefix or address as soon as it subscribes.
+// DHCPv6-PD exchanges stay with the leader, and static prefixes need no warming.
+func (f *DefaultReceiverFactory) CreateStandbyReceivers(spec dynamicprefixiov1alpha1.DynamicPrefixSpec) ([]StandbyReceiver, error) {
+	if f.registry == nil {
+		return nil, nil
+	}
+
+	receivers, err := f.createStandbyAcquisitionReceivers(spec.Acquisition)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, uplink := range spec.Uplinks {
+		uplinkReceivers, err := f.createStandbyAcquisitionReceivers(uplink.Acquisition)
+		if err != nil {
+			return nil, fmt.Errorf("uplink %q: %w", uplink.Name, err)
+		}
+		receivers = append(receivers, uplinkReceivers...)
+	}
+
+	if spec.IPv4 != nil {
+		receiver, err := f.CreateIPv4Receiver(spec.IPv4)
+		if err != nil {
+			return nil, fmt.Errorf("ipv4: %w", err)
+		}
+		receivers = append(receivers, receiver)
+	}
+	return receivers, nil
+}
+
+// createStandbyAcquisitionReceivers creates the RA and DNS receivers of an acquisition spec.
+func (f *DefaultReceiverFactory) createStandbyAcquisitionReceivers(spec dynamicprefixiov1alpha1.AcquisitionSpec) ([]StandbyReceiver, error) {
+	var receivers []StandbyReceiver
+	if len(spec.Sources) == 0 {
+		if spec.RouterAdvertisement != nil && spec.RouterAdvertisement.Enabled {
+			receiver, err := f.createRAReceiver(spec.RouterAdvertisement)
+			if err != nil {
+				return nil, err
+			}
+			receivers = append(receivers, receiver)
+		}
+		return receivers, nil
+	}
+
+	for _, source := range spec.Sources {
+		var receiver Receiver
+		var err error
+		switch {
+		case source.RouterAdvertisement != nil && source.RouterAdvertisement.Enabled:
+			receiver, err = f.createRAReceiver(source.RouterAdvertisement)
+		case source.DNS != nil:
+			receiver, err = f.createDNSReceiver(source.DNS)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("source %q: %w", source.Name, err)
+		}
+		receivers = append(receivers, receiver)
+	}
+	return receivers, nil
+}
+
+// createStaticReceiver creates a static receiver from the spec.
+func (f *DefaultReceiverFactory) createStaticReceiver(spec *dynamicprefixiov1alpha1.StaticSourceSpec) (*StaticReceiver, error) {
+	network, err := ParsePrefix(spec.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	if !network.Addr().Is6() || network.Addr().Is4In6() {
+		return nil, fmt.Errorf("static prefix %q is not an IPv6 prefix", spec.Prefix)
+	}
+
+	return NewStaticReceiver(network), nil
+}
+
+// parseUnicastIPv6 parses an IPv6 unicast address.
+func parseUnicastIPv6(s string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	if !addr.Is6() || addr.Is4In6() || addr.IsMulticast() || addr.IsUnspecified() {
+		return netip.Addr{}, fmt.Errorf("%q is not an IPv6 unicast address", s)
+	}
+	return addr, nil
+}
+
+// dhcpv6ClientOptions converts the custom client options of the spec.
+func dhcpv6ClientOptions(spec *dynamicprefixiov1alpha1.DHCPv6ClientOptionsSpec) ([]dhcpv6.Option, error) {
+	var options []dhcpv6.Option
+
+	if spec.VendorClass != nil {
+		vendorClass := &dhcpv6.OptVendorClass{EnterpriseNumber: uint32(spec.VendorClass.EnterpriseNumber)}
+		for i, value := range spec.VendorClass.Data {
+			data, err := optionValueBytes(value)
+			if err != nil {
+				return nil, fmt.Errorf("vendorClass data %d: %w", i, err)
+			}
+			vendorClass.Data = append(vendorClass.Data, data)
+		}
+		options = append(options, vendorClass)
+	}
+
+	if len(spec.UserClass) > 0 {
+		userClass := &dhcpv6.OptUserClass{}
+		for i, value := range spec.UserClass {
+			data, err := optionValueBytes(value)
+			if err != nil {
+				return nil, fmt.Errorf("userClass %d: %w", i, err)
+			}
+			userClass.UserClasses = append(userClass.UserClasses, data)
+		}
+		options = append(options, userClass)
+	}
+
+	if spec.VendorOptions != nil {
+		vendorOpts := &dhcpv6.OptVendorOpts{EnterpriseNumber: uint32(spec.VendorOptions.EnterpriseNumber)}
+		for _, raw := range spec.VendorOptions.Options {
+			opt, err := rawOption(raw)
+			if err != nil {
+				return nil, fmt.Errorf("vendorOptions: %w", err)
+			}
+			vendorOpts.VendorOpts = append(vendorOpts.VendorOpts, opt)
+		}
+		options = append(options, vendorOpts)
+	}
+
+	for _, raw := range spec.Raw {
+		opt, err := rawOption(raw)
+		if err != nil {
+			return nil, fmt.Errorf("raw: %w", err)
+		}
+		options = append(options, opt)
+	}
+
+	return options, nil
+}
+
+// rawOption converts an option given by code and payload.
+func rawOption(spec dynamicprefixiov1alpha1.DHCPv6RawOption) (dhcpv6.Option, error) {
+	if spec.Code < 1 || spec.Code > 0xffff {
+		return nil, fmt.Errorf("option code %d out of range", spec.Code)
+	}
+	data, err := optionValueBytes(spec.DHCPv6OptionValue)
+	if err != nil {
+		return nil, fmt.Errorf("option %d: %w", spec.Code, err)
+	}
+	return &dhcpv6.OptionGeneric{OptionCode: dhcpv6.OptionCode(spec.Code), OptionData: data}, nil
+}
+
+// optionValueBytes returns the payload of an option value.
+// Secret references must have been resolved into Hex by the caller.
+func optionValueBytes(value dynamicprefixiov1alpha1.DHCPv6OptionValue) ([]byte, error) {
+	if value.SecretKeyRef != nil {
+		ref := value.SecretKeyRef
+		return nil, fmt.Errorf("secret %s/%s key %q was not resolved", ref.Namespace, ref.Name, ref.Key)
+	}
+
+	switch {
+	case value.Text != "" && value.Hex != "":
+		return nil, fmt.Errorf("only one of text, hex or secretKeyRef may be set")
+	case value.Hex != "":
+		data, err := hex.DecodeString(strings.ReplaceAll(value.Hex, ":", ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex value: %w", err)
+		}
+		return data, nil
+	default:
+		return []byte(value.Text), nil
+	}
+}
+
+// dhcpv6AuthConfig converts the authentication settings of the spec.
+func dhcpv6AuthConfig(spec *dynamicprefixiov1alpha1.DHCPv6AuthenticationSpec) (*DHCPv6AuthConfig, error) {
+	auth := &DHCPv6AuthConfig{Protocol: DHCPv6AuthProtocol(spec.Protocol)}
+
+	switch auth.Protocol {
+	case DHCPv6AuthDelayed:
+		if spec.Key == nil {
+			return nil, fmt.Errorf("delayed authentication requires a key")
+		}
+		key, err := optionValueBytes(*spec.Key)
+		if err != nil {
+			return nil, fmt.Errorf("key: %w", err)
+		}
+		if len(key) == 0 {
+			return nil, fmt.Errorf("delayed authentication requires a key")
+		}
+		auth.Realm = spec.Realm
+		auth.KeyID = uint32(spec.KeyID)
+		auth.Key = key
+		return auth, nil
+	default:
+		return nil, fmt.Errorf("unsupported protocol %q", spec.Protocol)
+	}
+}
@@ -51,7 +51,7 @@ func TestDefaultReceiverFactory_CreateReceiver(t *testing.T) {
 					Enabled:   true,
 				},
 			},
-			expectedType:   "*prefix.RAReceiver",
+			expectedType:   "*prefix.subscription",
 			expectedSource: SourceRouterAdvertisement,
 			wantErr:        false,
 		},
@@ -109,11 +109,331 @@ func TestDefaultReceiverFactory_CreateReceiver(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "DHCPv6-PD with server address",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{
+					Interface:     "eth0",
+					ServerAddress: "2001:db8::1",
+				},
+			},
+			expectedType:   "*prefix.DHCPv6PDReceiver",
+			expectedSource: SourceDHCPv6PD,
+			wantErr:        false,
+		},
+		{
+			name: "DHCPv6-PD with multicast server address",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{
+					Interface:     "eth0",
+					ServerAddress: "ff02::1:2",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "DHCPv6-PD via relay",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{
+					Interface: "eth0",
+					Relay: &dynamicprefixiov1alpha1.DHCPv6RelaySpec{
+						Destination: "2001:db8:ffff::1",
+						LinkAddress: "2001:db8:1::10",
+					},
+				},
+			},
+			expectedType:   "*prefix.DHCPv6PDReceiver",
+			expectedSource: SourceDHCPv6PD,
+			wantErr:        false,
+		},
+		{
+			name: "DHCPv6-PD with server address and relay",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{
+					Interface:     "eth0",
+					ServerAddress: "2001:db8::1",
+					Relay:         &dynamicprefixiov1alpha1.DHCPv6RelaySpec{Destination: "2001:db8::2"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "DHCPv6-PD with client options and delayed authentication",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{
+					Interface: "eth0",
+					Options: &dynamicprefixiov1alpha1.DHCPv6ClientOptionsSpec{
+						VendorClass: &dynamicprefixiov1alpha1.DHCPv6VendorClassSpec{
+							EnterpriseNumber: 1038,
+							Data:             []dynamicprefixiov1alpha1.DHCPv6OptionValue{{Text: "sagem"}},
+						},
+						UserClass: []dynamicprefixiov1alpha1.DHCPv6OptionValue{{Text: "FSVDSL_livebox.Internet.softathome.Livebox3"}},
+						Raw: []dynamicprefixiov1alpha1.DHCPv6RawOption{
+							{Code: 11, DHCPv6OptionValue: dynamicprefixiov1alpha1.DHCPv6OptionValue{Hex: "00:00:00:00"}},
+						},
+					},
+					Authentication: &dynamicprefixiov1alpha1.DHCPv6AuthenticationSpec{
+						Protocol: "delayed",
+						Realm:    "isp.example",
+						Key:      &dynamicprefixiov1alpha1.DHCPv6OptionValue{Hex: "0102"},
+					},
+				},
+			},
+			expectedType:   "*prefix.DHCPv6PDReceiver",
+			expectedSource: SourceDHCPv6PD,
+			wantErr:        false,
+		},
+		{
+			name: "DHCPv6-PD with unresolved secret reference",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{
+					Interface: "eth0",
+					Options: &dynamicprefixiov1alpha1.DHCPv6ClientOptionsSpec{
+						Raw: []dynamicprefixiov1alpha1.DHCPv6RawOption{{
+							Code: 11,
+							DHCPv6OptionValue: dynamicprefixiov1alpha1.DHCPv6OptionValue{
+								SecretKeyRef: &dynamicprefixiov1alpha1.SecretKeySelector{Namespace: "ns", Name: "isp", Key: "auth"},
+							},
+						}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "DHCPv6-PD delayed authentication without key",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{
+					Interface:      "eth0",
+					Authentication: &dynamicprefixiov1alpha1.DHCPv6AuthenticationSpec{Protocol: "delayed"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "DHCPv6-PD reconfigure-key authentication is not supported",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{
+					Interface:      "eth0",
+					Authentication: &dynamicprefixiov1alpha1.DHCPv6AuthenticationSpec{Protocol: "reconfigure-key"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "DHCPv6-PD on a macvlan",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{
+					Interface: "eth0",
+					Macvlan:   &dynamicprefixiov1alpha1.MacvlanSpec{MACAddress: "02:00:5e:10:00:01"},
+				},
+			},
+			expectedType:   "*prefix.DHCPv6PDReceiver",
+			expectedSource: SourceDHCPv6PD,
+			wantErr:        false,
+		},
+		{
+			name: "DHCPv6-PD on a macvlan with multicast MAC",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{
+					Interface: "eth0",
+					Macvlan:   &dynamicprefixiov1alpha1.MacvlanSpec{MACAddress: "01:00:5e:10:00:01"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Ordered sources",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				Sources: []dynamicprefixiov1alpha1.AcquisitionSourceSpec{
+					{
+						Name:     "pd",
+						DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{Interface: "eth0"},
+					},
+					{
+						Name:     "static",
+						Priority: 10,
+						Static:   &dynamicprefixiov1alpha1.StaticSourceSpec{Prefix: "2001:db8::/56"},
+					},
+				},
+			},
+			expectedType:   "*prefix.CompositeReceiver",
+			expectedSource: SourceDHCPv6PD,
+			wantErr:        false,
+		},
+		{
+			name: "Sources take precedence over legacy fields",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{Interface: "eth0"},
+				Sources: []dynamicprefixiov1alpha1.AcquisitionSourceSpec{
+					{
+						Name:   "static",
+						Static: &dynamicprefixiov1alpha1.StaticSourceSpec{Prefix: "2001:db8::/56"},
+					},
+				},
+			},
+			expectedType:   "*prefix.CompositeReceiver",
+			expectedSource: SourceStatic,
+			wantErr:        false,
+		},
+		{
+			name: "Source with two receiver types",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				Sources: []dynamicprefixiov1alpha1.AcquisitionSourceSpec{
+					{
+						Name:     "both",
+						DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{Interface: "eth0"},
+						Static:   &dynamicprefixiov1alpha1.StaticSourceSpec{Prefix: "2001:db8::/56"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Static source with IPv4 prefix",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				Sources: []dynamicprefixiov1alpha1.AcquisitionSourceSpec{
+					{
+						Name:   "static",
+						Static: &dynamicprefixiov1alpha1.StaticSourceSpec{Prefix: "192.0.2.0/24"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "DNS source",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				Sources: []dynamicprefixiov1alpha1.AcquisitionSourceSpec{
+					{
+						Name: "dyndns",
+						DNS: &dynamicprefixiov1alpha1.DNSSourceSpec{
+							Hostname:     "home.example.org",
+							Resolver:     "2001:4860:4860::8888",
+							PrefixLength: 56,
+						},
+					},
+				},
+			},
+			expectedType:   "*prefix.CompositeReceiver",
+			expectedSource: SourceDNS,
+			wantErr:        false,
+		},
+		{
+			name: "DNS source with resolver hostname",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				Sources: []dynamicprefixiov1alpha1.AcquisitionSourceSpec{
+					{
+						Name: "dyndns",
+						DNS: &dynamicprefixiov1alpha1.DNSSourceSpec{
+							Hostname:     "home.example.org",
+							Resolver:     "dns.google",
+							PrefixLength: 56,
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "DNS source with invalid hostname",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				Sources: []dynamicprefixiov1alpha1.AcquisitionSourceSpec{
+					{
+						Name: "dyndns",
+						DNS: &dynamicprefixiov1alpha1.DNSSourceSpec{
+							Hostname:     "home..example.org",
+							Resolver:     "2001:4860:4860::8888",
+							PrefixLength: 56,
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Cross-check against unknown source",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				Sources: []dynamicprefixiov1alpha1.AcquisitionSourceSpec{
+					{
+						Name: "ra",
+						RouterAdvertisement: &dynamicprefixiov1alpha1.RouterAdvertisementSpec{
+							Interface: "eth0",
+							Enabled:   true,
+						},
+						CrossCheck: &dynamicprefixiov1alpha1.CrossCheckSpec{Within: "pd"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "DHCPv6-PD on the default route interface",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{
+					InterfaceSelector: &dynamicprefixiov1alpha1.InterfaceSelector{DefaultRoute: true},
+				},
+			},
+			expectedType:   "*prefix.selectingReceiver",
+			expectedSource: SourceDHCPv6PD,
+		},
+		{
+			name: "RA on interface selected by name pattern",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				RouterAdvertisement: &dynamicprefixiov1alpha1.RouterAdvertisementSpec{
+					InterfaceSelector: &dynamicprefixiov1alpha1.InterfaceSelector{NamePattern: "enp*"},
+					Enabled:           true,
+				},
+			},
+			expectedType:   "*prefix.selectingReceiver",
+			expectedSource: SourceRouterAdvertisement,
+		},
+		{
+			name: "Interface and selector both set",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{
+					Interface:         "eth0",
+					InterfaceSelector: &dynamicprefixiov1alpha1.InterfaceSelector{DefaultRoute: true},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Selector with several criteria",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{
+					InterfaceSelector: &dynamicprefixiov1alpha1.InterfaceSelector{
+						MACAddress:   "52:54:00:12:34:56",
+						DefaultRoute: true,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Selector with invalid regex",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				RouterAdvertisement: &dynamicprefixiov1alpha1.RouterAdvertisementSpec{
+					InterfaceSelector: &dynamicprefixiov1alpha1.InterfaceSelector{NameRegex: "enp("},
+					Enabled:           true,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Selector with invalid glob",
+			spec: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{
+					InterfaceSelector: &dynamicprefixiov1alpha1.InterfaceSelector{NamePattern: "enp[1"},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			receiver, err := factory.CreateReceiver(tt.spec)
+			receiver, err := factory.CreateReceiver("test", tt.spec)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CreateReceiver() error = %v, wantErr %v", err, tt.wantErr)
@@ -171,7 +491,7 @@ func TestDefaultReceiverFactory_DHCPv6PDPrefixLength(t *testing.T) {
 				},
 			}
 
-			receiver, err := factory.CreateReceiver(spec)
+			receiver, err := factory.CreateReceiver("test", spec)
 			if err != nil {
 				t.Fatalf("CreateReceiver() error = %v", err)
 			}
@@ -191,3 +511,90 @@ func TestDefaultReceiverFactory_DHCPv6PDPrefixLength(t *testing.T) {
 func intPtr(i int) *int {
 	return &i
 }
+
+func TestDefaultReceiverFactory_CreateStandbyReceivers(t *testing.T) {
+	factory := NewReceiverFactory()
+
+	spec := dynamicprefixiov1alpha1.AcquisitionSpec{
+		Sources: []dynamicprefixiov1alpha1.AcquisitionSourceSpec{
+			{Name: "isp", DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{Interface: "eth0"}},
+			{Name: "ra", RouterAdvertisement: &dynamicprefixiov1alpha1.RouterAdvertisementSpec{Interface: "eth0", Enabled: true}},
+			{Name: "dyndns", DNS: &dynamicprefixiov1alpha1.DNSSourceSpec{Hostname: "home.example.org", Resolver: "2001:4860:4860::8888", PrefixLength: 56}},
+			{Name: "fallback", Static: &dynamicprefixiov1alpha1.StaticSourceSpec{Prefix: "2001:db8::/48"}},
+		},
+	}
+
+	ipv4 := &dynamicprefixiov1alpha1.IPv4Spec{DHCPv4: dynamicprefixiov1alpha1.DHCPv4Spec{Interface: "eth0"}}
+	receivers, err := factory.CreateStandbyReceivers(dynamicprefixiov1alpha1.DynamicPrefixSpec{Acquisition: spec, IPv4: ipv4})
+	if err != nil {
+		t.Fatalf("CreateStandbyReceivers() error = %v", err)
+	}
+	if len(receivers) != 3 {
+		t.Fatalf("standby receivers = %d, want RA, DNS and the DHCPv4 observer", len(receivers))
+	}
+	var sources []Source
+	for _, r := range receivers[:2] {
+		sources = append(sources, r.(Receiver).Source())
+	}
+	if sources[0] != SourceRouterAdvertisement || sources[1] != SourceDNS {
+		t.Errorf("standby sources = %v, want RA and DNS only", sources)
+	}
+
+	// The leader's DHCPv4 observer is shared with the standby one
+	leaderIPv4, err := factory.CreateIPv4Receiver(ipv4)
+	if err != nil {
+		t.Fatalf("CreateIPv4Receiver() error = %v", err)
+	}
+	if leaderIPv4.(*ipv4Subscription).key != receivers[2].(*ipv4Subscription).key {
+		t.Error("leader and standby DHCPv4 observers are not shared")
+	}
+
+	// The leader's receiver subscribes to the same shared listener
+	leader, err := factory.CreateReceiver("home", dynamicprefixiov1alpha1.AcquisitionSpec{
+		RouterAdvertisement: &dynamicprefixiov1alpha1.RouterAdvertisementSpec{Interface: "eth0", Enabled: true},
+	})
+	if err != nil {
+		t.Fatalf("CreateReceiver() error = %v", err)
+	}
+	if leader.(*subscription).key != receivers[0].(*subscription).key {
+		t.Error("leader and standby RA receivers do not share a listener")
+	}
+
+	// Uplink RA sources are warmed too, sharing the listener of the leader's uplink receiver
+	uplinkAcquisition := dynamicprefixiov1alpha1.AcquisitionSpec{
+		RouterAdvertisement: &dynamicprefixiov1alpha1.RouterAdvertisementSpec{Interface: "eth1", Enabled: true},
+	}
+	withUplinks, err := factory.CreateStandbyReceivers(dynamicprefixiov1alpha1.DynamicPrefixSpec{
+		Acquisition: dynamicprefixiov1alpha1.AcquisitionSpec{
+			DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{Interface: "eth0"},
+		},
+		Uplinks: []dynamicprefixiov1alpha1.UplinkSpec{
+			{Name: "isp-b", Acquisition: uplinkAcquisition},
+			{Name: "isp-c", Acquisition: dynamicprefixiov1alpha1.AcquisitionSpec{
+				DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{Interface: "eth2"},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateStandbyReceivers(uplinks) error = %v", err)
+	}
+	if len(withUplinks) != 1 {
+		t.Fatalf("standby receivers = %d, want the RA receiver of uplink isp-b only", len(withUplinks))
+	}
+	leaderUplink, err := factory.CreateReceiver("home-isp-b", uplinkAcquisition)
+	if err != nil {
+		t.Fatalf("CreateReceiver() error = %v", err)
+	}
+	if leaderUplink.(*subscription).key != withUplinks[0].(*subscription).key {
+		t.Error("leader and standby uplink RA receivers do not share a listener")
+	}
+
+	legacy, err := factory.CreateStandbyReceivers(dynamicprefixiov1alpha1.DynamicPrefixSpec{
+		Acquisition: dynamicprefixiov1alpha1.AcquisitionSpec{
+			DHCPv6PD: &dynamicprefixiov1alpha1.DHCPv6PDSpec{Interface: "eth0"},
+		},
+	})
+	if err != nil || len(legacy) != 0 {
+		t.Errorf("CreateStandbyReceivers(DHCPv6-PD only) = %v, %v; want none", legacy, err)
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ipv6RouteFile lists the IPv6 routes of the calling thread's network namespace.
+const ipv6RouteFile = "/proc/thread-self/net/ipv6_route"
+
+// Route flags, from linux/route.h
+const (
+	rtfUp     = 0x0001
+	rtfReject = 0x0200
+)
+
+// InterfaceSelector selects a network interface by MAC address, name pattern or
+// default route. Exactly one criterion is set.
+type InterfaceSelector struct {
+	// MAC selects the interface with this hardware address
+	MAC net.HardwareAddr
+
+	// NamePattern selects interfaces whose name matches this glob
+	NamePattern string
+
+	// NameRegex selects interfaces whose name matches this expression
+	NameRegex *regexp.Regexp
+
+	// DefaultRoute selects the interface carrying the IPv6 default route
+	DefaultRoute bool
+}
+
+// String describes the selection criterion for logs and errors.
+func (s InterfaceSelector) String() string {
+	switch {
+	case s.MAC != nil:
+		return "macAddress " + s.MAC.String()
+	case s.NamePattern != "":
+		return fmt.Sprintf("namePattern %q", s.NamePattern)
+	case s.NameRegex != nil:
+		return fmt.Sprintf("nameRegex %q", s.NameRegex)
+	default:
+		return "defaultRoute"
+	}
+}
+
+// resolve returns the name of the selected interface in the calling thread's
+// network namespace.
+func (s InterfaceSelector) resolve() (string, error) {
+	if s.DefaultRoute {
+		f, err := os.Open(ipv6RouteFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read IPv6 routes: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		return parseIPv6DefaultRoute(f)
+	}
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", fmt.Errorf("failed to list interfaces: %w", err)
+	}
+	return s.choose(ifaces)
+}
+
+// choose picks the matching interface, preferring interfaces that are up and
+// then the lowest index. Loopback interfaces never match.
+func (s InterfaceSelector) choose(ifaces []net.Interface) (string, error) {
+	var chosen *net.Interface
+	for i := range ifaces {
+		ifi := &ifaces[i]
+		if ifi.Flags&net.FlagLoopback != 0 || !s.matches(ifi) {
+			continue
+		}
+		if chosen == nil || better(ifi, chosen) {
+			chosen = ifi
+		}
+	}
+	if chosen == nil {
+		return "", fmt.Errorf("no interface matches %s", s)
+	}
+	return chosen.Name, nil
+}
+
+// better reports whether a should be chosen over b.
+func better(a, b *net.Interface) bool {
+	aUp, bUp := a.Flags&net.FlagUp != 0, b.Flags&net.FlagUp != 0
+	if aUp != bUp {
+		return aUp
+	}
+	return a.Index < b.Index
+}
+
+// matches reports whether the interface satisfies the selector.
+func (s InterfaceSelector) matches(ifi *net.Interface) bool {
+	switch {
+	case s.MAC != nil:
+		return bytes.Equal(ifi.HardwareAddr, s.MAC)
+	case s.NamePattern != "":
+		ok, _ := path.Match(s.NamePattern, ifi.Name)
+		return ok
+	case s.NameRegex != nil:
+		return s.NameRegex.MatchString(ifi.Name)
+	default:
+		return false
+	}
+}
+
+// parseIPv6DefaultRoute returns the interface of the usable IPv6 default route
+// with the lowest metric from the contents of /proc/net/ipv6_route.
+func parseIPv6DefaultRoute(r io.Reader) (string, error) {
+	const defaultDestination = "00000000000000000000000000000000"
+
+	var (
+		iface      string
+		bestMetric uint64
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// destination, length, source, length, next hop, metric, refcount, use, flags, interface
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[0] != defaultDestination || fields[1] != "00" || fields[9] == "lo" {
+			continue
+		}
+		metric, err := strconv.ParseUint(fields[5], 16, 32)
+		if err != nil {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[8], 16, 32)
+		if err != nil || flags&rtfUp == 0 || flags&rtfReject != 0 {
+			continue
+		}
+		if iface == "" || metric < bestMetric {
+			iface, bestMetric = fields[9], metric
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read IPv6 routes: %w", err)
+	}
+	if iface == "" {
+		return "", fmt.Errorf("no IPv6 default route")
+	}
+	return iface, nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestInterfaceSelectorChoose(t *testing.T) {
+	mac := func(s string) net.HardwareAddr {
+		hw, err := net.ParseMAC(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hw
+	}
+	ifaces := []net.Interface{
+		{Index: 1, Name: "lo", Flags: net.FlagUp | net.FlagLoopback},
+		{Index: 2, Name: "enp1s0", HardwareAddr: mac("52:54:00:00:00:01")},
+		{Index: 3, Name: "enp2s0", HardwareAddr: mac("52:54:00:00:00:02"), Flags: net.FlagUp},
+		{Index: 4, Name: "bond0", HardwareAddr: mac("52:54:00:00:00:03"), Flags: net.FlagUp},
+		{Index: 5, Name: "bond0.10", HardwareAddr: mac("52:54:00:00:00:03"), Flags: net.FlagUp},
+	}
+
+	tests := []struct {
+		name     string
+		selector InterfaceSelector
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "MAC address",
+			selector: InterfaceSelector{MAC: mac("52:54:00:00:00:01")},
+			want:     "enp1s0",
+		},
+		{
+			name:     "MAC address shared by VLAN prefers lowest index",
+			selector: InterfaceSelector{MAC: mac("52:54:00:00:00:03")},
+			want:     "bond0",
+		},
+		{
+			name:     "glob prefers interfaces that are up",
+			selector: InterfaceSelector{NamePattern: "enp*"},
+			want:     "enp2s0",
+		},
+		{
+			name:     "glob matches VLAN",
+			selector: InterfaceSelector{NamePattern: "bond*.10"},
+			want:     "bond0.10",
+		},
+		{
+			name:     "regex",
+			selector: InterfaceSelector{NameRegex: regexp.MustCompile(`^bond[0-9]+\.`)},
+			want:     "bond0.10",
+		},
+		{
+			name:     "loopback never matches",
+			selector: InterfaceSelector{NamePattern: "lo"},
+			wantErr:  true,
+		},
+		{
+			name:     "no match",
+			selector: InterfaceSelector{NamePattern: "eth*"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.selector.choose(ifaces)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("choose() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("choose() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIPv6DefaultRoute(t *testing.T) {
+	const (
+		linkLocal   = "fe800000000000000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000001 00000000 00000001     eth0\n"
+		defaultEth0 = "00000000000000000000000000000000 00 00000000000000000000000000000000 00 fe80000000000000000000000000000a 00000400 00000001 00000000 00000003     eth0\n"
+		defaultWlan = "00000000000000000000000000000000 00 00000000000000000000000000000000 00 fe80000000000000000000000000000b 00000258 00000001 00000000 00000003    wlan0\n"
+		unreachable = "00000000000000000000000000000000 00 00000000000000000000000000000000 00 00000000000000000000000000000000 ffffffff 00000001 00000000 00200200       lo\n"
+		downRoute   = "00000000000000000000000000000000 00 00000000000000000000000000000000 00 fe80000000000000000000000000000c 00000001 00000001 00000000 00000002     eth1\n"
+	)
+
+	tests := []struct {
+		name    string
+		routes  string
+		want    string
+		wantErr bool
+	}{
+		{name: "single default route", routes: linkLocal + defaultEth0, want: "eth0"},
+		{name: "lowest metric wins", routes: defaultEth0 + defaultWlan, want: "wlan0"},
+		{name: "unreachable and down routes are ignored", routes: unreachable + downRoute + defaultEth0, want: "eth0"},
+		{name: "no default route", routes: linkLocal + unreachable, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIPv6DefaultRoute(strings.NewReader(tt.routes))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseIPv6DefaultRoute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseIPv6DefaultRoute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
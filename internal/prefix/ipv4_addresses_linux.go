@@ -0,0 +1,96 @@
+//go:build linux
+
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// interfaceIPv4Addresses lists the IPv4 addresses of iface in the calling
+// thread's network namespace, with the remaining lifetime of their lease.
+func interfaceIPv4Addresses(iface string) ([]ipv4Assignment, error) {
+	ifi, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interface %s: %w", iface, err)
+	}
+
+	rib, err := syscall.NetlinkRIB(unix.RTM_GETADDR, unix.AF_INET)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list IPv4 addresses: %w", err)
+	}
+	msgs, err := syscall.ParseNetlinkMessage(rib)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse IPv4 addresses: %w", err)
+	}
+
+	var addrs []ipv4Assignment
+	for i := range msgs {
+		m := &msgs[i]
+		if m.Header.Type != unix.RTM_NEWADDR || len(m.Data) < unix.SizeofIfAddrmsg {
+			continue
+		}
+		ifa := (*unix.IfAddrmsg)(unsafe.Pointer(&m.Data[0]))
+		if int(ifa.Index) != ifi.Index {
+			continue
+		}
+		attrs, err := syscall.ParseNetlinkRouteAttr(m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse IPv4 address: %w", err)
+		}
+
+		var (
+			addr  netip.Addr
+			valid uint32 = ^uint32(0)
+		)
+		for _, a := range attrs {
+			switch a.Attr.Type {
+			case unix.IFA_LOCAL:
+				addr, _ = netip.AddrFromSlice(a.Value)
+			case unix.IFA_ADDRESS:
+				// IFA_LOCAL wins on point-to-point links, where IFA_ADDRESS is the peer
+				if !addr.IsValid() {
+					addr, _ = netip.AddrFromSlice(a.Value)
+				}
+			case unix.IFA_CACHEINFO:
+				if len(a.Value) >= unix.SizeofIfaCacheinfo {
+					// prefered, valid, cstamp, tstamp in host byte order
+					valid = binary.NativeEndian.Uint32(a.Value[4:8])
+				}
+			}
+		}
+		if !addr.Is4() {
+			continue
+		}
+
+		assignment := ipv4Assignment{prefix: netip.PrefixFrom(addr, int(ifa.Prefixlen))}
+		if ifa.Flags&unix.IFA_F_PERMANENT == 0 && valid != ^uint32(0) {
+			assignment.validLifetime = time.Duration(valid) * time.Second
+		}
+		addrs = append(addrs, assignment)
+	}
+	return addrs, nil
+}
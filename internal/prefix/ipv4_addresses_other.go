@@ -0,0 +1,53 @@
+//go:build !linux
+
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+)
+
+// interfaceIPv4Addresses lists the IPv4 addresses of iface. Lease lifetimes
+// are only known on Linux, so every address is reported as permanent.
+func interfaceIPv4Addresses(iface string) ([]ipv4Assignment, error) {
+	ifi, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interface %s: %w", iface, err)
+	}
+	ifAddrs, err := ifi.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list addresses of %s: %w", iface, err)
+	}
+
+	var addrs []ipv4Assignment
+	for _, a := range ifAddrs {
+		ipNet, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		addr, ok := netip.AddrFromSlice(ipNet.IP)
+		if !ok || !addr.Unmap().Is4() {
+			continue
+		}
+		ones, _ := ipNet.Mask.Size()
+		addrs = append(addrs, ipv4Assignment{prefix: netip.PrefixFrom(addr.Unmap(), ones)})
+	}
+	return addrs, nil
+}
@@ -0,0 +1,338 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ipv4RouteFile lists the IPv4 routes of the calling thread's network namespace.
+const ipv4RouteFile = "/proc/thread-self/net/route"
+
+// ipv4ObserveInterval is how often the interface is re-read when no address
+// change notifications are available.
+const ipv4ObserveInterval = time.Minute
+
+// IPv4Address is the WAN IPv4 address with the subnet and router it came with
+type IPv4Address struct {
+	// Address is the address with the length of the subnet it was assigned in
+	Address netip.Prefix
+
+	// Router is the IPv4 default gateway on the interface, if any
+	Router netip.Addr
+
+	// ValidLifetime is the remaining lease time when the address was read;
+	// zero for addresses without a lease
+	ValidLifetime time.Duration
+
+	// ReceivedAt is when the address was read
+	ReceivedAt time.Time
+}
+
+// Subnet returns the subnet the address was assigned in
+func (a *IPv4Address) Subnet() netip.Prefix {
+	return a.Address.Masked()
+}
+
+// IPv4Receiver tracks the WAN IPv4 address. Events carry no prefix; they only
+// tell that CurrentIPv4 changed or could not be read.
+type IPv4Receiver interface {
+	// Start begins tracking the address
+	Start(ctx context.Context) error
+
+	// Stop stops tracking the address
+	Stop() error
+
+	// Events returns a channel of address events
+	Events() <-chan Event
+
+	// CurrentIPv4 returns the current address, if any
+	CurrentIPv4() *IPv4Address
+}
+
+// ipv4Assignment is an IPv4 address configured on an interface
+type ipv4Assignment struct {
+	prefix netip.Prefix
+
+	// validLifetime is zero for permanent addresses
+	validLifetime time.Duration
+}
+
+// DHCPv4Observer watches the address a DHCPv4 client on the node (such as
+// systemd-networkd or the router's own client) assigns to an interface. It
+// does not send DHCPv4 messages itself.
+type DHCPv4Observer struct {
+	mu      sync.RWMutex
+	iface   string
+	netns   string
+	current *IPv4Address
+	events  chan Event
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+
+	// read returns the addresses and default router of the interface; a test seam
+	read func() ([]ipv4Assignment, netip.Addr, error)
+}
+
+// NewDHCPv4Observer creates an observer for iface in the network namespace at
+// netns. An empty netns is the operator's own namespace.
+func NewDHCPv4Observer(iface, netns string) *DHCPv4Observer {
+	o := &DHCPv4Observer{
+		iface:  iface,
+		netns:  netns,
+		events: make(chan Event, 10),
+	}
+	o.read = o.readInterface
+	return o
+}
+
+// Start reads the address once and then follows address and route changes.
+func (o *DHCPv4Observer) Start(ctx context.Context) error {
+	o.mu.Lock()
+	if o.started {
+		o.mu.Unlock()
+		return nil
+	}
+	o.ctx, o.cancel = context.WithCancel(ctx)
+	o.started = true
+	o.mu.Unlock()
+
+	o.observe()
+	go o.watch()
+	return nil
+}
+
+// Stop stops following the interface.
+func (o *DHCPv4Observer) Stop() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !o.started {
+		return nil
+	}
+	o.started = false
+	o.cancel()
+	return nil
+}
+
+// Events returns the channel of address events.
+func (o *DHCPv4Observer) Events() <-chan Event {
+	return o.events
+}
+
+// CurrentIPv4 returns the current address, if any.
+func (o *DHCPv4Observer) CurrentIPv4() *IPv4Address {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.current
+}
+
+// watch re-reads the interface on link changes and periodically, since a
+// renewed lease does not always produce a notification.
+func (o *DHCPv4Observer) watch() {
+	log := logf.FromContext(o.ctx).WithName("dhcpv4-observer")
+
+	changes, err := watchLinks(o.ctx, o.netns)
+	if err != nil {
+		log.V(1).Info("Address change notifications unavailable, polling", "error", err.Error())
+	}
+	ticker := time.NewTicker(ipv4ObserveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-o.ctx.Done():
+			return
+		case <-changes:
+		case <-ticker.C:
+		}
+		o.observe()
+	}
+}
+
+// observe reads the interface and reports what changed
+func (o *DHCPv4Observer) observe() {
+	log := logf.FromContext(o.ctx).WithName("dhcpv4-observer")
+
+	addrs, router, err := o.read()
+	now := time.Now()
+
+	o.mu.Lock()
+	previous := o.current
+	var ev Event
+	switch {
+	case err != nil:
+		ev = Event{Type: EventTypeFailed, Error: err}
+	default:
+		chosen, ok := chooseIPv4Address(addrs)
+		switch {
+		case !ok && previous == nil:
+			ev = Event{Type: EventTypeFailed, Error: fmt.Errorf("no IPv4 address on %s", o.iface)}
+		case !ok:
+			o.current = nil
+			ev = Event{Type: EventTypeExpired}
+		default:
+			o.current = &IPv4Address{
+				Address:       chosen.prefix,
+				Router:        router,
+				ValidLifetime: chosen.validLifetime,
+				ReceivedAt:    now,
+			}
+			switch {
+			case previous == nil:
+				ev = Event{Type: EventTypeAcquired}
+			case previous.Address != chosen.prefix || previous.Router != router:
+				ev = Event{Type: EventTypeChanged}
+			case leaseExtended(previous, o.current):
+				ev = Event{Type: EventTypeRenewed}
+			}
+		}
+	}
+	current := o.current
+	o.mu.Unlock()
+
+	switch ev.Type {
+	case "":
+		return
+	case EventTypeFailed:
+		log.V(1).Info("Failed to read IPv4 address", "interface", o.iface, "error", ev.Error.Error())
+	default:
+		var addr string
+		if current != nil {
+			addr = current.Address.String()
+		}
+		log.Info("IPv4 address updated", "interface", o.iface, "event", ev.Type, "address", addr)
+	}
+
+	select {
+	case o.events <- ev:
+	default:
+		// Events only trigger a reconcile; a pending one covers this change too
+	}
+}
+
+// leaseExtended reports whether cur expires noticeably later than prev. The
+// kernel reports remaining lifetimes in whole seconds, so re-reading the same
+// lease moves its expiry by up to a second.
+func leaseExtended(prev, cur *IPv4Address) bool {
+	if prev.ValidLifetime == 0 || cur.ValidLifetime == 0 {
+		return false
+	}
+	prevExpiry := prev.ReceivedAt.Add(prev.ValidLifetime)
+	curExpiry := cur.ReceivedAt.Add(cur.ValidLifetime)
+	return curExpiry.Sub(prevExpiry) > 5*time.Second
+}
+
+// readInterface reads the addresses and default router of the interface
+// inside the observer's network namespace
+func (o *DHCPv4Observer) readInterface() ([]ipv4Assignment, netip.Addr, error) {
+	var (
+		addrs  []ipv4Assignment
+		router netip.Addr
+	)
+	err := inNetNS(o.netns, func() error {
+		var err error
+		addrs, err = interfaceIPv4Addresses(o.iface)
+		if err != nil {
+			return err
+		}
+
+		// The router is informational; without /proc it is simply not reported
+		if f, err := os.Open(ipv4RouteFile); err == nil {
+			defer func() { _ = f.Close() }()
+			router, _ = parseIPv4DefaultRouter(f, o.iface)
+		}
+		return nil
+	})
+	return addrs, router, err
+}
+
+// chooseIPv4Address picks the WAN address among the addresses of an
+// interface. Addresses with a lease are preferred over permanent ones, which
+// are usually configured by hand; link-local addresses never qualify.
+func chooseIPv4Address(addrs []ipv4Assignment) (ipv4Assignment, bool) {
+	var (
+		chosen ipv4Assignment
+		found  bool
+	)
+	for _, a := range addrs {
+		ip := a.prefix.Addr()
+		if !ip.Is4() || !ip.IsGlobalUnicast() {
+			continue
+		}
+		if !found || (chosen.validLifetime == 0 && a.validLifetime > 0) {
+			chosen, found = a, true
+		}
+	}
+	return chosen, found
+}
+
+// parseIPv4DefaultRouter returns the gateway of the IPv4 default route on
+// iface with the lowest metric from the contents of /proc/net/route.
+func parseIPv4DefaultRouter(r io.Reader, iface string) (netip.Addr, error) {
+	var (
+		router     netip.Addr
+		bestMetric uint64
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// interface, destination, gateway, flags, refcount, use, metric, mask, ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 || fields[0] != iface || fields[1] != "00000000" || fields[7] != "00000000" {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil || flags&rtfUp == 0 || flags&rtfReject != 0 {
+			continue
+		}
+		metric, err := strconv.ParseUint(fields[6], 10, 32)
+		if err != nil {
+			continue
+		}
+		gw, err := hex.DecodeString(fields[2])
+		if err != nil || len(gw) != 4 {
+			continue
+		}
+		// The kernel prints the address in host byte order
+		var addr [4]byte
+		binary.BigEndian.PutUint32(addr[:], binary.NativeEndian.Uint32(gw))
+		if !router.IsValid() || metric < bestMetric {
+			router, bestMetric = netip.AddrFrom4(addr), metric
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return netip.Addr{}, fmt.Errorf("failed to read IPv4 routes: %w", err)
+	}
+	if !router.IsValid() {
+		return netip.Addr{}, fmt.Errorf("no IPv4 default route on %s", iface)
+	}
+	return router, nil
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChooseIPv4Address(t *testing.T) {
+	tests := []struct {
+		name   string
+		addrs  []ipv4Assignment
+		want   string
+		wantOK bool
+	}{
+		{
+			name:  "none",
+			addrs: nil,
+		},
+		{
+			name: "link-local and loopback never qualify",
+			addrs: []ipv4Assignment{
+				{prefix: netip.MustParsePrefix("169.254.10.1/16")},
+				{prefix: netip.MustParsePrefix("127.0.0.1/8")},
+			},
+		},
+		{
+			name: "leased address preferred over permanent",
+			addrs: []ipv4Assignment{
+				{prefix: netip.MustParsePrefix("192.168.1.2/24")},
+				{prefix: netip.MustParsePrefix("203.0.113.7/29"), validLifetime: time.Hour},
+			},
+			want:   "203.0.113.7/29",
+			wantOK: true,
+		},
+		{
+			name: "first permanent address without a lease",
+			addrs: []ipv4Assignment{
+				{prefix: netip.MustParsePrefix("203.0.113.7/29")},
+				{prefix: netip.MustParsePrefix("203.0.113.8/29")},
+			},
+			want:   "203.0.113.7/29",
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := chooseIPv4Address(tt.addrs)
+			if ok != tt.wantOK {
+				t.Fatalf("chooseIPv4Address() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && got.prefix.String() != tt.want {
+				t.Errorf("chooseIPv4Address() = %s, want %s", got.prefix, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIPv4DefaultRouter(t *testing.T) {
+	// /proc/net/route prints addresses in host byte order; these values are
+	// from a little-endian host
+	routes := strings.Join([]string{
+		"Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT",
+		"wan0\t00000000\t027100CB\t0003\t0\t0\t200\t00000000\t0\t0\t0",
+		"wan0\t00000000\t017100CB\t0003\t0\t0\t100\t00000000\t0\t0\t0",
+		"wan0\t007100CB\t00000000\t0001\t0\t0\t100\t00FFFFFF\t0\t0\t0",
+		"lan0\t00000000\t0101A8C0\t0003\t0\t0\t50\t00000000\t0\t0\t0",
+	}, "\n")
+
+	got, err := parseIPv4DefaultRouter(strings.NewReader(routes), "wan0")
+	if err != nil {
+		t.Fatalf("parseIPv4DefaultRouter() error = %v", err)
+	}
+	if want := netip.MustParseAddr("203.0.113.1"); got != want {
+		t.Errorf("parseIPv4DefaultRouter() = %s, want %s", got, want)
+	}
+
+	if _, err := parseIPv4DefaultRouter(strings.NewReader(routes), "wan1"); err == nil {
+		t.Error("parseIPv4DefaultRouter() expected error for interface without default route")
+	}
+}
+
+func TestDHCPv4ObserverEvents(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		addrs  []ipv4Assignment
+		router = netip.MustParseAddr("203.0.113.1")
+	)
+	set := func(a ...ipv4Assignment) {
+		mu.Lock()
+		defer mu.Unlock()
+		addrs = a
+	}
+
+	o := NewDHCPv4Observer("wan0", "")
+	o.read = func() ([]ipv4Assignment, netip.Addr, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return addrs, router, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.ctx = ctx
+
+	expect := func(want EventType) {
+		t.Helper()
+		select {
+		case ev := <-o.Events():
+			if ev.Type != want {
+				t.Fatalf("event = %s (%v), want %s", ev.Type, ev.Error, want)
+			}
+		default:
+			t.Fatalf("no event, want %s", want)
+		}
+	}
+
+	o.observe()
+	expect(EventTypeFailed)
+
+	set(ipv4Assignment{prefix: netip.MustParsePrefix("203.0.113.7/29"), validLifetime: time.Hour})
+	o.observe()
+	expect(EventTypeAcquired)
+	if got := o.CurrentIPv4(); got == nil || got.Subnet().String() != "203.0.113.0/29" || got.Router != router {
+		t.Fatalf("CurrentIPv4() = %+v", got)
+	}
+
+	// Reading the same lease again is not an event
+	o.observe()
+	select {
+	case ev := <-o.Events():
+		t.Fatalf("unexpected event %s", ev.Type)
+	default:
+	}
+
+	set(ipv4Assignment{prefix: netip.MustParsePrefix("198.51.100.20/24"), validLifetime: time.Hour})
+	o.observe()
+	expect(EventTypeChanged)
+
+	set()
+	o.observe()
+	expect(EventTypeExpired)
+	if got := o.CurrentIPv4(); got != nil {
+		t.Errorf("CurrentIPv4() = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,82 @@
+//go:build linux
+
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// watchLinks returns a channel that receives a value after links, addresses
+// or routes change in the network namespace at netns. The
+// channel is never closed; it stops receiving once ctx is done.
+func watchLinks(ctx context.Context, netns string) (<-chan struct{}, error) {
+	var fd int
+	err := inNetNS(netns, func() error {
+		var err error
+		fd, err = unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_ROUTE)
+		if err != nil {
+			return fmt.Errorf("failed to open rtnetlink socket: %w", err)
+		}
+		groups := uint32(unix.RTMGRP_LINK | unix.RTMGRP_IPV6_IFADDR | unix.RTMGRP_IPV6_ROUTE |
+			unix.RTMGRP_IPV4_IFADDR | unix.RTMGRP_IPV4_ROUTE)
+		if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: groups}); err != nil {
+			_ = unix.Close(fd)
+			return fmt.Errorf("failed to subscribe to link changes: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Wake up regularly to notice that ctx is done
+	timeout := unix.NsecToTimeval(time.Second.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &timeout); err != nil {
+		_ = unix.Close(fd)
+		return nil, fmt.Errorf("failed to set rtnetlink receive timeout: %w", err)
+	}
+
+	changes := make(chan struct{}, 1)
+	go func() {
+		defer func() { _ = unix.Close(fd) }()
+
+		buf := make([]byte, 64*1024)
+		for ctx.Err() == nil {
+			_, _, err := unix.Recvfrom(fd, buf, 0)
+			switch {
+			case errors.Is(err, unix.EAGAIN), errors.Is(err, unix.EINTR):
+				continue
+			case err != nil && !errors.Is(err, unix.ENOBUFS):
+				// ENOBUFS means notifications were lost, which is a change too
+				return
+			}
+			select {
+			case changes <- struct{}{}:
+			default:
+				// A change is already pending
+			}
+		}
+	}()
+	return changes, nil
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+)
+
+// MacvlanMode is the mode of a macvlan sub-interface.
+type MacvlanMode string
+
+const (
+	// MacvlanModeBridge lets sub-interfaces of the same parent talk to each other directly.
+	MacvlanModeBridge MacvlanMode = "bridge"
+	// MacvlanModePrivate isolates the sub-interface from others on the same parent.
+	MacvlanModePrivate MacvlanMode = "private"
+	// MacvlanModeVEPA sends all traffic through the adjacent switch.
+	MacvlanModeVEPA MacvlanMode = "vepa"
+)
+
+// MacvlanConfig configures the macvlan sub-interface a DHCPv6-PD client runs on.
+type MacvlanConfig struct {
+	// Name is the name of the sub-interface.
+	Name string
+	// MAC is the hardware address of the sub-interface.
+	MAC net.HardwareAddr
+	// Mode is the macvlan mode.
+	Mode MacvlanMode
+}
+
+// defaultMacvlanConfig returns a macvlan configuration whose name and MAC
+// address are derived from the owner, so they stay the same across restarts.
+func defaultMacvlanConfig(owner string) MacvlanConfig {
+	sum := sha256.Sum256([]byte(owner))
+
+	// Locally administered, unicast
+	mac := net.HardwareAddr{0x02, sum[0], sum[1], sum[2], sum[3], sum[4]}
+
+	return MacvlanConfig{
+		Name: "dpo" + hex.EncodeToString(sum[:4]),
+		MAC:  mac,
+		Mode: MacvlanModeBridge,
+	}
+}
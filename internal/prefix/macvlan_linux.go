@@ -0,0 +1,188 @@
+//go:build linux
+
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Macvlan modes, from linux/if_link.h
+var macvlanModes = map[MacvlanMode]uint32{
+	MacvlanModePrivate: 1,
+	MacvlanModeVEPA:    2,
+	MacvlanModeBridge:  4,
+}
+
+// ensureMacvlan makes sure the macvlan sub-interface exists on the parent with
+// the configured MAC address and is up. A sub-interface with another MAC
+// address is replaced.
+func ensureMacvlan(parent string, cfg MacvlanConfig) error {
+	parentIfi, err := net.InterfaceByName(parent)
+	if err != nil {
+		return fmt.Errorf("failed to get parent interface %s: %w", parent, err)
+	}
+
+	if ifi, err := net.InterfaceByName(cfg.Name); err == nil {
+		if bytes.Equal(ifi.HardwareAddr, cfg.MAC) {
+			if ifi.Flags&net.FlagUp != 0 {
+				return nil
+			}
+			return setLinkUp(ifi.Index)
+		}
+		if err := deleteMacvlan(cfg.Name); err != nil {
+			return err
+		}
+	}
+
+	mode, ok := macvlanModes[cfg.Mode]
+	if !ok {
+		mode = macvlanModes[MacvlanModeBridge]
+	}
+
+	info := rtAttr(unix.IFLA_INFO_KIND, []byte("macvlan"))
+	info = append(info, rtAttr(unix.NLA_F_NESTED|unix.IFLA_INFO_DATA,
+		rtAttr(unix.IFLA_MACVLAN_MODE, binary.NativeEndian.AppendUint32(nil, mode)))...)
+
+	attrs := rtAttr(unix.IFLA_IFNAME, append([]byte(cfg.Name), 0))
+	attrs = append(attrs, rtAttr(unix.IFLA_LINK, binary.NativeEndian.AppendUint32(nil, uint32(parentIfi.Index)))...)
+	attrs = append(attrs, rtAttr(unix.IFLA_ADDRESS, cfg.MAC)...)
+	attrs = append(attrs, rtAttr(unix.NLA_F_NESTED|unix.IFLA_LINKINFO, info)...)
+
+	if err := rtnetlinkLink(unix.RTM_NEWLINK, unix.NLM_F_CREATE|unix.NLM_F_EXCL, unix.IfInfomsg{}, attrs); err != nil {
+		return fmt.Errorf("failed to create macvlan %s on %s: %w", cfg.Name, parent, err)
+	}
+
+	ifi, err := net.InterfaceByName(cfg.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get macvlan %s: %w", cfg.Name, err)
+	}
+
+	// Before the link comes up: skip DAD so the link-local address is usable at once,
+	// and keep the host from configuring addresses of its own on the sub-interface.
+	// Best effort, /proc/sys is often read-only in containers.
+	for _, setting := range []string{"accept_dad", "accept_ra", "autoconf"} {
+		_ = os.WriteFile(filepath.Join("/proc/sys/net/ipv6/conf", cfg.Name, setting), []byte("0"), 0o644)
+	}
+
+	return setLinkUp(ifi.Index)
+}
+
+// deleteMacvlan removes the macvlan sub-interface, if it exists.
+func deleteMacvlan(name string) error {
+	ifi, err := net.InterfaceByName(name)
+	if err != nil {
+		// Already gone
+		return nil
+	}
+	if err := rtnetlinkLink(unix.RTM_DELLINK, 0, unix.IfInfomsg{Index: int32(ifi.Index)}, nil); err != nil {
+		return fmt.Errorf("failed to delete macvlan %s: %w", name, err)
+	}
+	return nil
+}
+
+// setLinkUp sets the link with the given index administratively up.
+func setLinkUp(index int) error {
+	msg := unix.IfInfomsg{Index: int32(index), Flags: unix.IFF_UP, Change: unix.IFF_UP}
+	if err := rtnetlinkLink(unix.RTM_NEWLINK, 0, msg, nil); err != nil {
+		return fmt.Errorf("failed to set link %d up: %w", index, err)
+	}
+	return nil
+}
+
+// rtnetlinkLink sends a link request over rtnetlink and waits for the kernel's acknowledgement.
+func rtnetlinkLink(msgType uint16, flags uint16, ifinfo unix.IfInfomsg, attrs []byte) error {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_ROUTE)
+	if err != nil {
+		return fmt.Errorf("failed to open rtnetlink socket: %w", err)
+	}
+	defer func() { _ = unix.Close(fd) }()
+
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return fmt.Errorf("failed to bind rtnetlink socket: %w", err)
+	}
+
+	ifinfo.Family = unix.AF_UNSPEC
+	req := encodeLinkRequest(msgType, unix.NLM_F_REQUEST|unix.NLM_F_ACK|flags, ifinfo, attrs)
+	if err := unix.Sendto(fd, req, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return fmt.Errorf("failed to send rtnetlink request: %w", err)
+	}
+
+	buf := make([]byte, os.Getpagesize())
+	for {
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return fmt.Errorf("failed to receive rtnetlink response: %w", err)
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return fmt.Errorf("failed to parse rtnetlink response: %w", err)
+		}
+		for _, m := range msgs {
+			if m.Header.Type != unix.NLMSG_ERROR {
+				continue
+			}
+			if len(m.Data) < 4 {
+				return errors.New("truncated rtnetlink error")
+			}
+			if errno := int32(binary.NativeEndian.Uint32(m.Data)); errno != 0 {
+				return unix.Errno(-errno)
+			}
+			return nil
+		}
+	}
+}
+
+// encodeLinkRequest builds a netlink message carrying an ifinfomsg and its attributes.
+func encodeLinkRequest(msgType uint16, flags uint16, ifinfo unix.IfInfomsg, attrs []byte) []byte {
+	length := unix.SizeofNlMsghdr + unix.SizeofIfInfomsg + len(attrs)
+
+	b := make([]byte, 0, length)
+	b = binary.NativeEndian.AppendUint32(b, uint32(length))
+	b = binary.NativeEndian.AppendUint16(b, msgType)
+	b = binary.NativeEndian.AppendUint16(b, flags)
+	b = binary.NativeEndian.AppendUint32(b, 1) // sequence
+	b = binary.NativeEndian.AppendUint32(b, 0) // port ID, assigned by the kernel
+
+	b = append(b, ifinfo.Family, 0)
+	b = binary.NativeEndian.AppendUint16(b, ifinfo.Type)
+	b = binary.NativeEndian.AppendUint32(b, uint32(ifinfo.Index))
+	b = binary.NativeEndian.AppendUint32(b, ifinfo.Flags)
+	b = binary.NativeEndian.AppendUint32(b, ifinfo.Change)
+
+	return append(b, attrs...)
+}
+
+// rtAttr encodes a netlink route attribute, padded to 4 bytes.
+func rtAttr(attrType uint16, data []byte) []byte {
+	length := unix.SizeofRtAttr + len(data)
+	b := make([]byte, 0, (length+unix.RTA_ALIGNTO-1) & ^(unix.RTA_ALIGNTO-1))
+	b = binary.NativeEndian.AppendUint16(b, uint16(length))
+	b = binary.NativeEndian.AppendUint16(b, attrType)
+	b = append(b, data...)
+	return b[:cap(b)]
+}
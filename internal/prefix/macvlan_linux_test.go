@@ -0,0 +1,66 @@
+//go:build linux
+
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"encoding/binary"
+	"syscall"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestRtAttr(t *testing.T) {
+	attr := rtAttr(unix.IFLA_IFNAME, []byte("pd0\x00"))
+	if len(attr) != 8 {
+		t.Fatalf("len(rtAttr()) = %d, want 8", len(attr))
+	}
+
+	// Lengths exclude the padding
+	attr = rtAttr(unix.IFLA_INFO_KIND, []byte("macvlan"))
+	if len(attr) != 12 {
+		t.Fatalf("len(rtAttr()) = %d, want 12", len(attr))
+	}
+	if got := binary.NativeEndian.Uint16(attr); got != 11 {
+		t.Errorf("attribute length = %d, want 11", got)
+	}
+	if attr[11] != 0 {
+		t.Errorf("padding = %x, want 0", attr[11])
+	}
+}
+
+func TestEncodeLinkRequest(t *testing.T) {
+	attrs := rtAttr(unix.IFLA_IFNAME, []byte("pd0\x00"))
+	req := encodeLinkRequest(unix.RTM_NEWLINK, unix.NLM_F_REQUEST, unix.IfInfomsg{Index: 7}, attrs)
+
+	msgs, err := syscall.ParseNetlinkMessage(req)
+	if err != nil {
+		t.Fatalf("ParseNetlinkMessage() error = %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].Header.Type != unix.RTM_NEWLINK {
+		t.Fatalf("ParseNetlinkMessage() = %+v", msgs)
+	}
+	data := msgs[0].Data
+	if len(data) != unix.SizeofIfInfomsg+len(attrs) {
+		t.Fatalf("payload length = %d, want %d", len(data), unix.SizeofIfInfomsg+len(attrs))
+	}
+	if index := int32(binary.NativeEndian.Uint32(data[4:8])); index != 7 {
+		t.Errorf("ifinfomsg index = %d, want 7", index)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build !linux
+
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import "fmt"
+
+// ensureMacvlan is only supported on Linux.
+func ensureMacvlan(parent string, cfg MacvlanConfig) error {
+	return fmt.Errorf("macvlan interfaces are only supported on Linux")
+}
+
+// deleteMacvlan is only supported on Linux.
+func deleteMacvlan(name string) error {
+	return fmt.Errorf("macvlan interfaces are only supported on Linux")
+}
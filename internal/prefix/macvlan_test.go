@@ -0,0 +1,77 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"testing"
+
+	dynamicprefixiov1alpha1 "github.com/jr42/dynamic-prefix-operator/api/v1alpha1"
+)
+
+func TestDefaultMacvlanConfig(t *testing.T) {
+	cfg := defaultMacvlanConfig("home-prefix")
+
+	if len(cfg.Name) > 15 {
+		t.Errorf("Name %q is longer than 15 characters", cfg.Name)
+	}
+	if cfg.MAC[0]&0x02 == 0 || cfg.MAC[0]&0x01 != 0 {
+		t.Errorf("MAC %s is not a locally administered unicast address", cfg.MAC)
+	}
+	if cfg.Mode != MacvlanModeBridge {
+		t.Errorf("Mode = %s, want %s", cfg.Mode, MacvlanModeBridge)
+	}
+
+	// Stable for the same owner, distinct for others
+	if again := defaultMacvlanConfig("home-prefix"); again.Name != cfg.Name || again.MAC.String() != cfg.MAC.String() {
+		t.Errorf("defaultMacvlanConfig() is not stable: %+v != %+v", again, cfg)
+	}
+	if other := defaultMacvlanConfig("office-prefix"); other.Name == cfg.Name || other.MAC.String() == cfg.MAC.String() {
+		t.Errorf("defaultMacvlanConfig() collides for different owners: %+v", other)
+	}
+}
+
+func TestMacvlanConfig(t *testing.T) {
+	cfg, err := macvlanConfig("home-prefix", &dynamicprefixiov1alpha1.MacvlanSpec{
+		Name:       "pd0",
+		MACAddress: "02:00:5e:10:00:01",
+		Mode:       "private",
+	})
+	if err != nil {
+		t.Fatalf("macvlanConfig() error = %v", err)
+	}
+	if cfg.Name != "pd0" || cfg.MAC.String() != "02:00:5e:10:00:01" || cfg.Mode != MacvlanModePrivate {
+		t.Errorf("macvlanConfig() = %+v", cfg)
+	}
+
+	r := NewDHCPv6PDReceiverWithConfig(DHCPv6PDConfig{Interface: "eth0", Macvlan: cfg})
+	if r.iface != "pd0" || r.parent != "eth0" {
+		t.Errorf("receiver interface = %s on %s, want pd0 on eth0", r.iface, r.parent)
+	}
+}
+
+func TestRegistryMacvlanRefCount(t *testing.T) {
+	reg := NewRegistry()
+
+	reg.acquireMacvlan("pd0")
+	reg.acquireMacvlan("pd0")
+	if reg.releaseMacvlan("pd0") {
+		t.Error("Expected the sub-interface to be kept while another receiver uses it")
+	}
+	if !reg.releaseMacvlan("pd0") {
+		t.Error("Expected the last release to remove the sub-interface")
+	}
+}
@@ -98,10 +98,14 @@ func (m *MockReceiver) SimulatePrefix(prefix netip.Prefix, validLifetime time.Du
 		}
 	}
 
-	m.events <- Event{
+	event := Event{
 		Type:   eventType,
 		Prefix: m.currentPrefix,
 	}
+	if eventType == EventTypeChanged {
+		event.PreviousPrefix = oldPrefix
+	}
+	m.events <- event
 }
 
 // SimulatePrefixExpiry simulates prefix expiration (for testing)
@@ -0,0 +1,43 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"net"
+	"strconv"
+)
+
+// linkKey identifies an interface across network namespaces, for sharing per-interface resources.
+func linkKey(netns, iface string) string {
+	if netns == "" {
+		return iface
+	}
+	return netns + ":" + iface
+}
+
+// zonedInterface returns the interface to use for IPv6 zones of sockets in netns.
+// The net package resolves zone names in a process-wide cache, which may hold
+// another namespace's interface of the same name. Inside a namespace, the
+// returned copy is named after its index, which resolves without the cache.
+func zonedInterface(netns string, ifi *net.Interface) *net.Interface {
+	if netns == "" {
+		return ifi
+	}
+	zoned := *ifi
+	zoned.Name = strconv.Itoa(ifi.Index)
+	return &zoned
+}
@@ -0,0 +1,58 @@
+//go:build linux
+
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// inNetNS runs fn inside the network namespace at path. Sockets opened by fn
+// stay in that namespace after it returns. Without a path, fn runs in the
+// operator's own namespace.
+func inNetNS(path string, fn func() error) error {
+	if path == "" {
+		return fn()
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		// The thread is never unlocked: it exits with this goroutine instead of
+		// going back to the scheduler while still in the other namespace
+		runtime.LockOSThread()
+
+		ns, err := os.Open(path)
+		if err != nil {
+			errCh <- fmt.Errorf("failed to open network namespace %s: %w", path, err)
+			return
+		}
+		defer func() { _ = ns.Close() }()
+
+		if err := unix.Setns(int(ns.Fd()), unix.CLONE_NEWNET); err != nil {
+			errCh <- fmt.Errorf("failed to enter network namespace %s: %w", path, err)
+			return
+		}
+
+		errCh <- fn()
+	}()
+	return <-errCh
+}
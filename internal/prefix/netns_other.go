@@ -0,0 +1,29 @@
+//go:build !linux
+
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import "fmt"
+
+// inNetNS runs fn. Network namespaces are only supported on Linux.
+func inNetNS(path string, fn func() error) error {
+	if path != "" {
+		return fmt.Errorf("network namespaces are only supported on Linux")
+	}
+	return fn()
+}
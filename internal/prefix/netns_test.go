@@ -0,0 +1,64 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestLinkKey(t *testing.T) {
+	if got := linkKey("", "eth0"); got != "eth0" {
+		t.Errorf("linkKey() = %q, want eth0", got)
+	}
+	if linkKey("/var/run/netns/uplink", "eth0") == linkKey("/var/run/netns/other", "eth0") {
+		t.Error("Expected interfaces in different namespaces to have different keys")
+	}
+}
+
+func TestZonedInterface(t *testing.T) {
+	ifi := &net.Interface{Index: 7, Name: "eth0"}
+
+	if got := zonedInterface("", ifi); got != ifi {
+		t.Errorf("zonedInterface() = %+v, want the interface itself", got)
+	}
+
+	zoned := zonedInterface("/var/run/netns/uplink", ifi)
+	if zoned.Name != "7" || zoned.Index != 7 {
+		t.Errorf("zonedInterface() = %+v, want name 7", zoned)
+	}
+	if ifi.Name != "eth0" {
+		t.Errorf("zonedInterface() modified the interface: %+v", ifi)
+	}
+}
+
+func TestInNetNS(t *testing.T) {
+	want := errors.New("done")
+	if err := inNetNS("", func() error { return want }); !errors.Is(err, want) {
+		t.Errorf("inNetNS() error = %v, want %v", err, want)
+	}
+
+	called := false
+	err := inNetNS("/nonexistent/netns", func() error {
+		called = true
+		return nil
+	})
+	if err == nil || called {
+		t.Errorf("inNetNS() error = %v, called = %v; want an error without calling fn", err, called)
+	}
+}
@@ -34,6 +34,7 @@ import (
 type RAReceiver struct {
 	mu            sync.RWMutex
 	iface         string
+	netns         string
 	conn          *ndp.Conn
 	currentPrefix *Prefix
 	events        chan Event
@@ -41,12 +42,22 @@ type RAReceiver struct {
 	started       bool
 	ctx           context.Context
 	cancel        context.CancelFunc
+	lastRA        time.Time
+	lastRouter    netip.Addr
+	failures      failureTracker
 }
 
 // NewRAReceiver creates a new Router Advertisement receiver for the given interface.
 func NewRAReceiver(iface string) *RAReceiver {
+	return NewRAReceiverInNamespace(iface, "")
+}
+
+// NewRAReceiverInNamespace creates a Router Advertisement receiver for an interface
+// in the network namespace at netns. An empty netns is the operator's own namespace.
+func NewRAReceiverInNamespace(iface, netns string) *RAReceiver {
 	return &RAReceiver{
 		iface:  iface,
+		netns:  netns,
 		events: make(chan Event, 10),
 		stopCh: make(chan struct{}),
 	}
@@ -62,24 +73,33 @@ func (r *RAReceiver) Start(ctx context.Context) error {
 	}
 
 	log := logf.FromContext(ctx).WithName("ra-receiver")
-	log.Info("Looking up interface", "name", r.iface)
+	log.Info("Looking up interface", "name", r.iface, "netns", r.netns)
 
-	ifi, err := net.InterfaceByName(r.iface)
-	if err != nil {
-		return fmt.Errorf("failed to get interface %s: %w", r.iface, err)
-	}
+	// The interface lookup and the NDP socket belong to the receiver's namespace
+	var conn *ndp.Conn
+	var addr netip.Addr
+	err := inNetNS(r.netns, func() error {
+		ifi, err := net.InterfaceByName(r.iface)
+		if err != nil {
+			return fmt.Errorf("failed to get interface %s: %w", r.iface, err)
+		}
 
-	log.Info("Found interface",
-		"name", ifi.Name,
-		"index", ifi.Index,
-		"hwAddr", ifi.HardwareAddr.String(),
-		"mtu", ifi.MTU,
-		"flags", ifi.Flags.String())
+		log.Info("Found interface",
+			"name", ifi.Name,
+			"index", ifi.Index,
+			"hwAddr", ifi.HardwareAddr.String(),
+			"mtu", ifi.MTU,
+			"flags", ifi.Flags.String())
 
-	// Create NDP connection for listening to Router Advertisements
-	conn, addr, err := ndp.Listen(ifi, ndp.LinkLocal)
+		// Create NDP connection for listening to Router Advertisements
+		conn, addr, err = ndp.Listen(zonedInterface(r.netns, ifi), ndp.LinkLocal)
+		if err != nil {
+			return fmt.Errorf("failed to create NDP listener on %s: %w", r.iface, err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create NDP listener on %s: %w", r.iface, err)
+		return err
 	}
 
 	log.Info("NDP listener started", "interface", r.iface, "localAddr", addr.String())
@@ -132,6 +152,35 @@ func (r *RAReceiver) Source() Source {
 	return SourceRouterAdvertisement
 }
 
+// Diagnostics returns when the last Router Advertisement was seen and from which router.
+func (r *RAReceiver) Diagnostics() []Diagnostics {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d := Diagnostics{
+		Name:                    string(SourceRouterAdvertisement),
+		Source:                  SourceRouterAdvertisement,
+		Active:                  r.currentPrefix != nil,
+		Interface:               r.iface,
+		LastRouterAdvertisement: r.lastRA,
+	}
+	if r.lastRouter.IsValid() {
+		d.Router = r.lastRouter.String()
+	}
+	r.failures.fill(&d)
+	return []Diagnostics{d}
+}
+
+// recordRouterAdvertisement remembers when and from whom the last RA was received.
+func (r *RAReceiver) recordRouterAdvertisement(from netip.Addr) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastRA = time.Now()
+	r.lastRouter = from
+	r.failures.recordSuccess()
+}
+
 // receiveLoop continuously reads Router Advertisements from the interface.
 func (r *RAReceiver) receiveLoop() {
 	log := logf.Log.WithName("ra-receiver")
@@ -182,6 +231,7 @@ func (r *RAReceiver) receiveLoop() {
 		}
 
 		log.Info("Received Router Advertisement", "from", from, "optionCount", len(ra.Options))
+		r.recordRouterAdvertisement(from)
 		r.handleRouterAdvertisement(ra)
 	}
 }
@@ -280,19 +330,27 @@ func (r *RAReceiver) updatePrefix(prefix netip.Prefix, validLifetime, preferredL
 		"eventType", eventType,
 		"previousPrefix", r.currentPrefix)
 
+	event := Event{Type: eventType, Prefix: newPrefix}
+	if eventType == EventTypeChanged {
+		event.PreviousPrefix = r.currentPrefix
+	}
 	r.currentPrefix = newPrefix
 
 	// Send event (non-blocking to avoid deadlock)
 	select {
-	case r.events <- Event{Type: eventType, Prefix: newPrefix}:
+	case r.events <- event:
 		log.Info("Event sent successfully", "eventType", eventType)
 	default:
 		log.Info("Event channel full, event dropped", "eventType", eventType)
 	}
 }
 
-// sendError sends a failed event.
+// sendError records the failure and sends a failed event.
 func (r *RAReceiver) sendError(err error) {
+	r.mu.Lock()
+	r.failures.recordFailure(err)
+	r.mu.Unlock()
+
 	select {
 	case r.events <- Event{Type: EventTypeFailed, Error: err}:
 	default:
@@ -17,6 +17,7 @@ limitations under the License.
 package prefix
 
 import (
+	"errors"
 	"net/netip"
 	"testing"
 )
@@ -217,3 +218,35 @@ func TestRAReceiverEventChannel(t *testing.T) {
 		t.Errorf("Events channel capacity = %d, want 10", cap(events))
 	}
 }
+
+func TestRAReceiverDiagnostics(t *testing.T) {
+	r := NewRAReceiver("eth0")
+
+	r.sendError(errors.New("read failed"))
+	r.sendError(errors.New("read failed"))
+
+	diag := r.Diagnostics()
+	if len(diag) != 1 {
+		t.Fatalf("Diagnostics() returned %d entries, want 1", len(diag))
+	}
+	if diag[0].ConsecutiveFailures != 2 {
+		t.Errorf("ConsecutiveFailures = %d, want 2", diag[0].ConsecutiveFailures)
+	}
+	if diag[0].LastError != "read failed" || diag[0].LastErrorTime.IsZero() {
+		t.Errorf("LastError = %q at %v, want the recorded failure", diag[0].LastError, diag[0].LastErrorTime)
+	}
+
+	router := netip.MustParseAddr("fe80::1")
+	r.recordRouterAdvertisement(router)
+
+	diag = r.Diagnostics()
+	if diag[0].ConsecutiveFailures != 0 {
+		t.Errorf("ConsecutiveFailures = %d, want 0 after RA", diag[0].ConsecutiveFailures)
+	}
+	if diag[0].Router != router.String() {
+		t.Errorf("Router = %q, want %q", diag[0].Router, router)
+	}
+	if diag[0].LastRouterAdvertisement.IsZero() {
+		t.Error("LastRouterAdvertisement should be set")
+	}
+}
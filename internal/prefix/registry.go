@@ -0,0 +1,533 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// Registry shares network resources between receivers on the same interface.
+// All DynamicPrefixes on an interface share a single NDP listener, whose events
+// are fanned out to every subscriber, and a single DHCPv6 client identity (DUID),
+// with each DynamicPrefix requesting its own IA_PD. Receivers polling the same
+// DNS name, or observing the DHCPv4 address of the same interface, share a
+// single poller or observer in the same way.
+// Shared resources are reference-counted and released with their last user.
+type Registry struct {
+	mu sync.Mutex
+	// listeners maps a listener key to a passive receiver shared by its subscribers
+	listeners   map[string]*sharedListener
+	dhcpClients map[string]*dhcpv6Client
+	// ipv4Observers maps a link key to a DHCPv4 observer shared by its subscribers
+	ipv4Observers map[string]*sharedIPv4Observer
+	// macvlans counts the receivers using each macvlan sub-interface
+	macvlans map[string]int
+}
+
+// NewRegistry creates a new, empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		listeners:     make(map[string]*sharedListener),
+		dhcpClients:   make(map[string]*dhcpv6Client),
+		ipv4Observers: make(map[string]*sharedIPv4Observer),
+		macvlans:      make(map[string]int),
+	}
+}
+
+// RAReceiver returns a receiver that subscribes to the shared RA listener of the interface
+// in the network namespace at netns. An empty netns is the operator's own namespace.
+func (reg *Registry) RAReceiver(iface, netns string) Receiver {
+	return &subscription{
+		registry: reg,
+		key:      "ra/" + linkKey(netns, iface),
+		source:   SourceRouterAdvertisement,
+		idle:     Diagnostics{Name: string(SourceRouterAdvertisement), Source: SourceRouterAdvertisement, Interface: iface},
+		create:   func() listenedReceiver { return NewRAReceiverInNamespace(iface, netns) },
+		events:   make(chan Event, 10),
+	}
+}
+
+// DNSReceiver returns a receiver that subscribes to the shared poller of the
+// hostname, resolver, prefix length and interval.
+func (reg *Registry) DNSReceiver(hostname, resolver string, prefixLength int, interval time.Duration) Receiver {
+	return &subscription{
+		registry: reg,
+		key:      fmt.Sprintf("dns/%s/%s/%d/%s", fqdn(hostname), resolver, prefixLength, interval),
+		source:   SourceDNS,
+		idle:     Diagnostics{Name: string(SourceDNS), Source: SourceDNS},
+		create: func() listenedReceiver {
+			return NewDNSReceiver(hostname, resolver, prefixLength, interval)
+		},
+		events: make(chan Event, 10),
+	}
+}
+
+// IPv4Receiver returns a receiver that subscribes to the shared DHCPv4 observer of
+// the interface in the network namespace at netns.
+func (reg *Registry) IPv4Receiver(iface, netns string) IPv4Receiver {
+	return &ipv4Subscription{
+		registry: reg,
+		key:      linkKey(netns, iface),
+		create:   func() *DHCPv4Observer { return NewDHCPv4Observer(iface, netns) },
+		events:   make(chan Event, 10),
+	}
+}
+
+// DHCPv6PDReceiver returns a DHCPv6-PD receiver that uses the shared client of the interface.
+// The owner (typically the DynamicPrefix name) derives a stable IAID, so that
+// each owner gets its own IA_PD and keeps it across restarts.
+func (reg *Registry) DHCPv6PDReceiver(cfg DHCPv6PDConfig, owner string) *DHCPv6PDReceiver {
+	r := NewDHCPv6PDReceiverWithConfig(cfg)
+	r.registry = reg
+	r.owner = owner
+	return r
+}
+
+// acquireListener returns the shared listener for the key, starting it for the first user.
+func (reg *Registry) acquireListener(ctx context.Context, sub *subscription) (*sharedListener, error) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	listener, ok := reg.listeners[sub.key]
+	if !ok {
+		listener = &sharedListener{
+			receiver:    sub.create(),
+			subscribers: make(map[*subscription]struct{}),
+		}
+		// The listener outlives the subscriber that started it, so it must not
+		// inherit its cancellation
+		listenerCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		if err := listener.receiver.Start(listenerCtx); err != nil {
+			cancel()
+			return nil, err
+		}
+		listener.cancel = cancel
+		go listener.fanOut(listenerCtx)
+		reg.listeners[sub.key] = listener
+	}
+
+	listener.mu.Lock()
+	listener.subscribers[sub] = struct{}{}
+	listener.mu.Unlock()
+
+	return listener, nil
+}
+
+// releaseListener removes a subscriber and stops the listener after its last user.
+func (reg *Registry) releaseListener(sub *subscription) error {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	listener, ok := reg.listeners[sub.key]
+	if !ok {
+		return nil
+	}
+
+	listener.mu.Lock()
+	delete(listener.subscribers, sub)
+	remaining := len(listener.subscribers)
+	listener.mu.Unlock()
+
+	if remaining > 0 {
+		return nil
+	}
+
+	delete(reg.listeners, sub.key)
+	listener.cancel()
+	return listener.receiver.Stop()
+}
+
+// acquireIPv4Observer returns the shared observer for the subscription's key, starting it for the first user.
+func (reg *Registry) acquireIPv4Observer(ctx context.Context, sub *ipv4Subscription) (*sharedIPv4Observer, error) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	observer, ok := reg.ipv4Observers[sub.key]
+	if !ok {
+		observer = &sharedIPv4Observer{
+			observer:    sub.create(),
+			subscribers: make(map[*ipv4Subscription]struct{}),
+		}
+		// The observer outlives the subscriber that started it
+		observerCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		if err := observer.observer.Start(observerCtx); err != nil {
+			cancel()
+			return nil, err
+		}
+		observer.cancel = cancel
+		go observer.fanOut(observerCtx)
+		reg.ipv4Observers[sub.key] = observer
+	}
+
+	observer.mu.Lock()
+	observer.subscribers[sub] = struct{}{}
+	observer.mu.Unlock()
+
+	return observer, nil
+}
+
+// releaseIPv4Observer removes a subscriber and stops the observer after its last user.
+func (reg *Registry) releaseIPv4Observer(sub *ipv4Subscription) error {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	observer, ok := reg.ipv4Observers[sub.key]
+	if !ok {
+		return nil
+	}
+
+	observer.mu.Lock()
+	delete(observer.subscribers, sub)
+	remaining := len(observer.subscribers)
+	observer.mu.Unlock()
+
+	if remaining > 0 {
+		return nil
+	}
+
+	delete(reg.ipv4Observers, sub.key)
+	observer.cancel()
+	return observer.observer.Stop()
+}
+
+// acquireDHCPv6Client returns the shared client for the interface and reserves an IAID for the owner.
+// An owner that already holds an IAID on the interface gets the same one, so that a replacement
+// receiver continues the IA_PD of the receiver it replaces instead of requesting a new one.
+// The IAID depends only on the owner name: two owners whose IAIDs collide are reported
+// as an error instead of one of them being moved to another IAID depending on start order.
+func (reg *Registry) acquireDHCPv6Client(iface, owner string) (*dhcpv6Client, [4]byte, error) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	client, ok := reg.dhcpClients[iface]
+	if !ok {
+		client = &dhcpv6Client{
+			iaids:  make(map[[4]byte]string),
+			leases: make(map[string]*iaidLease),
+		}
+		reg.dhcpClients[iface] = client
+	}
+
+	if lease, ok := client.leases[owner]; ok {
+		lease.refs++
+		return client, lease.iaid, nil
+	}
+
+	iaid := ownerIAID(owner)
+	if other, used := client.iaids[iaid]; used {
+		return nil, iaid, fmt.Errorf("IAID %x of %q on %s collides with %q, rename one of them", iaid, owner, iface, other)
+	}
+	client.iaids[iaid] = owner
+	client.leases[owner] = &iaidLease{iaid: iaid, refs: 1}
+
+	return client, iaid, nil
+}
+
+// ownerIAID derives the IAID of an owner from its name, so that it is stable across restarts.
+func ownerIAID(owner string) [4]byte {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(owner))
+	var iaid [4]byte
+	binary.BigEndian.PutUint32(iaid[:], h.Sum32())
+	return iaid
+}
+
+// releaseDHCPv6Client drops a reference to the owner's IAID, frees the IAID after
+// the owner's last receiver and forgets the client after its last user.
+func (reg *Registry) releaseDHCPv6Client(iface, owner string) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	client, ok := reg.dhcpClients[iface]
+	if !ok {
+		return
+	}
+	lease, ok := client.leases[owner]
+	if !ok {
+		return
+	}
+
+	lease.refs--
+	if lease.refs > 0 {
+		return
+	}
+	delete(client.leases, owner)
+	delete(client.iaids, lease.iaid)
+	if len(client.iaids) == 0 {
+		delete(reg.dhcpClients, iface)
+	}
+}
+
+// acquireMacvlan records a user of the macvlan sub-interface. A replacement
+// receiver with the same sub-interface keeps it while the old one stops.
+func (reg *Registry) acquireMacvlan(name string) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.macvlans[name]++
+}
+
+// releaseMacvlan removes a user of the macvlan sub-interface and reports whether it was the last.
+func (reg *Registry) releaseMacvlan(name string) bool {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	reg.macvlans[name]--
+	if reg.macvlans[name] > 0 {
+		return false
+	}
+	delete(reg.macvlans, name)
+	return true
+}
+
+// dhcpv6Client is the DHCPv6 client state shared by all IA_PDs on an interface.
+// Exchanges are serialized so that only one socket is bound to the client port at a time.
+type dhcpv6Client struct {
+	// exchangeMu serializes DHCPv6 exchanges on the interface
+	exchangeMu sync.Mutex
+	// iaids maps the IAIDs in use to their owner, guarded by the registry lock
+	iaids map[[4]byte]string
+	// leases holds the IAID of each owner and the number of its receivers, guarded by the registry lock
+	leases map[string]*iaidLease
+}
+
+// iaidLease is an IAID reserved for an owner, shared by all receivers of that owner.
+type iaidLease struct {
+	iaid [4]byte
+	refs int
+}
+
+// listenedReceiver is a passive receiver that can be shared between subscribers.
+type listenedReceiver interface {
+	Receiver
+	DiagnosticsReporter
+}
+
+// sharedListener is a single passive receiver whose events are fanned out to all subscribers.
+type sharedListener struct {
+	receiver    listenedReceiver
+	cancel      context.CancelFunc
+	mu          sync.RWMutex
+	subscribers map[*subscription]struct{}
+}
+
+// fanOut copies every event of the underlying receiver to all subscribers.
+func (l *sharedListener) fanOut(ctx context.Context) {
+	events := l.receiver.Events()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-events:
+			l.mu.RLock()
+			for sub := range l.subscribers {
+				select {
+				case sub.events <- event:
+				default:
+					// Subscriber channel full, event dropped
+				}
+			}
+			l.mu.RUnlock()
+		}
+	}
+}
+
+// subscription is a Receiver backed by a shared listener.
+type subscription struct {
+	mu       sync.RWMutex
+	registry *Registry
+	// key identifies the shared listener
+	key    string
+	source Source
+	// idle is reported as diagnostics before the subscription starts
+	idle Diagnostics
+	// create builds the listener's receiver for the first subscriber
+	create   func() listenedReceiver
+	listener *sharedListener
+	events   chan Event
+}
+
+// Start subscribes to the shared listener, starting it if this is the first subscriber.
+func (s *subscription) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener != nil {
+		return nil
+	}
+
+	listener, err := s.registry.acquireListener(ctx, s)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+
+	// Late subscribers learn the prefix the listener already knows about
+	if current := listener.receiver.CurrentPrefix(); current != nil {
+		select {
+		case s.events <- Event{Type: EventTypeAcquired, Prefix: current}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Stop unsubscribes, stopping the shared listener after its last subscriber.
+func (s *subscription) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+
+	s.listener = nil
+	return s.registry.releaseListener(s)
+}
+
+// Events returns the channel of prefix events for this subscriber.
+func (s *subscription) Events() <-chan Event {
+	return s.events
+}
+
+// CurrentPrefix returns the prefix observed by the shared listener, if any.
+func (s *subscription) CurrentPrefix() *Prefix {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.receiver.CurrentPrefix()
+}
+
+// Source returns the source of the shared listener.
+func (s *subscription) Source() Source {
+	return s.source
+}
+
+// Diagnostics returns the diagnostics of the shared listener.
+func (s *subscription) Diagnostics() []Diagnostics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.listener == nil {
+		return []Diagnostics{s.idle}
+	}
+	return s.listener.receiver.Diagnostics()
+}
+
+// sharedIPv4Observer is a single DHCPv4 observer whose events are fanned out to all subscribers.
+type sharedIPv4Observer struct {
+	observer    *DHCPv4Observer
+	cancel      context.CancelFunc
+	mu          sync.RWMutex
+	subscribers map[*ipv4Subscription]struct{}
+}
+
+// fanOut copies every event of the observer to all subscribers.
+func (o *sharedIPv4Observer) fanOut(ctx context.Context) {
+	events := o.observer.Events()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-events:
+			o.mu.RLock()
+			for sub := range o.subscribers {
+				select {
+				case sub.events <- event:
+				default:
+					// Subscriber channel full, event dropped
+				}
+			}
+			o.mu.RUnlock()
+		}
+	}
+}
+
+// ipv4Subscription is an IPv4Receiver backed by a shared DHCPv4 observer.
+type ipv4Subscription struct {
+	mu       sync.RWMutex
+	registry *Registry
+	// key identifies the shared observer
+	key string
+	// create builds the observer for the first subscriber
+	create   func() *DHCPv4Observer
+	observer *sharedIPv4Observer
+	events   chan Event
+}
+
+// Start subscribes to the shared observer, starting it if this is the first subscriber.
+func (s *ipv4Subscription) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.observer != nil {
+		return nil
+	}
+
+	observer, err := s.registry.acquireIPv4Observer(ctx, s)
+	if err != nil {
+		return err
+	}
+	s.observer = observer
+
+	// Late subscribers learn the address the observer already knows about
+	if observer.observer.CurrentIPv4() != nil {
+		select {
+		case s.events <- Event{Type: EventTypeAcquired}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Stop unsubscribes, stopping the shared observer after its last subscriber.
+func (s *ipv4Subscription) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.observer == nil {
+		return nil
+	}
+
+	s.observer = nil
+	return s.registry.releaseIPv4Observer(s)
+}
+
+// Events returns the channel of address events for this subscriber.
+func (s *ipv4Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// CurrentIPv4 returns the address observed by the shared observer, if any.
+func (s *ipv4Subscription) CurrentIPv4() *IPv4Address {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.observer == nil {
+		return nil
+	}
+	return s.observer.observer.CurrentIPv4()
+}
@@ -0,0 +1,333 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+// injectRAListener registers a listener backed by an unstarted RAReceiver,
+// so subscriptions can be tested without opening an NDP socket.
+func injectRAListener(t *testing.T, reg *Registry, iface string) (*sharedListener, *RAReceiver) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	receiver := NewRAReceiver(iface)
+	listener := &sharedListener{
+		receiver:    receiver,
+		cancel:      cancel,
+		subscribers: make(map[*subscription]struct{}),
+	}
+	go listener.fanOut(ctx)
+	reg.listeners["ra/"+iface] = listener
+	t.Cleanup(cancel)
+	return listener, receiver
+}
+
+func TestRegistry_RAFanOut(t *testing.T) {
+	reg := NewRegistry()
+	listener, ra := injectRAListener(t, reg, "eth0")
+
+	a := reg.RAReceiver("eth0", "")
+	b := reg.RAReceiver("eth0", "")
+	for _, r := range []Receiver{a, b} {
+		if err := r.Start(context.Background()); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+	}
+
+	if got := len(listener.subscribers); got != 2 {
+		t.Fatalf("subscribers = %d, want 2", got)
+	}
+
+	p := &Prefix{Network: netip.MustParsePrefix("2001:db8:1::/64"), Source: SourceRouterAdvertisement}
+	ra.events <- Event{Type: EventTypeAcquired, Prefix: p}
+
+	for i, r := range []Receiver{a, b} {
+		select {
+		case event := <-r.Events():
+			if event.Prefix == nil || event.Prefix.Network != p.Network {
+				t.Errorf("subscriber %d got prefix %v, want %v", i, event.Prefix, p.Network)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("subscriber %d did not receive the event", i)
+		}
+	}
+}
+
+func TestRegistry_RAReferenceCounting(t *testing.T) {
+	reg := NewRegistry()
+	_, _ = injectRAListener(t, reg, "eth0")
+
+	a := reg.RAReceiver("eth0", "")
+	b := reg.RAReceiver("eth0", "")
+	_ = a.Start(context.Background())
+	_ = b.Start(context.Background())
+
+	if err := a.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if _, ok := reg.listeners["ra/eth0"]; !ok {
+		t.Fatal("listener released while still in use")
+	}
+
+	if err := b.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if _, ok := reg.listeners["ra/eth0"]; ok {
+		t.Error("listener not released after last subscriber stopped")
+	}
+
+	// Stopping twice is a no-op
+	if err := b.Stop(); err != nil {
+		t.Errorf("second Stop() error = %v", err)
+	}
+}
+
+func TestRegistry_LateSubscriberGetsCurrentPrefix(t *testing.T) {
+	reg := NewRegistry()
+	_, ra := injectRAListener(t, reg, "eth0")
+
+	p := &Prefix{Network: netip.MustParsePrefix("2001:db8:2::/64"), Source: SourceRouterAdvertisement}
+	ra.currentPrefix = p
+
+	r := reg.RAReceiver("eth0", "")
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	select {
+	case event := <-r.Events():
+		if event.Type != EventTypeAcquired || event.Prefix != p {
+			t.Errorf("event = %+v, want acquired %v", event, p.Network)
+		}
+	default:
+		t.Error("Expected acquired event for the already known prefix")
+	}
+
+	if got := r.CurrentPrefix(); got != p {
+		t.Errorf("CurrentPrefix() = %v, want %v", got, p)
+	}
+}
+
+func TestRegistry_DHCPv6IAIDs(t *testing.T) {
+	reg := NewRegistry()
+
+	clientA, iaidA, err := reg.acquireDHCPv6Client("eth0", "home")
+	if err != nil {
+		t.Fatalf("acquireDHCPv6Client() error = %v", err)
+	}
+	clientB, iaidB, err := reg.acquireDHCPv6Client("eth0", "lab")
+	if err != nil {
+		t.Fatalf("acquireDHCPv6Client() error = %v", err)
+	}
+
+	if clientA != clientB {
+		t.Error("Expected receivers on the same interface to share a client")
+	}
+	if iaidA == iaidB {
+		t.Errorf("Expected distinct IAIDs, both are %x", iaidA)
+	}
+
+	// The IAID is stable for an owner once released and reacquired
+	reg.releaseDHCPv6Client("eth0", "home")
+	_, again, _ := reg.acquireDHCPv6Client("eth0", "home")
+	if again != iaidA {
+		t.Errorf("IAID = %x after reacquire, want %x", again, iaidA)
+	}
+
+	// A second receiver of the same owner shares its IAID, which stays reserved until both release it
+	_, shared, _ := reg.acquireDHCPv6Client("eth0", "home")
+	if shared != iaidA {
+		t.Errorf("IAID = %x for the same owner, want %x", shared, iaidA)
+	}
+	reg.releaseDHCPv6Client("eth0", "home")
+	if owner := clientA.iaids[iaidA]; owner != "home" {
+		t.Errorf("IAID %x owner = %q after first release, want home", iaidA, owner)
+	}
+	reg.releaseDHCPv6Client("eth0", "home")
+	if _, used := clientA.iaids[iaidA]; used {
+		t.Errorf("IAID %x still reserved after last release", iaidA)
+	}
+
+	// The IAID depends only on the owner name, whatever the start order
+	if iaidA != ownerIAID("home") || iaidB != ownerIAID("lab") {
+		t.Errorf("IAIDs = %x, %x, want %x, %x", iaidA, iaidB, ownerIAID("home"), ownerIAID("lab"))
+	}
+
+	// An owner whose IAID is held by another owner is rejected instead of moved to another IAID
+	clientA.iaids[iaidA] = "other"
+	if _, _, err := reg.acquireDHCPv6Client("eth0", "home"); err == nil {
+		t.Error("Expected an error for a colliding owner")
+	}
+	delete(clientA.iaids, iaidA)
+
+	reg.releaseDHCPv6Client("eth0", "lab")
+	if _, ok := reg.dhcpClients["eth0"]; ok {
+		t.Error("client not released after last IA_PD")
+	}
+}
+
+func TestRegistry_DHCPv6ReceiverSwapKeepsIAID(t *testing.T) {
+	reg := NewRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A replacement receiver is started while the one it replaces is still running
+	old := reg.DHCPv6PDReceiver(DHCPv6PDConfig{Interface: "nonexistent-iface"}, "home")
+	if err := old.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	replacement := reg.DHCPv6PDReceiver(DHCPv6PDConfig{Interface: "nonexistent-iface", RequestedPrefixLength: 56}, "home")
+	if err := replacement.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	old.mu.RLock()
+	oldIAID := old.iaid
+	old.mu.RUnlock()
+	replacement.mu.RLock()
+	newIAID := replacement.iaid
+	replacement.mu.RUnlock()
+	if newIAID != oldIAID {
+		t.Fatalf("replacement IAID = %x, want the IAID %x of the receiver it replaces", newIAID, oldIAID)
+	}
+
+	// Stopping the old receiver leaves the IA_PD to the replacement
+	if err := old.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	client := reg.dhcpClients["nonexistent-iface"]
+	if client == nil || client.iaids[newIAID] != "home" {
+		t.Fatal("Expected the IAID to stay reserved for the replacement receiver")
+	}
+
+	// After a restart the owner gets the same IAID again
+	if err := replacement.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	restarted := reg.DHCPv6PDReceiver(DHCPv6PDConfig{Interface: "nonexistent-iface"}, "home")
+	if err := restarted.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer func() { _ = restarted.Stop() }()
+	restarted.mu.RLock()
+	defer restarted.mu.RUnlock()
+	if restarted.iaid != oldIAID {
+		t.Errorf("IAID after restart = %x, want %x", restarted.iaid, oldIAID)
+	}
+}
+
+func TestRegistry_DHCPv6ReceiverUsesSharedClient(t *testing.T) {
+	reg := NewRegistry()
+	r := reg.DHCPv6PDReceiver(DHCPv6PDConfig{Interface: "nonexistent-iface"}, "home")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := r.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	r.mu.RLock()
+	client := r.client
+	r.mu.RUnlock()
+	if client == nil || reg.dhcpClients["nonexistent-iface"] != client {
+		t.Fatal("Expected receiver to use the registry client")
+	}
+
+	if err := r.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if _, ok := reg.dhcpClients["nonexistent-iface"]; ok {
+		t.Error("client not released after Stop")
+	}
+}
+
+func TestRegistry_DNSShared(t *testing.T) {
+	reg := NewRegistry()
+
+	a := reg.DNSReceiver("home.example.org", "[::1]:53", 56, time.Minute).(*subscription)
+	b := reg.DNSReceiver("home.example.org.", "[::1]:53", 56, time.Minute).(*subscription)
+	c := reg.DNSReceiver("home.example.org", "[::1]:53", 56, 5*time.Minute).(*subscription)
+
+	if a.key != b.key {
+		t.Errorf("keys %q and %q differ for the same poller", a.key, b.key)
+	}
+	if a.key == c.key {
+		t.Errorf("key %q shared across intervals", a.key)
+	}
+	if a.Source() != SourceDNS {
+		t.Errorf("Source() = %s, want %s", a.Source(), SourceDNS)
+	}
+}
+
+func TestRegistry_IPv4ObserverShared(t *testing.T) {
+	reg := NewRegistry()
+
+	observers := 0
+	create := func() *DHCPv4Observer {
+		observers++
+		o := NewDHCPv4Observer("eth0", "")
+		o.read = func() ([]ipv4Assignment, netip.Addr, error) {
+			return []ipv4Assignment{{prefix: netip.MustParsePrefix("203.0.113.7/29"), validLifetime: time.Hour}}, netip.Addr{}, nil
+		}
+		return o
+	}
+
+	leader := reg.IPv4Receiver("eth0", "").(*ipv4Subscription)
+	standby := reg.IPv4Receiver("eth0", "").(*ipv4Subscription)
+	leader.create, standby.create = create, create
+
+	if err := standby.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := leader.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if observers != 1 {
+		t.Fatalf("observers created = %d, want 1", observers)
+	}
+
+	// The late subscriber learns the address the standby already observed
+	select {
+	case event := <-leader.Events():
+		if event.Type != EventTypeAcquired {
+			t.Errorf("event = %+v, want acquired", event)
+		}
+	default:
+		t.Error("Expected acquired event for the already known address")
+	}
+	if got := leader.CurrentIPv4(); got == nil || got.Address.String() != "203.0.113.7/29" {
+		t.Errorf("CurrentIPv4() = %v, want 203.0.113.7/29", got)
+	}
+
+	if err := standby.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if _, ok := reg.ipv4Observers[linkKey("", "eth0")]; !ok {
+		t.Fatal("observer stopped while the leader still subscribes")
+	}
+	if err := leader.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if len(reg.ipv4Observers) != 0 {
+		t.Error("observer not released after the last subscriber")
+	}
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"context"
+	"net/netip"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// reselectInterval is how often the selector is re-evaluated when no link
+// change notifications are available.
+const reselectInterval = time.Minute
+
+// selectingReceiver runs a receiver on the interface chosen by a selector.
+// The selector is re-evaluated whenever links or routes change; if it then
+// picks another interface, the inner receiver is replaced by one on that
+// interface.
+type selectingReceiver struct {
+	mu       sync.RWMutex
+	selector InterfaceSelector
+	netns    string
+	source   Source
+	create   func(iface string) Receiver
+	resolve  func() (string, error)
+
+	iface       string
+	inner       Receiver
+	innerCancel context.CancelFunc
+	hint        netip.Prefix
+	failures    failureTracker
+
+	events  chan Event
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+}
+
+// newSelectingReceiver creates a receiver that calls create with the name of
+// the selected interface in the network namespace at netns.
+func newSelectingReceiver(selector InterfaceSelector, netns string, source Source, create func(iface string) Receiver) *selectingReceiver {
+	return &selectingReceiver{
+		selector: selector,
+		netns:    netns,
+		source:   source,
+		create:   create,
+		resolve:  selector.resolve,
+		events:   make(chan Event, 10),
+	}
+}
+
+// Start selects the interface and starts watching for link changes. Failing to
+// select an interface is reported as a failed event, not an error, since the
+// interface may still appear.
+func (r *selectingReceiver) Start(ctx context.Context) error {
+	r.mu.Lock()
+	if r.started {
+		r.mu.Unlock()
+		return nil
+	}
+	r.ctx, r.cancel = context.WithCancel(ctx)
+	r.started = true
+	r.mu.Unlock()
+
+	r.reselect()
+	go r.watch()
+	return nil
+}
+
+// Stop stops the inner receiver and the link watch.
+func (r *selectingReceiver) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.started {
+		return nil
+	}
+	r.started = false
+	r.cancel()
+	return r.stopInner()
+}
+
+// Events returns the events of the current inner receiver.
+func (r *selectingReceiver) Events() <-chan Event {
+	return r.events
+}
+
+// CurrentPrefix returns the prefix of the current inner receiver.
+func (r *selectingReceiver) CurrentPrefix() *Prefix {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.inner == nil {
+		return nil
+	}
+	return r.inner.CurrentPrefix()
+}
+
+// Source returns the source of the receivers created on the selected interface.
+func (r *selectingReceiver) Source() Source {
+	return r.source
+}
+
+// Diagnostics returns the diagnostics of the inner receiver, or the selection
+// error while no interface is selected.
+func (r *selectingReceiver) Diagnostics() []Diagnostics {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if reporter, ok := r.inner.(DiagnosticsReporter); ok {
+		if inner := reporter.Diagnostics(); len(inner) > 0 {
+			d := inner[0]
+			if r.failures.consecutiveFailures > 0 {
+				r.failures.fill(&d)
+			}
+			return []Diagnostics{d}
+		}
+	}
+
+	d := Diagnostics{Name: string(r.source), Source: r.source, Interface: r.iface}
+	r.failures.fill(&d)
+	return []Diagnostics{d}
+}
+
+// SetPrefixHint remembers the hint for receivers created on later selections.
+func (r *selectingReceiver) SetPrefixHint(hint netip.Prefix) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hint = hint
+	if hinter, ok := r.inner.(PrefixHinter); ok {
+		hinter.SetPrefixHint(hint)
+	}
+}
+
+// watch re-evaluates the selector on link changes, and periodically in case
+// notifications are missed or unavailable.
+func (r *selectingReceiver) watch() {
+	changes, err := watchLinks(r.ctx, r.netns)
+	if err != nil {
+		logf.FromContext(r.ctx).WithName("interface-selector").Error(err,
+			"Not watching link changes, re-evaluating periodically", "selector", r.selector.String())
+	}
+	ticker := time.NewTicker(reselectInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-changes:
+			r.reselect()
+		case <-ticker.C:
+			r.reselect()
+		}
+	}
+}
+
+// reselect resolves the selector and moves the inner receiver to the selected
+// interface if it changed. When no interface is selected, the current inner
+// receiver keeps running and reports its own failures.
+func (r *selectingReceiver) reselect() {
+	var iface string
+	err := inNetNS(r.netns, func() error {
+		var err error
+		iface, err = r.resolve()
+		return err
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.started {
+		return
+	}
+	if err != nil {
+		r.failures.recordFailure(err)
+		r.sendEvent(Event{Type: EventTypeFailed, Error: err})
+		return
+	}
+	r.failures.recordSuccess()
+	if iface == r.iface && r.inner != nil {
+		return
+	}
+
+	logf.FromContext(r.ctx).WithName("interface-selector").Info("Selected interface",
+		"selector", r.selector.String(), "interface", iface, "previous", r.iface)
+	_ = r.stopInner()
+	r.iface = iface
+
+	inner := r.create(iface)
+	if hinter, ok := inner.(PrefixHinter); ok && r.hint.IsValid() {
+		hinter.SetPrefixHint(r.hint)
+	}
+	ctx, cancel := context.WithCancel(r.ctx)
+	if err := inner.Start(ctx); err != nil {
+		cancel()
+		r.failures.recordFailure(err)
+		r.sendEvent(Event{Type: EventTypeFailed, Error: err})
+		return
+	}
+	r.inner, r.innerCancel = inner, cancel
+	go r.forwardEvents(ctx, inner)
+}
+
+// stopInner stops the current inner receiver (must be called with lock held).
+func (r *selectingReceiver) stopInner() error {
+	if r.inner == nil {
+		return nil
+	}
+	r.innerCancel()
+	err := r.inner.Stop()
+	r.inner, r.innerCancel = nil, nil
+	return err
+}
+
+// forwardEvents forwards the events of an inner receiver until it is replaced.
+func (r *selectingReceiver) forwardEvents(ctx context.Context, inner Receiver) {
+	events := inner.Events()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			select {
+			case r.events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// sendEvent sends an event to the events channel (must be called with lock held).
+func (r *selectingReceiver) sendEvent(event Event) {
+	select {
+	case r.events <- event:
+	default:
+		// Channel full, event dropped
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSelectingReceiverFollowsSelection(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		selected   = "eth0"
+		resolveErr error
+		created    = map[string]*MockReceiver{}
+	)
+	r := newSelectingReceiver(InterfaceSelector{DefaultRoute: true}, "", SourceDHCPv6PD, func(iface string) Receiver {
+		mu.Lock()
+		defer mu.Unlock()
+		created[iface] = NewMockReceiver(SourceDHCPv6PD)
+		return created[iface]
+	})
+	r.resolve = func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return selected, resolveErr
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := r.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer func() { _ = r.Stop() }()
+
+	mu.Lock()
+	eth0 := created["eth0"]
+	mu.Unlock()
+	if eth0 == nil || !eth0.IsStarted() {
+		t.Fatal("expected a started receiver on eth0")
+	}
+
+	eth0.SimulatePrefix(netip.MustParsePrefix("2001:db8:1::/56"), time.Hour)
+	select {
+	case event := <-r.Events():
+		if event.Type != EventTypeAcquired {
+			t.Errorf("event type = %s, want %s", event.Type, EventTypeAcquired)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event of the inner receiver was not forwarded")
+	}
+	if got := r.CurrentPrefix(); got == nil || got.Network != netip.MustParsePrefix("2001:db8:1::/56") {
+		t.Errorf("CurrentPrefix() = %v, want 2001:db8:1::/56", got)
+	}
+
+	// Losing the selection keeps the current receiver
+	mu.Lock()
+	resolveErr = errors.New("no IPv6 default route")
+	mu.Unlock()
+	r.reselect()
+	if !eth0.IsStarted() {
+		t.Error("receiver on eth0 was stopped without a new selection")
+	}
+	if d := r.Diagnostics(); d[0].ConsecutiveFailures != 1 || d[0].LastError != "no IPv6 default route" {
+		t.Errorf("Diagnostics() = %+v, want the selection error", d[0])
+	}
+
+	// A new selection moves to the other interface
+	mu.Lock()
+	selected, resolveErr = "eth1", nil
+	mu.Unlock()
+	r.reselect()
+
+	mu.Lock()
+	eth1 := created["eth1"]
+	mu.Unlock()
+	if eth0.IsStarted() {
+		t.Error("receiver on eth0 still running after moving to eth1")
+	}
+	if eth1 == nil || !eth1.IsStarted() {
+		t.Fatal("expected a started receiver on eth1")
+	}
+	if d := r.Diagnostics(); d[0].ConsecutiveFailures != 0 {
+		t.Errorf("Diagnostics() = %+v, want no failures", d[0])
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"context"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+// StaticReceiver provides a fixed, manually configured prefix.
+// It is typically used as the last source in a failover list, or when the
+// prefix is known and never changes.
+type StaticReceiver struct {
+	mu            sync.RWMutex
+	network       netip.Prefix
+	currentPrefix *Prefix
+	events        chan Event
+	started       bool
+}
+
+// NewStaticReceiver creates a new static receiver for the given prefix.
+func NewStaticReceiver(network netip.Prefix) *StaticReceiver {
+	return &StaticReceiver{
+		network: network.Masked(),
+		events:  make(chan Event, 10),
+	}
+}
+
+// Start publishes the static prefix.
+func (r *StaticReceiver) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started {
+		return nil
+	}
+
+	r.started = true
+	r.currentPrefix = &Prefix{
+		Network:    r.network,
+		Source:     SourceStatic,
+		ReceivedAt: time.Now(),
+	}
+
+	select {
+	case r.events <- Event{Type: EventTypeAcquired, Prefix: r.currentPrefix}:
+	default:
+		// Channel full, event dropped
+	}
+
+	return nil
+}
+
+// Stop withdraws the static prefix.
+func (r *StaticReceiver) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.started = false
+	r.currentPrefix = nil
+	return nil
+}
+
+// Events returns the channel of prefix events.
+func (r *StaticReceiver) Events() <-chan Event {
+	return r.events
+}
+
+// CurrentPrefix returns the static prefix once started.
+func (r *StaticReceiver) CurrentPrefix() *Prefix {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.currentPrefix
+}
+
+// Source returns SourceStatic.
+func (r *StaticReceiver) Source() Source {
+	return SourceStatic
+}
+
+// Diagnostics reports whether the static prefix is published.
+func (r *StaticReceiver) Diagnostics() []Diagnostics {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return []Diagnostics{{
+		Name:   string(SourceStatic),
+		Source: SourceStatic,
+		Active: r.currentPrefix != nil,
+	}}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+)
+
+func TestStaticReceiver(t *testing.T) {
+	r := NewStaticReceiver(netip.MustParsePrefix("2001:db8:1234:ff::1/56"))
+
+	if r.Source() != SourceStatic {
+		t.Errorf("Source() = %v, want %v", r.Source(), SourceStatic)
+	}
+
+	if r.CurrentPrefix() != nil {
+		t.Error("Expected nil prefix before Start")
+	}
+
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	want := netip.MustParsePrefix("2001:db8:1234::/56")
+	if got := r.CurrentPrefix(); got == nil || got.Network != want {
+		t.Fatalf("CurrentPrefix() = %v, want %v", got, want)
+	}
+
+	select {
+	case event := <-r.Events():
+		if event.Type != EventTypeAcquired {
+			t.Errorf("event.Type = %v, want %v", event.Type, EventTypeAcquired)
+		}
+	default:
+		t.Error("Expected acquired event after Start")
+	}
+
+	if err := r.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if r.CurrentPrefix() != nil {
+		t.Error("Expected nil prefix after Stop")
+	}
+}
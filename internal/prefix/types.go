@@ -29,6 +29,7 @@ const (
 	SourceDHCPv6PD            Source = "dhcpv6-pd"
 	SourceRouterAdvertisement Source = "router-advertisement"
 	SourceStatic              Source = "static"
+	SourceDNS                 Source = "dns"
 	SourceUnknown             Source = "unknown"
 )
 
@@ -48,6 +49,27 @@ type Prefix struct {
 
 	// ReceivedAt is when this prefix was received
 	ReceivedAt time.Time
+
+	// Config is network configuration delivered along with the prefix, if any
+	Config *NetworkConfig
+}
+
+// NetworkConfig is configuration data an upstream server delivered along with a prefix
+type NetworkConfig struct {
+	// DNSServers are the recursive DNS servers
+	DNSServers []netip.Addr
+
+	// DomainSearch is the domain search list
+	DomainSearch []string
+
+	// NTPServers are NTP server addresses or FQDNs
+	NTPServers []string
+
+	// SNTPServers are SNTP server addresses
+	SNTPServers []netip.Addr
+
+	// SolMaxRT is the maximum SOLICIT retransmission time requested by the server
+	SolMaxRT time.Duration
 }
 
 // Event represents a prefix-related event
@@ -58,6 +80,9 @@ type Event struct {
 	// Prefix is the prefix involved (may be nil for some events)
 	Prefix *Prefix
 
+	// PreviousPrefix is the prefix that was replaced (only set for changed events)
+	PreviousPrefix *Prefix
+
 	// Error contains any error (for failure events)
 	Error error
 }
@@ -90,3 +115,111 @@ type Receiver interface {
 	// Source returns the type of this receiver
 	Source() Source
 }
+
+// StandbyReceiver is a passive receiver that is only run to keep a shared
+// listener or observer warm; its results are read through the leader's receivers.
+type StandbyReceiver interface {
+	// Start begins listening
+	Start(ctx context.Context) error
+
+	// Stop stops listening
+	Stop() error
+}
+
+// FailoverStatus describes which source of a multi-source receiver is active.
+type FailoverStatus struct {
+	// ActiveSource is the name of the source currently providing the prefix
+	ActiveSource string
+
+	// Reason explains why the active source was last switched
+	Reason string
+
+	// LastFailover is when the active source was last switched
+	LastFailover time.Time
+}
+
+// FailoverReporter is implemented by receivers that choose between several sources.
+type FailoverReporter interface {
+	// FailoverStatus returns the current failover state
+	FailoverStatus() FailoverStatus
+}
+
+// Diagnostics contains troubleshooting information about a receiver.
+// Fields that do not apply to a receiver type are left empty.
+type Diagnostics struct {
+	// Name identifies the receiver (the source name for multi-source receivers)
+	Name string
+
+	// Source is the type of the receiver
+	Source Source
+
+	// Active is true if this receiver currently provides the prefix
+	Active bool
+
+	// Interface is the network interface the receiver runs on
+	Interface string
+
+	// ConsecutiveFailures is the number of failures since the last success
+	ConsecutiveFailures int
+
+	// LastError is the most recent error, if any
+	LastError string
+
+	// LastErrorTime is when the most recent error occurred
+	LastErrorTime time.Time
+
+	// LastRouterAdvertisement is when the last Router Advertisement was received
+	LastRouterAdvertisement time.Time
+
+	// Router is the link-local address of the router that sent the last Router Advertisement
+	Router string
+
+	// LastExchange is the DHCPv6 exchange that was last attempted (e.g., "solicit", "renew")
+	LastExchange string
+
+	// LastExchangeTime is when the last DHCPv6 exchange finished
+	LastExchangeTime time.Time
+
+	// LastExchangeResult is "success" or the error of the last DHCPv6 exchange
+	LastExchangeResult string
+
+	// ServerDUID identifies the DHCPv6 server that delegated the prefix
+	ServerDUID string
+
+	// T1 is the DHCPv6 renewal time of the current lease
+	T1 time.Duration
+
+	// T2 is the DHCPv6 rebind time of the current lease
+	T2 time.Duration
+
+	// LastQueryTime is when the DNS hostname was last resolved
+	LastQueryTime time.Time
+
+	// DNSAddress is the IPv6 address of the last successful DNS answer
+	DNSAddress string
+
+	// DNSTTL is the time to live of the last successful DNS answer
+	DNSTTL time.Duration
+}
+
+// PrefixHinter is implemented by receivers that can ask for a specific prefix.
+type PrefixHinter interface {
+	// SetPrefixHint sets the prefix to ask for when no prefix is held, typically
+	// the last one delegated before a restart. It must be called before Start.
+	SetPrefixHint(hint netip.Prefix)
+}
+
+// SourcePrefixHinter is implemented by receivers that combine several named sources.
+// A hint only makes sense for the source that delegated the prefix, so it is passed
+// to that source alone.
+type SourcePrefixHinter interface {
+	// SetSourcePrefixHint sets the prefix the named source asks for when no prefix
+	// is held. It must be called before Start.
+	SetSourcePrefixHint(name string, hint netip.Prefix)
+}
+
+// DiagnosticsReporter is implemented by receivers that expose diagnostics.
+type DiagnosticsReporter interface {
+	// Diagnostics returns one entry per underlying receiver
+	Diagnostics() []Diagnostics
+}
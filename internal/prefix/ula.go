@@ -0,0 +1,52 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"net/netip"
+)
+
+// ULAPrefixLength is the length of an RFC 4193 unique local prefix
+const ULAPrefixLength = 48
+
+// ulaRange holds the locally assigned unique local addresses (L bit set)
+var ulaRange = netip.MustParsePrefix("fd00::/8")
+
+// GenerateULAPrefix derives an RFC 4193 unique local /48 from the given seed.
+// As in RFC 4193 section 3.2.2, the 40-bit global ID is the least significant
+// 40 bits of a SHA-1 digest, so the same seed always yields the same prefix.
+func GenerateULAPrefix(seed []byte) netip.Prefix {
+	sum := sha1.Sum(seed)
+	var addr [16]byte
+	addr[0] = 0xfd
+	copy(addr[1:6], sum[len(sum)-5:])
+	return netip.PrefixFrom(netip.AddrFrom16(addr), ULAPrefixLength)
+}
+
+// ParseULAPrefix parses a unique local /48 in CIDR notation.
+func ParseULAPrefix(cidr string) (netip.Prefix, error) {
+	p, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid ULA prefix %q: %w", cidr, err)
+	}
+	if p.Bits() != ULAPrefixLength || !ulaRange.Contains(p.Addr()) {
+		return netip.Prefix{}, fmt.Errorf("ULA prefix %q is not a /%d within %s", cidr, ULAPrefixLength, ulaRange)
+	}
+	return p.Masked(), nil
+}
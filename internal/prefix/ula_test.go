@@ -0,0 +1,64 @@
+/*
+Copyright 2026 jr42.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"testing"
+)
+
+func TestGenerateULAPrefix(t *testing.T) {
+	p := GenerateULAPrefix([]byte("8c5b1d6e-4f1a-4c2e-9b7a-0d3e5f6a7b8c"))
+	if p.Bits() != 48 || !ulaRange.Contains(p.Addr()) {
+		t.Errorf("GenerateULAPrefix() = %s, want a /48 within fd00::/8", p)
+	}
+	if p != p.Masked() {
+		t.Errorf("GenerateULAPrefix() = %s, want no bits past the /48", p)
+	}
+	if again := GenerateULAPrefix([]byte("8c5b1d6e-4f1a-4c2e-9b7a-0d3e5f6a7b8c")); again != p {
+		t.Errorf("GenerateULAPrefix() = %s then %s, want the same prefix for the same seed", p, again)
+	}
+	if other := GenerateULAPrefix([]byte("another-uid")); other == p {
+		t.Errorf("GenerateULAPrefix() = %s for different seeds", other)
+	}
+}
+
+func TestParseULAPrefix(t *testing.T) {
+	tests := []struct {
+		cidr    string
+		want    string
+		wantErr bool
+	}{
+		{cidr: "fd12:3456:789a::/48", want: "fd12:3456:789a::/48"},
+		{cidr: "fd12:3456:789a:1::/48", want: "fd12:3456:789a::/48"},
+		{cidr: "fc00:1:2::/48", wantErr: true},
+		{cidr: "2001:db8::/48", wantErr: true},
+		{cidr: "fd12:3456:789a::/56", wantErr: true},
+		{cidr: "not-a-prefix", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cidr, func(t *testing.T) {
+			got, err := ParseULAPrefix(tt.cidr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseULAPrefix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("ParseULAPrefix() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}